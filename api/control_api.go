@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// ControlApi is struct for api of local control of manager,
+// served in unix socket with access restricted by file permissions
+type ControlApi struct {
+	socketPath string
+	control    libinterfaces.IManagerControl
+	router     *mux.Router
+	srv        *http.Server
+	logger     libinterfaces.ILogger
+}
+
+// NewControlApi return instance of control api
+func NewControlApi(socketPath string, control libinterfaces.IManagerControl, logger libinterfaces.ILogger) *ControlApi {
+	return &ControlApi{
+		socketPath: socketPath,
+		control:    control,
+		logger:     logger.Named("control_api"),
+	}
+}
+
+// Setup execute configuration for api
+func (c *ControlApi) Setup() error {
+	router := mux.NewRouter()
+	c.router = router
+	c.srv = &http.Server{
+		Handler:      router,
+		WriteTimeout: 60 * time.Second,
+		ReadTimeout:  30 * time.Second,
+	}
+	controlRoutes := NewControlRoutes(c.logger, c.control)
+	if err := controlRoutes.Setup(); err != nil {
+		return err
+	}
+	if err := controlRoutes.BuildRoutes(c.router); err != nil {
+		return err
+	}
+	return nil
+}
+
+// privateDir execute create of dir of socket only accessible by owner,
+// dir existing with access of others is restricted, the socket is
+// created with umask of process and is protected by dir until chmod
+func (c *ControlApi) privateDir() error {
+	dir := filepath.Dir(c.socketPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0077 == 0 {
+		return nil
+	}
+	// shared dirs like /tmp are not restricted
+	if info.Mode()&os.ModeSticky != 0 {
+		return fmt.Errorf("%w: %s", pkg.ErrControlDirShared, dir)
+	}
+	c.logger.Warn("restrict permission of dir of control socket", "trace", "docp-agent-os-instance.control_api.privateDir", "dir", dir, "mode", info.Mode().Perm().String())
+	return os.Chmod(dir, 0700)
+}
+
+// listen return listener in socket only accessible by owner
+func (c *ControlApi) listen() (net.Listener, error) {
+	if err := c.privateDir(); err != nil {
+		return nil, err
+	}
+	// remove socket left by previous process
+	if err := os.Remove(c.socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", c.socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(c.socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Run execute running the api
+func (c *ControlApi) Run() error {
+	listener, err := c.listen()
+	if err != nil {
+		return err
+	}
+	c.logger.Info("control api listening", "trace", "docp-agent-os-instance.control_api.Run", "socket", c.socketPath)
+	if err := c.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close execute shutdown of api
+func (c *ControlApi) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return c.srv.Shutdown(ctx)
+}
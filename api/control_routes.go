@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+
+	controllers "github.com/DelfiaProducts/docp-agent-os-instance/libs/controllers"
+	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/gorilla/mux"
+)
+
+// ControlRoutes is struct for routes the control of manager
+type ControlRoutes struct {
+	controller *controllers.ControlHttpController
+	control    libinterfaces.IManagerControl
+	logger     libinterfaces.ILogger
+}
+
+// NewControlRoutes return instance of control routers
+func NewControlRoutes(logger libinterfaces.ILogger, control libinterfaces.IManagerControl) *ControlRoutes {
+	return &ControlRoutes{
+		logger:  logger,
+		control: control,
+	}
+}
+
+// Setup execute configuration
+func (c *ControlRoutes) Setup() error {
+	controller := controllers.NewControlHttpController(c.logger, c.control)
+	if err := controller.Setup(); err != nil {
+		return err
+	}
+	c.controller = controller
+	return nil
+}
+
+// Status is handler for status
+func (c *ControlRoutes) Status(w http.ResponseWriter, r *http.Request) {
+	c.controller.Status(w, r)
+}
+
+// PlanActions is handler for plan actions
+func (c *ControlRoutes) PlanActions(w http.ResponseWriter, r *http.Request) {
+	c.controller.PlanActions(w, r)
+}
+
+// Tasks is handler for tasks
+func (c *ControlRoutes) Tasks(w http.ResponseWriter, r *http.Request) {
+	c.controller.Tasks(w, r)
+}
+
+// Errors is handler for errors
+func (c *ControlRoutes) Errors(w http.ResponseWriter, r *http.Request) {
+	c.controller.Errors(w, r)
+}
+
+// ForcePoll is handler for force poll
+func (c *ControlRoutes) ForcePoll(w http.ResponseWriter, r *http.Request) {
+	c.controller.ForcePoll(w, r)
+}
+
+// ForceMetadataSync is handler for force metadata sync
+func (c *ControlRoutes) ForceMetadataSync(w http.ResponseWriter, r *http.Request) {
+	c.controller.ForceMetadataSync(w, r)
+}
+
+// Pause is handler for pause
+func (c *ControlRoutes) Pause(w http.ResponseWriter, r *http.Request) {
+	c.controller.Pause(w, r)
+}
+
+// Resume is handler for resume
+func (c *ControlRoutes) Resume(w http.ResponseWriter, r *http.Request) {
+	c.controller.Resume(w, r)
+}
+
+// Update is handler for update
+func (c *ControlRoutes) Update(w http.ResponseWriter, r *http.Request) {
+	c.controller.Update(w, r)
+}
+
+// Rollback is handler for rollback
+func (c *ControlRoutes) Rollback(w http.ResponseWriter, r *http.Request) {
+	c.controller.Rollback(w, r)
+}
+
+// LogLevels is handler for log levels
+func (c *ControlRoutes) LogLevels(w http.ResponseWriter, r *http.Request) {
+	c.controller.LogLevels(w, r)
+}
+
+// SetLogLevel is handler for set log level
+func (c *ControlRoutes) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	c.controller.SetLogLevel(w, r)
+}
+
+// Diagnostics is handler for diagnostics
+func (c *ControlRoutes) Diagnostics(w http.ResponseWriter, r *http.Request) {
+	c.controller.Diagnostics(w, r)
+}
+
+// BuildRoutes execute build the routes control
+func (c *ControlRoutes) BuildRoutes(router *mux.Router) error {
+	router.HandleFunc(pkg.CONTROL_ROUTE_STATUS, c.Status).Methods("GET")
+	router.HandleFunc(pkg.CONTROL_ROUTE_PLAN, c.PlanActions).Methods("GET")
+	router.HandleFunc(pkg.CONTROL_ROUTE_TASKS, c.Tasks).Methods("GET")
+	router.HandleFunc(pkg.CONTROL_ROUTE_ERRORS, c.Errors).Methods("GET")
+	router.HandleFunc(pkg.CONTROL_ROUTE_POLL, c.ForcePoll).Methods("POST")
+	router.HandleFunc(pkg.CONTROL_ROUTE_METADATA_SYNC, c.ForceMetadataSync).Methods("POST")
+	router.HandleFunc(pkg.CONTROL_ROUTE_PAUSE, c.Pause).Methods("POST")
+	router.HandleFunc(pkg.CONTROL_ROUTE_RESUME, c.Resume).Methods("POST")
+	router.HandleFunc(pkg.CONTROL_ROUTE_UPDATE, c.Update).Methods("POST")
+	router.HandleFunc(pkg.CONTROL_ROUTE_ROLLBACK, c.Rollback).Methods("POST")
+	router.HandleFunc(pkg.CONTROL_ROUTE_LOG_LEVELS, c.LogLevels).Methods("GET")
+	router.HandleFunc(pkg.CONTROL_ROUTE_LOG_LEVELS, c.SetLogLevel).Methods("POST")
+	router.HandleFunc(pkg.CONTROL_ROUTE_DIAGNOSTICS, c.Diagnostics).Methods("POST")
+	return nil
+}
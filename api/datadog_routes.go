@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	controllers "github.com/DelfiaProducts/docp-agent-os-instance/libs/controllers"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 	"github.com/gorilla/mux"
 )
@@ -12,18 +13,20 @@ import (
 type DatadogRoutes struct {
 	controller *controllers.DatadogHttpController
 	logger     libinterfaces.ILogger
+	config     dto.RuntimeConfig
 }
 
 // NewDatadogRoutes return instance of datadog routers
-func NewDatadogRoutes(logger libinterfaces.ILogger) *DatadogRoutes {
+func NewDatadogRoutes(logger libinterfaces.ILogger, config dto.RuntimeConfig) *DatadogRoutes {
 	return &DatadogRoutes{
 		logger: logger,
+		config: config,
 	}
 }
 
 // Setup execute configuration
 func (d *DatadogRoutes) Setup() error {
-	controller := controllers.NewDatadogHttpController(d.logger)
+	controller := controllers.NewDatadogHttpController(d.logger, d.config)
 	if err := controller.Setup(); err != nil {
 		return err
 	}
@@ -41,6 +44,16 @@ func (d *DatadogRoutes) InstallTracer(w http.ResponseWriter, r *http.Request) {
 	d.controller.InstallTracer(w, r)
 }
 
+// UpgradeAgent is handler for upgrade agent datadog
+func (d *DatadogRoutes) UpgradeAgent(w http.ResponseWriter, r *http.Request) {
+	d.controller.UpgradeAgent(w, r)
+}
+
+// ApplyIntegrations is handler for apply integrations of agent datadog
+func (d *DatadogRoutes) ApplyIntegrations(w http.ResponseWriter, r *http.Request) {
+	d.controller.ApplyIntegrations(w, r)
+}
+
 // UninstallAgent is handler for uninstall agent datadog
 func (d *DatadogRoutes) UninstallAgent(w http.ResponseWriter, r *http.Request) {
 	d.controller.UninstallAgent(w, r)
@@ -55,8 +68,10 @@ func (d *DatadogRoutes) UpdateAgentConfigurations(w http.ResponseWriter, r *http
 func (d *DatadogRoutes) BuildRoutes(router *mux.Router) error {
 	route := router.PathPrefix("/datadog").Subrouter()
 	route.HandleFunc("/install", d.InstallAgent).Methods("POST")
+	route.HandleFunc("/upgrade", d.UpgradeAgent).Methods("POST")
 	route.HandleFunc("/uninstall", d.UninstallAgent).Methods("POST")
 	route.HandleFunc("/tracer/install", d.InstallTracer).Methods("POST")
 	route.HandleFunc("/configurations", d.UpdateAgentConfigurations).Methods("POST")
+	route.HandleFunc("/integrations", d.ApplyIntegrations).Methods("POST")
 	return nil
 }
@@ -7,22 +7,25 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 )
 
 // DocpApi is struct for api docp
 type DocpApi struct {
 	port   string
+	config dto.RuntimeConfig
 	router *mux.Router
 	srv    *http.Server
 	logger libinterfaces.ILogger
 }
 
-// NewDocpApi return instance of docp api
-func NewDocpApi(port string, logger libinterfaces.ILogger) *DocpApi {
+// NewDocpApi return instance of docp api in port of agent of config
+func NewDocpApi(config dto.RuntimeConfig, logger libinterfaces.ILogger) *DocpApi {
 	return &DocpApi{
-		port:   port,
-		logger: logger,
+		port:   config.AgentPort,
+		config: config,
+		logger: logger.Named("docp_api"),
 	}
 }
 
@@ -40,7 +43,7 @@ func (d *DocpApi) setupCommonRoutes() error {
 
 // setupDatadogRoutes execute configuration the routes datadog
 func (d *DocpApi) setupDatadogRoutes() error {
-	datadogRoutes := NewDatadogRoutes(d.logger)
+	datadogRoutes := NewDatadogRoutes(d.logger, d.config)
 	if err := datadogRoutes.Setup(); err != nil {
 		return err
 	}
@@ -1,11 +1,12 @@
 package builders
 
 import (
+	libdto "github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 	"github.com/DelfiaProducts/docp-agent-os-instance/operators"
 )
 
 // AgentOperatorBuilder return operator agent by distro
-func AgentOperatorBuilder() libinterfaces.IOperator {
-	return operators.NewAgentOperator()
+func AgentOperatorBuilder(config libdto.RuntimeConfig) libinterfaces.IOperator {
+	return operators.NewAgentOperator(config)
 }
@@ -3,10 +3,11 @@ package builders
 import (
 	"github.com/DelfiaProducts/docp-agent-os-instance/agents"
 
+	libdto "github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 )
 
 // AgentBuilder return agent by distro
-func AgentBuilder() libinterfaces.IAgent {
-	return agents.NewDocpAgent(AgentOperatorBuilder())
+func AgentBuilder(config libdto.RuntimeConfig) libinterfaces.IAgent {
+	return agents.NewDocpAgent(AgentOperatorBuilder(config))
 }
@@ -2,10 +2,11 @@ package builders
 
 import (
 	"github.com/DelfiaProducts/docp-agent-os-instance/agents"
+	libdto "github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 )
 
 // ManagerBuilder return manager by distro
-func ManagerBuilder() libinterfaces.IManager {
-	return agents.NewManagerAgent(ManagerOperatorBuilder())
+func ManagerBuilder(config libdto.RuntimeConfig) libinterfaces.IManager {
+	return agents.NewManagerAgent(ManagerOperatorBuilder(config))
 }
@@ -1,11 +1,12 @@
 package builders
 
 import (
+	libdto "github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 	"github.com/DelfiaProducts/docp-agent-os-instance/operators"
 )
 
 // ManagerOperatorBuilder return operator agent by distro
-func ManagerOperatorBuilder() libinterfaces.IOperator {
-	return operators.NewManagerOperator()
+func ManagerOperatorBuilder(config libdto.RuntimeConfig) libinterfaces.IOperator {
+	return operators.NewManagerOperator(config)
 }
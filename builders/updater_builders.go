@@ -2,10 +2,11 @@ package builders
 
 import (
 	"github.com/DelfiaProducts/docp-agent-os-instance/agents"
+	libdto "github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 )
 
 // UpdaterBuilder return updater by distro
-func UpdaterBuilder() libinterfaces.IUpdater {
-	return agents.NewUpdaterAgent(UpdaterOperatorBuilder())
+func UpdaterBuilder(config libdto.RuntimeConfig) libinterfaces.IUpdater {
+	return agents.NewUpdaterAgent(UpdaterOperatorBuilder(config))
 }
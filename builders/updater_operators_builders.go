@@ -1,11 +1,12 @@
 package builders
 
 import (
+	libdto "github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 	"github.com/DelfiaProducts/docp-agent-os-instance/operators"
 )
 
 // UpdaterOperatorBuilder return updater operator by distro
-func UpdaterOperatorBuilder() libinterfaces.IOperator {
-	return operators.NewUpdaterOperator()
+func UpdaterOperatorBuilder(config libdto.RuntimeConfig) libinterfaces.IOperator {
+	return operators.NewUpdaterOperator(config)
 }
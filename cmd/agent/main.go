@@ -1,9 +1,20 @@
 package main
 
-import "github.com/DelfiaProducts/docp-agent-os-instance/builders"
+import (
+	"fmt"
+	"os"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/builders"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
 
 func main() {
-	agent := builders.AgentBuilder()
+	config, err := utils.LoadRuntimeConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
+	}
+	agent := builders.AgentBuilder(config)
 	if agent == nil {
 		panic("agent not found")
 	}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/cli"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func main() {
+	docpctl := cli.NewDocpctl(os.Stdout, utils.NewDocpLoggerText(io.Discard))
+	if err := docpctl.Setup(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err.Error())
+		os.Exit(1)
+	}
+	if err := docpctl.Run(os.Args[1:]); err != nil {
+		if errors.Is(err, cli.ErrUsage) {
+			docpctl.Usage()
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "error:", err.Error())
+		os.Exit(1)
+	}
+}
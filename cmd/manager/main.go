@@ -1,9 +1,20 @@
 package main
 
-import "github.com/DelfiaProducts/docp-agent-os-instance/builders"
+import (
+	"fmt"
+	"os"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/builders"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
 
 func main() {
-	manager := builders.ManagerBuilder()
+	config, err := utils.LoadRuntimeConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(2)
+	}
+	manager := builders.ManagerBuilder(config)
 	if err := manager.Start(); err != nil {
 		panic(err)
 	}
@@ -2,25 +2,28 @@ package adapters
 
 import (
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/components"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 )
 
 // AgentAdapter is struct for adapter agent
 type AgentAdapter struct {
 	logger      interfaces.ILogger
+	config      dto.RuntimeConfig
 	osOperation interfaces.IOSOperation
 }
 
 // NewAgentAdapter return instance of agent adapter
-func NewAgentAdapter(logger interfaces.ILogger) *AgentAdapter {
+func NewAgentAdapter(logger interfaces.ILogger, config dto.RuntimeConfig) *AgentAdapter {
 	return &AgentAdapter{
-		logger: logger,
+		logger: logger.Named("agent_adapter"),
+		config: config,
 	}
 }
 
 // Prepare configure agent adapter
 func (a *AgentAdapter) Prepare() error {
-	osOperation, err := components.SystemOperation(a.logger)
+	osOperation, err := components.SystemOperation(a.logger, a.config)
 	if err != nil {
 		return err
 	}
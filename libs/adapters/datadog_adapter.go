@@ -13,27 +13,30 @@ import (
 type DatadogAdapter struct {
 	base64Client     *pkg.Base64Client
 	datadogOperation interfaces.IDatadogOperation
+	integrations     *components.DatadogIntegrations
 	logger           interfaces.ILogger
+	config           dto.RuntimeConfig
 	osOperation      interfaces.IOSOperation
 }
 
 // NewDatadogAdapter return instance of datadog adapter
-func NewDatadogAdapter(logger interfaces.ILogger) *DatadogAdapter {
+func NewDatadogAdapter(logger interfaces.ILogger, config dto.RuntimeConfig) *DatadogAdapter {
 	return &DatadogAdapter{
-		logger: logger,
+		logger: logger.Named("datadog_adapter"),
+		config: config,
 	}
 }
 
 // Setup execute configuration the adapter
 func (d *DatadogAdapter) Setup() error {
-	osOperation, err := components.SystemOperation(d.logger)
+	osOperation, err := components.SystemOperation(d.logger, d.config)
 	if err != nil {
 		return err
 	}
 	if err := osOperation.Setup(); err != nil {
 		return err
 	}
-	datadogOperation, err := components.DatadogOperation(d.logger)
+	datadogOperation, err := components.DatadogOperation(d.logger, d.config)
 	if err != nil {
 		return err
 	}
@@ -41,6 +44,11 @@ func (d *DatadogAdapter) Setup() error {
 		return err
 	}
 	d.datadogOperation = datadogOperation
+	integrations := components.NewDatadogIntegrations(d.logger, d.config)
+	if err := integrations.Setup(); err != nil {
+		return err
+	}
+	d.integrations = integrations
 	base64Client := pkg.NewBase64Client()
 	d.base64Client = base64Client
 	return nil
@@ -61,15 +69,25 @@ func (d *DatadogAdapter) IsActive() (bool, error) {
 }
 
 // InstallAgent execute install the agent in linux
-func (d *DatadogAdapter) InstallAgent(ddSite, ddApiKey string) error {
-	d.logger.Debug("install agent", "trace", "docp-agent-os-instance.datadog_linux_adapter.InstallAgent", "ddSite", ddSite, "ddApiKey", ddApiKey)
-	if err := d.datadogOperation.InstallAgent(ddSite, ddApiKey); err != nil {
+func (d *DatadogAdapter) InstallAgent(ddSite, ddApiKey, version, transactionId string) error {
+	d.logger.Debug("install agent", "trace", "docp-agent-os-instance.datadog_linux_adapter.InstallAgent", "ddSite", ddSite, "ddApiKey", ddApiKey, "version", version, "transactionId", transactionId)
+	if err := d.datadogOperation.InstallAgent(ddSite, ddApiKey, version, transactionId); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// UpgradeAgent execute upgrade or downgrade of agent to version pinned
+func (d *DatadogAdapter) UpgradeAgent(version, transactionId string) error {
+	d.logger.Debug("upgrade agent", "trace", "docp-agent-os-instance.datadog_linux_adapter.UpgradeAgent", "version", version, "transactionId", transactionId)
+	if err := d.datadogOperation.UpgradeAgent(version, transactionId); err != nil {
+		d.logger.Error("error in upgrade agent", "trace", "docp-agent-os-instance.datadog_linux_adapter.UpgradeAgent", "error", err.Error())
+		return err
+	}
+	return nil
+}
+
 // GetApmEnvVarsTracingLibrary get envs apm datadog in mode tracing library
 func (d *DatadogAdapter) GetApmEnvVarsTracingLibrary(envs []dto.DatadogEnvVars) (string, string, string) {
 	d.logger.Debug("get apm envs vars tracing library", "trace", "docp-agent-os-instance.datadog_linux_adapter.GetApmEnvVarsTracingLibrary", "envs", envs)
@@ -169,6 +187,23 @@ func (d *DatadogAdapter) UpdateConfigFileDatadog(filePath string) error {
 	return nil
 }
 
+// ApplyIntegrations execute apply of integrations of signal in
+// conf.d of datadog agent
+func (d *DatadogAdapter) ApplyIntegrations(integrations []dto.DatadogIntegration, transactionId string) error {
+	d.logger.Debug("apply integrations", "trace", "docp-agent-os-instance.datadog_linux_adapter.ApplyIntegrations", "integrations", len(integrations), "transactionId", transactionId)
+	datadogPath, err := d.datadogOperation.DiscoverDatadogConfigPath()
+	if err != nil {
+		d.logger.Error("error in discover datadog config path", "trace", "docp-agent-os-instance.datadog_linux_adapter.ApplyIntegrations", "error", err.Error())
+		return err
+	}
+	d.integrations.SetConfPath(datadogPath)
+	if err := d.integrations.Apply(integrations, transactionId); err != nil {
+		d.logger.Error("error in apply integrations", "trace", "docp-agent-os-instance.datadog_linux_adapter.ApplyIntegrations", "error", err.Error())
+		return err
+	}
+	return nil
+}
+
 // DPKGConfigure execute configure dpkg
 func (d *DatadogAdapter) DPKGConfigure() error {
 	d.logger.Debug("dpkg configure", "trace", "docp-agent-os-instance.datadog_linux_adapter.DPKGConfigure")
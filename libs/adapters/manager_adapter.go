@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,15 +24,21 @@ import (
 
 // ManagerAdapter is struct for manager adapter
 type ManagerAdapter struct {
+	config                   dto.RuntimeConfig
 	interval                 time.Duration
 	hostStats                *pkg.HostStats
 	chanMetadata             chan []byte
 	chanClose                chan struct{}
+	closeOnce                sync.Once
+	chanInterval             chan time.Duration
 	isClosed                 bool
 	wg                       *sync.WaitGroup
 	logger                   interfaces.ILogger
 	program                  *pkg.ExecProgram
 	osOperation              interfaces.IOSOperation
+	vendorDiscovery          interfaces.IVendorDiscovery
+	vendorOperation          interfaces.IVendorOperation
+	datadogIntegrations      *components.DatadogIntegrations
 	fileSystem               *pkg.FileSystem
 	ymlClient                *pkg.YmlClient
 	client                   *http.Client
@@ -39,19 +46,34 @@ type ManagerAdapter struct {
 	store                    *utils.Store
 	stateCheck               *services.StateCheckService
 	auth                     *services.AuthService
+	tokenManager             *services.TokenManager
 	utilityService           *services.UtilityService
+	diagnostics              *services.DiagnosticsService
 	docpApiPort              string
 	delay                    time.Duration
 	pendingTransactionEvents []dto.TransactionStatus
 	LockedEvents             bool
+	// updateRing is ring of host in rollout reported in metadata
+	updateRing   *dto.AgentUpdateRing
+	updateRingMu sync.Mutex
+	// datadogHealth is last health of datadog agent reported in metadata,
+	// datadogHealthRead is last health from status read of agent
+	datadogHealth     *dto.DatadogHealth
+	datadogHealthRead *dto.DatadogHealth
+	// datadogStatus is last status of agent read by check of health,
+	// shared with status of integrations of metadata
+	datadogStatus   *dto.DatadogAgentStatus
+	datadogStatusAt time.Time
+	datadogHealthMu sync.Mutex
 }
 
 // NewManagerAdapter return instance of linux manager adapter
-func NewManagerAdapter(logger interfaces.ILogger) *ManagerAdapter {
+func NewManagerAdapter(logger interfaces.ILogger, config dto.RuntimeConfig) *ManagerAdapter {
 	store := utils.NewStore()
 	store.StartCleanupGoroutine([]string{"metadata", "action", "signal"}, time.Minute*1)
 	return &ManagerAdapter{
-		logger:                   logger,
+		logger:                   logger.Named("manager_adapter"),
+		config:                   config,
 		store:                    store,
 		delay:                    time.Second * 1,
 		pendingTransactionEvents: make([]dto.TransactionStatus, 0),
@@ -61,25 +83,21 @@ func NewManagerAdapter(logger interfaces.ILogger) *ManagerAdapter {
 
 // Prepare configure manager adapter
 func (l *ManagerAdapter) Prepare() error {
-	interval, err := utils.GetCollectInterval()
-	if err != nil {
-		return err
-	}
-	ymlClient := pkg.NewYmlClient()
 	chanLinuxMetrics := make(chan []byte, 1)
-	chanClose := make(chan struct{}, 1)
+	chanClose := make(chan struct{})
 	wg := &sync.WaitGroup{}
 	hostStats := pkg.NewHostStats()
-	l.interval = interval
-	l.ymlClient = ymlClient
+	l.interval = l.config.Intervals.Collect
+	l.ymlClient = utils.NewConfigYmlClient(l.config.WorkDirPath)
 	l.hostStats = hostStats
 	l.chanMetadata = chanLinuxMetrics
 	l.chanClose = chanClose
+	l.chanInterval = make(chan time.Duration, 1)
 	l.isClosed = false
 	l.wg = wg
 	execProgram := pkg.NewExecProgram()
 	l.program = execProgram
-	osOperation, err := components.SystemOperation(l.logger)
+	osOperation, err := components.SystemOperation(l.logger, l.config)
 	if err != nil {
 		return err
 	}
@@ -87,33 +105,56 @@ func (l *ManagerAdapter) Prepare() error {
 		return err
 	}
 	l.osOperation = osOperation
-	fileSystem := pkg.NewFileSystem()
-	l.fileSystem = fileSystem
-	apiPort, err := utils.GetPortAgentApi()
-	if err != nil {
+	vendorDiscovery := components.NewVendorDiscovery(l.logger)
+	if err := vendorDiscovery.Setup(); err != nil {
 		return err
 	}
-	l.docpApiPort = apiPort
-	agentWorkDir, err := utils.GetWorkDirPath()
-	if err != nil {
+	l.vendorDiscovery = vendorDiscovery
+	datadogIntegrations := components.NewDatadogIntegrations(l.logger, l.config)
+	if err := datadogIntegrations.Setup(); err != nil {
 		return err
 	}
-	l.agentWorkDir = agentWorkDir
-	stateCheck := services.NewStateCheckService(l.logger)
+	l.datadogIntegrations = datadogIntegrations
+	vendorOperation, err := components.VendorOperation(l.logger)
+	if err != nil {
+		l.logger.Debug("vendor operation not available", "trace", "docp-agent-os-instance.manager_adapter.Prepare", "error", err.Error())
+	} else {
+		if err := vendorOperation.Setup(); err != nil {
+			return err
+		}
+		l.vendorOperation = vendorOperation
+	}
+	fileSystem := pkg.NewFileSystem()
+	l.fileSystem = fileSystem
+	l.docpApiPort = l.config.AgentPort
+	l.agentWorkDir = l.config.WorkDirPath
+	stateCheck := services.NewStateCheckService(l.logger, l.config)
 	if err := stateCheck.Setup(); err != nil {
 		return err
 	}
 	l.stateCheck = stateCheck
-	authService := services.NewAuthService(l.logger)
+	authService := services.NewAuthService(l.logger, l.config)
 	if err := authService.Setup(); err != nil {
 		return err
 	}
 	l.auth = authService
-	utilityService := services.NewUtilityService(l.logger)
+	tokenManager := services.NewTokenManager(l.logger, l.config)
+	if err := tokenManager.Setup(); err != nil {
+		return err
+	}
+	l.tokenManager = tokenManager
+	stateCheck.SetTokenManager(tokenManager)
+	utilityService := services.NewUtilityService(l.logger, l.config)
 	if err := utilityService.Setup(); err != nil {
 		return err
 	}
 	l.utilityService = utilityService
+	diagnostics := services.NewDiagnosticsService(l.logger, l.config)
+	if err := diagnostics.Setup(); err != nil {
+		return err
+	}
+	diagnostics.SetTokenManager(tokenManager)
+	l.diagnostics = diagnostics
 	client := &http.Client{
 		Timeout: time.Second * 30,
 	}
@@ -150,6 +191,25 @@ func (l *ManagerAdapter) unmarshaller(content []byte, inner any) error {
 	return nil
 }
 
+// getProcessFilter return process filter from config file,
+// without rules when config file is invalid
+func (l *ManagerAdapter) getProcessFilter() *pkg.ProcessFilter {
+	l.logger.Debug("get process filter", "trace", "docp-agent-os-instance.manager_adapter.getProcessFilter")
+	var inventoryConfig dto.ProcessInventoryConfig
+	configAgent, err := l.GetConfigAgent()
+	if err != nil {
+		l.logger.Debug("config agent not available for process filter", "trace", "docp-agent-os-instance.manager_adapter.getProcessFilter", "error", err.Error())
+	} else {
+		inventoryConfig = configAgent.ProcessInventory
+	}
+	filter, err := pkg.NewProcessFilter(inventoryConfig)
+	if err != nil {
+		l.logger.Error("error in process inventory config", "trace", "docp-agent-os-instance.manager_adapter.getProcessFilter", "error", err.Error())
+		filter, _ = pkg.NewProcessFilter(dto.ProcessInventoryConfig{})
+	}
+	return filter
+}
+
 // getInfos execute get the infos in host
 func (l *ManagerAdapter) getInfos() {
 	l.logger.Debug("get infos", "trace", "docp-agent-os-instance.manager_adapter.getInfos")
@@ -169,19 +229,33 @@ func (l *ManagerAdapter) getInfos() {
 	if err != nil {
 		l.logger.Error("error disk info", "trace", "docp-agent-os-instance.manager_adapter.getInfos", "error", err.Error())
 	}
-	processInfo, err := l.hostStats.ProcessInfo()
+	processInfo, processAggregates, detectedVendors, err := l.hostStats.ProcessInventory(l.getProcessFilter())
 	if err != nil {
 		l.logger.Error("error process info", "trace", "docp-agent-os-instance.manager_adapter.getInfos", "error", err.Error())
 	}
 	linuxMetadata := dto.Metadata{
-		ComputeInfo:  computeInfo,
-		CPUInfo:      cpuInfo,
-		MemoryInfo:   memoryInfo,
-		DiskInfo:     diskInfo,
-		ProcessInfos: processInfo,
+		ComputeInfo:       computeInfo,
+		CPUInfo:           cpuInfo,
+		MemoryInfo:        memoryInfo,
+		DiskInfo:          diskInfo,
+		ProcessInfos:      processInfo,
+		ProcessAggregates: processAggregates,
+		DetectedVendors:   detectedVendors,
+	}
+	// vendors of processes are from inventory, not scanned again
+	linuxMetadata.Vendors = l.vendorDiscovery.DiscoverFrom(detectedVendors)
+	linuxMetadata.UpdateRing = l.getUpdateRing()
+	if l.VerifyDatadogInstalled() {
+		integrations, err := l.DatadogIntegrationsStatus()
+		if err != nil {
+			l.logger.Error("error datadog integrations status", "trace", "docp-agent-os-instance.manager_adapter.getInfos", "error", err.Error())
+		}
+		linuxMetadata.Integrations = integrations
+		linuxMetadata.DatadogHealth = l.getDatadogHealth()
 	}
-	if !l.isClosed {
-		l.chanMetadata <- l.marshallerMetadata(linuxMetadata)
+	select {
+	case l.chanMetadata <- l.marshallerMetadata(linuxMetadata):
+	case <-l.chanClose:
 	}
 	l.wg.Done()
 }
@@ -189,29 +263,39 @@ func (l *ManagerAdapter) getInfos() {
 // firstGetInfos execute first get infos in host
 func (l *ManagerAdapter) firstGetInfos() {
 	l.logger.Debug("first get infos", "trace", "docp-agent-os-instance.manager_adapter.firstGetInfos")
-	l.wg.Add(1)
-	time.Sleep(time.Second * 5)
-	go l.getInfos()
-	l.wg.Done()
+	defer l.wg.Done()
+	select {
+	case <-time.After(time.Second * 5):
+		l.wg.Add(1)
+		go l.getInfos()
+	case <-l.chanClose:
+	}
 }
 
-// closeChannels closing channels
-func (l *ManagerAdapter) closeChannels() {
-	l.logger.Debug("close channels", "trace", "docp-agent-os-instance.manager_adapter.closeChannels")
-	close(l.chanClose)
-	close(l.chanMetadata)
-	l.isClosed = true
-	l.wg.Done()
+// closed return if collect loop is closed
+func (l *ManagerAdapter) closed() bool {
+	select {
+	case <-l.chanClose:
+		return true
+	default:
+		return false
+	}
 }
 
 // start execute loop for adapter
 func (l *ManagerAdapter) start() {
 	l.logger.Debug("start loop", "trace", "docp-agent-os-instance.manager_adapter.start")
+	defer l.wg.Done()
 	tick := time.NewTicker(l.interval)
+	defer tick.Stop()
 	for {
 		select {
 		case <-l.chanClose:
-			break
+			return
+		case interval := <-l.chanInterval:
+			l.logger.Info("collect interval changed", "trace", "docp-agent-os-instance.manager_adapter.start", "interval", interval.String())
+			l.interval = interval
+			tick.Reset(interval)
 		case <-tick.C:
 			l.wg.Add(1)
 			go l.getInfos()
@@ -219,55 +303,46 @@ func (l *ManagerAdapter) start() {
 	}
 }
 
+// SetCollectInterval execute change of interval of collect loop
+func (l *ManagerAdapter) SetCollectInterval(interval time.Duration) {
+	select {
+	case <-l.chanInterval:
+	default:
+	}
+	l.chanInterval <- interval
+}
+
 // IsLocked return if operator locked for send transactions events
 func (l *ManagerAdapter) IsLockedEvents() bool {
 	return l.LockedEvents
 }
 
+// PendingEventsCount return quantity of transaction events
+// waiting for send to state check
+func (l *ManagerAdapter) PendingEventsCount() int {
+	return len(l.pendingTransactionEvents)
+}
+
 // ExecuteAuthCall execute call to auth and save access token received
 func (l *ManagerAdapter) ExecuteAuthCall() error {
 	l.logger.Debug("execute auth call", "timestamp", time.Now())
-	configAgent, err := l.GetConfigAgent()
-	if err != nil {
-		return err
-	}
-
-	authPayload := dto.AuthPayload{}
-	authPayload.ApiKey = configAgent.Agent.ApiKey
-	authPayload.ComputeId = configAgent.ComputeId
-	resp, statusCode, err := l.auth.AuthCall(authPayload)
-	if err != nil {
+	if _, err := l.tokenManager.ForceRefresh(); err != nil {
 		return err
 	}
-
-	l.logger.Debug("execute auth call", "statusCode", statusCode, "resp", string(resp))
-	var authResponse dto.AuthResponse
-	switch statusCode {
-	case 200:
-		if err := l.unmarshaller(resp, &authResponse); err != nil {
-			return err
-		}
-
-		configAgent.AccessToken = authResponse.AccessToken
-		if err := l.UpdateConfigAgent(configAgent); err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
+// TokenManager return token manager of access token
+func (l *ManagerAdapter) TokenManager() *services.TokenManager {
+	return l.tokenManager
+}
+
 // NotifyStatus execute notify the status to state check
 func (l *ManagerAdapter) NotifyStatus(status string, typeEvent string, message string, ctx context.Context) error {
-	content, err := l.fileSystem.GetFileContent(filepath.Join(l.agentWorkDir, "config.yml"))
+	accessToken, err := l.tokenManager.GetToken()
 	if err != nil {
 		return err
 	}
-	var config dto.ConfigAgent
-
-	if err := l.ymlClient.Unmarshall(content, &config); err != nil {
-		return err
-	}
-	accessToken := config.AccessToken
 	if len(accessToken) > 0 {
 		transactionStatus := utils.GetTransactionFromContext(ctx)
 		if len(transactionStatus.ID) > 0 {
@@ -285,21 +360,8 @@ func (l *ManagerAdapter) NotifyStatus(status string, typeEvent string, message s
 					return err
 				}
 
-				switch statusCode {
-				case 403:
-					if err := l.ExecuteAuthCall(); err != nil {
-						l.logger.Error("execute auth call after notify received not authorized", "error", err.Error())
-						return err
-					}
-					// retry last transaction
-					res, statusCodeRetry, err := l.stateCheck.SendStatus(transactionStatus)
-					if err != nil {
-						l.logger.Error("notify status send status", "error", err.Error())
-						return err
-					}
-
-					l.logger.Debug("retry notify status", "timestamp", time.Now(), "response", string(res), "statusCodeRetry", statusCodeRetry)
-				case 500:
+				// not authorized and retryable status are retried by state check service
+				if statusCode == 500 {
 					l.LockedEvents = true
 					l.pendingTransactionEvents = append(l.pendingTransactionEvents, transactionStatus)
 				}
@@ -322,22 +384,33 @@ func (l *ManagerAdapter) Collect() <-chan []byte {
 	return l.chanMetadata
 }
 
-// Close closing collect loop
+// CollectNow execute collect the metrics the host immediately
+func (l *ManagerAdapter) CollectNow() {
+	l.logger.Debug("collect metadata now", "trace", "docp-agent-os-instance.manager_adapter.CollectNow")
+	if l.closed() {
+		return
+	}
+	l.wg.Add(1)
+	go l.getInfos()
+}
+
+// Close execute stop of collect loop, waiting collects in flight
+// before close the channel of metadata
 func (l *ManagerAdapter) Close() error {
 	l.logger.Debug("execute close", "trace", "docp-agent-os-instance.manager_adapter.Close")
-	l.chanClose <- struct{}{}
-	l.wg.Add(1)
-	go l.closeChannels()
+	l.closeOnce.Do(func() {
+		close(l.chanClose)
+		l.wg.Wait()
+		close(l.chanMetadata)
+		l.isClosed = true
+	})
 	return nil
 }
 
 // GetAgentVersion return version installed agent
 func (l *ManagerAdapter) GetAgentVersion() (string, error) {
 	var configAgent dto.ConfigAgent
-	configPath, err := utils.GetConfigFilePath()
-	if err != nil {
-		return "", err
-	}
+	configPath := l.config.ConfigFilePath
 
 	content, err := l.fileSystem.GetFileContent(configPath)
 	if err != nil {
@@ -354,10 +427,7 @@ func (l *ManagerAdapter) GetAgentVersion() (string, error) {
 // GetAgentRollbackVersion return rollback version installed agent
 func (l *ManagerAdapter) GetAgentRollbackVersion() (string, error) {
 	var configAgent dto.ConfigAgent
-	configPath, err := utils.GetConfigFilePath()
-	if err != nil {
-		return "", err
-	}
+	configPath := l.config.ConfigFilePath
 
 	content, err := l.fileSystem.GetFileContent(configPath)
 	if err != nil {
@@ -385,13 +455,56 @@ func (l *ManagerAdapter) GetAgentVersionFromSignalBytes(data []byte) (string, er
 	return version, nil
 }
 
+// GetDocpAgentFromSignalBytes return docp agent from signal
+func (l *ManagerAdapter) GetDocpAgentFromSignalBytes(data []byte) (dto.StateCheckDocpAgent, error) {
+	var stateCheckResponse dto.StateCheckResponse
+	if err := l.unmarshaller(data, &stateCheckResponse); err != nil {
+		return dto.StateCheckDocpAgent{}, err
+	}
+	return stateCheckResponse.Signal.Agents.DocpAgent, nil
+}
+
+// SetUpdateRing execute save of ring of host in rollout for metadata
+func (l *ManagerAdapter) SetUpdateRing(ring dto.AgentUpdateRing) {
+	l.updateRingMu.Lock()
+	defer l.updateRingMu.Unlock()
+	l.updateRing = &ring
+}
+
+// getUpdateRing return ring of host in rollout, nil when not resolved
+func (l *ManagerAdapter) getUpdateRing() *dto.AgentUpdateRing {
+	l.updateRingMu.Lock()
+	defer l.updateRingMu.Unlock()
+	return l.updateRing
+}
+
+// SaveUpdateTarget save version of update resolved for updater,
+// applied by updater before version of signal
+func (l *ManagerAdapter) SaveUpdateTarget(version string) error {
+	l.logger.Debug("save update target", "trace", "docp-agent-os-instance.manager_adapter.SaveUpdateTarget", "version", version)
+	return l.fileSystem.WriteFileContent(filepath.Join(l.agentWorkDir, "state", "update_target"), []byte(version))
+}
+
+// LoadReleases return manifest of releases installed
+func (l *ManagerAdapter) LoadReleases() (*utils.ReleaseManifest, error) {
+	return utils.LoadReleaseManifest(utils.GetReleasesDirPath(l.config.WorkDirPath))
+}
+
+// lockConfig execute lock of config file shared with writers
+// of config in other processes, return func for unlock
+func (l *ManagerAdapter) lockConfig() (func() error, error) {
+	return l.fileSystem.LockFile(utils.GetConfigLockPath(l.config.WorkDirPath))
+}
+
 // SaveAgentVersion save version installed agent
 func (l *ManagerAdapter) SaveAgentVersion(version string) error {
-	var configAgent dto.ConfigAgent
-	configPath, err := utils.GetConfigFilePath()
+	unlock, err := l.lockConfig()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+	var configAgent dto.ConfigAgent
+	configPath := l.config.ConfigFilePath
 
 	content, err := l.fileSystem.GetFileContent(configPath)
 	if err != nil {
@@ -404,6 +517,9 @@ func (l *ManagerAdapter) SaveAgentVersion(version string) error {
 
 	configAgent.Version = version
 
+	if err := l.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return err
+	}
 	ymlBytes, err := l.ymlClient.Marshall(&configAgent)
 	if err != nil {
 		return err
@@ -417,11 +533,13 @@ func (l *ManagerAdapter) SaveAgentVersion(version string) error {
 
 // SaveAgentRollbackVersion save rollback version installed agent
 func (l *ManagerAdapter) SaveAgentRollbackVersion(version string) error {
-	var configAgent dto.ConfigAgent
-	configPath, err := utils.GetConfigFilePath()
+	unlock, err := l.lockConfig()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+	var configAgent dto.ConfigAgent
+	configPath := l.config.ConfigFilePath
 
 	content, err := l.fileSystem.GetFileContent(configPath)
 	if err != nil {
@@ -434,6 +552,9 @@ func (l *ManagerAdapter) SaveAgentRollbackVersion(version string) error {
 
 	configAgent.RollbackVersion = version
 
+	if err := l.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return err
+	}
 	ymlBytes, err := l.ymlClient.Marshall(&configAgent)
 	if err != nil {
 		return err
@@ -573,9 +694,10 @@ func (l *ManagerAdapter) requestForAgentInstallDatadog(url string, method string
 	return respBytes, nil
 }
 
-// DocpAgentApiInstallDatadog execute call to api docp for install datadog agent
-func (l *ManagerAdapter) DocpAgentApiInstallDatadog(ddApiKey, ddSite string) ([]byte, error) {
-	l.logger.Debug("execute send request for install datadog agent", "trace", "docp-agent-os-instance.manager_adapter.DocpAgentApiInstallDatadog", "ddApiKey", ddApiKey, "ddSite", ddSite)
+// DocpAgentApiInstallDatadog execute call to api docp for install datadog agent,
+// version pinned like 7.52 or 7.52.1 is installed instead of latest
+func (l *ManagerAdapter) DocpAgentApiInstallDatadog(ddApiKey, ddSite, version string) ([]byte, error) {
+	l.logger.Debug("execute send request for install datadog agent", "trace", "docp-agent-os-instance.manager_adapter.DocpAgentApiInstallDatadog", "ddApiKey", ddApiKey, "ddSite", ddSite, "version", version)
 
 	transaction := utils.NewTransactionStatus()
 	ctx := context.WithValue(context.Background(), dto.ContextTransactionStatus, transaction)
@@ -584,8 +706,10 @@ func (l *ManagerAdapter) DocpAgentApiInstallDatadog(ddApiKey, ddSite string) ([]
 	time.Sleep(l.delay)
 
 	datadogDto := dto.DatadogInstallDTO{
-		DDSite:   ddSite,
-		DDApiKey: ddApiKey,
+		DDSite:        ddSite,
+		DDApiKey:      ddApiKey,
+		Version:       version,
+		TransactionId: transaction.ID,
 	}
 	bDatadogDto, err := l.marshaller(&datadogDto)
 	if err != nil {
@@ -603,10 +727,106 @@ func (l *ManagerAdapter) DocpAgentApiInstallDatadog(ddApiKey, ddSite string) ([]
 		return nil, err
 	}
 
-	go l.NotifyStatus("install_docp_vendor_completed", pkg.TransactionEventClose, "install docp vendor completed", ctx)
+	// steps of install and close of transaction are sent by docp agent
+	go l.NotifyStatus("install_docp_vendor_accepted", pkg.TransactionEventUpdate, "install docp vendor accepted", ctx)
+	return respBytes, nil
+}
+
+// DocpAgentApiUpgradeDatadog execute call to api docp for upgrade
+// or downgrade of datadog agent to version pinned, steps of upgrade
+// are sent by docp agent in transaction
+func (l *ManagerAdapter) DocpAgentApiUpgradeDatadog(version, transactionId string) ([]byte, error) {
+	l.logger.Debug("execute send request for upgrade datadog agent", "trace", "docp-agent-os-instance.manager_adapter.DocpAgentApiUpgradeDatadog", "version", version, "transactionId", transactionId)
+	bDatadogDto, err := l.marshaller(&dto.DatadogInstallDTO{Version: version, TransactionId: transactionId})
+	if err != nil {
+		return nil, err
+	}
+	urlDocpUpgradeDatadog := fmt.Sprintf("http://127.0.0.1:%s/datadog/upgrade", l.docpApiPort)
+	return l.requestForAgentInstallDatadog(urlDocpUpgradeDatadog, http.MethodPost, bDatadogDto)
+}
+
+// DatadogInstalledVersion return version of package of datadog
+// agent installed, empty when not installed or unknown
+func (l *ManagerAdapter) DatadogInstalledVersion() string {
+	version, _ := l.vendorDiscovery.PackageVersion("datadog")
+	return version
+}
+
+// DocpAgentApiApplyIntegrationsDatadog execute call to api docp for apply
+// of integrations of datadog agent, steps of apply and close of
+// transaction are sent by docp agent
+func (l *ManagerAdapter) DocpAgentApiApplyIntegrationsDatadog(integrations []dto.DatadogIntegration) ([]byte, error) {
+	l.logger.Debug("execute send request for apply integrations datadog agent", "trace", "docp-agent-os-instance.manager_adapter.DocpAgentApiApplyIntegrationsDatadog", "integrations", len(integrations))
+
+	transaction := utils.NewTransactionStatus()
+	ctx := context.WithValue(context.Background(), dto.ContextTransactionStatus, transaction)
+
+	go l.NotifyStatus("update_docp_vendor_integrations_received", pkg.TransactionEventOpen, "apply integrations received", ctx)
+	time.Sleep(l.delay)
+
+	bIntegrationsDto, err := l.marshaller(&dto.DatadogIntegrationsDTO{Integrations: integrations, TransactionId: transaction.ID})
+	if err != nil {
+		go l.NotifyStatus("update_docp_vendor_integrations_error", pkg.TransactionEventClose, "failed apply integrations", ctx)
+		return nil, err
+	}
+	urlDocpIntegrationsDatadog := fmt.Sprintf("http://127.0.0.1:%s/datadog/integrations", l.docpApiPort)
+	respBytes, err := l.requestForAgentInstallDatadog(urlDocpIntegrationsDatadog, http.MethodPost, bIntegrationsDto)
+	if err != nil {
+		go l.NotifyStatus("update_docp_vendor_integrations_error", pkg.TransactionEventClose, "failed apply integrations", ctx)
+		return nil, err
+	}
+	go l.NotifyStatus("update_docp_vendor_integrations_accepted", pkg.TransactionEventUpdate, "apply integrations accepted", ctx)
 	return respBytes, nil
 }
 
+// DatadogIntegrationsStatus return integrations of datadog agent and
+// status of checks from status of agent, status read by check of
+// health is used when recent
+func (l *ManagerAdapter) DatadogIntegrationsStatus() ([]dto.DatadogIntegrationStatus, error) {
+	l.datadogHealthMu.Lock()
+	status, statusAt := l.datadogStatus, l.datadogStatusAt
+	l.datadogHealthMu.Unlock()
+	if status != nil && time.Since(statusAt) < time.Second*pkg.DATADOG_STATUS_MAX_AGE {
+		return l.datadogIntegrations.StatusFrom(*status)
+	}
+	return l.datadogIntegrations.Status()
+}
+
+// CheckDatadogHealth return health of datadog agent from status of
+// agent and previous health, health is saved for metadata, new errors
+// of forwarder are counted from last status read of agent
+func (l *ManagerAdapter) CheckDatadogHealth() (dto.DatadogHealth, *dto.DatadogHealth) {
+	l.logger.Debug("check datadog health", "trace", "docp-agent-os-instance.manager_adapter.CheckDatadogHealth")
+	l.datadogHealthMu.Lock()
+	previous, previousRead := l.datadogHealth, l.datadogHealthRead
+	l.datadogHealthMu.Unlock()
+	var health dto.DatadogHealth
+	status, err := l.datadogIntegrations.AgentStatus()
+	if err != nil {
+		l.logger.Error("error datadog agent status", "trace", "docp-agent-os-instance.manager_adapter.CheckDatadogHealth", "error", err.Error())
+		health = utils.DatadogHealthUnavailable(err)
+	} else {
+		health = utils.DatadogHealth(status, previousRead)
+	}
+	l.datadogHealthMu.Lock()
+	defer l.datadogHealthMu.Unlock()
+	l.datadogHealth = &health
+	if err == nil {
+		l.datadogHealthRead = &health
+		l.datadogStatus = &status
+		l.datadogStatusAt = time.Now()
+	}
+	return health, previous
+}
+
+// getDatadogHealth return last health of datadog agent, nil when
+// not checked
+func (l *ManagerAdapter) getDatadogHealth() *dto.DatadogHealth {
+	l.datadogHealthMu.Lock()
+	defer l.datadogHealthMu.Unlock()
+	return l.datadogHealth
+}
+
 // DocpAgentApiInstallDatadog execute call to api docp for install datadog agent
 func (l *ManagerAdapter) DocpAgentApiInstallDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries string) ([]byte, error) {
 	l.logger.Debug("execute send request for install datadog agent with apm single step", "trace", "docp-agent-os-instance.manager_adapter.DocpAgentApiInstallDatadogWithApmSingleStep", "ddApiKey", ddApiKey, "ddSite", ddSite, "ddEnv", ddEnv, "ddApmInstrumentationEnabled", ddApmInstrumentationEnabled, "ddApmInstrumentationLibraries", ddApmInstrumentationLibraries)
@@ -830,6 +1050,15 @@ func (l *ManagerAdapter) GetStateCurrent() ([]byte, error) {
 	return res, nil
 }
 
+// GetStateReceivedAt return time of last state received
+func (l *ManagerAdapter) GetStateReceivedAt() (time.Time, error) {
+	info, err := os.Stat(filepath.Join(l.agentWorkDir, "state", "received"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 // SaveStateReceived execute save the state received
 func (l *ManagerAdapter) SaveStateReceived(stateData []byte) error {
 	l.logger.Debug("save state received", "trace", "docp-agent-os-instance.manager_adapter.SaveStateReceived", "stateData", string(stateData))
@@ -848,16 +1077,56 @@ func (l *ManagerAdapter) SaveStateCurrent(stateData []byte) error {
 	return nil
 }
 
-// ExisteOtherVendors verify if exist other vendors
+// ExisteOtherVendors verify if exist other vendors requested
+// for remove, all is expanded with vendors detected on host
 func (l *ManagerAdapter) ExisteOtherVendors() (bool, error) {
 	vendors, err := l.GetRemoveOtherVendors()
 	if err != nil {
 		return false, err
 	}
-	if vendors != nil && len(vendors) > 0 {
-		return true, nil
+	if len(vendors) == 0 {
+		return false, nil
 	}
-	return false, nil
+	detectedVendors, err := l.GetDetectedVendors()
+	if err != nil {
+		return false, err
+	}
+	l.logger.Debug("existe other vendors", "trace", "docp-agent-os-instance.manager_adapter.ExisteOtherVendors", "vendors", vendors, "detectedVendors", detectedVendors)
+	// same vendors removed by uninstall with other vendors
+	return len(utils.ExpandRemoveVendors(vendors, detectedVendors)) > 0, nil
+}
+
+// GetDetectedVendors return names of known vendors discovered on host
+// by service units, package database or processes
+func (l *ManagerAdapter) GetDetectedVendors() ([]string, error) {
+	l.logger.Debug("get detected vendors", "trace", "docp-agent-os-instance.manager_adapter.GetDetectedVendors")
+	vendors, err := l.DiscoverVendors()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(vendors))
+	for _, vendor := range vendors {
+		names = append(names, vendor.Name)
+	}
+	return names, nil
+}
+
+// UninstallOtherVendor execute uninstall recipe of monitoring vendor
+func (l *ManagerAdapter) UninstallOtherVendor(name string) error {
+	l.logger.Debug("uninstall other vendor", "trace", "docp-agent-os-instance.manager_adapter.UninstallOtherVendor", "vendor", name)
+	if l.vendorOperation == nil {
+		return pkg.ErrVendorNotSupported
+	}
+	if err := l.vendorOperation.UninstallVendor(name); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DiscoverVendors return monitoring vendors discovered on host
+func (l *ManagerAdapter) DiscoverVendors() ([]dto.VendorInfo, error) {
+	l.logger.Debug("discover vendors", "trace", "docp-agent-os-instance.manager_adapter.DiscoverVendors")
+	return l.vendorDiscovery.Discover()
 }
 
 // GetRemoveOtherVendors return other vendors from signal
@@ -881,6 +1150,13 @@ func (l *ManagerAdapter) GetRemoveOtherVendors() ([]string, error) {
 func (l *ManagerAdapter) prepareDocpAgentAction(stateCheckSignal dto.StateCheckSignal) dto.StateAction {
 	l.logger.Debug("prepare docp agent action", "trace", "docp-agent-os-instance.manager_adapter.prepareDocpAgentAction", "stateCheckSignal", stateCheckSignal)
 	if stateCheckSignal.TypeSignal == "update" {
+		if len(stateCheckSignal.Agents.DocpAgent.RollbackTo) > 0 {
+			return dto.StateAction{
+				Type:    "docp-agent",
+				Action:  "rollback",
+				Version: stateCheckSignal.Agents.DocpAgent.RollbackTo,
+			}
+		}
 		if len(stateCheckSignal.Agents.DocpAgent.Version) > 0 {
 			return dto.StateAction{
 				Type:    "docp-agent",
@@ -1083,6 +1359,7 @@ func (l *ManagerAdapter) prepareAgentDatadogAction(stateCheckSignal dto.StateChe
 				Type:          "datadog",
 				Action:        "install",
 				Mode:          "",
+				Version:       datadogAgent.Version,
 				Component:     "agent",
 				ComponentEnvs: componetEnvVars,
 				Envs:          envVars,
@@ -1135,6 +1412,30 @@ func (l *ManagerAdapter) prepareAgentDatadogUpdateAction(stateCheckSignal dto.St
 	return action
 }
 
+// prepareAgentDatadogIntegrationsAction execute prepare for integrations
+// of agent datadog, without integrations in signal action is only
+// prepared when integrations applied must be removed
+func (l *ManagerAdapter) prepareAgentDatadogIntegrationsAction(stateCheckSignal dto.StateCheckSignal) dto.StateAction {
+	l.logger.Debug("prepare agent datadog integrations action", "trace", "docp-agent-os-instance.manager_adapter.prepareAgentDatadogIntegrationsAction")
+	var action dto.StateAction
+	if stateCheckSignal.TypeSignal != "update" || len(stateCheckSignal.Agents.DatadogAgent.Version) == 0 {
+		return action
+	}
+	integrations := stateCheckSignal.Agents.DatadogAgent.Integrations
+	if len(integrations) == 0 {
+		managed, err := utils.LoadDatadogIntegrations(utils.GetDatadogIntegrationsFilePath(l.config.WorkDirPath))
+		if err != nil || len(managed) == 0 {
+			return action
+		}
+	}
+	return dto.StateAction{
+		Type:         "datadog",
+		Action:       pkg.ACTION_INTEGRATIONS,
+		Component:    "agent",
+		Integrations: integrations,
+	}
+}
+
 // removeAgentDatadogIfTracerSingleStepExists remove agent if tracer single step exists
 func (l *ManagerAdapter) removeAgentDatadogIfTracerSingleStepExists(arrStateActions []dto.StateAction) []dto.StateAction {
 	newArrStateActions := []dto.StateAction{}
@@ -1164,28 +1465,67 @@ func (l *ManagerAdapter) removeAgentDatadogIfTracerSingleStepExists(arrStateActi
 
 // GetActions return actions for agents
 func (l *ManagerAdapter) GetActions(stateCheckResponse *dto.StateCheckResponse) ([]dto.StateAction, error) {
-	l.logger.Debug("get actions", "trace", "docp-agent-os-instance.manager_adapter.GetActions", "stateCheckResponse", stateCheckResponse)
-	var arrStateActions []dto.StateAction
+	actions, _, err := l.getActions(stateCheckResponse, true, nil)
+	return actions, err
+}
+
+// PlanActions return actions for agents that would be executed
+// now, without register them as already executed
+func (l *ManagerAdapter) PlanActions() ([]dto.StateAction, error) {
+	l.logger.Debug("plan actions", "trace", "docp-agent-os-instance.manager_adapter.PlanActions")
+	var stateData dto.StateCheckResponse
+	content, err := l.GetStateReceived()
+	if err != nil {
+		return nil, err
+	}
+	if err := l.unmarshaller(content, &stateData); err != nil {
+		return nil, err
+	}
+	actions, _, err := l.getActions(&stateData, false, nil)
+	return actions, err
+}
+
+// setActionHash execute save hash of action executed, only when apply
+func (l *ManagerAdapter) setActionHash(apply bool, key string, hash string) error {
+	if !apply {
+		return nil
+	}
+	return l.SetStore(key, hash)
+}
+
+// getActions return actions for agents, when apply register
+// hash of actions for not repeat them, actions deferred are
+// returned apart and hash is not registered so they repeat later
+func (l *ManagerAdapter) getActions(stateCheckResponse *dto.StateCheckResponse, apply bool, deferred func(dto.StateAction) bool) ([]dto.StateAction, []dto.StateAction, error) {
+	l.logger.Debug("get actions", "trace", "docp-agent-os-instance.manager_adapter.getActions", "stateCheckResponse", stateCheckResponse, "apply", apply)
+	var arrStateActions, arrDeferredActions []dto.StateAction
+	addAction := func(action dto.StateAction, key string, hash string) error {
+		if deferred != nil && deferred(action) {
+			arrDeferredActions = append(arrDeferredActions, action)
+			return nil
+		}
+		arrStateActions = append(arrStateActions, action)
+		return l.setActionHash(apply, key, hash)
+	}
 
 	// prepare docp agent action
 	docpAgentAction := l.prepareDocpAgentAction(stateCheckResponse.Signal)
 	lastDocpAgentActionHash := l.GetStore("action.docp.state")
 	docpAgentActionBytes, err := l.marshaller(&docpAgentAction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	newDocpAgentActionHash := utils.GenerateMd5Hash(docpAgentActionBytes)
 	if newDocpAgentActionHash != lastDocpAgentActionHash {
-		arrStateActions = append(arrStateActions, docpAgentAction)
-		if err := l.SetStore("action.docp.state", newDocpAgentActionHash); err != nil {
-			return nil, err
+		if err := addAction(docpAgentAction, "action.docp.state", newDocpAgentActionHash); err != nil {
+			return nil, nil, err
 		}
 	}
 	// validate datadog installed
 	alreadyInstalled, err := l.AlreadyInstalled("datadog")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// prepare datadog agent action
@@ -1193,14 +1533,13 @@ func (l *ManagerAdapter) GetActions(stateCheckResponse *dto.StateCheckResponse)
 	lastAgentDatadogActionHash := l.GetStore("action.datadog.state")
 	datadogAgentActionBytes, err := l.marshaller(&agentDatadogAction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	newAgentDatadogActionHash := fmt.Sprintf("%s.%v", utils.GenerateMd5Hash(datadogAgentActionBytes), alreadyInstalled)
 	if newAgentDatadogActionHash != lastAgentDatadogActionHash {
-		arrStateActions = append(arrStateActions, agentDatadogAction)
-		if err := l.SetStore("action.datadog.state", newAgentDatadogActionHash); err != nil {
-			return nil, err
+		if err := addAction(agentDatadogAction, "action.datadog.state", newAgentDatadogActionHash); err != nil {
+			return nil, nil, err
 		}
 	}
 
@@ -1209,15 +1548,31 @@ func (l *ManagerAdapter) GetActions(stateCheckResponse *dto.StateCheckResponse)
 	lastAgentDatadogUpdateActionHash := l.GetStore("action.datadog.update")
 	datadogUpdateAgentActionBytes, err := l.marshaller(&agentDatadogUpdateAction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	newAgentDatadogUpdateActionHash := utils.GenerateMd5Hash(datadogUpdateAgentActionBytes)
 	if newAgentDatadogUpdateActionHash != lastAgentDatadogUpdateActionHash {
 		if alreadyInstalled {
-			arrStateActions = append(arrStateActions, agentDatadogUpdateAction)
-			if err := l.SetStore("action.datadog.update", newAgentDatadogUpdateActionHash); err != nil {
-				return nil, err
+			if err := addAction(agentDatadogUpdateAction, "action.datadog.update", newAgentDatadogUpdateActionHash); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// prepare datadog integrations action
+	agentDatadogIntegrationsAction := l.prepareAgentDatadogIntegrationsAction(stateCheckResponse.Signal)
+	lastAgentDatadogIntegrationsActionHash := l.GetStore("action.datadog.integrations")
+	datadogIntegrationsActionBytes, err := l.marshaller(&agentDatadogIntegrationsAction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newAgentDatadogIntegrationsActionHash := utils.GenerateMd5Hash(datadogIntegrationsActionBytes)
+	if newAgentDatadogIntegrationsActionHash != lastAgentDatadogIntegrationsActionHash {
+		if alreadyInstalled && len(agentDatadogIntegrationsAction.Action) > 0 {
+			if err := addAction(agentDatadogIntegrationsAction, "action.datadog.integrations", newAgentDatadogIntegrationsActionHash); err != nil {
+				return nil, nil, err
 			}
 		}
 	}
@@ -1227,14 +1582,13 @@ func (l *ManagerAdapter) GetActions(stateCheckResponse *dto.StateCheckResponse)
 	lastTracerDatadogLibraryActionHash := l.GetStore("action.datadog.tracer.library")
 	tracerDatadogLibraryActionBytes, err := l.marshaller(&tracerDatadogLibraryAction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	newTracerDatadogLibraryActionHash := utils.GenerateMd5Hash(tracerDatadogLibraryActionBytes)
 	if newTracerDatadogLibraryActionHash != lastTracerDatadogLibraryActionHash {
-		arrStateActions = append(arrStateActions, tracerDatadogLibraryAction)
-		if err := l.SetStore("action.datadog.tracer.library", newTracerDatadogLibraryActionHash); err != nil {
-			return nil, err
+		if err := addAction(tracerDatadogLibraryAction, "action.datadog.tracer.library", newTracerDatadogLibraryActionHash); err != nil {
+			return nil, nil, err
 		}
 	}
 
@@ -1243,14 +1597,13 @@ func (l *ManagerAdapter) GetActions(stateCheckResponse *dto.StateCheckResponse)
 	lastTracerDatadogSingleStepActionHash := l.GetStore("action.datadog.tracer.single.step")
 	tracerDatadogSingleStepActionBytes, err := l.marshaller(&tracerDatadogSingleStepAction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	newTracerDatadogSingleStepActionHash := utils.GenerateMd5Hash(tracerDatadogSingleStepActionBytes)
 	if newTracerDatadogSingleStepActionHash != lastTracerDatadogSingleStepActionHash {
-		arrStateActions = append(arrStateActions, tracerDatadogSingleStepAction)
-		if err := l.SetStore("action.datadog.tracer.single.step", newTracerDatadogSingleStepActionHash); err != nil {
-			return nil, err
+		if err := addAction(tracerDatadogSingleStepAction, "action.datadog.tracer.single.step", newTracerDatadogSingleStepActionHash); err != nil {
+			return nil, nil, err
 		}
 	}
 
@@ -1258,7 +1611,7 @@ func (l *ManagerAdapter) GetActions(stateCheckResponse *dto.StateCheckResponse)
 	l.logger.Debug("get actions", "trace", "docp-agent-os-instance.manager_adapter.GetActions", "docpAgentAction", docpAgentAction, "agentDatadogAction", agentDatadogAction, "agentDatadogUpdateAction", agentDatadogUpdateAction, "tracerDatadogLibraryAction", tracerDatadogLibraryAction, "tracerDatadogSingleStepAction", tracerDatadogSingleStepAction)
 	l.logger.Debug("get actions", "trace", "docp-agent-os-instance.manager_adapter.GetActions", "arrStateActions", arrStateActions)
 
-	return arrStateActionsFiltered, nil
+	return arrStateActionsFiltered, arrDeferredActions, nil
 }
 
 // SaveState save state from state check
@@ -1272,31 +1625,51 @@ func (l *ManagerAdapter) SaveState(data []byte) error {
 
 // GetState get state from state check
 func (l *ManagerAdapter) GetState() ([]byte, error) {
-	l.logger.Debug("get state", "trace", "docp-agent-os-instance.manager_adapter.GetState")
+	actions, _, err := l.GetStateDeferring(nil)
+	return actions, err
+}
+
+// GetStateDeferring get state from state check, actions where deferred
+// return true are not executed now and are returned apart
+func (l *ManagerAdapter) GetStateDeferring(deferred func(dto.StateAction) bool) ([]byte, []dto.StateAction, error) {
+	l.logger.Debug("get state", "trace", "docp-agent-os-instance.manager_adapter.GetStateDeferring")
 	var stateData dto.StateCheckResponse
 
 	// get state received
 	pathStateReceived := filepath.Join(l.agentWorkDir, "state", "received")
 	content, err := l.fileSystem.GetFileContent(pathStateReceived)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := l.unmarshaller(content, &stateData); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	stateActions, err := l.GetActions(&stateData)
+	stateActions, deferredActions, err := l.getActions(&stateData, true, deferred)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	bStateActions, err := l.marshaller(&stateActions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return bStateActions, nil
+	return bStateActions, deferredActions, nil
+}
+
+// GetDatadogAgentFromReceived return datadog agent of signal received
+func (l *ManagerAdapter) GetDatadogAgentFromReceived() (dto.StateCheckDatadogAgent, error) {
+	var agentState dto.StateCheckResponse
+	content, err := l.GetStateReceived()
+	if err != nil {
+		return dto.StateCheckDatadogAgent{}, err
+	}
+	if err := l.unmarshaller(content, &agentState); err != nil {
+		return dto.StateCheckDatadogAgent{}, err
+	}
+	return agentState.Signal.Agents.DatadogAgent, nil
 }
 
 // GetDatadogStateFromReceived get state datadog from received file
@@ -1426,10 +1799,7 @@ func (l *ManagerAdapter) Validate() error {
 	docpAgentIsOK := false
 	datadogAgentIsOK := false
 
-	workdir, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
+	workdir := l.config.WorkDirPath
 
 	receivedFilePath := filepath.Join(workdir, "state", "received")
 	currentFilePath := filepath.Join(workdir, "state", "current")
@@ -1554,6 +1924,11 @@ func (l *ManagerAdapter) IsAlreadyCreated() (bool, error) {
 // SaveAlreadyTracer save already tracer on config file
 func (l *ManagerAdapter) SaveAlreadyTracer(value bool) error {
 	l.logger.Debug("save already tracer", "trace", "docp-agent-os-instance.manager_adapter.SaveAlreadyTracer")
+	unlock, err := l.lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	var configAgent dto.ConfigAgent
 	pathConfigFile := filepath.Join(l.agentWorkDir, "config.yml")
 	contentConfigAgent, err := l.fileSystem.GetFileContent(pathConfigFile)
@@ -1566,6 +1941,9 @@ func (l *ManagerAdapter) SaveAlreadyTracer(value bool) error {
 
 	configAgent.AlreadyTracer = value
 
+	if err := l.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return err
+	}
 	newConfigAgentBytes, err := l.ymlClient.Marshall(&configAgent)
 	if err != nil {
 		return err
@@ -1595,6 +1973,11 @@ func (l *ManagerAdapter) GetAlreadyTracer() (bool, error) {
 // SaveInitialConfigFromRegister save initial config from register in file
 func (l *ManagerAdapter) SaveInitialConfigFromRegister(data []byte) error {
 	l.logger.Debug("save initial config from register", "trace", "docp-agent-os-instance.manager_adapter.SaveInitialConfigFromRegister", "data", string(data))
+	unlock, err := l.lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	var configAgent dto.ConfigAgent
 	var registerDataResponseSuccess dto.AgentRegisterDataResponseSuccess
 	pathConfigFile := filepath.Join(l.agentWorkDir, "config.yml")
@@ -1619,6 +2002,9 @@ func (l *ManagerAdapter) SaveInitialConfigFromRegister(data []byte) error {
 	configAgent.AlreadyCreated = true
 	configAgent.AlreadyTracer = false
 
+	if err := l.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return err
+	}
 	newConfigAgentBytes, err := l.ymlClient.Marshall(&configAgent)
 	if err != nil {
 		return err
@@ -1626,6 +2012,7 @@ func (l *ManagerAdapter) SaveInitialConfigFromRegister(data []byte) error {
 	if err := l.fileSystem.WriteFileContent(pathConfigFile, newConfigAgentBytes); err != nil {
 		return err
 	}
+	l.tokenManager.SetToken(token)
 	return nil
 }
 
@@ -1653,6 +2040,11 @@ func (l *ManagerAdapter) ExistTracerLanguage(language string) (bool, error) {
 // AddTracerLanguage append tracer language in slice the config file
 func (l *ManagerAdapter) AddTracerLanguage(language string) error {
 	l.logger.Debug("add tracer language", "trace", "docp-agent-os-instance.manager_adapter.AddTracerLanguage", "language", language)
+	unlock, err := l.lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	var configAgent dto.ConfigAgent
 	pathConfigFile := filepath.Join(l.agentWorkDir, "config.yml")
 	contentConfigAgent, err := l.fileSystem.GetFileContent(pathConfigFile)
@@ -1674,6 +2066,9 @@ func (l *ManagerAdapter) AddTracerLanguage(language string) error {
 	}
 	configAgent.TracerLanguages = slice
 
+	if err := l.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return err
+	}
 	bytYml, err := l.ymlClient.Marshall(&configAgent)
 	if err != nil {
 		return err
@@ -1688,6 +2083,11 @@ func (l *ManagerAdapter) AddTracerLanguage(language string) error {
 // ClearTracerLanguage append tracer language in slice the config file
 func (l *ManagerAdapter) ClearTracerLanguage() error {
 	l.logger.Debug("clear tracer language", "trace", "docp-agent-os-instance.manager_adapter.ClearTracerLanguage")
+	unlock, err := l.lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	var configAgent dto.ConfigAgent
 	pathConfigFile := filepath.Join(l.agentWorkDir, "config.yml")
 	contentConfigAgent, err := l.fileSystem.GetFileContent(pathConfigFile)
@@ -1699,6 +2099,9 @@ func (l *ManagerAdapter) ClearTracerLanguage() error {
 	}
 	configAgent.TracerLanguages = []string{}
 
+	if err := l.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return err
+	}
 	bytYml, err := l.ymlClient.Marshall(&configAgent)
 	if err != nil {
 		return err
@@ -1743,8 +2146,16 @@ func (l *ManagerAdapter) GetConfigAgent() (dto.ConfigAgent, error) {
 // UpdateConfigAgent update config agent for file
 func (l *ManagerAdapter) UpdateConfigAgent(configAgent dto.ConfigAgent) error {
 	l.logger.Debug("update config agent", "trace", "docp-agent-os-instance.manager_adapter.UpdateConfigAgent")
+	unlock, err := l.lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	pathConfigFile := filepath.Join(l.agentWorkDir, "config.yml")
 
+	if err := l.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return err
+	}
 	configBytes, err := l.ymlClient.Marshall(&configAgent)
 	if err != nil {
 		return err
@@ -1772,3 +2183,190 @@ func (l *ManagerAdapter) HandlerSCMManager() error {
 	}
 	return nil
 }
+
+// SetStopHandler configure func called when service manager of os
+// request stop and channel closed when shutdown is finished
+func (l *ManagerAdapter) SetStopHandler(stop func(), stopped <-chan struct{}) {
+	l.osOperation.SetStopHandler(stop, stopped)
+}
+
+// SaveInterruptedTasks execute save of tasks interrupted by shutdown
+func (l *ManagerAdapter) SaveInterruptedTasks(tasks []dto.ControlTask) error {
+	l.logger.Debug("save interrupted tasks", "trace", "docp-agent-os-instance.manager_adapter.SaveInterruptedTasks", "tasks", len(tasks))
+	content, err := l.marshaller(tasks)
+	if err != nil {
+		return err
+	}
+	return l.fileSystem.WriteFileContent(filepath.Join(l.agentWorkDir, "state", "interrupted"), content)
+}
+
+// TakeInterruptedTasks return tasks interrupted by last shutdown,
+// removing the checkpoint file
+func (l *ManagerAdapter) TakeInterruptedTasks() ([]dto.ControlTask, error) {
+	l.logger.Debug("take interrupted tasks", "trace", "docp-agent-os-instance.manager_adapter.TakeInterruptedTasks")
+	var tasks []dto.ControlTask
+	checkpointPath := filepath.Join(l.agentWorkDir, "state", "interrupted")
+	content, err := os.ReadFile(checkpointPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return tasks, nil
+		}
+		return tasks, err
+	}
+	if err := l.unmarshaller(content, &tasks); err != nil {
+		return tasks, err
+	}
+	return tasks, os.Remove(checkpointPath)
+}
+
+// GetDiagnosticsRequest return id of diagnostics request in state received,
+// empty when not requested or already uploaded
+func (l *ManagerAdapter) GetDiagnosticsRequest() (string, error) {
+	l.logger.Debug("get diagnostics request", "trace", "docp-agent-os-instance.manager_adapter.GetDiagnosticsRequest")
+	var stateData dto.StateCheckResponse
+	content, err := l.GetStateReceived()
+	if err != nil {
+		return "", err
+	}
+	if err := l.unmarshaller(content, &stateData); err != nil {
+		return "", err
+	}
+	diagnostics := stateData.Signal.Diagnostics
+	if diagnostics == nil || len(diagnostics.RequestId) == 0 {
+		return "", nil
+	}
+	lastRequestId, err := l.fileSystem.GetFileContent(filepath.Join(l.agentWorkDir, "state", "diagnostics"))
+	if err == nil && string(lastRequestId) == diagnostics.RequestId {
+		return "", nil
+	}
+	// request failed waits backoff before next attempt
+	attempts := l.getDiagnosticsAttempts()
+	if attempts.RequestId == diagnostics.RequestId && time.Now().Before(attempts.NextAt) {
+		return "", nil
+	}
+	return diagnostics.RequestId, nil
+}
+
+// getDiagnosticsAttempts return failed attempts of last diagnostics
+// request, empty when not exists
+func (l *ManagerAdapter) getDiagnosticsAttempts() dto.DiagnosticsAttempts {
+	var attempts dto.DiagnosticsAttempts
+	content, err := l.fileSystem.GetFileContent(filepath.Join(l.agentWorkDir, "state", "diagnostics_attempts"))
+	if err != nil {
+		return attempts
+	}
+	if err := l.unmarshaller(content, &attempts); err != nil {
+		return dto.DiagnosticsAttempts{}
+	}
+	return attempts
+}
+
+// saveDiagnosticsAttempts execute save of failed attempts of diagnostics request
+func (l *ManagerAdapter) saveDiagnosticsAttempts(attempts dto.DiagnosticsAttempts) error {
+	content, err := l.marshaller(&attempts)
+	if err != nil {
+		return err
+	}
+	return l.fileSystem.WriteFileContent(filepath.Join(l.agentWorkDir, "state", "diagnostics_attempts"), content)
+}
+
+// GetMaintenanceConfig return maintenance windows from signal
+// received, or from config file when signal not define windows,
+// urgent is only defined by signal
+func (l *ManagerAdapter) GetMaintenanceConfig() (dto.MaintenanceConfig, error) {
+	l.logger.Debug("get maintenance config", "trace", "docp-agent-os-instance.manager_adapter.GetMaintenanceConfig")
+	var maintenance dto.MaintenanceConfig
+	var stateData dto.StateCheckResponse
+	content, err := l.GetStateReceived()
+	if err == nil && len(content) > 0 {
+		if err := l.unmarshaller(content, &stateData); err != nil {
+			return dto.MaintenanceConfig{}, err
+		}
+		if stateData.Signal.Maintenance != nil {
+			maintenance = *stateData.Signal.Maintenance
+		}
+	}
+	if len(maintenance.Windows) > 0 {
+		return maintenance, nil
+	}
+	configAgent, err := l.GetConfigAgent()
+	if err != nil {
+		return dto.MaintenanceConfig{}, err
+	}
+	maintenance.Timezone = configAgent.Maintenance.Timezone
+	maintenance.Windows = configAgent.Maintenance.Windows
+	return maintenance, nil
+}
+
+// UploadDiagnostics execute collect and upload of diagnostics bundle
+// with transaction of status, when retry failed attempts are retried
+// with backoff in same transaction until max of attempts, client
+// errors of upload are not retried
+func (l *ManagerAdapter) UploadDiagnostics(requestId string, retry bool) error {
+	l.logger.Info("upload diagnostics", "trace", "docp-agent-os-instance.manager_adapter.UploadDiagnostics", "requestId", requestId)
+	attempts := l.getDiagnosticsAttempts()
+	if attempts.RequestId != requestId {
+		attempts = dto.DiagnosticsAttempts{RequestId: requestId, TransactionId: utils.NewTransactionStatus().ID}
+	}
+	transaction := dto.TransactionStatus{ID: attempts.TransactionId}
+	ctx := context.WithValue(context.Background(), dto.ContextTransactionStatus, transaction)
+
+	if attempts.Attempts == 0 {
+		go l.NotifyStatus("diagnostics_received", pkg.TransactionEventOpen, "diagnostics received", ctx)
+		time.Sleep(l.delay)
+	}
+
+	statuses := make(map[string]string)
+	for _, service := range []string{"manager", "agent", "datadog"} {
+		status, err := l.Status(service)
+		if err != nil {
+			status = fmt.Sprintf("unknown: %s", err.Error())
+		}
+		statuses[service] = status
+	}
+	bundle, err := l.diagnostics.Collect(requestId, statuses)
+	if err != nil {
+		return l.failDiagnostics(ctx, attempts, retry, false, fmt.Errorf("collect diagnostics: %w", err))
+	}
+	go l.NotifyStatus("diagnostics_processing", pkg.TransactionEventUpdate, "diagnostics uploading", ctx)
+	time.Sleep(l.delay)
+
+	// not authorized is retried by diagnostics service with token refreshed
+	_, statusCode, err := l.diagnostics.Upload(transaction, requestId, bundle)
+	if err != nil {
+		return l.failDiagnostics(ctx, attempts, retry, false, fmt.Errorf("upload diagnostics: %w", err))
+	}
+	if statusCode >= http.StatusBadRequest {
+		terminal := statusCode < http.StatusInternalServerError && statusCode != http.StatusUnauthorized && statusCode != http.StatusForbidden
+		return l.failDiagnostics(ctx, attempts, retry, terminal, fmt.Errorf("upload diagnostics status code %d", statusCode))
+	}
+	if err := l.fileSystem.WriteFileContent(filepath.Join(l.agentWorkDir, "state", "diagnostics"), []byte(requestId)); err != nil {
+		return err
+	}
+	go l.NotifyStatus("diagnostics_completed", pkg.TransactionEventClose, "diagnostics uploaded", ctx)
+	return nil
+}
+
+// failDiagnostics execute register of failed attempt of diagnostics
+// request, next attempt wait backoff doubled by attempt, request
+// terminal or with max of attempts is closed as failed
+func (l *ManagerAdapter) failDiagnostics(ctx context.Context, attempts dto.DiagnosticsAttempts, retry bool, terminal bool, err error) error {
+	attempts.Attempts++
+	if retry && !terminal && attempts.Attempts < pkg.DIAGNOSTICS_MAX_ATTEMPTS {
+		backoff := time.Second * pkg.DIAGNOSTICS_RETRY_BACKOFF << (attempts.Attempts - 1)
+		attempts.NextAt = time.Now().Add(min(backoff, time.Second*pkg.DIAGNOSTICS_MAX_BACKOFF))
+		if errSave := l.saveDiagnosticsAttempts(attempts); errSave != nil {
+			err = errors.Join(err, errSave)
+		}
+		message := fmt.Sprintf("failed upload diagnostics, attempt %d of %d, retry at %s", attempts.Attempts, pkg.DIAGNOSTICS_MAX_ATTEMPTS, attempts.NextAt.Format(time.RFC3339))
+		go l.NotifyStatus("diagnostics_retry", pkg.TransactionEventUpdate, message, ctx)
+		return err
+	}
+	// request is registered as finished for not be requested again
+	if errSave := l.fileSystem.WriteFileContent(filepath.Join(l.agentWorkDir, "state", "diagnostics"), []byte(attempts.RequestId)); errSave != nil {
+		err = errors.Join(err, errSave)
+	}
+	message := fmt.Sprintf("failed upload diagnostics after %d attempts", attempts.Attempts)
+	go l.NotifyStatus("diagnostics_error", pkg.TransactionEventClose, message, ctx)
+	return err
+}
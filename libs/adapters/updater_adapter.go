@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -24,6 +25,7 @@ var (
 
 // UpdaterAdapter is struct for updater adapter
 type UpdaterAdapter struct {
+	config         dto.RuntimeConfig
 	chanClose      chan struct{}
 	agentWorkDir   string
 	isClosed       bool
@@ -39,9 +41,10 @@ type UpdaterAdapter struct {
 }
 
 // NewUpdaterAdapter return instance of linux updater adapter
-func NewUpdaterAdapter(logger interfaces.ILogger) *UpdaterAdapter {
+func NewUpdaterAdapter(logger interfaces.ILogger, config dto.RuntimeConfig) *UpdaterAdapter {
 	return &UpdaterAdapter{
-		logger:  logger,
+		logger:  logger.Named("updater_adapter"),
+		config:  config,
 		program: pkg.NewExecProgram(),
 		delay:   time.Second * 1,
 	}
@@ -49,14 +52,13 @@ func NewUpdaterAdapter(logger interfaces.ILogger) *UpdaterAdapter {
 
 // Prepare configure manager adapter
 func (l *UpdaterAdapter) Prepare() error {
-	ymlClient := pkg.NewYmlClient()
 	chanClose := make(chan struct{}, 1)
 	wg := &sync.WaitGroup{}
-	l.ymlClient = ymlClient
+	l.ymlClient = utils.NewConfigYmlClient(l.config.WorkDirPath)
 	l.chanClose = chanClose
 	l.isClosed = false
 	l.wg = wg
-	osOperation, err := components.SystemOperation(l.logger)
+	osOperation, err := components.SystemOperation(l.logger, l.config)
 	if err != nil {
 		return err
 	}
@@ -67,12 +69,8 @@ func (l *UpdaterAdapter) Prepare() error {
 	fileSystem := pkg.NewFileSystem()
 	l.fileSystem = fileSystem
 
-	agentWorkDir, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
-	l.agentWorkDir = agentWorkDir
-	utilityService := services.NewUtilityService(l.logger)
+	l.agentWorkDir = l.config.WorkDirPath
+	utilityService := services.NewUtilityService(l.logger, l.config)
 	if err := utilityService.Setup(); err != nil {
 		return err
 	}
@@ -185,23 +183,28 @@ func (l *UpdaterAdapter) ExecuteUpdateVersion(version string) error {
 		}
 	}
 
-	respManager, _, err := utils.GetBinary(managerUrl)
-	if err != nil {
-		return err
-	}
+	workdir := l.config.WorkDirPath
 
-	respAgent, _, err := utils.GetBinary(agentUrl)
-	if err != nil {
-		return err
-	}
+	//validate path version
+	pathVersion := filepath.Join(workdir, "bin", "releases", version)
+	pathManagerBinary := filepath.Join(pathVersion, "manager")
+	pathAgentBinary := filepath.Join(pathVersion, "agent")
+	// release retained in releases dir is installed without download
+	retained := l.ReleaseRetained(version)
 
-	workdir, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
+	var respManager, respAgent []byte
+	if !retained {
+		respManager, _, err = utils.GetBinary(l.config, managerUrl)
+		if err != nil {
+			return err
+		}
+
+		respAgent, _, err = utils.GetBinary(l.config, agentUrl)
+		if err != nil {
+			return err
+		}
 	}
 
-	//validate path version
-	pathVersion := filepath.Join(workdir, "bin", "releases", version)
 	if err := l.fileSystem.VerifyDirExistAndCreate(pathVersion); err != nil {
 		return err
 	}
@@ -211,27 +214,26 @@ func (l *UpdaterAdapter) ExecuteUpdateVersion(version string) error {
 		return err
 	}
 
-	pathManagerBinary := filepath.Join(pathVersion, "manager")
-	pathAgentBinary := filepath.Join(pathVersion, "agent")
-
 	pathCurrentManager := filepath.Join(pathCurrent, "manager")
 	pathCurrentAgent := filepath.Join(pathCurrent, "agent")
 
-	if err := l.fileSystem.WriteBinaryContent(pathManagerBinary, respManager); err != nil {
-		return err
-	}
-	if err := l.fileSystem.WriteBinaryContent(pathAgentBinary, respAgent); err != nil {
-		return err
-	}
+	if !retained {
+		if err := l.fileSystem.WriteBinaryContent(pathManagerBinary, respManager); err != nil {
+			return err
+		}
+		if err := l.fileSystem.WriteBinaryContent(pathAgentBinary, respAgent); err != nil {
+			return err
+		}
 
-	err = os.Chmod(pathManagerBinary, 0755)
-	if err != nil {
-		return err
-	}
+		err = os.Chmod(pathManagerBinary, 0755)
+		if err != nil {
+			return err
+		}
 
-	err = os.Chmod(pathAgentBinary, 0755)
-	if err != nil {
-		return err
+		err = os.Chmod(pathAgentBinary, 0755)
+		if err != nil {
+			return err
+		}
 	}
 	//create symlink manager
 	if err := l.fileSystem.CreateOrUpdateSymlink(pathManagerBinary, pathCurrentManager); err != nil {
@@ -256,15 +258,59 @@ func (l *UpdaterAdapter) ExecuteUpdateVersion(version string) error {
 
 // GetContentReceived return content received from update
 func (l *UpdaterAdapter) GetContentReceived() ([]byte, error) {
-	workdir, err := utils.GetWorkDirPath()
+	res, err := l.fileSystem.GetFileContent(filepath.Join(l.config.WorkDirPath, "state", "received"))
 	if err != nil {
 		return nil, err
 	}
-	res, err := l.fileSystem.GetFileContent(filepath.Join(workdir, "state", "received"))
+	return res, nil
+}
+
+// GetUpdateTarget return version of update resolved by manager,
+// removing the target file, empty when manager not informed
+func (l *UpdaterAdapter) GetUpdateTarget() (string, error) {
+	targetPath := filepath.Join(l.agentWorkDir, "state", "update_target")
+	content, err := os.ReadFile(targetPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return res, nil
+	if err := os.Remove(targetPath); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// LoadReleases return manifest of releases installed
+func (l *UpdaterAdapter) LoadReleases() (*utils.ReleaseManifest, error) {
+	return utils.LoadReleaseManifest(utils.GetReleasesDirPath(l.config.WorkDirPath))
+}
+
+// ReleaseRetained return if release of version is retained in manifest
+// of releases with binaries of manager and agent in releases dir,
+// release failed or out of manifest must be downloaded again
+func (l *UpdaterAdapter) ReleaseRetained(version string) bool {
+	releases, err := l.LoadReleases()
+	if err != nil || !releases.Retained(version) {
+		return false
+	}
+	pathVersion := filepath.Join(utils.GetReleasesDirPath(l.config.WorkDirPath), version)
+	return l.fileSystem.VerifyFileExist(filepath.Join(pathVersion, "manager")) == nil && l.fileSystem.VerifyFileExist(filepath.Join(pathVersion, "agent")) == nil
+}
+
+// GetReleasesKeep return number of verified releases kept for rollback
+func (l *UpdaterAdapter) GetReleasesKeep() int {
+	var configAgent dto.ConfigAgent
+	configPath := l.config.ConfigFilePath
+	content, err := l.fileSystem.GetFileContent(configPath)
+	if err != nil {
+		return pkg.RELEASES_DEFAULT_KEEP
+	}
+	if err := l.ymlClient.Unmarshall(content, &configAgent); err != nil || configAgent.Releases.Keep <= 0 {
+		return pkg.RELEASES_DEFAULT_KEEP
+	}
+	return configAgent.Releases.Keep
 }
 
 // GetAgentVersionFromSignal return agent version from signal
@@ -283,10 +329,7 @@ func (l *UpdaterAdapter) GetAgentVersionFromSignal(response []byte) (string, err
 // GetAgentVersion return rollback version installed agent
 func (l *UpdaterAdapter) GetAgentVersion() (string, error) {
 	var configAgent dto.ConfigAgent
-	configPath, err := utils.GetConfigFilePath()
-	if err != nil {
-		return "", err
-	}
+	configPath := l.config.ConfigFilePath
 
 	content, err := l.fileSystem.GetFileContent(configPath)
 	if err != nil {
@@ -303,10 +346,7 @@ func (l *UpdaterAdapter) GetAgentVersion() (string, error) {
 // GetAgentRollbackVersion return rollback version installed agent
 func (l *UpdaterAdapter) GetAgentRollbackVersion() (string, error) {
 	var configAgent dto.ConfigAgent
-	configPath, err := utils.GetConfigFilePath()
-	if err != nil {
-		return "", err
-	}
+	configPath := l.config.ConfigFilePath
 
 	content, err := l.fileSystem.GetFileContent(configPath)
 	if err != nil {
@@ -335,10 +375,7 @@ func (l *UpdaterAdapter) ValidateSuccessUpdated() (bool, error) {
 
 // ExecuteRollbackVersion execute rollback to previous version
 func (l *UpdaterAdapter) ExecuteRollbackVersion(version string) error {
-	workdir, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
+	workdir := l.config.WorkDirPath
 	//validate path version
 	pathVersion := filepath.Join(workdir, "bin", "releases", version)
 	if err := l.fileSystem.VerifyDirExistAndCreate(pathVersion); err != nil {
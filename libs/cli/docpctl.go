@@ -0,0 +1,859 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/components"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/services"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+	"gopkg.in/yaml.v2"
+)
+
+const usage = `docpctl is tool for inspection and operations of docp agent on host
+
+usage:
+  docpctl status [--json]
+  docpctl state show [received|current] [--show-secrets]
+  docpctl state diff [--show-secrets]
+  docpctl actions plan [--json]
+  docpctl config get [key] [--show-secrets]
+  docpctl config set <key> <value>
+  docpctl datadog files [--json]
+  docpctl update --to <version> [--allow-downgrade]
+  docpctl rollback [--to <version>]
+  docpctl tasks [--json]
+  docpctl errors [--json]
+  docpctl poll
+  docpctl sync
+  docpctl pause
+  docpctl resume
+  docpctl diagnostics
+  docpctl logs [--service manager|agent|updater] [-n lines] [-f]
+  docpctl log-level [name] [debug|info|warn|error] [--json]
+`
+
+// ErrUsage is error for invalid command line
+var ErrUsage = errors.New("invalid usage")
+
+// tailBlockSize is size of blocks read from end of file by logs
+const tailBlockSize = 64 * 1024
+
+// Docpctl is struct for command line tool of docp agent
+type Docpctl struct {
+	out            io.Writer
+	logger         interfaces.ILogger
+	control        *services.ControlClient
+	osOperation    interfaces.IOSOperation
+	fileSystem     *pkg.FileSystem
+	ymlClient      *pkg.YmlClient
+	redactor       *utils.Redactor
+	workDirPath    string
+	configFilePath string
+}
+
+// NewDocpctl return instance of docpctl writing output in out
+func NewDocpctl(out io.Writer, logger interfaces.ILogger) *Docpctl {
+	return &Docpctl{
+		out:    out,
+		logger: logger,
+	}
+}
+
+// Setup configure docpctl
+func (d *Docpctl) Setup() error {
+	// args of docpctl are commands, not flags of runtime config
+	config, err := utils.LoadRuntimeConfig(nil)
+	if err != nil {
+		return err
+	}
+	d.workDirPath = config.WorkDirPath
+	d.configFilePath = config.ConfigFilePath
+	control := services.NewControlClient(d.logger, config)
+	if err := control.Setup(); err != nil {
+		return err
+	}
+	d.control = control
+	osOperation, err := components.SystemOperation(d.logger, config)
+	if err != nil {
+		return err
+	}
+	if err := osOperation.Setup(); err != nil {
+		return err
+	}
+	d.osOperation = osOperation
+	d.ymlClient = utils.NewConfigYmlClient(config.WorkDirPath)
+	d.fileSystem = pkg.NewFileSystem()
+	d.redactor = utils.NewConfiguredRedactor()
+	return nil
+}
+
+// Usage execute print of usage
+func (d *Docpctl) Usage() {
+	fmt.Fprint(d.out, usage)
+}
+
+// Run execute command from args
+func (d *Docpctl) Run(args []string) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+	switch args[0] {
+	case "status":
+		return d.status(args[1:])
+	case "state":
+		if len(args) < 2 {
+			return ErrUsage
+		}
+		switch args[1] {
+		case "show":
+			return d.stateShow(args[2:])
+		case "diff":
+			return d.stateDiff(args[2:])
+		}
+	case "actions":
+		if len(args) >= 2 && args[1] == "plan" {
+			return d.actionsPlan(args[2:])
+		}
+	case "config":
+		if len(args) < 2 {
+			return ErrUsage
+		}
+		switch args[1] {
+		case "get":
+			return d.configGet(args[2:])
+		case "set":
+			return d.configSet(args[2:])
+		}
+	case "datadog":
+		if len(args) >= 2 && args[1] == "files" {
+			return d.datadogFiles(args[2:])
+		}
+	case "update":
+		return d.update(args[1:])
+	case "rollback":
+		return d.rollback(args[1:])
+	case "tasks":
+		return d.tasks(args[1:])
+	case "errors":
+		return d.showErrors(args[1:])
+	case "poll":
+		return d.command(args[1:], d.control.ForcePoll)
+	case "sync":
+		return d.command(args[1:], d.control.ForceMetadataSync)
+	case "pause":
+		return d.command(args[1:], d.control.Pause)
+	case "resume":
+		return d.command(args[1:], d.control.Resume)
+	case "diagnostics":
+		return d.command(args[1:], d.control.Diagnostics)
+	case "log-level":
+		return d.logLevel(args[1:])
+	case "logs":
+		return d.logs(args[1:])
+	case "help", "-h", "--help":
+		d.Usage()
+		return nil
+	}
+	return ErrUsage
+}
+
+// parseFlags return positional args after parse of flags,
+// accepting flags before and after positional args
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUsage, err.Error())
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// printJSON execute print of value in json indented
+func (d *Docpctl) printJSON(value any) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(d.out, string(content))
+	return nil
+}
+
+// statePath return path of state file by name
+func (d *Docpctl) statePath(name string) string {
+	return filepath.Join(d.workDirPath, "state", name)
+}
+
+// readState return content of state file, empty when not exist
+func (d *Docpctl) readState(name string) ([]byte, error) {
+	content, err := os.ReadFile(d.statePath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// localStatus return status from files and services
+// on host when control socket is not available
+func (d *Docpctl) localStatus() dto.ControlStatus {
+	status := dto.ControlStatus{
+		Services:      make(map[string]string),
+		PendingEvents: -1,
+	}
+	for _, service := range []string{"manager", "agent", "datadog"} {
+		state, err := d.osOperation.Status(service)
+		if err != nil {
+			state = "unknown"
+		}
+		status.Services[service] = state
+	}
+	if configAgent, err := d.readConfig(); err == nil {
+		status.Versions = dto.ControlVersions{Agent: configAgent.Version, Rollback: configAgent.RollbackVersion}
+	}
+	if releases, err := utils.LoadReleaseManifest(filepath.Join(d.workDirPath, "bin", "releases")); err == nil {
+		status.Releases = releases.List()
+	}
+	if info, err := os.Stat(d.statePath("received")); err == nil {
+		status.LastSignalAt = info.ModTime()
+	}
+	if received, err := d.readState("received"); err == nil && len(received) > 0 {
+		status.LastSignalHash = utils.GenerateMd5Hash(received)
+	}
+	return status
+}
+
+// status execute print of status of services, versions and signal
+func (d *Docpctl) status(args []string) error {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "output in json")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	status, err := d.control.Status()
+	if errors.Is(err, pkg.ErrControlUnavailable) {
+		fmt.Fprintln(os.Stderr, "warning: manager control socket not available, showing local status")
+		status = d.localStatus()
+	} else if err != nil {
+		return err
+	}
+	if *asJSON {
+		return d.printJSON(status)
+	}
+	writer := tabwriter.NewWriter(d.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "SERVICES")
+	for _, service := range []string{"manager", "agent", "datadog"} {
+		fmt.Fprintf(writer, "  %s\t%s\n", service, status.Services[service])
+	}
+	fmt.Fprintln(writer, "VERSIONS")
+	fmt.Fprintf(writer, "  agent\t%s\n", status.Versions.Agent)
+	fmt.Fprintf(writer, "  rollback\t%s\n", status.Versions.Rollback)
+	if len(status.Releases) > 0 {
+		fmt.Fprintln(writer, "RELEASES")
+		for _, release := range status.Releases {
+			fmt.Fprintf(writer, "  %s\t%s\tinstalled %s\n", release.Version, release.Health, release.InstalledAt.Format(time.RFC3339))
+		}
+	}
+	fmt.Fprintln(writer, "SIGNAL")
+	lastSignal := "never"
+	if !status.LastSignalAt.IsZero() {
+		lastSignal = fmt.Sprintf("%s (%s ago)", status.LastSignalAt.Format(time.RFC3339), time.Since(status.LastSignalAt).Round(time.Second))
+	}
+	fmt.Fprintf(writer, "  last received\t%s\n", lastSignal)
+	fmt.Fprintf(writer, "  hash\t%s\n", status.LastSignalHash)
+	pendingEvents := "unknown"
+	if status.PendingEvents >= 0 {
+		pendingEvents = fmt.Sprintf("%d", status.PendingEvents)
+	}
+	fmt.Fprintf(writer, "  pending events\t%s\n", pendingEvents)
+	fmt.Fprintln(writer, "RECONCILIATION")
+	reconciliation := "running"
+	if status.Paused {
+		reconciliation = "paused"
+	}
+	fmt.Fprintf(writer, "  state\t%s\n", reconciliation)
+	if len(status.Queues) > 0 {
+		fmt.Fprintln(writer, "QUEUES")
+		for _, queue := range status.Queues {
+			current := "idle"
+			if len(queue.Current) > 0 {
+				current = fmt.Sprintf("%s (%s)", queue.Current, time.Since(queue.StartedAt).Round(time.Second))
+			}
+			fmt.Fprintf(writer, "  %s\t%s\tpending %d\n", queue.Component, current, queue.Depth)
+		}
+	}
+	return writer.Flush()
+}
+
+// redactState return state content masked unless show secrets
+func (d *Docpctl) redactState(content []byte, showSecrets bool) ([]byte, error) {
+	if showSecrets {
+		return content, nil
+	}
+	return d.redactor.RedactJSON(content)
+}
+
+// stateShow execute print of state received or current
+func (d *Docpctl) stateShow(args []string) error {
+	flags := flag.NewFlagSet("state show", flag.ContinueOnError)
+	showSecrets := flags.Bool("show-secrets", false, "show secrets in plaintext")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	name := "received"
+	if len(positional) > 0 {
+		name = positional[0]
+	}
+	if name != "received" && name != "current" {
+		return ErrUsage
+	}
+	content, err := d.readState(name)
+	if err != nil {
+		return err
+	}
+	if len(content) == 0 {
+		fmt.Fprintf(d.out, "state %s is empty\n", name)
+		return nil
+	}
+	content, err = d.redactState(content, *showSecrets)
+	if err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, content, "", "  "); err != nil {
+		return err
+	}
+	fmt.Fprintln(d.out, indented.String())
+	return nil
+}
+
+// stateDiff execute print of differences between current and received state
+func (d *Docpctl) stateDiff(args []string) error {
+	flags := flag.NewFlagSet("state diff", flag.ContinueOnError)
+	showSecrets := flags.Bool("show-secrets", false, "show secrets in plaintext")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	current, err := d.readState("current")
+	if err != nil {
+		return err
+	}
+	received, err := d.readState("received")
+	if err != nil {
+		return err
+	}
+	if current, err = d.redactState(current, *showSecrets); err != nil {
+		return err
+	}
+	if received, err = d.redactState(received, *showSecrets); err != nil {
+		return err
+	}
+	lines, err := pkg.DiffJSON(current, received)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		fmt.Fprintln(d.out, "received and current states are equal")
+		return nil
+	}
+	fmt.Fprintln(d.out, "--- current")
+	fmt.Fprintln(d.out, "+++ received")
+	for _, line := range lines {
+		fmt.Fprintln(d.out, line)
+	}
+	return nil
+}
+
+// actionsPlan execute print of actions manager would execute now
+func (d *Docpctl) actionsPlan(args []string) error {
+	flags := flag.NewFlagSet("actions plan", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "output in json")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	actions, err := d.control.PlanActions()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		content, err := json.Marshal(actions)
+		if err != nil {
+			return err
+		}
+		if content, err = d.redactor.RedactJSON(content); err != nil {
+			return err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, content, "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(d.out, indented.String())
+		return nil
+	}
+	if len(actions) == 0 {
+		fmt.Fprintln(d.out, "no actions planned")
+		return nil
+	}
+	writer := tabwriter.NewWriter(d.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "TYPE\tACTION\tCOMPONENT\tMODE\tVERSION\tENVS\tFILES")
+	for _, action := range actions {
+		var envs []string
+		for _, env := range action.Envs {
+			envs = append(envs, env.Name)
+		}
+		for _, env := range action.ComponentEnvs {
+			envs = append(envs, env.Name)
+		}
+		var files []string
+		for _, file := range action.Files {
+			files = append(files, file.FilePath)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", action.Type, action.Action, action.Component, action.Mode, action.Version, strings.Join(envs, ","), strings.Join(files, ","))
+	}
+	return writer.Flush()
+}
+
+// readConfig return config agent from config file with secrets
+func (d *Docpctl) readConfig() (dto.ConfigAgent, error) {
+	var configAgent dto.ConfigAgent
+	content, err := d.fileSystem.GetFileContent(d.configFilePath)
+	if err != nil {
+		return configAgent, err
+	}
+	if err := d.ymlClient.Unmarshall(content, &configAgent); err != nil {
+		return configAgent, err
+	}
+	return configAgent, nil
+}
+
+// configTree return config agent as tree of yml
+func configTree(configAgent dto.ConfigAgent) (map[any]any, error) {
+	content, err := yaml.Marshal(&configAgent)
+	if err != nil {
+		return nil, err
+	}
+	tree := make(map[any]any)
+	if err := yaml.Unmarshal(content, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// configGet execute print of config file or value of key
+func (d *Docpctl) configGet(args []string) error {
+	flags := flag.NewFlagSet("config get", flag.ContinueOnError)
+	showSecrets := flags.Bool("show-secrets", false, "show secrets in plaintext")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	configAgent, err := d.readConfig()
+	if err != nil {
+		return err
+	}
+	tree, err := configTree(configAgent)
+	if err != nil {
+		return err
+	}
+	var value any = tree
+	if len(positional) > 0 {
+		var ok bool
+		value, ok = lookupKey(tree, positional[0])
+		if !ok {
+			return fmt.Errorf("%w: config key %s", pkg.ErrNotFound, positional[0])
+		}
+		keys := strings.Split(positional[0], ".")
+		if !*showSecrets && d.redactor.IsSensitiveKey(keys[len(keys)-1]) {
+			value = utils.REDACTED_VALUE
+		}
+	}
+	if !*showSecrets {
+		value = d.redactor.RedactTree(value)
+	}
+	switch v := value.(type) {
+	case map[any]any, []any:
+		content, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(d.out, string(content))
+	default:
+		fmt.Fprintln(d.out, v)
+	}
+	return nil
+}
+
+// lookupKey return value of key separated by dot in tree
+func lookupKey(tree map[any]any, key string) (any, bool) {
+	var current any = tree
+	for _, part := range strings.Split(key, ".") {
+		node, ok := current.(map[any]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = node[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setKey execute set value of key separated by dot in tree,
+// creating intermediate nodes
+func setKey(tree map[any]any, key string, value any) error {
+	parts := strings.Split(key, ".")
+	node := tree
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := node[part]
+		if !ok || child == nil {
+			child = make(map[any]any)
+			node[part] = child
+		}
+		childNode, ok := child.(map[any]any)
+		if !ok {
+			return fmt.Errorf("config key %s is not a section", part)
+		}
+		node = childNode
+	}
+	node[parts[len(parts)-1]] = value
+	return nil
+}
+
+// configSet execute set value of key in config file, value is parsed as yml,
+// with lock of config file taken by writers of manager
+func (d *Docpctl) configSet(args []string) error {
+	flags := flag.NewFlagSet("config set", flag.ContinueOnError)
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return ErrUsage
+	}
+	key, rawValue := positional[0], positional[1]
+	unlock, err := d.fileSystem.LockFile(utils.GetConfigLockPath(d.workDirPath))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	configAgent, err := d.readConfig()
+	if err != nil {
+		return err
+	}
+	tree, err := configTree(configAgent)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := yaml.Unmarshal([]byte(rawValue), &value); err != nil {
+		return err
+	}
+	if err := setKey(tree, key, value); err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	// strict for reject unknown keys and invalid types
+	var updated dto.ConfigAgent
+	if err := yaml.UnmarshalStrict(content, &updated); err != nil {
+		return fmt.Errorf("invalid config key %s: %w", key, err)
+	}
+	if err := d.ymlClient.SaveSecrets(&updated); err != nil {
+		return err
+	}
+	configBytes, err := d.ymlClient.Marshall(&updated)
+	if err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(d.configFilePath); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.WriteFile(d.configFilePath, configBytes, perm); err != nil {
+		return err
+	}
+	fmt.Fprintf(d.out, "config key %s updated\n", key)
+	return nil
+}
+
+// datadogFiles execute print of datadog files from signal
+// and if content on disk is equal to signal
+func (d *Docpctl) datadogFiles(args []string) error {
+	flags := flag.NewFlagSet("datadog files", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "output in json")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	received, err := d.readState("received")
+	if err != nil {
+		return err
+	}
+	var stateCheckResponse dto.StateCheckResponse
+	if len(received) > 0 {
+		if err := json.Unmarshal(received, &stateCheckResponse); err != nil {
+			return err
+		}
+	}
+	filesStatus := []dto.DatadogFileStatus{}
+	for _, file := range stateCheckResponse.Signal.Agents.DatadogAgent.Configurations.Files {
+		status := pkg.DATADOG_FILE_STATUS_IN_SYNC
+		content, err := os.ReadFile(file.FilePath)
+		if err != nil {
+			status = pkg.DATADOG_FILE_STATUS_MISSING
+		} else if !bytes.Equal(bytes.TrimSpace(content), bytes.TrimSpace([]byte(file.Content))) {
+			status = pkg.DATADOG_FILE_STATUS_CHANGED
+		}
+		filesStatus = append(filesStatus, dto.DatadogFileStatus{FilePath: file.FilePath, Status: status})
+	}
+	if *asJSON {
+		return d.printJSON(filesStatus)
+	}
+	if len(filesStatus) == 0 {
+		fmt.Fprintln(d.out, "no datadog files in signal")
+		return nil
+	}
+	writer := tabwriter.NewWriter(d.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "FILE\tSTATUS")
+	for _, fileStatus := range filesStatus {
+		fmt.Fprintf(writer, "%s\t%s\n", fileStatus.FilePath, fileStatus.Status)
+	}
+	return writer.Flush()
+}
+
+// update execute request of update agent for version
+func (d *Docpctl) update(args []string) error {
+	flags := flag.NewFlagSet("update", flag.ContinueOnError)
+	version := flags.String("to", "", "version or constraint of agent, like ^1.2.0")
+	allowDowngrade := flags.Bool("allow-downgrade", false, "allow update to version lower than installed")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if len(*version) == 0 {
+		return ErrUsage
+	}
+	controlResponse, err := d.control.Update(*version, *allowDowngrade)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(d.out, controlResponse.Message)
+	return nil
+}
+
+// rollback execute request of rollback agent for release retained
+func (d *Docpctl) rollback(args []string) error {
+	flags := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	version := flags.String("to", "", "version of release retained, empty is previous release")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return ErrUsage
+	}
+	controlResponse, err := d.control.Rollback(*version)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(d.out, controlResponse.Message)
+	return nil
+}
+
+// command execute request of command in manager and print result
+func (d *Docpctl) command(args []string, request func() (dto.ControlResponse, error)) error {
+	if len(args) > 0 {
+		return ErrUsage
+	}
+	controlResponse, err := request()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(d.out, controlResponse.Message)
+	return nil
+}
+
+// tasks execute print of tasks in flight in manager
+func (d *Docpctl) tasks(args []string) error {
+	flags := flag.NewFlagSet("tasks", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "output in json")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	tasks, err := d.control.Tasks()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return d.printJSON(tasks)
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(d.out, "no tasks in flight")
+		return nil
+	}
+	writer := tabwriter.NewWriter(d.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "TASK\tRUNNING")
+	for _, task := range tasks {
+		fmt.Fprintf(writer, "%s\t%s\n", task.Name, time.Since(task.StartedAt).Round(time.Second))
+	}
+	return writer.Flush()
+}
+
+// showErrors execute print of last errors received in manager
+func (d *Docpctl) showErrors(args []string) error {
+	flags := flag.NewFlagSet("errors", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "output in json")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	controlErrors, err := d.control.Errors()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return d.printJSON(controlErrors)
+	}
+	if len(controlErrors) == 0 {
+		fmt.Fprintln(d.out, "no errors received")
+		return nil
+	}
+	writer := tabwriter.NewWriter(d.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "TIME\tFROM\tPRIORITY\tERROR")
+	for _, controlError := range controlErrors {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%s\n", controlError.Time.Format(time.RFC3339), controlError.From, controlError.Priority, d.redactor.RedactString(controlError.Error))
+	}
+	return writer.Flush()
+}
+
+// logLevel execute print of levels of loggers in manager, or change
+// of level of logger when name and level informed
+func (d *Docpctl) logLevel(args []string) error {
+	flags := flag.NewFlagSet("log-level", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "output in json")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	var levels map[string]string
+	switch len(positional) {
+	case 0:
+		levels, err = d.control.LogLevels()
+	case 1:
+		levels, err = d.control.SetLogLevel("", positional[0])
+	case 2:
+		levels, err = d.control.SetLogLevel(positional[0], positional[1])
+	default:
+		return ErrUsage
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return d.printJSON(levels)
+	}
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writer := tabwriter.NewWriter(d.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "LOGGER\tLEVEL")
+	for _, name := range names {
+		fmt.Fprintf(writer, "%s\t%s\n", name, levels[name])
+	}
+	return writer.Flush()
+}
+
+// logs execute print of logs of service from journald when
+// logs are in stdout, or from log file otherwise
+func (d *Docpctl) logs(args []string) error {
+	flags := flag.NewFlagSet("logs", flag.ContinueOnError)
+	service := flags.String("service", "manager", "service of logs")
+	lines := flags.Int("n", 100, "number of lines")
+	follow := flags.Bool("f", false, "follow logs")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	config, err := utils.GetLoggingConfig(d.configFilePath)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "linux" && utils.GetLogOutput(config.Output) == pkg.LOG_OUTPUT_STDOUT {
+		if journalctl, err := exec.LookPath("journalctl"); err == nil && len(utils.ChoiceNameService(*service)) > 0 {
+			cmdArgs := []string{"-u", utils.ChoiceNameService(*service), "-n", fmt.Sprintf("%d", *lines), "--no-pager"}
+			if *follow {
+				cmdArgs = append(cmdArgs, "-f")
+			}
+			cmd := exec.Command(journalctl, cmdArgs...)
+			cmd.Stdout = d.out
+			cmd.Stderr = os.Stderr
+			return cmd.Run()
+		}
+	}
+	logPath := utils.GetServiceLogFilePath(*service, d.workDirPath, config.File)
+	return d.tailFile(logPath, *lines, *follow)
+}
+
+// tailFile execute print of last lines of file, following new lines,
+// file is read by blocks from end until lines are found
+func (d *Docpctl) tailFile(path string, lines int, follow bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	var content []byte
+	// line before the first line printed is needed for not print it partial
+	for offset := size; offset > 0 && bytes.Count(content, []byte("\n")) <= lines; {
+		block := min(offset, tailBlockSize)
+		offset -= block
+		buf := make([]byte, block)
+		if _, err := file.ReadAt(buf, offset); err != nil {
+			return err
+		}
+		content = append(buf, content...)
+	}
+	fileLines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(fileLines) > lines {
+		fileLines = fileLines[len(fileLines)-lines:]
+	}
+	for _, line := range fileLines {
+		fmt.Fprintln(d.out, line)
+	}
+	if !follow {
+		return nil
+	}
+	for {
+		time.Sleep(time.Second * 1)
+		if _, err := io.Copy(d.out, file); err != nil {
+			return err
+		}
+	}
+}
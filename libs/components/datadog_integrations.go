@@ -0,0 +1,287 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/services"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// DatadogIntegrations is struct for lifecycle of integrations of datadog
+// agent, third party integrations are installed by agent and conf.d
+// of integrations applied is managed by docp agent
+type DatadogIntegrations struct {
+	logger     interfaces.ILogger
+	config     dto.RuntimeConfig
+	program    *pkg.ExecProgram
+	stateCheck *services.StateCheckService
+	agentPath  string
+	confPath   string
+	statePath  string
+}
+
+// NewDatadogIntegrations return instance of datadog integrations
+func NewDatadogIntegrations(logger interfaces.ILogger, config dto.RuntimeConfig) *DatadogIntegrations {
+	return &DatadogIntegrations{
+		logger: logger.Named("datadog_integrations"),
+		config: config,
+	}
+}
+
+// Setup execute configuration of datadog integrations
+func (d *DatadogIntegrations) Setup() error {
+	d.program = pkg.NewExecProgram()
+	stateCheck := services.NewStateCheckService(d.logger, d.config)
+	if err := stateCheck.Setup(); err != nil {
+		return err
+	}
+	d.stateCheck = stateCheck
+	agentPath, err := utils.GetDatadogBinaryAgentPath()
+	if err != nil {
+		return err
+	}
+	// binary of agent is inside of dir of agent
+	d.agentPath = filepath.Join(agentPath, "agent")
+	if runtime.GOOS == "windows" {
+		d.agentPath = agentPath + ".exe"
+	}
+	d.statePath = utils.GetDatadogIntegrationsFilePath(d.config.WorkDirPath)
+	return nil
+}
+
+// SetConfPath execute change of config dir of datadog agent
+func (d *DatadogIntegrations) SetConfPath(confPath string) {
+	d.confPath = confPath
+}
+
+// sendStatus execute send status in transaction of manager for
+// state check, without transaction status is not sent
+func (d *DatadogIntegrations) sendStatus(transactionId, status, typeEvent, message string) error {
+	if len(transactionId) == 0 {
+		return nil
+	}
+	transaction := dto.TransactionStatus{
+		ID:        transactionId,
+		UlidEvent: utils.GetUlid(),
+		TypeEvent: typeEvent,
+		Status:    status,
+		Message:   message,
+	}
+	if _, _, err := d.stateCheck.SendStatus(transaction); err != nil {
+		d.logger.Error("error in send status", "trace", "docp-agent-os-instance.datadog_integrations.sendStatus", "status", status, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// agentCommand execute command of datadog agent, in linux as user of agent
+func (d *DatadogIntegrations) agentCommand(args ...string) (string, error) {
+	if runtime.GOOS == "windows" {
+		return d.program.ExecuteWithOutput(d.agentPath, []string{}, args...)
+	}
+	return d.program.ExecuteWithOutput("sudo", []string{}, append([]string{"-u", "dd-agent", d.agentPath}, args...)...)
+}
+
+// Apply execute install, config and remove of integrations to match
+// integrations of signal, transaction is closed when finished
+func (d *DatadogIntegrations) Apply(integrations []dto.DatadogIntegration, transactionId string) error {
+	changed, err := d.apply(integrations, transactionId)
+	if changed {
+		if errRestart := d.restartAgent(); errRestart != nil {
+			err = errors.Join(err, fmt.Errorf("restart datadog agent: %w", errRestart))
+		}
+	}
+	if err != nil {
+		d.logger.Error("error in apply integrations", "trace", "docp-agent-os-instance.datadog_integrations.Apply", "error", err.Error())
+		d.sendStatus(transactionId, "update_docp_vendor_integrations_error", pkg.TransactionEventClose, "failed apply integrations: "+err.Error())
+		return err
+	}
+	d.sendStatus(transactionId, "update_docp_vendor_integrations_completed", pkg.TransactionEventClose, "apply integrations completed")
+	return nil
+}
+
+// apply return if any integration changed, integrations already
+// applied are kept in state file even when other integration fail
+func (d *DatadogIntegrations) apply(integrations []dto.DatadogIntegration, transactionId string) (bool, error) {
+	for _, integration := range integrations {
+		if err := utils.ValidateDatadogIntegration(integration); err != nil {
+			return false, err
+		}
+	}
+	managed, err := utils.LoadDatadogIntegrations(d.statePath)
+	if err != nil {
+		return false, err
+	}
+	installed := map[string]string{}
+	if slices.ContainsFunc(integrations, func(integration dto.DatadogIntegration) bool { return len(integration.Version) > 0 }) ||
+		slices.ContainsFunc(managed, func(integration dto.DatadogManagedIntegration) bool { return len(integration.Version) > 0 }) {
+		output, err := d.agentCommand("integration", "freeze")
+		if err != nil {
+			return false, fmt.Errorf("integration freeze: %w", err)
+		}
+		installed = utils.ParseDatadogIntegrationFreeze(output)
+	}
+
+	changed := false
+	var errs []error
+	for _, removed := range utils.RemovedDatadogIntegrations(integrations, managed) {
+		if err := d.remove(removed, installed); err != nil {
+			errs = append(errs, fmt.Errorf("remove %s: %w", removed.Name, err))
+			continue
+		}
+		changed = true
+		managed = slices.DeleteFunc(managed, func(integration dto.DatadogManagedIntegration) bool {
+			return integration.Name == removed.Name
+		})
+		d.sendStatus(transactionId, "update_docp_vendor_integration_removed", pkg.TransactionEventUpdate, "integration "+removed.Name+" removed")
+	}
+	for _, integration := range integrations {
+		applied, integrationChanged, err := d.ensure(integration, managed, installed)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("integration %s: %w", integration.Name, err))
+			continue
+		}
+		index := slices.IndexFunc(managed, func(item dto.DatadogManagedIntegration) bool { return item.Name == integration.Name })
+		if index >= 0 {
+			managed[index] = applied
+		} else {
+			managed = append(managed, applied)
+		}
+		if integrationChanged {
+			changed = true
+			d.sendStatus(transactionId, "update_docp_vendor_integration_applied", pkg.TransactionEventUpdate, "integration "+integration.Name+" applied")
+		}
+	}
+	if err := utils.SaveDatadogIntegrations(d.statePath, managed); err != nil {
+		errs = append(errs, err)
+	}
+	return changed, errors.Join(errs...)
+}
+
+// ensure execute install of third party integration in version and write
+// of conf.yaml, nothing is done when integration is already applied
+func (d *DatadogIntegrations) ensure(integration dto.DatadogIntegration, managed []dto.DatadogManagedIntegration, installed map[string]string) (dto.DatadogManagedIntegration, bool, error) {
+	content, err := utils.RenderDatadogIntegrationConfig(integration)
+	if err != nil {
+		return dto.DatadogManagedIntegration{}, false, err
+	}
+	applied := dto.DatadogManagedIntegration{Name: integration.Name, Version: integration.Version, ConfigHash: utils.GenerateMd5Hash(content), AppliedAt: time.Now()}
+	changed := false
+	if len(integration.Version) > 0 && installed[integration.Name] != integration.Version {
+		d.logger.Info("install integration", "trace", "docp-agent-os-instance.datadog_integrations.ensure", "name", integration.Name, "version", integration.Version, "installed", installed[integration.Name])
+		if _, err := d.agentCommand("integration", "install", "-t", fmt.Sprintf("%s==%s", utils.DatadogIntegrationPackage(integration.Name), integration.Version)); err != nil {
+			return applied, false, fmt.Errorf("install: %w", err)
+		}
+		changed = true
+	}
+	index := slices.IndexFunc(managed, func(item dto.DatadogManagedIntegration) bool { return item.Name == integration.Name })
+	configFile := d.configFile(integration.Name)
+	if index >= 0 && managed[index].ConfigHash == applied.ConfigHash && d.fileExists(configFile) {
+		applied.AppliedAt = managed[index].AppliedAt
+		return applied, changed, nil
+	}
+	if err := d.writeFile(configFile, content); err != nil {
+		return applied, false, err
+	}
+	return applied, true, nil
+}
+
+// remove execute remove of conf.yaml of integration and of third party
+// integration installed by docp agent
+func (d *DatadogIntegrations) remove(integration dto.DatadogManagedIntegration, installed map[string]string) error {
+	d.logger.Info("remove integration", "trace", "docp-agent-os-instance.datadog_integrations.remove", "name", integration.Name)
+	if err := d.removeFile(d.configFile(integration.Name)); err != nil {
+		return err
+	}
+	if _, ok := installed[integration.Name]; ok && len(integration.Version) > 0 {
+		if _, err := d.agentCommand("integration", "remove", utils.DatadogIntegrationPackage(integration.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configFile return path of conf.yaml of integration
+func (d *DatadogIntegrations) configFile(name string) string {
+	return filepath.Join(d.confPath, "conf.d", name+".d", "conf.yaml")
+}
+
+// writeFile execute write of config file of integration readable by agent
+func (d *DatadogIntegrations) writeFile(filePath string, content []byte) error {
+	if runtime.GOOS == "windows" {
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filePath, content, 0640)
+	}
+	return writeRootFile(d.program, filePath, content, "0640", "dd-agent")
+}
+
+// removeFile execute remove of config file of integration and of
+// dir of integration when empty
+func (d *DatadogIntegrations) removeFile(filePath string) error {
+	if runtime.GOOS == "windows" {
+		if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		os.Remove(filepath.Dir(filePath))
+		return nil
+	}
+	if err := d.program.Execute("sudo", []string{}, "rm", "-f", filePath); err != nil {
+		return err
+	}
+	return d.program.Execute("sudo", []string{}, "rmdir", "--ignore-fail-on-non-empty", filepath.Dir(filePath))
+}
+
+// fileExists return if file exists, file not readable by agent exists
+func (d *DatadogIntegrations) fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
+
+// restartAgent execute restart of datadog agent for load of integrations
+func (d *DatadogIntegrations) restartAgent() error {
+	if runtime.GOOS == "windows" {
+		_, err := d.program.ExecuteWithOutput("powershell", []string{}, "-Command", "Restart-Service -Force datadogagent")
+		return err
+	}
+	return d.program.Execute("sudo", []string{}, "systemctl", "restart", pkg.DATADOG_SERVICE_NAME)
+}
+
+// Status return status of integrations from status of datadog agent
+// with integrations applied by docp agent
+func (d *DatadogIntegrations) Status() ([]dto.DatadogIntegrationStatus, error) {
+	status, err := d.AgentStatus()
+	if err != nil {
+		return nil, err
+	}
+	return d.StatusFrom(status)
+}
+
+// StatusFrom return status of integrations from status of datadog
+// agent already read
+func (d *DatadogIntegrations) StatusFrom(status dto.DatadogAgentStatus) ([]dto.DatadogIntegrationStatus, error) {
+	managed, err := utils.LoadDatadogIntegrations(d.statePath)
+	if err != nil {
+		return nil, err
+	}
+	return utils.DatadogIntegrationsStatus(status, managed), nil
+}
+
+// AgentStatus return status of datadog agent from status in json
+func (d *DatadogIntegrations) AgentStatus() (dto.DatadogAgentStatus, error) {
+	output, err := d.agentCommand("status", "--json")
+	if err != nil {
+		return dto.DatadogAgentStatus{}, err
+	}
+	return utils.ParseDatadogStatus([]byte(output))
+}
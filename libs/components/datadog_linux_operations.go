@@ -3,7 +3,6 @@ package components
 import (
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
@@ -22,6 +21,7 @@ const (
 
 type DatadogLinuxOperation struct {
 	logger           interfaces.ILogger
+	config           dto.RuntimeConfig
 	program          *pkg.ExecProgram
 	hostStats        *pkg.HostStats
 	stateCheck       *services.StateCheckService
@@ -29,9 +29,10 @@ type DatadogLinuxOperation struct {
 	datadogApmTracer *DatadogAPMTracer
 }
 
-func NewDatadogLinuxOperation(logger interfaces.ILogger) *DatadogLinuxOperation {
+func NewDatadogLinuxOperation(logger interfaces.ILogger, config dto.RuntimeConfig) *DatadogLinuxOperation {
 	return &DatadogLinuxOperation{
-		logger: logger,
+		logger: logger.Named("datadog_linux_operations"),
+		config: config,
 	}
 }
 
@@ -65,7 +66,7 @@ func (d *DatadogLinuxOperation) Setup() error {
 	d.program = execProgram
 	hostStats := pkg.NewHostStats()
 	d.hostStats = hostStats
-	stateCheck := services.NewStateCheckService(d.logger)
+	stateCheck := services.NewStateCheckService(d.logger, d.config)
 	if err := stateCheck.Setup(); err != nil {
 		return err
 	}
@@ -77,22 +78,129 @@ func (d *DatadogLinuxOperation) Setup() error {
 	return nil
 }
 
-// InstallAgent execute install the agent in linux
-func (d *DatadogLinuxOperation) InstallAgent(ddSite, ddApiKey string) error {
-	envs := d.prepareEnvs(ddSite, ddApiKey)
+// prepareVersionEnvs return envs of install script for version
+// pinned, empty for latest version of agent 7
+func (d *DatadogLinuxOperation) prepareVersionEnvs(version string) []string {
+	pin, ok := utils.ParseDatadogVersionPin(version)
+	if !ok {
+		return nil
+	}
+	return pin.Envs()
+}
+
+// sendStatus execute send status of step in transaction of manager
+// for state check, without transaction status is not sent
+func (d *DatadogLinuxOperation) sendStatus(transactionId, status, typeEvent, message string) error {
+	if len(transactionId) == 0 {
+		return nil
+	}
+	transaction := dto.TransactionStatus{
+		ID:        transactionId,
+		UlidEvent: utils.GetUlid(),
+		TypeEvent: typeEvent,
+		Status:    status,
+		Message:   message,
+	}
+	if _, _, err := d.stateCheck.SendStatus(transaction); err != nil {
+		d.logger.Error("error in send status", "trace", "docp-agent-os-instance.datadog_linux_operations.sendStatus", "status", status, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// packageInstaller return installer by package manager, nil when
+// installer of config is install script, host without apt or yum
+// must configure install script
+func (d *DatadogLinuxOperation) packageInstaller() (*DatadogPackageInstaller, error) {
+	config, err := utils.GetDatadogInstallConfig(d.config.ConfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if config.Installer == pkg.DATADOG_INSTALLER_SCRIPT {
+		return nil, nil
+	}
+	installer := NewDatadogPackageInstaller(d.logger, d.program, config, d.config)
+	if err := installer.Setup(); err != nil {
+		return nil, fmt.Errorf("%w, installer script must be configured", err)
+	}
+	return installer, nil
+}
+
+// InstallAgent execute install the agent in linux, version pinned like
+// 7.52 or 7.52.1 is installed instead of latest, steps of install are
+// reported in transaction and transaction is closed when finished
+func (d *DatadogLinuxOperation) InstallAgent(ddSite, ddApiKey, version, transactionId string) error {
+	if err := d.installAgent(ddSite, ddApiKey, version, transactionId); err != nil {
+		d.logger.Error("error in install agent", "trace", "docp-agent-os-instance.datadog_linux_operations.InstallAgent", "error", err.Error())
+		d.sendStatus(transactionId, "install_docp_vendor_error", pkg.TransactionEventClose, "failed install docp vendor: "+err.Error())
+		return err
+	}
+	d.sendStatus(transactionId, "install_docp_vendor_completed", pkg.TransactionEventClose, "install docp vendor completed")
+	return nil
+}
+
+// installAgent execute install the agent by package manager or by
+// install script when configured
+func (d *DatadogLinuxOperation) installAgent(ddSite, ddApiKey, version, transactionId string) error {
 	aptOrDpkgIsRunning, err := d.hostStats.AptOrDpkgIsRunning()
 	if err != nil {
 		return err
 	}
-	if !aptOrDpkgIsRunning {
-		d.logger.Debug("install agent", "trace", "docp-agent-os-instance.datadog_linux_operations.InstallAgent", "aptOrDpkgIsRunning", aptOrDpkgIsRunning)
-		if err := d.program.Execute("bash", envs, "-c", fmt.Sprintf("export DD_API_KEY=%s;export DD_SITE=%s;%s", ddApiKey, ddSite, CURL_INSTALL_SH)); err != nil {
-			return err
-		}
-	} else {
-		d.logger.Debug("install agent", "trace", "docp-agent-os-instance.datadog_linux_operations.InstallAgent", "aptOrDpkgIsRunning", aptOrDpkgIsRunning)
+	d.logger.Debug("install agent", "trace", "docp-agent-os-instance.datadog_linux_operations.installAgent", "aptOrDpkgIsRunning", aptOrDpkgIsRunning)
+	if aptOrDpkgIsRunning {
+		return errors.New("package manager is running")
+	}
+	installer, err := d.packageInstaller()
+	if err != nil {
+		return err
 	}
+	if installer != nil {
+		return installer.Install(ddSite, ddApiKey, version, func(step string, changed bool) {
+			message := fmt.Sprintf("install docp vendor %s already done", step)
+			if changed {
+				message = fmt.Sprintf("install docp vendor %s completed", step)
+			}
+			d.sendStatus(transactionId, "install_docp_vendor_"+step, pkg.TransactionEventUpdate, message)
+		})
+	}
+	envs := d.prepareEnvs(ddSite, ddApiKey)
+	envs = append(envs, d.prepareVersionEnvs(version)...)
+	return d.program.Execute("bash", envs, "-c", CURL_INSTALL_SH)
+}
 
+// UpgradeAgent execute upgrade or downgrade of agent installed
+// to version pinned, keeping configuration of agent, steps of
+// upgrade are reported in transaction
+func (d *DatadogLinuxOperation) UpgradeAgent(version, transactionId string) error {
+	pin, ok := utils.ParseDatadogVersionPin(version)
+	if !ok {
+		return fmt.Errorf("%w: %s", pkg.ErrInvalidVersion, version)
+	}
+	aptOrDpkgIsRunning, err := d.hostStats.AptOrDpkgIsRunning()
+	if err != nil {
+		return err
+	}
+	d.logger.Debug("upgrade agent", "trace", "docp-agent-os-instance.datadog_linux_operations.UpgradeAgent", "version", pin.String(), "aptOrDpkgIsRunning", aptOrDpkgIsRunning)
+	if aptOrDpkgIsRunning {
+		return errors.New("package manager is running")
+	}
+	installer, err := d.packageInstaller()
+	if err != nil {
+		return err
+	}
+	if installer != nil {
+		return installer.Upgrade(pin.String(), func(step string, changed bool) {
+			message := fmt.Sprintf("update docp vendor %s already done", step)
+			if changed {
+				message = fmt.Sprintf("update docp vendor %s completed", step)
+			}
+			d.sendStatus(transactionId, "update_docp_vendor_"+step, pkg.TransactionEventUpdate, message)
+		})
+	}
+	envs := append([]string{"DD_UPGRADE=true"}, pin.Envs()...)
+	if err := d.program.Execute("bash", envs, "-c", CURL_INSTALL_SH); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -104,9 +212,13 @@ func (d *DatadogLinuxOperation) InstallAgentApmSingleStep(ddSite string, ddApiKe
 		return err
 	}
 	ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries := d.getApmEnvVarsSingleStep(datadogEnvVars)
+	envs = append(envs, fmt.Sprintf("DD_APM_INSTRUMENTATION_ENABLED=%s", ddApmInstrumentationEnabled))
+	envs = append(envs, fmt.Sprintf("DD_ENV=%s", ddEnv))
+	envs = append(envs, fmt.Sprintf("DD_APM_INSTRUMENTATION_LIBRARIES=%s", ddApmInstrumentationLibraries))
 	if !aptOrDpkgIsRunning {
 		d.logger.Debug("install agent apm single step", "trace", "docp-agent-os-instance.datadog_linux_operations.InstallAgentApmSingleStep", "aptOrDpkgIsRunning", aptOrDpkgIsRunning)
-		if err := d.program.Execute("bash", envs, "-c", fmt.Sprintf("export DD_API_KEY=%s;export DD_SITE=%s;export DD_APM_INSTRUMENTATION_ENABLED=%s;export DD_ENV=%s;export DD_APM_INSTRUMENTATION_LIBRARIES=%s;%s", ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries, CURL_INSTALL_SH)); err != nil {
+		// keys are passed only by environment, never in command line
+		if err := d.program.Execute("bash", envs, "-c", CURL_INSTALL_SH); err != nil {
 			return err
 		}
 	} else {
@@ -151,9 +263,8 @@ func (d *DatadogLinuxOperation) UninstallAgent() error {
 
 // DiscoverDatadogConfigPath return file path the datadog config
 func (d *DatadogLinuxOperation) DiscoverDatadogConfigPath() (string, error) {
-	ddConfPathEnv := os.Getenv("DD_CONF_PATH")
-	if len(ddConfPathEnv) > 0 {
-		return ddConfPathEnv, nil
+	if len(d.config.DatadogConfPath) > 0 {
+		return d.config.DatadogConfPath, nil
 	}
 	exist, err := d.fileSystem.VerifyDirExist("/etc/datadog-agent")
 	if err != nil {
@@ -183,10 +294,7 @@ func (d *DatadogLinuxOperation) DatadogAddPermitionUser() error {
 
 // BackupConfigFileDatadog execute backup the current config file datadog
 func (d *DatadogLinuxOperation) BackupConfigFileDatadog(filePath string, content []byte) error {
-	docpFilePath, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
+	docpFilePath := d.config.WorkDirPath
 	filePathState := filepath.Join(docpFilePath, "state", "datadog", filePath)
 	if err := d.fileSystem.VerifyFileExist(filePathState); err != nil {
 		if errCrt := d.fileSystem.CreatePathCompleted(filePathState); errCrt != nil {
@@ -201,10 +309,7 @@ func (d *DatadogLinuxOperation) BackupConfigFileDatadog(filePath string, content
 
 // UpdateConfigFileDatadog execute update the config file datadog
 func (d *DatadogLinuxOperation) UpdateConfigFileDatadog(filePath string) error {
-	docpFilePath, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
+	docpFilePath := d.config.WorkDirPath
 	datadogFilePathDir := filepath.Dir(filePath)
 	docpStateDatadogPath := filepath.Join(docpFilePath, "state", "datadog", filePath)
 	if err := d.program.Execute("sudo", []string{}, "-u", "dd-agent", "bash", "-c", fmt.Sprintf("mkdir -p %s", datadogFilePathDir)); err != nil {
@@ -0,0 +1,406 @@
+package components
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// DatadogInstallStep is func for report of step of install completed,
+// changed false when step was already in desired state
+type DatadogInstallStep func(step string, changed bool)
+
+// DatadogPackageInstaller is struct for install of datadog agent by
+// package manager of host, from repository of datadog verified by
+// gpg keys or from local package, without install script
+type DatadogPackageInstaller struct {
+	logger         interfaces.ILogger
+	program        *pkg.ExecProgram
+	config         dto.DatadogInstallConfig
+	runtimeConfig  dto.RuntimeConfig
+	packageManager string
+	rpm            bool
+}
+
+// NewDatadogPackageInstaller return instance of datadog package installer,
+// keys are fetched with network config of runtime config
+func NewDatadogPackageInstaller(logger interfaces.ILogger, program *pkg.ExecProgram, config dto.DatadogInstallConfig, runtimeConfig dto.RuntimeConfig) *DatadogPackageInstaller {
+	return &DatadogPackageInstaller{
+		logger:        logger.Named("datadog_package_installer"),
+		program:       program,
+		config:        config,
+		runtimeConfig: runtimeConfig,
+	}
+}
+
+// Setup execute discover of package manager of host
+func (i *DatadogPackageInstaller) Setup() error {
+	for _, packageManager := range []string{"apt-get", "dnf", "yum"} {
+		if _, err := exec.LookPath(packageManager); err == nil {
+			i.packageManager = packageManager
+			i.rpm = packageManager != "apt-get"
+			return nil
+		}
+	}
+	return pkg.ErrPackageManagerNotFound
+}
+
+// Install execute install of datadog agent in version with api key
+// and site in datadog.yaml, each step is reported when completed
+func (i *DatadogPackageInstaller) Install(ddSite, ddApiKey, version string, report DatadogInstallStep) error {
+	packageChanged, err := i.ensureAgentPackage(version, report)
+	if err != nil {
+		return err
+	}
+	configChanged, err := i.EnsureConfig(ddSite, ddApiKey)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	report("config", configChanged)
+	serviceChanged, err := i.EnsureService(packageChanged || configChanged)
+	if err != nil {
+		return fmt.Errorf("service: %w", err)
+	}
+	report("service", serviceChanged)
+	return nil
+}
+
+// Upgrade execute change of version of datadog agent installed,
+// keeping datadog.yaml, service is restarted when package changed
+func (i *DatadogPackageInstaller) Upgrade(version string, report DatadogInstallStep) error {
+	packageChanged, err := i.ensureAgentPackage(version, report)
+	if err != nil {
+		return err
+	}
+	serviceChanged, err := i.EnsureService(packageChanged)
+	if err != nil {
+		return fmt.Errorf("service: %w", err)
+	}
+	report("service", serviceChanged)
+	return nil
+}
+
+// ensureAgentPackage execute steps of package, from local package
+// when informed in config or from repository of datadog
+func (i *DatadogPackageInstaller) ensureAgentPackage(version string, report DatadogInstallStep) (bool, error) {
+	if len(i.config.LocalPackage) > 0 {
+		if err := i.VerifyLocalPackage(); err != nil {
+			return false, fmt.Errorf("verify: %w", err)
+		}
+		report("verify", false)
+		changed, err := i.EnsureLocalPackage()
+		if err != nil {
+			return false, fmt.Errorf("package: %w", err)
+		}
+		report("package", changed)
+		return changed, nil
+	}
+	changed, err := i.EnsureRepository()
+	if err != nil {
+		return false, fmt.Errorf("repository: %w", err)
+	}
+	report("repository", changed)
+	changed, err = i.EnsurePackage(version)
+	if err != nil {
+		return false, fmt.Errorf("package: %w", err)
+	}
+	report("package", changed)
+	return changed, nil
+}
+
+// EnsureRepository execute import of gpg keys and write of repository
+// of datadog, nothing is done when repository is already configured
+func (i *DatadogPackageInstaller) EnsureRepository() (bool, error) {
+	repoFile, content := pkg.DATADOG_APT_SOURCE_FILE, utils.DatadogAptSource(i.config)
+	if i.rpm {
+		repoFile, content = pkg.DATADOG_YUM_REPO_FILE, utils.DatadogYumRepo(i.config, utils.DatadogRpmArch(runtime.GOARCH))
+	}
+	current, err := os.ReadFile(repoFile)
+	if err == nil && string(current) == content && (i.rpm || i.fileExists(pkg.DATADOG_APT_KEYRING)) {
+		i.logger.Debug("repository already configured", "trace", "docp-agent-os-instance.datadog_package_installer.EnsureRepository", "repoFile", repoFile)
+		return false, nil
+	}
+	if err := i.importKeys(); err != nil {
+		return false, err
+	}
+	if err := writeRootFile(i.program, repoFile, []byte(content), "0644", ""); err != nil {
+		return false, err
+	}
+	if i.rpm {
+		if err := i.program.Execute("sudo", []string{}, i.packageManager, "makecache", "-y", "--disablerepo=*", "--enablerepo=datadog"); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err := i.program.Execute("sudo", []string{}, "apt-get", "update", "-o", "Dir::Etc::sourcelist="+pkg.DATADOG_APT_SOURCE_FILE, "-o", "Dir::Etc::sourceparts=-", "-o", "APT::Get::List-Cleanup=0"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// importKeys execute import of gpg keys of repository, in keyring
+// of apt source or in rpm database
+func (i *DatadogPackageInstaller) importKeys() error {
+	keyUrls := utils.DatadogGpgKeyUrls(i.config, i.rpm)
+	if !i.rpm {
+		if err := i.program.Execute("sudo", []string{}, "install", "-D", "-m", "0644", "/dev/null", pkg.DATADOG_APT_KEYRING); err != nil {
+			return err
+		}
+	}
+	for _, keyUrl := range keyUrls {
+		keyFile, err := i.fetchKey(keyUrl)
+		if err != nil {
+			return err
+		}
+		if i.rpm {
+			err = i.program.Execute("sudo", []string{}, "rpm", "--import", keyFile)
+		} else {
+			err = i.program.Execute("sudo", []string{}, "gpg", "--batch", "--no-default-keyring", "--keyring", pkg.DATADOG_APT_KEYRING, "--import", keyFile)
+		}
+		os.Remove(keyFile)
+		if err != nil {
+			return fmt.Errorf("import key %s: %w", keyUrl, err)
+		}
+	}
+	return nil
+}
+
+// fetchKey return temporary file with key of url, url file:// is file
+// of host for install without internet, other schemes are rejected
+func (i *DatadogPackageInstaller) fetchKey(keyUrl string) (string, error) {
+	var reader io.Reader
+	if err := utils.ValidateDatadogKeyUrl(keyUrl); err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(keyUrl, "https://") {
+		client, err := utils.NewOutboundHttpClient(i.runtimeConfig, 60*time.Second)
+		if err != nil {
+			return "", err
+		}
+		res, err := client.Get(keyUrl)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("download key %s: status %d", keyUrl, res.StatusCode)
+		}
+		reader = res.Body
+	} else {
+		file, err := os.Open(strings.TrimPrefix(keyUrl, "file://"))
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		reader = file
+	}
+	keyFile, err := os.CreateTemp("", "datadog-key-*.public")
+	if err != nil {
+		return "", err
+	}
+	defer keyFile.Close()
+	if _, err := io.Copy(keyFile, reader); err != nil {
+		os.Remove(keyFile.Name())
+		return "", err
+	}
+	return keyFile.Name(), nil
+}
+
+// EnsurePackage execute install of datadog agent from repository, nothing
+// is done when version installed match version pinned or when not
+// pinned and agent is already installed
+func (i *DatadogPackageInstaller) EnsurePackage(version string) (bool, error) {
+	installed := i.InstalledVersion()
+	command := "install"
+	if pin, ok := utils.ParseDatadogVersionPin(version); ok && len(installed) > 0 {
+		result, err := pin.Compare(installed)
+		if err != nil {
+			return false, err
+		}
+		if result == 0 {
+			return false, nil
+		}
+		if result > 0 && i.rpm {
+			command = "downgrade"
+		}
+	} else if len(installed) > 0 {
+		return false, nil
+	}
+	spec := utils.DatadogPackageSpec(i.rpm, version)
+	i.logger.Debug("install package", "trace", "docp-agent-os-instance.datadog_package_installer.EnsurePackage", "spec", spec, "installed", installed)
+	if err := i.installPackage(command, spec); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyLocalPackage execute verify of local package, checksum of config
+// is required and signature of rpm is verified by keys imported
+func (i *DatadogPackageInstaller) VerifyLocalPackage() error {
+	if len(i.config.LocalPackageSha256) == 0 {
+		return fmt.Errorf("%w: local_package_sha256 required for %s", pkg.ErrPackageChecksum, i.config.LocalPackage)
+	}
+	file, err := os.Open(i.config.LocalPackage)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+	if !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), i.config.LocalPackageSha256) {
+		return fmt.Errorf("%w: %s", pkg.ErrPackageChecksum, i.config.LocalPackage)
+	}
+	if i.rpm {
+		if len(i.config.GpgKeyUrls) > 0 {
+			if err := i.importKeys(); err != nil {
+				return err
+			}
+		}
+		if err := i.program.Execute("rpm", []string{}, "--checksig", i.config.LocalPackage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureLocalPackage execute install of local package, nothing is done
+// when version of package is already installed
+func (i *DatadogPackageInstaller) EnsureLocalPackage() (bool, error) {
+	localPackage, err := filepath.Abs(i.config.LocalPackage)
+	if err != nil {
+		return false, err
+	}
+	var output string
+	if i.rpm {
+		output, err = i.program.ExecuteWithOutput("rpm", []string{}, "-qp", "--qf", "%{VERSION}-%{RELEASE}", localPackage)
+	} else {
+		output, err = i.program.ExecuteWithOutput("dpkg-deb", []string{}, "-f", localPackage, "Version")
+	}
+	if err != nil {
+		return false, err
+	}
+	if installed := i.InstalledVersion(); len(installed) > 0 && installed == strings.TrimSpace(output) {
+		return false, nil
+	}
+	if err := i.installPackage("install", localPackage); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// installPackage execute command of package manager for package
+func (i *DatadogPackageInstaller) installPackage(command, spec string) error {
+	if i.rpm {
+		return i.program.Execute("sudo", []string{}, i.packageManager, command, "-y", spec)
+	}
+	return i.program.Execute("sudo", []string{}, "env", "DEBIAN_FRONTEND=noninteractive", "apt-get", command, "-y", "--allow-downgrades", spec)
+}
+
+// InstalledVersion return version of datadog agent installed,
+// empty when not installed
+func (i *DatadogPackageInstaller) InstalledVersion() string {
+	var output string
+	var err error
+	if i.rpm {
+		output, err = i.program.ExecuteWithOutput("rpm", []string{}, "-q", "--qf", "%{VERSION}-%{RELEASE}", pkg.DATADOG_PACKAGE_NAME)
+	} else {
+		output, err = i.program.ExecuteWithOutput("dpkg-query", []string{}, "-W", "-f=${db:Status-Abbrev}|${Version}", pkg.DATADOG_PACKAGE_NAME)
+		status, version, _ := strings.Cut(strings.TrimSpace(output), "|")
+		if !strings.HasPrefix(status, "ii") {
+			return ""
+		}
+		output = version
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// EnsureConfig execute write of api key and site in datadog.yaml, file
+// is created from example of package and not written when unchanged
+func (i *DatadogPackageInstaller) EnsureConfig(ddSite, ddApiKey string) (bool, error) {
+	if len(ddApiKey) == 0 {
+		return false, nil
+	}
+	content, err := i.program.ExecuteWithOutput("sudo", []string{}, "cat", pkg.DATADOG_CONFIG_FILE)
+	if err != nil {
+		example, errExample := os.ReadFile(pkg.DATADOG_CONFIG_FILE + ".example")
+		if errExample != nil && !errors.Is(errExample, os.ErrNotExist) {
+			return false, errExample
+		}
+		content = string(example)
+	}
+	merged, changed, err := utils.MergeDatadogConfig([]byte(content), ddApiKey, ddSite)
+	if err != nil {
+		return false, err
+	}
+	if !changed && i.fileExists(pkg.DATADOG_CONFIG_FILE) {
+		return false, nil
+	}
+	if err := writeRootFile(i.program, pkg.DATADOG_CONFIG_FILE, merged, "0640", "dd-agent"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureService execute enable and start of service of datadog agent,
+// restart when package or config changed
+func (i *DatadogPackageInstaller) EnsureService(restart bool) (bool, error) {
+	if err := i.program.Execute("sudo", []string{}, "systemctl", "enable", pkg.DATADOG_SERVICE_NAME); err != nil {
+		return false, err
+	}
+	command := "start"
+	if restart {
+		command = "restart"
+	}
+	if err := i.program.Execute("sudo", []string{}, "systemctl", command, pkg.DATADOG_SERVICE_NAME); err != nil {
+		return false, err
+	}
+	return restart, nil
+}
+
+// writeRootFile execute write of file owned by root or by owner
+// informed, content is written in temporary file and installed
+func writeRootFile(program *pkg.ExecProgram, filePath string, content []byte, mode, owner string) error {
+	tmpFile, err := os.CreateTemp("", "datadog-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	args := []string{"install", "-D", "-m", mode}
+	if len(owner) > 0 {
+		args = append(args, "-o", owner, "-g", owner)
+	}
+	args = append(args, tmpFile.Name(), filePath)
+	return program.Execute("sudo", []string{}, args...)
+}
+
+// fileExists return if file exists, file not readable by agent exists
+func (i *DatadogPackageInstaller) fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
@@ -9,7 +9,7 @@ import (
 
 type DatadogWindowsOperation struct{}
 
-func NewDatadogWindowsOperation(logger interfaces.ILogger) *DatadogWindowsOperation {
+func NewDatadogWindowsOperation(logger interfaces.ILogger, config dto.RuntimeConfig) *DatadogWindowsOperation {
 	return &DatadogWindowsOperation{}
 }
 
@@ -20,7 +20,7 @@ func (d *DatadogWindowsOperation) prepareEnvs(ddSite, ddApiKey string) []string
 }
 
 // sendStatus execute send status for state check
-func (d *DatadogWindowsOperation) sendStatus(status, message string) error {
+func (d *DatadogWindowsOperation) sendStatus(transactionId, status, typeEvent, message string) error {
 	return nil
 }
 
@@ -34,7 +34,12 @@ func (d *DatadogWindowsOperation) Setup() error {
 }
 
 // InstallAgent execute install the agent in linux
-func (d *DatadogWindowsOperation) InstallAgent(ddSite, ddApiKey string) error {
+func (d *DatadogWindowsOperation) InstallAgent(ddSite, ddApiKey, version, transactionId string) error {
+	return nil
+}
+
+// UpgradeAgent execute upgrade or downgrade of agent to version pinned
+func (d *DatadogWindowsOperation) UpgradeAgent(version, transactionId string) error {
 	return nil
 }
 
@@ -20,11 +20,13 @@ import (
 )
 
 const (
-	URL_DATADOG_AGENT = "https://s3.amazonaws.com/ddagent-windows-stable/datadog-agent-7-latest.amd64.msi"
+	URL_DATADOG_AGENT         = "https://s3.amazonaws.com/ddagent-windows-stable/datadog-agent-7-latest.amd64.msi"
+	URL_DATADOG_AGENT_VERSION = "https://s3.amazonaws.com/ddagent-windows-stable/ddagent-cli-%s.msi"
 )
 
 type DatadogWindowsOperation struct {
 	logger           interfaces.ILogger
+	config           dto.RuntimeConfig
 	program          *pkg.ExecProgram
 	hostStats        *pkg.HostStats
 	stateCheck       *services.StateCheckService
@@ -32,9 +34,10 @@ type DatadogWindowsOperation struct {
 	datadogApmTracer *DatadogWindowsAPMTracer
 }
 
-func NewDatadogWindowsOperation(logger interfaces.ILogger) *DatadogWindowsOperation {
+func NewDatadogWindowsOperation(logger interfaces.ILogger, config dto.RuntimeConfig) *DatadogWindowsOperation {
 	return &DatadogWindowsOperation{
-		logger: logger,
+		logger: logger.Named("datadog_windows_operations"),
+		config: config,
 	}
 }
 
@@ -68,7 +71,7 @@ func (d *DatadogWindowsOperation) Setup() error {
 	d.program = execProgram
 	hostStats := pkg.NewHostStats()
 	d.hostStats = hostStats
-	stateCheck := services.NewStateCheckService(d.logger)
+	stateCheck := services.NewStateCheckService(d.logger, d.config)
 	if err := stateCheck.Setup(); err != nil {
 		return err
 	}
@@ -80,8 +83,48 @@ func (d *DatadogWindowsOperation) Setup() error {
 	return nil
 }
 
-// InstallAgent execute install the agent in linux
-func (d *DatadogWindowsOperation) InstallAgent(ddSite, ddApiKey string) error {
+// agentInstallerUrl return url of installer for version, only exact
+// version have installer, pin of minor install latest
+func (d *DatadogWindowsOperation) agentInstallerUrl(version string) string {
+	if pin, ok := utils.ParseDatadogVersionPin(version); ok && pin.Exact {
+		return fmt.Sprintf(URL_DATADOG_AGENT_VERSION, pin.String())
+	}
+	return URL_DATADOG_AGENT
+}
+
+// sendStatus execute send status in transaction of manager for
+// state check, without transaction status is not sent
+func (d *DatadogWindowsOperation) sendStatus(transactionId, status, typeEvent, message string) error {
+	if len(transactionId) == 0 {
+		return nil
+	}
+	transaction := dto.TransactionStatus{
+		ID:        transactionId,
+		UlidEvent: utils.GetUlid(),
+		TypeEvent: typeEvent,
+		Status:    status,
+		Message:   message,
+	}
+	if _, _, err := d.stateCheck.SendStatus(transaction); err != nil {
+		d.logger.Error("error in send status", "trace", "docp-agent-os-instance.datadog_windows_operations.sendStatus", "status", status, "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// InstallAgent execute install the agent in windows, transaction
+// is closed when install is finished
+func (d *DatadogWindowsOperation) InstallAgent(ddSite, ddApiKey, version, transactionId string) error {
+	if err := d.installAgent(ddSite, ddApiKey, version); err != nil {
+		d.sendStatus(transactionId, "install_docp_vendor_error", pkg.TransactionEventClose, "failed install docp vendor: "+err.Error())
+		return err
+	}
+	d.sendStatus(transactionId, "install_docp_vendor_completed", pkg.TransactionEventClose, "install docp vendor completed")
+	return nil
+}
+
+// installAgent execute install the agent by msi when service not exists
+func (d *DatadogWindowsOperation) installAgent(ddSite, ddApiKey, version string) error {
 	m, err := mgr.Connect()
 	if err != nil {
 		return err
@@ -91,8 +134,9 @@ func (d *DatadogWindowsOperation) InstallAgent(ddSite, ddApiKey string) error {
 	if err != nil {
 		d.logger.Error("error in install datadog agent", "error", err)
 		if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
-			command := fmt.Sprintf(`Start-Process -Wait msiexec -ArgumentList '/qn /i %s APIKEY="%s" SITE="%s"'`, URL_DATADOG_AGENT, ddApiKey, ddSite)
-			out, err := d.program.ExecuteWithOutput("powershell", []string{}, "-Command", command)
+			// keys are read from environment by powershell, never in command line
+			command := fmt.Sprintf(`Start-Process -Wait msiexec -ArgumentList ('/qn /i %s APIKEY="' + $env:DD_API_KEY + '" SITE="' + $env:DD_SITE + '"')`, d.agentInstallerUrl(version))
+			out, err := d.program.ExecuteWithOutput("powershell", d.prepareEnvs(ddSite, ddApiKey), "-Command", command)
 			if err != nil {
 				d.logger.Error("error in install datadog agent start process", "error", err)
 				return err
@@ -106,6 +150,23 @@ func (d *DatadogWindowsOperation) InstallAgent(ddSite, ddApiKey string) error {
 	return nil
 }
 
+// UpgradeAgent execute upgrade or downgrade of agent installed to
+// exact version, installer keep configuration of agent
+func (d *DatadogWindowsOperation) UpgradeAgent(version, transactionId string) error {
+	pin, ok := utils.ParseDatadogVersionPin(version)
+	if !ok || !pin.Exact {
+		return fmt.Errorf("%w: %s", pkg.ErrInvalidVersion, version)
+	}
+	command := fmt.Sprintf(`Start-Process -Wait msiexec -ArgumentList ('/qn /i %s')`, d.agentInstallerUrl(version))
+	out, err := d.program.ExecuteWithOutput("powershell", []string{}, "-Command", command)
+	if err != nil {
+		d.logger.Error("error in upgrade datadog agent", "error", err)
+		return err
+	}
+	d.logger.Debug("upgrade agent datadog", "output", out)
+	return nil
+}
+
 // InstallAgentApmSingleStep execute install the agent in linux with apm tracer on mode single step
 func (d *DatadogWindowsOperation) InstallAgentApmSingleStep(ddSite string, ddApiKey string, datadogEnvVars []dto.DatadogEnvVars) error {
 	d.logger.Debug("install agent apm single step", "trace", "docp-agent-os-instance.datadog_windows_operations.InstallAgentApmSingleStep")
@@ -148,9 +209,8 @@ func (d *DatadogWindowsOperation) UninstallAgent() error {
 
 // DiscoverDatadogConfigPath return file path the datadog config
 func (d *DatadogWindowsOperation) DiscoverDatadogConfigPath() (string, error) {
-	ddConfPathEnv := os.Getenv("DD_CONF_PATH")
-	if len(ddConfPathEnv) > 0 {
-		return ddConfPathEnv, nil
+	if len(d.config.DatadogConfPath) > 0 {
+		return d.config.DatadogConfPath, nil
 	}
 	programData := os.Getenv("ProgramData")
 
@@ -170,10 +230,7 @@ func (d *DatadogWindowsOperation) DatadogAddPermitionUser() error {
 
 // BackupConfigFileDatadog execute backup the current config file datadog
 func (d *DatadogWindowsOperation) BackupConfigFileDatadog(filePath string, content []byte) error {
-	docpFilePath, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
+	docpFilePath := d.config.WorkDirPath
 	programData := os.Getenv("ProgramData")
 	basePath := filepath.Join(programData, "Datadog")
 	filteredPath := strings.TrimPrefix(filePath, basePath)
@@ -191,10 +248,7 @@ func (d *DatadogWindowsOperation) BackupConfigFileDatadog(filePath string, conte
 
 // UpdateConfigFileDatadog execute update the config file datadog
 func (d *DatadogWindowsOperation) UpdateConfigFileDatadog(filePath string) error {
-	docpFilePath, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
+	docpFilePath := d.config.WorkDirPath
 	programData := os.Getenv("ProgramData")
 	basePath := filepath.Join(programData, "Datadog")
 	filteredPath := strings.TrimPrefix(filePath, basePath)
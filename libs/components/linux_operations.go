@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
@@ -21,15 +22,17 @@ const (
 // LinuxOperations is instance of linux operations
 type LinuxOperations struct {
 	logger     interfaces.ILogger
+	config     dto.RuntimeConfig
 	systemd    *pkg.SystemdClient
 	fileSystem *pkg.FileSystem
 	program    *pkg.ExecProgram
 }
 
 // NewLinuxOperations return instance of linux operations
-func NewLinuxOperations(looger interfaces.ILogger) *LinuxOperations {
+func NewLinuxOperations(looger interfaces.ILogger, config dto.RuntimeConfig) *LinuxOperations {
 	return &LinuxOperations{
-		logger:  looger,
+		logger:  looger.Named("linux_operations"),
+		config:  config,
 		program: pkg.NewExecProgram(),
 	}
 }
@@ -98,13 +101,8 @@ func (l *LinuxOperations) InstallAgent(version string) error {
 
 // InstallUpdater execute install the updater docp
 func (l *LinuxOperations) InstallUpdater(version string) error {
-	workdir, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
-
 	//validate path version
-	pathVersion := filepath.Join(workdir, "bin", "releases", version)
+	pathVersion := filepath.Join(utils.GetReleasesDirPath(l.config.WorkDirPath), version)
 	if err := l.fileSystem.VerifyDirExistAndCreate(pathVersion); err != nil {
 		return err
 	}
@@ -161,3 +159,7 @@ func (l *LinuxOperations) AutoUninstall() error {
 func (l *LinuxOperations) Execute() error {
 	return nil
 }
+
+// SetStopHandler configure handler of stop, service manager
+// of os not send stop to process
+func (l *LinuxOperations) SetStopHandler(stop func(), stopped <-chan struct{}) {}
@@ -26,7 +26,7 @@ type MacosOperations struct {
 // NewMacosOperations return instance of macos operations
 func NewMacosOperations(logger interfaces.ILogger) *MacosOperations {
 	return &MacosOperations{
-		logger:    logger,
+		logger:    logger.Named("macos_operations"),
 		program:   pkg.NewExecProgram(),
 		hostStats: pkg.NewHostStats(),
 	}
@@ -137,3 +137,7 @@ func (l *MacosOperations) AutoUninstall() error {
 func (l *MacosOperations) Execute() error {
 	return nil
 }
+
+// SetStopHandler configure handler of stop, service manager
+// of os not send stop to process
+func (l *MacosOperations) SetStopHandler(stop func(), stopped <-chan struct{}) {}
@@ -4,14 +4,15 @@ import (
 	"errors"
 	"runtime"
 
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 )
 
 // SystemOperation return os operations for manager
-func SystemOperation(logger interfaces.ILogger) (interfaces.IOSOperation, error) {
+func SystemOperation(logger interfaces.ILogger, config dto.RuntimeConfig) (interfaces.IOSOperation, error) {
 	switch runtime.GOOS {
 	case "linux":
-		return NewLinuxOperations(logger), nil
+		return NewLinuxOperations(logger, config), nil
 	case "darwin":
 		return NewMacosOperations(logger), nil
 	case "windows":
@@ -21,14 +22,23 @@ func SystemOperation(logger interfaces.ILogger) (interfaces.IOSOperation, error)
 }
 
 // DatadogOperation return datadog operation
-func DatadogOperation(logger interfaces.ILogger) (interfaces.IDatadogOperation, error) {
+func DatadogOperation(logger interfaces.ILogger, config dto.RuntimeConfig) (interfaces.IDatadogOperation, error) {
 	switch runtime.GOOS {
 	case "linux":
-		return NewDatadogLinuxOperation(logger), nil
+		return NewDatadogLinuxOperation(logger, config), nil
 	case "darwin":
 		return nil, nil
 	case "windows":
-		return NewDatadogWindowsOperation(logger), nil
+		return NewDatadogWindowsOperation(logger, config), nil
 	}
 	return nil, errors.New("datadog operation not configured")
 }
+
+// VendorOperation return operation for monitoring vendors
+func VendorOperation(logger interfaces.ILogger) (interfaces.IVendorOperation, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return NewVendorLinuxOperation(logger), nil
+	}
+	return nil, errors.New("vendor operation not configured")
+}
@@ -0,0 +1,236 @@
+package components
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// VendorDiscovery is struct for discovery of monitoring vendors on host
+type VendorDiscovery struct {
+	logger  interfaces.ILogger
+	program *pkg.ExecProgram
+	vendors []pkg.VendorDefinition
+	sources VendorSources
+}
+
+// VendorSources is struct for sources of vendors on host, source
+// nil is configured in setup for os, packages return version by
+// name of package installed
+type VendorSources struct {
+	ServiceStates func(units []string) (map[string]string, error)
+	Packages      func() (map[string]string, error)
+	Processes     func() ([]dto.ProcessInfo, error)
+}
+
+// NewVendorDiscovery return instance of vendor discovery
+func NewVendorDiscovery(logger interfaces.ILogger) *VendorDiscovery {
+	return NewVendorDiscoveryWithSources(logger, VendorSources{})
+}
+
+// NewVendorDiscoveryWithSources return instance of vendor discovery
+// with sources of vendors
+func NewVendorDiscoveryWithSources(logger interfaces.ILogger, sources VendorSources) *VendorDiscovery {
+	return &VendorDiscovery{
+		logger:  logger.Named("vendor_discovery"),
+		program: pkg.NewExecProgram(),
+		vendors: pkg.KnownVendors,
+		sources: sources,
+	}
+}
+
+// Setup configure vendor discovery
+func (v *VendorDiscovery) Setup() error {
+	if v.sources.Processes == nil {
+		v.sources.Processes = pkg.NewHostStats().ProcessInfo
+	}
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	if v.sources.ServiceStates == nil {
+		v.sources.ServiceStates = pkg.NewSystemdClient().UnitsActiveState
+	}
+	if v.sources.Packages == nil {
+		v.sources.Packages = v.installedPackages
+	}
+	return nil
+}
+
+// Discover return monitoring vendors found on host by service units,
+// package database and running processes
+func (v *VendorDiscovery) Discover() ([]dto.VendorInfo, error) {
+	processes, err := v.sources.Processes()
+	if err != nil {
+		return nil, err
+	}
+	return v.DiscoverFrom(pkg.ProcessVendors(processes)), nil
+}
+
+// DiscoverFrom return monitoring vendors found on host by service
+// units, package database and vendors of processes already collected
+func (v *VendorDiscovery) DiscoverFrom(processVendors []string) []dto.VendorInfo {
+	v.logger.Debug("discover vendors", "trace", "docp-agent-os-instance.vendor_discovery.DiscoverFrom")
+	found := make(map[string]*dto.VendorInfo)
+	if v.sources.ServiceStates != nil {
+		v.discoverServices(found)
+	}
+	if v.sources.Packages != nil {
+		v.discoverPackages(found)
+	}
+	for _, vendor := range processVendors {
+		v.mark(found, vendor, pkg.VENDOR_SOURCE_PROCESS, pkg.VENDOR_STATUS_RUNNING)
+	}
+	vendors := []dto.VendorInfo{}
+	for _, vendor := range v.vendors {
+		if info, ok := found[vendor.Name]; ok {
+			vendors = append(vendors, *info)
+		}
+	}
+	v.logger.Debug("vendors discovered", "trace", "docp-agent-os-instance.vendor_discovery.DiscoverFrom", "vendors", vendors)
+	return vendors
+}
+
+// mark execute register source and status for vendor,
+// keeping the status with highest priority
+func (v *VendorDiscovery) mark(found map[string]*dto.VendorInfo, name, source, status string) *dto.VendorInfo {
+	info, ok := found[name]
+	if !ok {
+		info = &dto.VendorInfo{Name: name, Status: status}
+		found[name] = info
+	}
+	exist := false
+	for _, s := range info.Sources {
+		if s == source {
+			exist = true
+			break
+		}
+	}
+	if !exist {
+		info.Sources = append(info.Sources, source)
+	}
+	if vendorStatusPriority(status) > vendorStatusPriority(info.Status) {
+		info.Status = status
+	}
+	return info
+}
+
+// vendorStatusPriority return priority of vendor status
+func vendorStatusPriority(status string) int {
+	switch status {
+	case pkg.VENDOR_STATUS_RUNNING:
+		return 3
+	case pkg.VENDOR_STATUS_STOPPED:
+		return 2
+	case pkg.VENDOR_STATUS_INSTALLED:
+		return 1
+	}
+	return 0
+}
+
+// discoverServices execute discovery of vendors by systemd units
+func (v *VendorDiscovery) discoverServices(found map[string]*dto.VendorInfo) {
+	var units []string
+	for _, vendor := range v.vendors {
+		units = append(units, vendor.ServiceUnits...)
+	}
+	states, err := v.sources.ServiceStates(units)
+	if err != nil {
+		v.logger.Debug("systemd not available for discovery", "trace", "docp-agent-os-instance.vendor_discovery.discoverServices", "error", err.Error())
+		return
+	}
+	for _, vendor := range v.vendors {
+		for _, unit := range vendor.ServiceUnits {
+			state, ok := states[unit]
+			if !ok {
+				continue
+			}
+			status := pkg.VENDOR_STATUS_STOPPED
+			if state == "active" {
+				status = pkg.VENDOR_STATUS_RUNNING
+			}
+			v.mark(found, vendor.Name, pkg.VENDOR_SOURCE_SERVICE, status)
+		}
+	}
+}
+
+// discoverPackages execute discovery of vendors by package database
+func (v *VendorDiscovery) discoverPackages(found map[string]*dto.VendorInfo) {
+	packages, err := v.sources.Packages()
+	if err != nil {
+		v.logger.Debug("package manager not available for discovery", "trace", "docp-agent-os-instance.vendor_discovery.discoverPackages", "error", err.Error())
+		return
+	}
+	for _, vendor := range v.vendors {
+		for _, packageName := range vendor.Packages {
+			version, installed := packages[packageName]
+			if !installed {
+				continue
+			}
+			info := v.mark(found, vendor.Name, pkg.VENDOR_SOURCE_PACKAGE, pkg.VENDOR_STATUS_INSTALLED)
+			if len(info.Version) == 0 {
+				info.Version = version
+			}
+		}
+	}
+}
+
+// PackageVersion return version of package of vendor installed,
+// false when package database not available or not installed
+func (v *VendorDiscovery) PackageVersion(vendor string) (string, bool) {
+	if v.sources.Packages == nil {
+		return "", false
+	}
+	packages, err := v.sources.Packages()
+	if err != nil {
+		return "", false
+	}
+	for _, definition := range v.vendors {
+		if definition.Name != vendor {
+			continue
+		}
+		for _, packageName := range definition.Packages {
+			if version, installed := packages[packageName]; installed {
+				return version, true
+			}
+		}
+	}
+	return "", false
+}
+
+// installedPackages return version of packages installed by dpkg or
+// rpm, listed in one call of package manager
+func (v *VendorDiscovery) installedPackages() (map[string]string, error) {
+	var output string
+	var err error
+	installedPrefix := ""
+	if _, errLook := exec.LookPath("dpkg-query"); errLook == nil {
+		output, err = v.program.ExecuteWithOutput("dpkg-query", []string{}, "-W", "-f=${db:Status-Abbrev}|${Package}|${Version}\n")
+		installedPrefix = "ii"
+	} else if _, errLook := exec.LookPath("rpm"); errLook == nil {
+		output, err = v.program.ExecuteWithOutput("rpm", []string{}, "-qa", "--qf", "|%{NAME}|%{VERSION}-%{RELEASE}\n")
+	} else {
+		return nil, pkg.ErrPackageManagerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseInstalledPackages(output, installedPrefix), nil
+}
+
+// parseInstalledPackages return version by package from lines of
+// status|name|version, only lines with status of prefix are installed
+func parseInstalledPackages(output, installedPrefix string) map[string]string {
+	packages := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 3)
+		if len(parts) != 3 || !strings.HasPrefix(parts[0], installedPrefix) {
+			continue
+		}
+		packages[parts[1]] = parts[2]
+	}
+	return packages
+}
@@ -0,0 +1,133 @@
+package components
+
+import (
+	"os/exec"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// VendorLinuxOperation is struct for operations in monitoring vendors on linux
+type VendorLinuxOperation struct {
+	logger     interfaces.ILogger
+	program    *pkg.ExecProgram
+	fileSystem *pkg.FileSystem
+}
+
+// NewVendorLinuxOperation return instance of vendor linux operation
+func NewVendorLinuxOperation(logger interfaces.ILogger) *VendorLinuxOperation {
+	return &VendorLinuxOperation{
+		logger: logger.Named("vendor_linux_operations"),
+	}
+}
+
+// Setup configure vendor linux operation
+func (v *VendorLinuxOperation) Setup() error {
+	v.program = pkg.NewExecProgram()
+	v.fileSystem = pkg.NewFileSystem()
+	return nil
+}
+
+// UninstallVendor execute uninstall recipe of vendor,
+// stopping services, removing packages and purging files
+func (v *VendorLinuxOperation) UninstallVendor(name string) error {
+	v.logger.Debug("uninstall vendor", "trace", "docp-agent-os-instance.vendor_linux_operations.UninstallVendor", "vendor", name)
+	vendor, ok := pkg.GetVendorDefinition(name)
+	if !ok || vendor.Name == "datadog" {
+		return pkg.ErrVendorNotSupported
+	}
+	if len(vendor.Packages) == 0 && len(vendor.UninstallCommand) == 0 {
+		return pkg.ErrVendorNotSupported
+	}
+	v.stopServices(vendor)
+	if err := v.runUninstallCommand(vendor); err != nil {
+		return err
+	}
+	if err := v.removePackages(vendor); err != nil {
+		return err
+	}
+	v.purgePaths(vendor)
+	if len(vendor.ServiceUnits) > 0 {
+		if err := v.program.Execute("sudo", []string{}, "systemctl", "daemon-reload"); err != nil {
+			v.logger.Warn("failed daemon reload", "trace", "docp-agent-os-instance.vendor_linux_operations.UninstallVendor", "vendor", name, "error", err.Error())
+		}
+	}
+	return nil
+}
+
+// stopServices execute stop and disable the services of vendor,
+// failures are ignored because the service may not exist
+func (v *VendorLinuxOperation) stopServices(vendor pkg.VendorDefinition) {
+	for _, unit := range vendor.ServiceUnits {
+		if err := v.program.Execute("sudo", []string{}, "systemctl", "disable", "--now", unit); err != nil {
+			v.logger.Debug("failed stop service vendor", "trace", "docp-agent-os-instance.vendor_linux_operations.stopServices", "unit", unit, "error", err.Error())
+		}
+	}
+}
+
+// runUninstallCommand execute uninstall command of vendor when exists on host
+func (v *VendorLinuxOperation) runUninstallCommand(vendor pkg.VendorDefinition) error {
+	if len(vendor.UninstallCommand) == 0 {
+		return nil
+	}
+	if err := v.fileSystem.VerifyFileExist(vendor.UninstallCommand[0]); err != nil {
+		v.logger.Debug("uninstall command not found", "trace", "docp-agent-os-instance.vendor_linux_operations.runUninstallCommand", "command", vendor.UninstallCommand[0])
+		return nil
+	}
+	return v.program.Execute("sudo", []string{}, vendor.UninstallCommand...)
+}
+
+// removePackages execute purge of packages of vendor
+// with package manager available
+func (v *VendorLinuxOperation) removePackages(vendor pkg.VendorDefinition) error {
+	if len(vendor.Packages) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("apt-get"); err == nil {
+		return v.removePackagesInstalled([]string{"apt-get", "purge", "-y"}, vendor.Packages, v.dpkgInstalled)
+	}
+	if _, err := exec.LookPath("yum"); err == nil {
+		return v.removePackagesInstalled([]string{"yum", "remove", "-y"}, vendor.Packages, v.rpmInstalled)
+	}
+	if _, err := exec.LookPath("zypper"); err == nil {
+		return v.removePackagesInstalled([]string{"zypper", "--non-interactive", "remove"}, vendor.Packages, v.rpmInstalled)
+	}
+	return pkg.ErrVendorNotSupported
+}
+
+// removePackagesInstalled execute remove command only
+// for packages installed on host
+func (v *VendorLinuxOperation) removePackagesInstalled(command []string, packages []string, installed func(string) bool) error {
+	args := append([]string{}, command...)
+	for _, packageName := range packages {
+		if installed(packageName) {
+			args = append(args, packageName)
+		}
+	}
+	if len(args) == len(command) {
+		return nil
+	}
+	v.logger.Debug("remove packages vendor", "trace", "docp-agent-os-instance.vendor_linux_operations.removePackagesInstalled", "args", args)
+	return v.program.Execute("sudo", []string{}, args...)
+}
+
+// dpkgInstalled return if package installed by dpkg
+func (v *VendorLinuxOperation) dpkgInstalled(packageName string) bool {
+	_, err := v.program.ExecuteWithOutput("dpkg-query", []string{}, "-W", packageName)
+	return err == nil
+}
+
+// rpmInstalled return if package installed by rpm
+func (v *VendorLinuxOperation) rpmInstalled(packageName string) bool {
+	_, err := v.program.ExecuteWithOutput("rpm", []string{}, "-q", packageName)
+	return err == nil
+}
+
+// purgePaths execute remove of config and log paths of vendor
+func (v *VendorLinuxOperation) purgePaths(vendor pkg.VendorDefinition) {
+	for _, path := range vendor.PurgePaths {
+		if err := v.program.Execute("sudo", []string{}, "rm", "-rf", path); err != nil {
+			v.logger.Warn("failed purge path vendor", "trace", "docp-agent-os-instance.vendor_linux_operations.purgePaths", "path", path, "error", err.Error())
+		}
+	}
+}
@@ -41,7 +41,7 @@ func NewWindowsOperations(logger interfaces.ILogger) *WindowsOperations {
 		srvName = "DocpManager"
 	}
 	return &WindowsOperations{
-		logger:                 logger,
+		logger:                 logger.Named("windows_operations"),
 		program:                pkg.NewExecProgram(),
 		filesystem:             pkg.NewFileSystem(),
 		ymlClient:              pkg.NewYmlClient(),
@@ -282,3 +282,9 @@ func (l *WindowsOperations) Execute() error {
 	}
 	return nil
 }
+
+// SetStopHandler configure func called when scm request stop
+// and channel closed when shutdown is finished
+func (l *WindowsOperations) SetStopHandler(stop func(), stopped <-chan struct{}) {
+	l.scmManager.handlerSCM.SetStopHandler(stop, stopped)
+}
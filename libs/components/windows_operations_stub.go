@@ -97,3 +97,7 @@ func (l *WindowsOperations) AutoUninstall() error {
 func (l *WindowsOperations) Execute() error {
 	return nil
 }
+
+// SetStopHandler configure handler of stop, service manager
+// of os not send stop to process
+func (l *WindowsOperations) SetStopHandler(stop func(), stopped <-chan struct{}) {}
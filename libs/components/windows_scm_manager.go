@@ -5,30 +5,56 @@ package components
 import (
 	"time"
 
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 	"golang.org/x/sys/windows/svc"
 )
 
-type HandlerSCM struct{}
+type HandlerSCM struct {
+	stop    func()
+	stopped <-chan struct{}
+}
 
 func NewHandlerSCM() *HandlerSCM {
 	return &HandlerSCM{}
 }
 
+// SetStopHandler configure func called on stop of service
+// and channel closed when process finish the shutdown
+func (h *HandlerSCM) SetStopHandler(stop func(), stopped <-chan struct{}) {
+	h.stop = stop
+	h.stopped = stopped
+}
+
+// waitStopped execute stop of process and wait the shutdown, bounded
+// by timeout of shutdown
+func (h *HandlerSCM) waitStopped() {
+	if h.stop != nil {
+		h.stop()
+	}
+	if h.stopped == nil {
+		return
+	}
+	select {
+	case <-h.stopped:
+	case <-time.After(time.Second * (pkg.SHUTDOWN_TASKS_TIMEOUT + pkg.SHUTDOWN_ACTIONS_TIMEOUT)):
+	}
+}
+
 func (h *HandlerSCM) Execute(args []string, changes <-chan svc.ChangeRequest, status chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
 	status <- svc.Status{State: svc.StartPending}
 	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
 
-	for {
-		select {
-		case change := <-changes:
-			if change.Cmd == svc.Stop || change.Cmd == svc.Shutdown {
-				status <- svc.Status{State: svc.StopPending}
-				return false, 0
-			}
-		default:
-			time.Sleep(5 * time.Second)
+	for change := range changes {
+		switch change.Cmd {
+		case svc.Interrogate:
+			status <- change.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending, WaitHint: uint32((pkg.SHUTDOWN_TASKS_TIMEOUT + pkg.SHUTDOWN_ACTIONS_TIMEOUT) * 1000)}
+			h.waitStopped()
+			return false, 0
 		}
 	}
+	return false, 0
 }
 
 type SCMManager struct {
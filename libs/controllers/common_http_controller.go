@@ -17,7 +17,7 @@ type CommonHttpController struct {
 // NewCommonHttpController return instance of common http controller
 func NewCommonHttpController(logger interfaces.ILogger) *CommonHttpController {
 	return &CommonHttpController{
-		logger: logger,
+		logger: logger.Named("common_http_controller"),
 	}
 }
 
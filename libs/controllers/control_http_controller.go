@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+)
+
+// ControlHttpController is struct the controller for
+// local control socket of manager
+type ControlHttpController struct {
+	logger  interfaces.ILogger
+	control interfaces.IManagerControl
+}
+
+// NewControlHttpController return instance of control http controller
+func NewControlHttpController(logger interfaces.ILogger, control interfaces.IManagerControl) *ControlHttpController {
+	return &ControlHttpController{
+		logger:  logger.Named("control_http_controller"),
+		control: control,
+	}
+}
+
+// Setup execute configuration the controller
+func (c *ControlHttpController) Setup() error {
+	return nil
+}
+
+// writeJSON execute write of response in json
+func (c *ControlHttpController) writeJSON(w http.ResponseWriter, statusCode int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		c.logger.Error("error in marshal response control", "trace", "docp-agent-os-instance.control_http_controller.writeJSON", "error", err.Error())
+	}
+}
+
+// writeError execute write of error response
+func (c *ControlHttpController) writeError(w http.ResponseWriter, statusCode int, err error) {
+	c.writeJSON(w, statusCode, &dto.ControlResponse{Status: "error", Message: err.Error()})
+}
+
+// writeAccepted execute write of accepted response
+func (c *ControlHttpController) writeAccepted(w http.ResponseWriter, message string) {
+	c.writeJSON(w, http.StatusAccepted, &dto.ControlResponse{Status: "accepted", Message: message})
+}
+
+// Status execute return of status of manager
+func (c *ControlHttpController) Status(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("status", "trace", "docp-agent-os-instance.control_http_controller.Status")
+	status, err := c.control.ControlStatus()
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	c.writeJSON(w, http.StatusOK, &status)
+}
+
+// PlanActions execute return of actions manager would execute now
+func (c *ControlHttpController) PlanActions(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("plan actions", "trace", "docp-agent-os-instance.control_http_controller.PlanActions")
+	actions, err := c.control.ControlPlanActions()
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if actions == nil {
+		actions = []dto.StateAction{}
+	}
+	c.writeJSON(w, http.StatusOK, actions)
+}
+
+// Tasks execute return of tasks in flight
+func (c *ControlHttpController) Tasks(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("tasks", "trace", "docp-agent-os-instance.control_http_controller.Tasks")
+	c.writeJSON(w, http.StatusOK, c.control.ControlTasks())
+}
+
+// Errors execute return of last errors received
+func (c *ControlHttpController) Errors(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("errors", "trace", "docp-agent-os-instance.control_http_controller.Errors")
+	c.writeJSON(w, http.StatusOK, c.control.ControlErrors())
+}
+
+// ForcePoll execute poll of state check now
+func (c *ControlHttpController) ForcePoll(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("force poll", "trace", "docp-agent-os-instance.control_http_controller.ForcePoll")
+	if err := c.control.ControlForcePoll(); err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	c.writeAccepted(w, "state poll executed")
+}
+
+// ForceMetadataSync execute send of metadata now
+func (c *ControlHttpController) ForceMetadataSync(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("force metadata sync", "trace", "docp-agent-os-instance.control_http_controller.ForceMetadataSync")
+	if err := c.control.ControlForceMetadataSync(); err != nil {
+		c.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	c.writeAccepted(w, "metadata sync requested")
+}
+
+// Pause execute pause of reconciliation
+func (c *ControlHttpController) Pause(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("pause", "trace", "docp-agent-os-instance.control_http_controller.Pause")
+	c.control.ControlPause()
+	c.writeAccepted(w, "reconciliation paused")
+}
+
+// Resume execute resume of reconciliation
+func (c *ControlHttpController) Resume(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("resume", "trace", "docp-agent-os-instance.control_http_controller.Resume")
+	c.control.ControlResume()
+	c.writeAccepted(w, "reconciliation resumed")
+}
+
+// Update execute update of agent for version or constraint
+func (c *ControlHttpController) Update(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("update", "trace", "docp-agent-os-instance.control_http_controller.Update")
+	var updateRequest dto.ControlUpdateRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+		c.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(updateRequest.Version) == 0 {
+		c.writeError(w, http.StatusBadRequest, fmt.Errorf("version is required"))
+		return
+	}
+	if err := c.control.ControlUpdate(updateRequest.Version, updateRequest.AllowDowngrade); err != nil {
+		c.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	c.writeAccepted(w, fmt.Sprintf("update to version %s requested", updateRequest.Version))
+}
+
+// LogLevels execute return of levels of loggers
+func (c *ControlHttpController) LogLevels(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("log levels", "trace", "docp-agent-os-instance.control_http_controller.LogLevels")
+	c.writeJSON(w, http.StatusOK, c.control.ControlLogLevels())
+}
+
+// SetLogLevel execute change of level of logger
+func (c *ControlHttpController) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("set log level", "trace", "docp-agent-os-instance.control_http_controller.SetLogLevel")
+	var logLevelRequest dto.ControlLogLevelRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&logLevelRequest); err != nil {
+		c.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := c.control.ControlSetLogLevel(logLevelRequest.Name, logLevelRequest.Level); err != nil {
+		c.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	c.writeJSON(w, http.StatusOK, c.control.ControlLogLevels())
+}
+
+// Diagnostics execute upload of diagnostics bundle
+func (c *ControlHttpController) Diagnostics(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("diagnostics", "trace", "docp-agent-os-instance.control_http_controller.Diagnostics")
+	requestId, err := c.control.ControlDiagnostics()
+	if err != nil {
+		c.writeError(w, http.StatusConflict, err)
+		return
+	}
+	c.writeAccepted(w, fmt.Sprintf("diagnostics upload requested with id %s", requestId))
+}
+
+// Rollback execute rollback of agent for release retained,
+// request without body is rollback to previous release
+func (c *ControlHttpController) Rollback(w http.ResponseWriter, r *http.Request) {
+	c.logger.Debug("rollback", "trace", "docp-agent-os-instance.control_http_controller.Rollback")
+	var rollbackRequest dto.ControlRollbackRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&rollbackRequest); err != nil && !errors.Is(err, io.EOF) {
+		c.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	version, err := c.control.ControlRollback(rollbackRequest.To)
+	if err != nil {
+		c.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	c.writeAccepted(w, fmt.Sprintf("rollback to version %s requested", version))
+}
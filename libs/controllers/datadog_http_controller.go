@@ -15,19 +15,21 @@ import (
 // api docp
 type DatadogHttpController struct {
 	logger  interfaces.ILogger
+	config  dto.RuntimeConfig
 	adapter *adapters.DatadogAdapter
 }
 
 // NewDatadogHttpController return instance of datadog http controller
-func NewDatadogHttpController(logger interfaces.ILogger) *DatadogHttpController {
+func NewDatadogHttpController(logger interfaces.ILogger, config dto.RuntimeConfig) *DatadogHttpController {
 	return &DatadogHttpController{
-		logger: logger,
+		logger: logger.Named("datadog_http_controller"),
+		config: config,
 	}
 }
 
 // Setup execute configuration
 func (d *DatadogHttpController) Setup() error {
-	adapter := adapters.NewDatadogAdapter(d.logger)
+	adapter := adapters.NewDatadogAdapter(d.logger, d.config)
 	if err := adapter.Setup(); err != nil {
 		return err
 	}
@@ -85,7 +87,7 @@ func (d *DatadogHttpController) InstallAgent(w http.ResponseWriter, r *http.Requ
 		}
 	} else {
 		d.logger.Debug("install agent", "trace", "docp-agent-os-instance.datadog_http_controller.InstallAgent", "datadogInstallDto", datadogInstallDto)
-		go d.adapter.InstallAgent(datadogInstallDto.DDSite, datadogInstallDto.DDApiKey)
+		go d.adapter.InstallAgent(datadogInstallDto.DDSite, datadogInstallDto.DDApiKey, datadogInstallDto.Version, datadogInstallDto.TransactionId)
 	}
 	w.WriteHeader(http.StatusAccepted)
 	if err := json.NewEncoder(w).Encode(&dto.DatadogResponse{Status: "accepted", Code: "DATADOG_INSTALL_ACCEPTED", Message: "accepted install"}); err != nil {
@@ -94,6 +96,50 @@ func (d *DatadogHttpController) InstallAgent(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// UpgradeAgent execute upgrade or downgrade of agent datadog to version
+func (d *DatadogHttpController) UpgradeAgent(w http.ResponseWriter, r *http.Request) {
+	d.logger.Debug("upgrade agent", "trace", "docp-agent-os-instance.datadog_http_controller.UpgradeAgent")
+	var datadogInstallDto dto.DatadogInstallDTO
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&datadogInstallDto); err != nil || len(datadogInstallDto.Version) == 0 {
+		message := "version is required"
+		if err != nil {
+			message = err.Error()
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		if errMarshal := json.NewEncoder(w).Encode(&dto.DatadogResponse{Status: "error", Code: "DATADOG_UPGRADE_ERR", Message: message}); errMarshal != nil {
+			d.logger.Error("error in marshal response datadog", "trace", "docp-agent-os-instance.datadog_http_controller.UpgradeAgent", "error", errMarshal.Error())
+		}
+		return
+	}
+	go d.adapter.UpgradeAgent(datadogInstallDto.Version, datadogInstallDto.TransactionId)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(&dto.DatadogResponse{Status: "accepted", Code: "DATADOG_UPGRADE_ACCEPTED", Message: "accepted upgrade"}); err != nil {
+		d.logger.Error("error in marshal response datadog", "trace", "docp-agent-os-instance.datadog_http_controller.UpgradeAgent", "error", err.Error())
+		return
+	}
+}
+
+// ApplyIntegrations execute apply of integrations of agent datadog
+func (d *DatadogHttpController) ApplyIntegrations(w http.ResponseWriter, r *http.Request) {
+	d.logger.Debug("apply integrations", "trace", "docp-agent-os-instance.datadog_http_controller.ApplyIntegrations")
+	var integrationsDto dto.DatadogIntegrationsDTO
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&integrationsDto); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		if errMarshal := json.NewEncoder(w).Encode(&dto.DatadogResponse{Status: "error", Code: "DATADOG_INTEGRATIONS_ERR", Message: err.Error()}); errMarshal != nil {
+			d.logger.Error("error in marshal response datadog", "trace", "docp-agent-os-instance.datadog_http_controller.ApplyIntegrations", "error", errMarshal.Error())
+		}
+		return
+	}
+	go d.adapter.ApplyIntegrations(integrationsDto.Integrations, integrationsDto.TransactionId)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(&dto.DatadogResponse{Status: "accepted", Code: "DATADOG_INTEGRATIONS_ACCEPTED", Message: "accepted integrations"}); err != nil {
+		d.logger.Error("error in marshal response datadog", "trace", "docp-agent-os-instance.datadog_http_controller.ApplyIntegrations", "error", err.Error())
+		return
+	}
+}
+
 // UninstallAgent execute uninstall agent datadog
 func (d *DatadogHttpController) UninstallAgent(w http.ResponseWriter, r *http.Request) {
 	d.logger.Debug("uninstall agent", "trace", "docp-agent-os-instance.datadog_http_controller.UninstallAgent")
@@ -0,0 +1,80 @@
+package dto
+
+import "time"
+
+// ControlStatus is struct for status of manager
+// returned by local control socket
+type ControlStatus struct {
+	Services       map[string]string `json:"services"`
+	Versions       ControlVersions   `json:"versions"`
+	LastSignalAt   time.Time         `json:"last_signal_at,omitempty"`
+	LastSignalHash string            `json:"last_signal_hash,omitempty"`
+	PendingEvents  int               `json:"pending_events"`
+	Paused         bool              `json:"paused"`
+	Queues         []ControlQueue    `json:"queues,omitempty"`
+	Releases       []AgentRelease    `json:"releases,omitempty"`
+}
+
+// ControlVersions is struct for versions installed
+type ControlVersions struct {
+	Agent    string `json:"agent"`
+	Rollback string `json:"rollback"`
+}
+
+// ControlTask is struct for task in flight in manager
+type ControlTask struct {
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ControlQueue is struct for queue of actions of component,
+// actions of same component are executed in order
+type ControlQueue struct {
+	Component string    `json:"component"`
+	Current   string    `json:"current,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	Pending   []string  `json:"pending"`
+	Depth     int       `json:"depth"`
+}
+
+// ControlError is struct for error received in manager
+type ControlError struct {
+	From     string    `json:"from"`
+	Priority int       `json:"priority"`
+	Error    string    `json:"error"`
+	Time     time.Time `json:"time"`
+}
+
+// ControlUpdateRequest is struct for request of update
+// by local control socket
+type ControlUpdateRequest struct {
+	Version        string `json:"version"`
+	AllowDowngrade bool   `json:"allow_downgrade,omitempty"`
+}
+
+// ControlRollbackRequest is struct for request of rollback by
+// local control socket, to empty is previous healthy release
+type ControlRollbackRequest struct {
+	To string `json:"to,omitempty"`
+}
+
+// ControlLogLevelRequest is struct for request of change
+// of log level by local control socket, name empty is default level
+type ControlLogLevelRequest struct {
+	Name  string `json:"name,omitempty"`
+	Level string `json:"level"`
+}
+
+// ControlResponse is struct for response of commands
+// in local control socket
+type ControlResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// DatadogFileStatus is struct for status of datadog file
+// from signal compared with file on disk
+type DatadogFileStatus struct {
+	FilePath string `json:"file_path"`
+	Status   string `json:"status"`
+}
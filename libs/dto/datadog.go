@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // DatadogResponse is dto for response datadog
 type DatadogResponse struct {
 	Status  string `json:"status"`
@@ -14,9 +16,137 @@ type DatadogEnvVars struct {
 
 // DatadogInstallDTO is struct for payload the install datadog
 type DatadogInstallDTO struct {
-	DDSite    string           `json:"dd_site"`
-	DDApiKey  string           `json:"dd_api_key"`
-	Mode      string           `json:"mode"`
-	Component string           `json:"component"`
-	EnvVars   []DatadogEnvVars `json:"env_vars,omitempty"`
+	DDSite        string           `json:"dd_site"`
+	DDApiKey      string           `json:"dd_api_key"`
+	Mode          string           `json:"mode"`
+	Component     string           `json:"component"`
+	EnvVars       []DatadogEnvVars `json:"env_vars,omitempty"`
+	Version       string           `json:"version,omitempty"`
+	TransactionId string           `json:"transaction_id,omitempty"`
+}
+
+// DatadogIntegration is struct for integration of datadog agent with
+// instances of check, version informed is third party integration
+// installed by agent
+type DatadogIntegration struct {
+	Name       string                   `json:"name"`
+	Version    string                   `json:"version,omitempty"`
+	InitConfig map[string]interface{}   `json:"init_config,omitempty"`
+	Instances  []map[string]interface{} `json:"instances,omitempty"`
+}
+
+// DatadogIntegrationsDTO is struct for payload the apply of integrations
+type DatadogIntegrationsDTO struct {
+	Integrations  []DatadogIntegration `json:"integrations"`
+	TransactionId string               `json:"transaction_id,omitempty"`
+}
+
+// DatadogManagedIntegration is struct for integration applied by docp
+// agent, saved for remove when integration is not in signal
+type DatadogManagedIntegration struct {
+	Name       string    `json:"name"`
+	Version    string    `json:"version,omitempty"`
+	ConfigHash string    `json:"config_hash"`
+	AppliedAt  time.Time `json:"applied_at"`
+}
+
+// DatadogIntegrationStatus is struct for status of integration in
+// datadog agent, status is ok, warning, error or not_running
+type DatadogIntegrationStatus struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Managed   bool   `json:"managed"`
+	Status    string `json:"status"`
+	Instances int    `json:"instances"`
+	Error     string `json:"error,omitempty"`
+}
+
+// DatadogAgentStatus is struct for output of status of datadog agent
+type DatadogAgentStatus struct {
+	Version             string                     `json:"version"`
+	RunnerStats         DatadogRunnerStats         `json:"runnerStats"`
+	CheckSchedulerStats DatadogCheckSchedulerStats `json:"checkSchedulerStats"`
+	AutoConfigStats     DatadogAutoConfigStats     `json:"autoConfigStats"`
+	ForwarderStats      DatadogForwarderStats      `json:"forwarderStats"`
+	LogsStats           DatadogLogsStats           `json:"logsStats"`
+}
+
+// DatadogRunnerStats is struct for stats of checks by name and instance
+type DatadogRunnerStats struct {
+	Checks map[string]map[string]DatadogCheckStats `json:"Checks"`
+}
+
+// DatadogCheckStats is struct for stats of instance of check
+type DatadogCheckStats struct {
+	CheckName     string   `json:"CheckName"`
+	CheckVersion  string   `json:"CheckVersion"`
+	TotalRuns     int      `json:"TotalRuns"`
+	TotalErrors   int      `json:"TotalErrors"`
+	TotalWarnings int      `json:"TotalWarnings"`
+	LastError     string   `json:"LastError"`
+	LastWarnings  []string `json:"LastWarnings"`
+}
+
+// DatadogCheckSchedulerStats is struct for errors of load of checks
+type DatadogCheckSchedulerStats struct {
+	LoaderErrors map[string]map[string]string `json:"LoaderErrors"`
+}
+
+// DatadogAutoConfigStats is struct for errors of config of checks
+type DatadogAutoConfigStats struct {
+	ConfigErrors map[string]string `json:"ConfigErrors"`
+}
+
+// DatadogForwarderStats is struct for stats of forwarder of datadog agent
+type DatadogForwarderStats struct {
+	Transactions  DatadogForwarderTransactions `json:"Transactions"`
+	APIKeyStatus  map[string]string            `json:"APIKeyStatus"`
+	APIKeyFailure map[string]string            `json:"APIKeyFailure"`
+}
+
+// DatadogForwarderTransactions is struct for counters of transactions
+// of forwarder since start of agent
+type DatadogForwarderTransactions struct {
+	Success          int64            `json:"Success"`
+	Errors           int64            `json:"Errors"`
+	Dropped          int64            `json:"Dropped"`
+	HTTPErrors       int64            `json:"HTTPErrors"`
+	ErrorsByType     map[string]int64 `json:"ErrorsByType"`
+	HTTPErrorsByCode map[string]int64 `json:"HTTPErrorsByCode"`
+}
+
+// DatadogLogsStats is struct for state of logs agent of datadog agent
+type DatadogLogsStats struct {
+	IsRunning bool     `json:"is_running"`
+	Errors    []string `json:"errors"`
+	Warnings  []string `json:"warnings"`
+}
+
+// DatadogHealth is struct for health of datadog agent summarized from
+// status of agent, reported in metadata
+type DatadogHealth struct {
+	Status      string                 `json:"status"`
+	Version     string                 `json:"version,omitempty"`
+	APIKey      string                 `json:"api_key"`
+	Forwarder   DatadogForwarderHealth `json:"forwarder"`
+	CheckErrors []string               `json:"check_errors,omitempty"`
+	Logs        DatadogLogsHealth      `json:"logs"`
+	Issues      []string               `json:"issues,omitempty"`
+	CheckedAt   time.Time              `json:"checked_at"`
+}
+
+// DatadogForwarderHealth is struct for transactions of forwarder, new
+// errors are errors since last check
+type DatadogForwarderHealth struct {
+	Success      int64            `json:"success"`
+	Errors       int64            `json:"errors"`
+	HTTPErrors   int64            `json:"http_errors"`
+	NewErrors    int64            `json:"new_errors"`
+	ErrorsByType map[string]int64 `json:"errors_by_type,omitempty"`
+}
+
+// DatadogLogsHealth is struct for state of logs agent
+type DatadogLogsHealth struct {
+	State  string   `json:"state"`
+	Errors []string `json:"errors,omitempty"`
 }
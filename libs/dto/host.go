@@ -56,8 +56,29 @@ type DiskInfo struct {
 type ProcessInfo struct {
 	Pid        int32  `json:"pid"`
 	Name       string `json:"name"`
+	User       string `json:"user,omitempty"`
 	NumFDs     int32  `json:"num_file_descriptors"`
 	NumThreads int32  `json:"num_threads"`
 	ExecPath   string `json:"exec_path"`
 	Background bool   `json:"background"`
+	Vendor     string `json:"vendor,omitempty"`
+	CreateTime int64  `json:"-"`
+}
+
+// ProcessAggregate is struct for processes grouped by executable
+type ProcessAggregate struct {
+	Name       string `json:"name"`
+	ExecPath   string `json:"exec_path"`
+	Count      int    `json:"count"`
+	NumThreads int32  `json:"num_threads"`
+	NumFDs     int32  `json:"num_file_descriptors"`
+	Vendor     string `json:"vendor,omitempty"`
+}
+
+// VendorInfo is struct for monitoring vendor discovered on host
+type VendorInfo struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version,omitempty"`
+	Status  string   `json:"status"`
+	Sources []string `json:"sources"`
 }
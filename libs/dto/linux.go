@@ -2,11 +2,17 @@ package dto
 
 // Metadata is struct for metadata the host
 type Metadata struct {
-	ComputeInfo  ComputeInfo   `json:"compute_info"`
-	CPUInfo      []CPUInfo     `json:"cpus_info"`
-	MemoryInfo   MemoryInfo    `json:"memory_info"`
-	DiskInfo     DiskInfo      `json:"disk_info"`
-	ProcessInfos []ProcessInfo `json:"process_infos"`
+	ComputeInfo       ComputeInfo                `json:"compute_info"`
+	CPUInfo           []CPUInfo                  `json:"cpus_info"`
+	MemoryInfo        MemoryInfo                 `json:"memory_info"`
+	DiskInfo          DiskInfo                   `json:"disk_info"`
+	ProcessInfos      []ProcessInfo              `json:"process_infos"`
+	ProcessAggregates []ProcessAggregate         `json:"process_aggregates,omitempty"`
+	DetectedVendors   []string                   `json:"detected_vendors,omitempty"`
+	Vendors           []VendorInfo               `json:"vendors,omitempty"`
+	UpdateRing        *AgentUpdateRing           `json:"update_ring,omitempty"`
+	Integrations      []DatadogIntegrationStatus `json:"datadog_integrations,omitempty"`
+	DatadogHealth     *DatadogHealth             `json:"datadog_health,omitempty"`
 }
 
 // LinuxAgent is struct for agent
@@ -17,16 +23,23 @@ type LinuxAgent struct {
 
 // ConfigAgent is struct for config file agent
 type ConfigAgent struct {
-	Version            string   `yaml:"version"`
-	RollbackVersion    string   `yaml:"rollback_version"`
-	AlreadyCreated     bool     `yaml:"already_created,omitempty"`
-	AlreadyTracer      bool     `yaml:"already_tracer"`
-	TracerLanguages    []string `yaml:"tracer_languages"`
-	NoGroupAssociation bool     `yaml:"no_group_association,omitempty"`
-	Agent              Agent    `yaml:"agent"`
-	AccessToken        string   `json:"access_token"`
-	ComputeId          string   `json:"compute_id"`
-	DocpOrgId          int      `json:"docp_org_id"`
+	Version            string                 `yaml:"version"`
+	RollbackVersion    string                 `yaml:"rollback_version"`
+	AlreadyCreated     bool                   `yaml:"already_created,omitempty"`
+	AlreadyTracer      bool                   `yaml:"already_tracer"`
+	TracerLanguages    []string               `yaml:"tracer_languages"`
+	NoGroupAssociation bool                   `yaml:"no_group_association,omitempty"`
+	Agent              Agent                  `yaml:"agent"`
+	ProcessInventory   ProcessInventoryConfig `yaml:"process_inventory,omitempty"`
+	Network            NetworkConfig          `yaml:"network,omitempty"`
+	Logging            LoggingConfig          `yaml:"logging,omitempty"`
+	Runtime            RuntimeConfig          `yaml:"runtime,omitempty"`
+	Maintenance        MaintenanceConfig      `yaml:"maintenance,omitempty"`
+	Releases           ReleasesConfig         `yaml:"releases,omitempty"`
+	Datadog            DatadogInstallConfig   `yaml:"datadog,omitempty"`
+	AccessToken        string                 `json:"access_token"`
+	ComputeId          string                 `json:"compute_id"`
+	DocpOrgId          int                    `json:"docp_org_id"`
 }
 
 // Agent is struct for config file agent
@@ -35,16 +48,89 @@ type Agent struct {
 	Tags   map[string]interface{} `yaml:"tags"`
 }
 
+// LoggingConfig is struct for logging in config file, levels is
+// by logger name, like manager_operator, and output is stdout, file or both
+type LoggingConfig struct {
+	Level  string            `yaml:"level,omitempty"`
+	Levels map[string]string `yaml:"levels,omitempty"`
+	Output string            `yaml:"output,omitempty"`
+	File   LogFileConfig     `yaml:"file,omitempty"`
+}
+
+// LogFileConfig is struct for rotation of log files, dir
+// empty is logs dir in work dir
+type LogFileConfig struct {
+	Dir        string `yaml:"dir,omitempty"`
+	MaxSizeMB  int    `yaml:"max_size_mb,omitempty"`
+	MaxAgeDays int    `yaml:"max_age_days,omitempty"`
+	MaxBackups int    `yaml:"max_backups,omitempty"`
+	Compress   bool   `yaml:"compress,omitempty"`
+}
+
+// MaintenanceConfig is struct for maintenance windows of disruptive
+// actions, without windows actions execute anytime and urgent
+// execute actions outside of windows
+type MaintenanceConfig struct {
+	Timezone string              `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	Windows  []MaintenanceWindow `yaml:"windows,omitempty" json:"windows,omitempty"`
+	Urgent   bool                `yaml:"urgent,omitempty" json:"urgent,omitempty"`
+}
+
+// MaintenanceWindow is struct for window defined by cron expression
+// with duration, like "0 2 * * *" and "3h", or by weekdays with
+// start and end time, like "22:00" and "04:00"
+type MaintenanceWindow struct {
+	Cron     string   `yaml:"cron,omitempty" json:"cron,omitempty"`
+	Duration string   `yaml:"duration,omitempty" json:"duration,omitempty"`
+	Weekdays []string `yaml:"weekdays,omitempty" json:"weekdays,omitempty"`
+	Start    string   `yaml:"start,omitempty" json:"start,omitempty"`
+	End      string   `yaml:"end,omitempty" json:"end,omitempty"`
+}
+
+// ReleasesConfig is struct for retention of releases, keep is
+// number of verified releases kept for rollback
+type ReleasesConfig struct {
+	Keep int `yaml:"keep,omitempty"`
+}
+
+// DatadogInstallConfig is struct for install of datadog agent, installer
+// is package, the default, or script, repository and keys replace of datadog for
+// mirror and local package is .deb or .rpm installed without repository
+type DatadogInstallConfig struct {
+	Installer          string   `yaml:"installer,omitempty"`
+	RepositoryUrl      string   `yaml:"repository_url,omitempty"`
+	GpgKeyUrls         []string `yaml:"gpg_key_urls,omitempty"`
+	LocalPackage       string   `yaml:"local_package,omitempty"`
+	LocalPackageSha256 string   `yaml:"local_package_sha256,omitempty"`
+}
+
+// ProcessInventoryConfig is struct for process inventory filters in config file
+type ProcessInventoryConfig struct {
+	Aggregate bool          `yaml:"aggregate,omitempty"`
+	MinUptime string        `yaml:"min_uptime,omitempty"`
+	Include   []ProcessRule `yaml:"include,omitempty"`
+	Exclude   []ProcessRule `yaml:"exclude,omitempty"`
+}
+
+// ProcessRule is struct for rule the process inventory,
+// all fields informed must match
+type ProcessRule struct {
+	NameRegex string `yaml:"name_regex,omitempty"`
+	User      string `yaml:"user,omitempty"`
+	ExecPath  string `yaml:"exec_path,omitempty"`
+}
+
 // StateAction is struct for actions
 type StateAction struct {
-	Type          string             `json:"type"`
-	Action        string             `json:"action"`
-	Version       string             `json:"version"`
-	Mode          string             `json:"mode,omitempty"`
-	Component     string             `json:"component"`
-	ComponentEnvs []StateActionEnvs  `json:"component_envs,omitempty"`
-	Envs          []StateActionEnvs  `json:"envs,omitempty"`
-	Files         []StateActionFiles `json:"files,omitempty"`
+	Type          string               `json:"type"`
+	Action        string               `json:"action"`
+	Version       string               `json:"version"`
+	Mode          string               `json:"mode,omitempty"`
+	Component     string               `json:"component"`
+	ComponentEnvs []StateActionEnvs    `json:"component_envs,omitempty"`
+	Envs          []StateActionEnvs    `json:"envs,omitempty"`
+	Files         []StateActionFiles   `json:"files,omitempty"`
+	Integrations  []DatadogIntegration `json:"integrations,omitempty"`
 }
 
 // StateActionEnvs is struct for envs the actions
@@ -63,4 +149,16 @@ type StateActionFiles struct {
 type AuthTokenClaims struct {
 	DocpOrgId int    `json:"docp_org_id"`
 	ComputeId string `json:"compute_id"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// NetworkConfig is struct for config of outbound connections
+type NetworkConfig struct {
+	ProxyUrl      string `yaml:"proxy_url,omitempty"`
+	ProxyUser     string `yaml:"proxy_user,omitempty"`
+	ProxyPassword string `yaml:"proxy_password,omitempty"`
+	NoProxy       string `yaml:"no_proxy,omitempty"`
+	CABundle      string `yaml:"ca_bundle,omitempty"`
+	ClientCert    string `yaml:"client_cert,omitempty"`
+	ClientKey     string `yaml:"client_key,omitempty"`
 }
@@ -25,6 +25,7 @@ type ManagerStateAction struct {
 	ComponentEnvs []ManagerStateActionEnvs  `json:"component_envs,omitempty"`
 	Envs          []ManagerStateActionEnvs  `json:"envs,omitempty"`
 	Files         []ManagerStateActionFiles `json:"files,omitempty"`
+	Integrations  []DatadogIntegration      `json:"integrations,omitempty"`
 }
 
 // ManagerStateActionEnvs is struct for envs the actions
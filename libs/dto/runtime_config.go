@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// RuntimeConfig is struct for configuration of manager, agent and updater,
+// loaded from defaults, runtime section of config file, envs and flags
+type RuntimeConfig struct {
+	Args            []string         `yaml:"-"`
+	ConfigFilePath  string           `yaml:"-"`
+	PidFile         string           `yaml:"-"`
+	Domain          string           `yaml:"domain,omitempty"`
+	WorkDirPath     string           `yaml:"workdir_path,omitempty"`
+	AgentPort       string           `yaml:"agent_port,omitempty"`
+	ErrorLevel      string           `yaml:"error_level,omitempty"`
+	DatadogConfPath string           `yaml:"datadog_conf_path,omitempty"`
+	Intervals       RuntimeIntervals `yaml:"intervals,omitempty"`
+}
+
+// RuntimeIntervals is struct for intervals of periodic tasks
+type RuntimeIntervals struct {
+	Collect       time.Duration `yaml:"collect,omitempty"`
+	StateCheck    time.Duration `yaml:"state_check,omitempty"`
+	Tasks         time.Duration `yaml:"tasks,omitempty"`
+	AutoUpdate    time.Duration `yaml:"auto_update,omitempty"`
+	Metadata      time.Duration `yaml:"metadata,omitempty"`
+	DatadogHealth time.Duration `yaml:"datadog_health,omitempty"`
+}
+
+// ConfigChanges is struct for changes between config applied and
+// config file changed, restart is fields applied only after restart
+type ConfigChanges struct {
+	Tags       bool
+	ApiKey     bool
+	LogLevels  bool
+	Intervals  bool
+	ErrorLevel bool
+	Restart    []string
+}
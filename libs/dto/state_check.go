@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // StateCheckResponse is struct for response the state check
 type StateCheckResponse struct {
 	Signal StateCheckSignal `json:"signal"`
@@ -7,10 +9,26 @@ type StateCheckResponse struct {
 
 // StateCheckSignal is struct for signal
 type StateCheckSignal struct {
-	TypeSignal         string           `json:"type"`
-	Agents             StateCheckAgents `json:"agents"`
-	Duration           string           `json:"duration"`
-	RemoveOtherVendors []string         `json:"remove_other_vendors"`
+	TypeSignal         string                 `json:"type"`
+	Agents             StateCheckAgents       `json:"agents"`
+	Duration           string                 `json:"duration"`
+	RemoveOtherVendors []string               `json:"remove_other_vendors"`
+	Diagnostics        *StateCheckDiagnostics `json:"diagnostics,omitempty"`
+	Maintenance        *MaintenanceConfig     `json:"maintenance,omitempty"`
+}
+
+// StateCheckDiagnostics is struct for request of diagnostics bundle
+type StateCheckDiagnostics struct {
+	RequestId string `json:"request_id"`
+}
+
+// DiagnosticsAttempts is struct for failed attempts of upload of
+// diagnostics request, transaction is kept between attempts
+type DiagnosticsAttempts struct {
+	RequestId     string    `json:"request_id"`
+	TransactionId string    `json:"transaction_id"`
+	Attempts      int       `json:"attempts"`
+	NextAt        time.Time `json:"next_at"`
 }
 
 // StateCheckAgents is struct for agents payload
@@ -21,9 +39,16 @@ type StateCheckAgents struct {
 	DatadogTracerSingleStep StateCheckDatadogTracerSingleStep `json:"datadog-tracer-single-step"`
 }
 
-// StateCheckDocpAgent is component for docp agents
+// StateCheckDocpAgent is component for docp agents, version is
+// latest, exact version or constraint like ^1.2.0 and rollback to
+// is release retained in host, replacing version
 type StateCheckDocpAgent struct {
-	Version string `json:"version"`
+	Version         string `json:"version"`
+	Hold            bool   `json:"hold,omitempty"`
+	Pin             string `json:"pin,omitempty"`
+	AllowDowngrade  bool   `json:"allow_downgrade,omitempty"`
+	AllowPrerelease bool   `json:"allow_prerelease,omitempty"`
+	RollbackTo      string `json:"rollback_to,omitempty"`
 }
 
 // StateCheckDatadogAgent is component for datadog agent
@@ -34,6 +59,7 @@ type StateCheckDatadogAgent struct {
 	AppKey         string                          `json:"app-key"`
 	Site           string                          `json:"site"`
 	Configurations StateCheckDatadogConfigurations `json:"configurations,omitempty"`
+	Integrations   []DatadogIntegration            `json:"integrations,omitempty"`
 }
 
 // StateCheckDatadogConfigurations is struct for configurations
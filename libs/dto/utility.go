@@ -1,7 +1,46 @@
 package dto
 
+import "time"
+
 // AgentVersions struct for agent versions
 type AgentVersions struct {
-	LatestVersion string   `json:"latest"`
-	Versions      []string `json:"versions"`
+	LatestVersion string        `json:"latest"`
+	Versions      []string      `json:"versions"`
+	Rollout       *AgentRollout `json:"rollout,omitempty"`
+}
+
+// AgentRollout is struct for staged rollout of version, by rings
+// or by percentage of hosts, version empty is latest version
+type AgentRollout struct {
+	Version    string        `json:"version,omitempty"`
+	Percentage int           `json:"percentage,omitempty"`
+	Rings      []RolloutRing `json:"rings,omitempty"`
+}
+
+// RolloutRing is struct for ring of rollout, hosts with bucket
+// below percentage and not in previous rings are in ring and
+// receive the version after start
+type RolloutRing struct {
+	Name       string    `json:"name"`
+	Percentage int       `json:"percentage"`
+	StartAt    time.Time `json:"start_at"`
+}
+
+// AgentUpdateRing is struct for ring of host in rollout of agent,
+// version empty is host without update allowed now
+type AgentUpdateRing struct {
+	Ring    string `json:"ring"`
+	Bucket  int    `json:"bucket"`
+	Version string `json:"version,omitempty"`
+	Hold    bool   `json:"hold,omitempty"`
+	Pin     string `json:"pin,omitempty"`
+}
+
+// AgentRelease is struct for release of agent in releases dir,
+// health is pending until updater verify services of release
+type AgentRelease struct {
+	Version     string    `json:"version"`
+	InstalledAt time.Time `json:"installed_at"`
+	Health      string    `json:"health"`
+	CheckedAt   time.Time `json:"checked_at,omitempty"`
 }
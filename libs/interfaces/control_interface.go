@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// IManagerControl is interface for local control of manager
+type IManagerControl interface {
+	ControlStatus() (dto.ControlStatus, error)
+	ControlPlanActions() ([]dto.StateAction, error)
+	ControlTasks() []dto.ControlTask
+	ControlErrors() []dto.ControlError
+	ControlForcePoll() error
+	ControlForceMetadataSync() error
+	ControlPause()
+	ControlResume()
+	ControlUpdate(version string, allowDowngrade bool) error
+	ControlRollback(to string) (string, error)
+	ControlLogLevels() map[string]string
+	ControlSetLogLevel(name string, level string) error
+	ControlDiagnostics() (string, error)
+}
@@ -6,7 +6,8 @@ import (
 
 type IDatadogOperation interface {
 	Setup() error
-	InstallAgent(ddSite, ddApiKey string) error
+	InstallAgent(ddSite, ddApiKey, version, transactionId string) error
+	UpgradeAgent(version, transactionId string) error
 	InstallAgentApmSingleStep(ddSite string, ddApiKey string, datadogEnvVars []dto.DatadogEnvVars) error
 	InstallAgentApmTracingLibrary(languageName, pathTracer, version string) error
 	UninstallAgent() error
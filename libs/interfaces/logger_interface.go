@@ -7,4 +7,5 @@ type ILogger interface {
 	Warn(msg string, args ...any)
 	Error(msg string, args ...any)
 	Close() error
+	Named(name string) ILogger
 }
@@ -9,6 +9,7 @@ type IOperationSystem interface {
 type IOSOperation interface {
 	Setup() error
 	Execute() error
+	SetStopHandler(stop func(), stopped <-chan struct{})
 	Status(serviceName string) (string, error)
 	AlreadyInstalled(serviceName string) (bool, error)
 	RestartService(serviceName string) error
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// IVendorDiscovery is interface for discovery of monitoring vendors
+type IVendorDiscovery interface {
+	Setup() error
+	Discover() ([]dto.VendorInfo, error)
+	DiscoverFrom(processVendors []string) []dto.VendorInfo
+	PackageVersion(vendor string) (string, bool)
+}
+
+// IVendorOperation is interface for operations in monitoring vendors
+type IVendorOperation interface {
+	Setup() error
+	UninstallVendor(name string) error
+}
@@ -6,3 +6,136 @@ const (
 	DOCP_BINARIES_REPO            = "https://test-docp-agent-data.s3.amazonaws.com"
 	DOCP_FILE_AGENT_VERSIONS_NAME = "index.json"
 )
+
+const (
+	HEADER_IDEMPOTENCY_KEY = "Idempotency-Key"
+)
+
+const (
+	VENDOR_STATUS_RUNNING   = "running"
+	VENDOR_STATUS_STOPPED   = "stopped"
+	VENDOR_STATUS_INSTALLED = "installed"
+	VENDOR_SOURCE_SERVICE   = "service"
+	VENDOR_SOURCE_PACKAGE   = "package"
+	VENDOR_SOURCE_PROCESS   = "process"
+)
+
+const (
+	SECRET_KEY_FILE_NAME       = "secret.key"
+	SECRET_STORE_FILE_NAME     = "secrets.enc"
+	SECRET_LOCK_FILE_NAME      = "secrets.lock"
+	SECRET_NAME_API_KEY        = "agent.api_key"
+	SECRET_NAME_ACCESS_TOKEN   = "access_token"
+	SECRET_NAME_PROXY_PASSWORD = "network.proxy_password"
+)
+
+const (
+	CONTROL_SOCKET_FILE_NAME    = "manager.sock"
+	CONTROL_ROUTE_STATUS        = "/v1/status"
+	CONTROL_ROUTE_PLAN          = "/v1/actions/plan"
+	CONTROL_ROUTE_UPDATE        = "/v1/update"
+	CONTROL_ROUTE_ROLLBACK      = "/v1/rollback"
+	CONTROL_ROUTE_TASKS         = "/v1/tasks"
+	CONTROL_ROUTE_ERRORS        = "/v1/errors"
+	CONTROL_ROUTE_POLL          = "/v1/poll"
+	CONTROL_ROUTE_METADATA_SYNC = "/v1/metadata/sync"
+	CONTROL_ROUTE_PAUSE         = "/v1/reconciliation/pause"
+	CONTROL_ROUTE_RESUME        = "/v1/reconciliation/resume"
+	CONTROL_ROUTE_LOG_LEVELS    = "/v1/log-levels"
+	CONTROL_ROUTE_DIAGNOSTICS   = "/v1/diagnostics"
+	DATADOG_FILE_STATUS_IN_SYNC = "in_sync"
+	DATADOG_FILE_STATUS_CHANGED = "changed"
+	DATADOG_FILE_STATUS_MISSING = "missing"
+)
+
+const (
+	LOG_OUTPUT_STDOUT           = "stdout"
+	LOG_OUTPUT_FILE             = "file"
+	LOG_OUTPUT_BOTH             = "both"
+	LOG_FILE_DEFAULT_MAX_SIZE   = 10
+	LOG_FILE_DEFAULT_MAX_AGE    = 7
+	LOG_FILE_DEFAULT_MAX_BACKUP = 5
+)
+
+const (
+	DIAGNOSTICS_MAX_BUNDLE_SIZE = 20 * 1024 * 1024
+	DIAGNOSTICS_MAX_FILE_SIZE   = 4 * 1024 * 1024
+	DIAGNOSTICS_JOURNAL_LINES   = 2000
+	DIAGNOSTICS_COMMAND_TIMEOUT = 60
+	DIAGNOSTICS_MAX_ATTEMPTS    = 5
+	DIAGNOSTICS_RETRY_BACKOFF   = 60
+	DIAGNOSTICS_MAX_BACKOFF     = 1800
+)
+
+const (
+	CONFIG_WATCH_POLL_INTERVAL = 10
+	CONFIG_WATCH_DEBOUNCE      = 500
+	CONFIG_LOCK_FILE_NAME      = ".config.lock"
+)
+
+const (
+	SHUTDOWN_TASKS_TIMEOUT   = 10
+	SHUTDOWN_ACTIONS_TIMEOUT = 30
+)
+
+const (
+	ACTION_COMPONENT_DOCP_AGENT     = "docp-agent"
+	ACTION_COMPONENT_DATADOG_AGENT  = "datadog-agent"
+	ACTION_COMPONENT_DATADOG_TRACER = "datadog-tracer"
+	ACTION_INSTALL                  = "install"
+	ACTION_UNINSTALL                = "uninstall"
+	ACTION_UPDATE                   = "update"
+	ACTION_UPGRADE                  = "upgrade"
+	ACTION_ROLLBACK                 = "rollback"
+	ACTION_INTEGRATIONS             = "integrations"
+)
+
+const (
+	DATADOG_UPGRADE_TIMEOUT       = 15
+	DATADOG_UPGRADE_POLL_INTERVAL = 20
+	// retry of upgrade failed in minutes, doubled by attempt
+	DATADOG_UPGRADE_RETRY_BASE = 5
+	DATADOG_UPGRADE_RETRY_MAX  = 360
+	// status of agent read by health is reused in seconds
+	DATADOG_STATUS_MAX_AGE = 60
+)
+
+const (
+	DATADOG_INSTALLER_PACKAGE  = "package"
+	DATADOG_INSTALLER_SCRIPT   = "script"
+	DATADOG_APT_REPOSITORY_URL = "https://apt.datadoghq.com/"
+	DATADOG_YUM_REPOSITORY_URL = "https://yum.datadoghq.com/stable/7"
+	DATADOG_APT_KEYRING        = "/usr/share/keyrings/datadog-archive-keyring.gpg"
+	DATADOG_APT_SOURCE_FILE    = "/etc/apt/sources.list.d/datadog.list"
+	DATADOG_YUM_REPO_FILE      = "/etc/yum.repos.d/datadog.repo"
+	DATADOG_CONFIG_FILE        = "/etc/datadog-agent/datadog.yaml"
+	DATADOG_PACKAGE_NAME       = "datadog-agent"
+	DATADOG_SERVICE_NAME       = "datadog-agent"
+)
+
+const (
+	DATADOG_INTEGRATIONS_FILE_NAME         = "integrations.json"
+	DATADOG_INTEGRATION_STATUS_OK          = "ok"
+	DATADOG_INTEGRATION_STATUS_WARNING     = "warning"
+	DATADOG_INTEGRATION_STATUS_ERROR       = "error"
+	DATADOG_INTEGRATION_STATUS_NOT_RUNNING = "not_running"
+)
+
+const (
+	DATADOG_HEALTH_HEALTHY         = "healthy"
+	DATADOG_HEALTH_DEGRADED        = "degraded"
+	DATADOG_HEALTH_UNHEALTHY       = "unhealthy"
+	DATADOG_API_KEY_VALID          = "valid"
+	DATADOG_API_KEY_INVALID        = "invalid"
+	DATADOG_API_KEY_UNKNOWN        = "unknown"
+	DATADOG_LOGS_AGENT_RUNNING     = "running"
+	DATADOG_LOGS_AGENT_NOT_RUNNING = "not_running"
+)
+
+const (
+	RELEASES_MANIFEST_FILE_NAME = "releases.json"
+	RELEASES_DEFAULT_KEEP       = 3
+	RELEASE_HEALTH_PENDING      = "pending"
+	RELEASE_HEALTH_HEALTHY      = "healthy"
+	RELEASE_HEALTH_FAILED       = "failed"
+)
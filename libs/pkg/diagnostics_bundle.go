@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"time"
+)
+
+// DiagnosticsBundleFile is struct for file registered in manifest of bundle
+type DiagnosticsBundleFile struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// DiagnosticsBundleManifest is struct for manifest of bundle
+type DiagnosticsBundleManifest struct {
+	RequestId string                  `json:"request_id"`
+	CreatedAt time.Time               `json:"created_at"`
+	Files     []DiagnosticsBundleFile `json:"files"`
+	Skipped   []string                `json:"skipped,omitempty"`
+	Errors    map[string]string       `json:"errors,omitempty"`
+}
+
+// DiagnosticsBundle is struct for build the tar gzip of diagnostics
+// with cap of size, files bigger than cap keep only the last bytes
+type DiagnosticsBundle struct {
+	maxSize     int
+	maxFileSize int
+	used        int
+	buffer      *bytes.Buffer
+	gzipWriter  *gzip.Writer
+	tarWriter   *tar.Writer
+	manifest    DiagnosticsBundleManifest
+}
+
+// NewDiagnosticsBundle return instance of diagnostics bundle
+func NewDiagnosticsBundle(requestId string, maxSize, maxFileSize int) *DiagnosticsBundle {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	return &DiagnosticsBundle{
+		maxSize:     maxSize,
+		maxFileSize: maxFileSize,
+		buffer:      buffer,
+		gzipWriter:  gzipWriter,
+		tarWriter:   tar.NewWriter(gzipWriter),
+		manifest: DiagnosticsBundleManifest{
+			RequestId: requestId,
+			CreatedAt: time.Now(),
+			Errors:    make(map[string]string),
+		},
+	}
+}
+
+// writeFile execute write of file in tar
+func (d *DiagnosticsBundle) writeFile(name string, content []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: d.manifest.CreatedAt,
+	}
+	if err := d.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := d.tarWriter.Write(content)
+	return err
+}
+
+// Add execute add of file in bundle, file is skipped when
+// size cap of bundle is reached
+func (d *DiagnosticsBundle) Add(name string, content []byte) error {
+	remaining := d.maxSize - d.used
+	if remaining <= 0 {
+		d.manifest.Skipped = append(d.manifest.Skipped, name)
+		return nil
+	}
+	limit := min(d.maxFileSize, remaining)
+	truncated := false
+	if len(content) > limit {
+		content = content[len(content)-limit:]
+		truncated = true
+	}
+	if err := d.writeFile(name, content); err != nil {
+		return err
+	}
+	d.used += len(content)
+	d.manifest.Files = append(d.manifest.Files, DiagnosticsBundleFile{Name: name, Size: len(content), Truncated: truncated})
+	return nil
+}
+
+// AddError execute register of error in collect of file
+func (d *DiagnosticsBundle) AddError(name string, err error) {
+	d.manifest.Errors[name] = err.Error()
+}
+
+// Bytes return content of bundle with manifest, bundle
+// can not receive files after
+func (d *DiagnosticsBundle) Bytes() ([]byte, error) {
+	manifest, err := json.MarshalIndent(&d.manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := d.writeFile("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	if err := d.tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := d.gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return d.buffer.Bytes(), nil
+}
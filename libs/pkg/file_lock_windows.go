@@ -0,0 +1,27 @@
+//go:build windows
+
+package pkg
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile execute exclusive lock of file between processes, blocking
+// until lock is released by other process, return func for unlock
+func lockFile(filePath string) (func() error, error) {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	overlapped := new(windows.Overlapped)
+	if err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() error {
+		defer file.Close()
+		return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, overlapped)
+	}, nil
+}
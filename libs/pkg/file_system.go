@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 )
@@ -91,6 +92,26 @@ func (fls *FileSystem) GetFileContent(filePath string) ([]byte, error) {
 	return content, nil
 }
 
+// GetFileTail return last bytes of file up to size,
+// without read of beginning of large files
+func (fls *FileSystem) GetFileTail(filePath string, size int64) ([]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > size {
+		if _, err := file.Seek(info.Size()-size, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(io.LimitReader(file, size))
+}
+
 // CreateFile create file
 func (fls *FileSystem) CreateFile(filePath string) error {
 	f, err := os.OpenFile(filePath, os.O_CREATE, os.ModePerm)
@@ -131,6 +152,12 @@ func (fls *FileSystem) CreateAndWriteFileContent(filePath string, content []byte
 	return nil
 }
 
+// LockFile execute exclusive lock of file between processes, blocking
+// until lock is released by other process, return func for unlock
+func (fls *FileSystem) LockFile(filePath string) (func() error, error) {
+	return lockFile(filePath)
+}
+
 // WriteFileContent execute write the content for file
 func (fls *FileSystem) WriteFileContent(filePath string, content []byte) error {
 	if err := os.WriteFile(filePath, content, os.ModePerm); err != nil {
@@ -66,20 +66,40 @@ func (h *HostStats) ProcessInfo() ([]dto.ProcessInfo, error) {
 		numThreads, _ := prcs.NumThreads()
 		execPath, _ := prcs.Exe()
 		background, _ := prcs.Background()
+		user, _ := prcs.Username()
+		createTime, _ := prcs.CreateTime()
 
 		prs := dto.ProcessInfo{
 			Pid:        prcs.Pid,
 			Name:       name,
+			User:       user,
 			NumFDs:     numFds,
 			NumThreads: numThreads,
 			ExecPath:   execPath,
 			Background: background,
+			Vendor:     DetectVendor(name, execPath),
+			CreateTime: createTime,
 		}
 		allProcess = append(allProcess, prs)
 	}
 	return allProcess, nil
 }
 
+// ProcessInventory return process info from host filtered by rules,
+// when filter is aggregate the processes are grouped by executable
+func (h *HostStats) ProcessInventory(filter *ProcessFilter) ([]dto.ProcessInfo, []dto.ProcessAggregate, []string, error) {
+	allProcess, err := h.ProcessInfo()
+	if err != nil {
+		return []dto.ProcessInfo{}, nil, nil, err
+	}
+	vendors := ProcessVendors(allProcess)
+	filtered := filter.Filter(allProcess)
+	if filter.IsAggregate() {
+		return []dto.ProcessInfo{}, filter.Aggregate(filtered), vendors, nil
+	}
+	return filtered, nil, vendors, nil
+}
+
 // MemoryInfo return memory info from host
 func (h *HostStats) MemoryInfo() (dto.MemoryInfo, error) {
 	memoryInfo, err := mem.VirtualMemory()
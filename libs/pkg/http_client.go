@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy is struct for retry policy the http client
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// BreakerPolicy is struct for circuit breaker policy the http client
+type BreakerPolicy struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// DefaultRetryPolicy return retry policy used by control plane calls
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Second * 1,
+		MaxDelay:   time.Second * 30,
+	}
+}
+
+// DefaultBreakerPolicy return circuit breaker policy used by control plane calls
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{
+		FailureThreshold: 5,
+		OpenTimeout:      time.Minute * 2,
+	}
+}
+
+// Backoff return delay with full jitter for attempt,
+// random between zero and capped exponential
+func Backoff(attempt int, baseDelay, maxDelay time.Duration) time.Duration {
+	if baseDelay <= 0 {
+		return 0
+	}
+	delay := maxDelay
+	if attempt < 32 {
+		if exp := baseDelay << uint(attempt); exp > 0 && exp < maxDelay {
+			delay = exp
+		}
+	}
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
+
+// circuitBreaker is struct for state of circuit breaker by endpoint
+type circuitBreaker struct {
+	failures  int
+	openUntil time.Time
+	halfOpen  bool
+}
+
+// circuitBreakers is registry of circuit breakers shared by http clients,
+// so all services calling same endpoint see same state
+var circuitBreakers = struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}{breakers: make(map[string]*circuitBreaker)}
+
+// HttpClient is struct for http client with retry and circuit breaker
+type HttpClient struct {
+	client  *http.Client
+	retry   RetryPolicy
+	breaker BreakerPolicy
+}
+
+// NewHttpClient return instance of http client with retry and circuit breaker
+func NewHttpClient(client *http.Client, retry RetryPolicy, breaker BreakerPolicy) *HttpClient {
+	return &HttpClient{
+		client:  client,
+		retry:   retry,
+		breaker: breaker,
+	}
+}
+
+// endpointKey return key of endpoint for circuit breaker
+func endpointKey(req *http.Request) string {
+	return req.Method + " " + req.URL.Host + req.URL.Path
+}
+
+// allow return if request for endpoint is allowed by circuit breaker
+func (h *HttpClient) allow(key string) bool {
+	circuitBreakers.mu.Lock()
+	defer circuitBreakers.mu.Unlock()
+	cb, ok := circuitBreakers.breakers[key]
+	if !ok || cb.openUntil.IsZero() {
+		return true
+	}
+	if time.Now().Before(cb.openUntil) || cb.halfOpen {
+		return false
+	}
+	// open timeout expired, allow one trial request
+	cb.halfOpen = true
+	return true
+}
+
+// record execute register result of request for endpoint
+func (h *HttpClient) record(key string, success bool) {
+	circuitBreakers.mu.Lock()
+	defer circuitBreakers.mu.Unlock()
+	cb, ok := circuitBreakers.breakers[key]
+	if !ok {
+		cb = &circuitBreaker{}
+		circuitBreakers.breakers[key] = cb
+	}
+	if success {
+		cb.failures = 0
+		cb.openUntil = time.Time{}
+		cb.halfOpen = false
+		return
+	}
+	cb.failures++
+	if cb.halfOpen || (h.breaker.FailureThreshold > 0 && cb.failures >= h.breaker.FailureThreshold) {
+		cb.openUntil = time.Now().Add(h.breaker.OpenTimeout)
+		cb.halfOpen = false
+	}
+}
+
+// isRetryableStatus return if status code must be retried
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusInternalServerError:
+		return true
+	}
+	return false
+}
+
+// isIdempotent return if request can be sent again without duplicate
+// effect, by method or by idempotency key informed by caller
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return len(req.Header.Get(HEADER_IDEMPOTENCY_KEY)) > 0
+}
+
+// retryAfter return delay informed in header Retry-After
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// Do execute request with retry for network failures and retryable
+// status, respecting Retry-After and the circuit breaker of endpoint,
+// request not idempotent is sent only once
+func (h *HttpClient) Do(req *http.Request) (*http.Response, error) {
+	key := endpointKey(req)
+	if !h.allow(key) {
+		return nil, ErrCircuitOpen
+	}
+	maxRetries := h.retry.MaxRetries
+	if !isIdempotent(req) {
+		maxRetries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.New("request body not replayable")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		res, err := h.client.Do(req)
+		retryable := err != nil || isRetryableStatus(res.StatusCode)
+		h.record(key, !retryable)
+		if !retryable || attempt >= maxRetries || req.Context().Err() != nil {
+			return res, err
+		}
+		if !h.allow(key) {
+			if res != nil {
+				return res, nil
+			}
+			return nil, ErrCircuitOpen
+		}
+		delay := Backoff(attempt, h.retry.BaseDelay, h.retry.MaxDelay)
+		if res != nil {
+			if after, ok := retryAfter(res); ok {
+				delay = min(after, h.retry.MaxDelay)
+			}
+			res.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package pkg
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// processRule is struct for compiled rule the process inventory
+type processRule struct {
+	nameRegex *regexp.Regexp
+	user      string
+	execPath  string
+}
+
+// ProcessFilter is struct for filter and aggregate the process inventory
+type ProcessFilter struct {
+	include   []processRule
+	exclude   []processRule
+	minUptime time.Duration
+	aggregate bool
+}
+
+// NewProcessFilter return instance of process filter from config
+func NewProcessFilter(config dto.ProcessInventoryConfig) (*ProcessFilter, error) {
+	include, err := compileProcessRules(config.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileProcessRules(config.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	var minUptime time.Duration
+	if len(config.MinUptime) > 0 {
+		minUptime, err = time.ParseDuration(config.MinUptime)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &ProcessFilter{
+		include:   include,
+		exclude:   exclude,
+		minUptime: minUptime,
+		aggregate: config.Aggregate,
+	}, nil
+}
+
+// compileProcessRules execute compile the rules from config
+func compileProcessRules(rules []dto.ProcessRule) ([]processRule, error) {
+	var compiled []processRule
+	for _, rule := range rules {
+		cRule := processRule{
+			user:     rule.User,
+			execPath: rule.ExecPath,
+		}
+		if len(rule.NameRegex) > 0 {
+			nameRegex, err := regexp.Compile(rule.NameRegex)
+			if err != nil {
+				return nil, err
+			}
+			cRule.nameRegex = nameRegex
+		}
+		compiled = append(compiled, cRule)
+	}
+	return compiled, nil
+}
+
+// match return if process match all fields informed in rule
+func (r processRule) match(process dto.ProcessInfo) bool {
+	if r.nameRegex != nil && !r.nameRegex.MatchString(process.Name) {
+		return false
+	}
+	if len(r.user) > 0 && r.user != process.User {
+		return false
+	}
+	if len(r.execPath) > 0 && !strings.HasPrefix(process.ExecPath, r.execPath) {
+		return false
+	}
+	return true
+}
+
+// IsAggregate return if inventory must be aggregated by executable
+func (f *ProcessFilter) IsAggregate() bool {
+	return f.aggregate
+}
+
+// Match return if process must be kept on inventory,
+// processes from known vendors are always kept
+func (f *ProcessFilter) Match(process dto.ProcessInfo, now time.Time) bool {
+	if len(process.Vendor) > 0 {
+		return true
+	}
+	if f.minUptime > 0 && process.CreateTime > 0 {
+		if now.Sub(time.UnixMilli(process.CreateTime)) < f.minUptime {
+			return false
+		}
+	}
+	if len(f.include) > 0 {
+		included := false
+		for _, rule := range f.include {
+			if rule.match(process) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, rule := range f.exclude {
+		if rule.match(process) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter return processes kept by rules
+func (f *ProcessFilter) Filter(processes []dto.ProcessInfo) []dto.ProcessInfo {
+	now := time.Now()
+	filtered := []dto.ProcessInfo{}
+	for _, process := range processes {
+		if f.Match(process, now) {
+			filtered = append(filtered, process)
+		}
+	}
+	return filtered
+}
+
+// Aggregate return processes grouped by executable with counts
+func (f *ProcessFilter) Aggregate(processes []dto.ProcessInfo) []dto.ProcessAggregate {
+	groups := make(map[string]*dto.ProcessAggregate)
+	for _, process := range processes {
+		key := process.ExecPath
+		if len(key) == 0 {
+			key = process.Name
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &dto.ProcessAggregate{
+				Name:     process.Name,
+				ExecPath: process.ExecPath,
+				Vendor:   process.Vendor,
+			}
+			groups[key] = group
+		}
+		group.Count++
+		group.NumThreads += process.NumThreads
+		group.NumFDs += process.NumFDs
+	}
+	aggregates := make([]dto.ProcessAggregate, 0, len(groups))
+	for _, group := range groups {
+		aggregates = append(aggregates, *group)
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		if aggregates[i].Count != aggregates[j].Count {
+			return aggregates[i].Count > aggregates[j].Count
+		}
+		return aggregates[i].ExecPath < aggregates[j].ExecPath
+	})
+	return aggregates
+}
+
+// ProcessVendors return names the known vendors present in processes
+func ProcessVendors(processes []dto.ProcessInfo) []string {
+	seen := make(map[string]struct{})
+	vendors := []string{}
+	for _, process := range processes {
+		if len(process.Vendor) == 0 {
+			continue
+		}
+		if _, ok := seen[process.Vendor]; ok {
+			continue
+		}
+		seen[process.Vendor] = struct{}{}
+		vendors = append(vendors, process.Vendor)
+	}
+	sort.Strings(vendors)
+	return vendors
+}
@@ -0,0 +1,208 @@
+package pkg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+// SecretStore is struct for secrets encrypted at rest,
+// using key bound to machine
+type SecretStore struct {
+	dirPath string
+}
+
+// NewSecretStore return instance of secret store in directory
+func NewSecretStore(dirPath string) *SecretStore {
+	return &SecretStore{
+		dirPath: dirPath,
+	}
+}
+
+// machineId return identifier of machine
+func machineId() string {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		content, err := os.ReadFile(path)
+		if err == nil && len(strings.TrimSpace(string(content))) > 0 {
+			return strings.TrimSpace(string(content))
+		}
+	}
+	id, err := host.HostID()
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// encryptionKey return key derived from key file and machine,
+// creating the key file with permission 0600 when not exist
+func (s *SecretStore) encryptionKey() ([]byte, error) {
+	if err := os.MkdirAll(s.dirPath, 0700); err != nil {
+		return nil, err
+	}
+	keyPath := filepath.Join(s.dirPath, SECRET_KEY_FILE_NAME)
+	keyFile, err := os.ReadFile(keyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		keyFile = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, keyFile); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(keyPath, keyFile, 0600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if len(keyFile) < 32 {
+		return nil, ErrSecretKeyInvalid
+	}
+	hash := sha256.New()
+	hash.Write(keyFile)
+	hash.Write([]byte(machineId()))
+	return hash.Sum(nil), nil
+}
+
+// gcm return cipher for encryption of secrets
+func (s *SecretStore) gcm() (cipher.AEAD, error) {
+	key, err := s.encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// load return secrets decrypted from store file
+func (s *SecretStore) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	content, err := os.ReadFile(filepath.Join(s.dirPath, SECRET_STORE_FILE_NAME))
+	if errors.Is(err, fs.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	aead, err := s.gcm()
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < aead.NonceSize() {
+		return nil, ErrSecretKeyInvalid
+	}
+	nonce, ciphertext := content[:aead.NonceSize()], content[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrSecretKeyInvalid
+	}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// save execute encrypt and write secrets in store file
+func (s *SecretStore) save(secrets map[string]string) error {
+	aead, err := s.gcm()
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	content := aead.Seal(nonce, nonce, plaintext, nil)
+	storePath := filepath.Join(s.dirPath, SECRET_STORE_FILE_NAME)
+	tmpPath := storePath + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, storePath)
+}
+
+// lock execute lock of secret files shared by manager, updater, agent
+// and docpctl, load and save of secrets are done with lock
+func (s *SecretStore) lock() (func() error, error) {
+	if err := os.MkdirAll(s.dirPath, 0700); err != nil {
+		return nil, err
+	}
+	return lockFile(filepath.Join(s.dirPath, SECRET_LOCK_FILE_NAME))
+}
+
+// Get return secret by name, empty when not exist
+func (s *SecretStore) Get(name string) (string, error) {
+	// without store file nothing to read, lock is not created
+	if _, err := os.Stat(filepath.Join(s.dirPath, SECRET_STORE_FILE_NAME)); errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return secrets[name], nil
+}
+
+// Set execute save secret by name, empty value is not accepted,
+// secret is removed only by Delete
+func (s *SecretStore) Set(name, value string) error {
+	if len(value) == 0 {
+		return ErrSecretEmpty
+	}
+	return s.update(func(secrets map[string]string) bool {
+		if secrets[name] == value {
+			return false
+		}
+		secrets[name] = value
+		return true
+	})
+}
+
+// Delete execute remove of secret by name
+func (s *SecretStore) Delete(name string) error {
+	return s.update(func(secrets map[string]string) bool {
+		if _, ok := secrets[name]; !ok {
+			return false
+		}
+		delete(secrets, name)
+		return true
+	})
+}
+
+// update execute load, change and save of secrets with lock, secrets
+// are saved only when change return true
+func (s *SecretStore) update(change func(secrets map[string]string) bool) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if !change(secrets) {
+		return nil
+	}
+	return s.save(secrets)
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// flattenJSON execute flatten of json value in paths
+// separated by dot with value formatted
+func flattenJSON(prefix string, value any, out map[string]string) {
+	join := func(key string) string {
+		if len(prefix) == 0 {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && len(prefix) > 0 {
+			out[prefix] = "{}"
+		}
+		for key, item := range v {
+			flattenJSON(join(key), item, out)
+		}
+	case []any:
+		if len(v) == 0 && len(prefix) > 0 {
+			out[prefix] = "[]"
+		}
+		for i, item := range v {
+			flattenJSON(prefix+"["+strconv.Itoa(i)+"]", item, out)
+		}
+	default:
+		content, err := json.Marshal(v)
+		if err != nil {
+			out[prefix] = fmt.Sprintf("%v", v)
+			return
+		}
+		out[prefix] = string(content)
+	}
+}
+
+// decodeFlatJSON return json content flattened, empty content is empty state
+func decodeFlatJSON(content []byte) (map[string]string, error) {
+	flat := make(map[string]string)
+	if len(content) == 0 {
+		return flat, nil
+	}
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		return nil, err
+	}
+	flattenJSON("", value, flat)
+	return flat, nil
+}
+
+// DiffJSON return lines with differences between two json contents,
+// prefixed by "-" for removed, "+" for added and "~" for changed paths
+func DiffJSON(from, to []byte) ([]string, error) {
+	fromFlat, err := decodeFlatJSON(from)
+	if err != nil {
+		return nil, err
+	}
+	toFlat, err := decodeFlatJSON(to)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(fromFlat)+len(toFlat))
+	for path := range fromFlat {
+		paths = append(paths, path)
+	}
+	for path := range toFlat {
+		if _, ok := fromFlat[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	var lines []string
+	for _, path := range paths {
+		fromValue, inFrom := fromFlat[path]
+		toValue, inTo := toFlat[path]
+		switch {
+		case inFrom && !inTo:
+			lines = append(lines, fmt.Sprintf("- %s: %s", path, fromValue))
+		case !inFrom && inTo:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", path, toValue))
+		case fromValue != toValue:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", path, fromValue, toValue))
+		}
+	}
+	return lines, nil
+}
@@ -54,3 +54,30 @@ func (s *SystemdClient) AlreadyInstalledService(service string) (bool, error) {
 
 	return true, nil
 }
+
+// UnitsActiveState return active state of loaded services by name,
+// services not found are omitted
+func (s *SystemdClient) UnitsActiveState(services []string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	conn, err := dbus.NewWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	units, err := conn.ListUnitsByNamesContext(ctx, services)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]string)
+	for _, unit := range units {
+		if unit.LoadState == "not-found" {
+			continue
+		}
+		states[unit.Name] = unit.ActiveState
+	}
+	return states, nil
+}
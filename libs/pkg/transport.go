@@ -0,0 +1,179 @@
+package pkg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// NewTransport return transport for outbound connections with proxy,
+// extra ca bundle and client certificate used only for mtls host
+func NewTransport(network dto.NetworkConfig, mtlsHost string) (http.RoundTripper, error) {
+	tlsConfig, err := newTLSConfig(network)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := newProxyFunc(network)
+	if err != nil {
+		return nil, err
+	}
+	transport := newBaseTransport(proxy, tlsConfig)
+	if len(network.ClientCert) == 0 && len(network.ClientKey) == 0 {
+		return transport, nil
+	}
+	if len(network.ClientCert) == 0 || len(network.ClientKey) == 0 {
+		return nil, errors.New("client cert and client key must be informed together")
+	}
+	certificate, err := tls.LoadX509KeyPair(network.ClientCert, network.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+	mtlsConfig := tlsConfig.Clone()
+	mtlsConfig.Certificates = []tls.Certificate{certificate}
+	return &hostRoundTripper{
+		host:             mtlsHost,
+		mtlsTransport:    newBaseTransport(proxy, mtlsConfig),
+		defaultTransport: transport,
+	}, nil
+}
+
+// newBaseTransport return transport with defaults of http package
+func newBaseTransport(proxy func(*http.Request) (*url.URL, error), tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// newTLSConfig return tls config with system pool and extra ca bundle
+func newTLSConfig(network dto.NetworkConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(network.CABundle) == 0 {
+		return tlsConfig, nil
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	content, err := os.ReadFile(network.CABundle)
+	if err != nil {
+		return nil, err
+	}
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, errors.New("no certificates found in ca bundle")
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// newProxyFunc return proxy func for transport, explicit proxy
+// has priority over HTTP_PROXY and HTTPS_PROXY envs, no proxy of
+// config is applied for both
+func newProxyFunc(network dto.NetworkConfig) (func(*http.Request) (*url.URL, error), error) {
+	if len(network.ProxyUrl) == 0 {
+		return func(req *http.Request) (*url.URL, error) {
+			if len(network.NoProxy) > 0 && BypassProxy(req.URL.Hostname(), network.NoProxy) {
+				return nil, nil
+			}
+			return http.ProxyFromEnvironment(req)
+		}, nil
+	}
+	proxyUrl, err := url.Parse(network.ProxyUrl)
+	if err != nil {
+		return nil, err
+	}
+	if len(proxyUrl.Host) == 0 {
+		return nil, errors.New("invalid proxy url")
+	}
+	if len(network.ProxyUser) > 0 {
+		proxyUrl.User = url.UserPassword(network.ProxyUser, network.ProxyPassword)
+	}
+	noProxy := network.NoProxy
+	if len(noProxy) == 0 {
+		noProxy = os.Getenv("NO_PROXY")
+		if len(noProxy) == 0 {
+			noProxy = os.Getenv("no_proxy")
+		}
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if BypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxyUrl, nil
+	}, nil
+}
+
+// BypassProxy return if host must not use proxy, localhost is
+// always bypassed and no proxy accepts hosts, domains and cidrs
+func BypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if len(entry) == 0 {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, "*")
+		if host == strings.TrimPrefix(entry, ".") {
+			return true
+		}
+		if !strings.HasPrefix(entry, ".") {
+			entry = "." + entry
+		}
+		if strings.HasSuffix(host, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostRoundTripper is struct for round tripper by host,
+// used for send client certificate only for one host
+type hostRoundTripper struct {
+	host             string
+	mtlsTransport    http.RoundTripper
+	defaultTransport http.RoundTripper
+}
+
+// RoundTrip execute request with transport of host
+func (h *hostRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.EqualFold(req.URL.Hostname(), h.host) {
+		return h.mtlsTransport.RoundTrip(req)
+	}
+	return h.defaultTransport.RoundTrip(req)
+}
@@ -3,12 +3,45 @@ package pkg
 import "errors"
 
 var (
-	ErrNotAuthorized          = errors.New("not authorized")
-	ErrAuthTokenClaimsInvalid = errors.New("invalid token claims")
-	ErrNotFound               = errors.New("not found")
-	ErrSignalAlreadyExists    = errors.New("signal already exists")
-	ErrFailedGetAgentVersions = errors.New("failed get agent versions")
-	ErrAgentVersionNotFound   = errors.New("agent version not found")
+	ErrNotAuthorized           = errors.New("not authorized")
+	ErrAuthTokenClaimsInvalid  = errors.New("invalid token claims")
+	ErrNotFound                = errors.New("not found")
+	ErrSignalAlreadyExists     = errors.New("signal already exists")
+	ErrFailedGetAgentVersions  = errors.New("failed get agent versions")
+	ErrAgentVersionNotFound    = errors.New("agent version not found")
+	ErrVendorNotSupported      = errors.New("vendor uninstall not supported")
+	ErrVendorUninstallTimeout  = errors.New("timeout waiting vendors uninstall")
+	ErrCircuitOpen             = errors.New("circuit breaker open for endpoint")
+	ErrSecretKeyInvalid        = errors.New("secret key invalid for secret store")
+	ErrSecretEmpty             = errors.New("secret value empty")
+	ErrControlUnavailable      = errors.New("manager control socket not available")
+	ErrControlDirShared        = errors.New("dir of control socket is shared with other users")
+	ErrShutdownTimeout         = errors.New("timeout waiting tasks on shutdown")
+	ErrMaintenanceInvalid      = errors.New("invalid maintenance window")
+	ErrInvalidVersion          = errors.New("invalid version or constraint")
+	ErrAgentDowngrade          = errors.New("downgrade of agent not allowed")
+	ErrReleaseNotRetained      = errors.New("release not retained for rollback")
+	ErrPackageManagerNotFound  = errors.New("package manager apt or yum not found")
+	ErrPackageChecksum         = errors.New("checksum of package not match")
+	ErrDatadogInstallerInvalid = errors.New("datadog installer must be package or script")
+	ErrKeyUrlInsecure          = errors.New("key url must be https or file")
+	ErrIntegrationInvalid      = errors.New("invalid datadog integration")
+	ErrActionPanic             = errors.New("action panic recovered")
+	ErrActionDeferred          = errors.New("action deferred outside of maintenance window")
+
+	// keys of datadog repositories
+	DatadogAptGpgKeyUrls = []string{
+		"https://keys.datadoghq.com/DATADOG_APT_KEY_CURRENT.public",
+		"https://keys.datadoghq.com/DATADOG_APT_KEY_C0962C7D.public",
+		"https://keys.datadoghq.com/DATADOG_APT_KEY_F14F620E.public",
+		"https://keys.datadoghq.com/DATADOG_APT_KEY_382E94DE.public",
+	}
+	DatadogRpmGpgKeyUrls = []string{
+		"https://keys.datadoghq.com/DATADOG_RPM_KEY_CURRENT.public",
+		"https://keys.datadoghq.com/DATADOG_RPM_KEY_4F09D16B.public",
+		"https://keys.datadoghq.com/DATADOG_RPM_KEY_B01082D3.public",
+		"https://keys.datadoghq.com/DATADOG_RPM_KEY_FD4BF915.public",
+	}
 
 	// transactions events
 	TransactionEventOpen   = "open"
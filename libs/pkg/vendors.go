@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// VendorDefinition is struct for known monitoring vendor signatures
+type VendorDefinition struct {
+	Name         string
+	ProcessNames []string
+	ExecPaths    []string
+	ServiceUnits []string
+	Packages     []string
+	// UninstallCommand is command executed before package removal,
+	// used by vendors installed without package manager
+	UninstallCommand []string
+	PurgePaths       []string
+}
+
+// KnownVendors is list of monitoring vendors detectable on host
+var KnownVendors = []VendorDefinition{
+	{
+		Name:         "datadog",
+		ProcessNames: []string{"datadog-agent", "trace-agent", "process-agent", "system-probe", "security-agent"},
+		ExecPaths:    []string{"/opt/datadog-agent/", "\\datadog agent\\"},
+		ServiceUnits: []string{"datadog-agent.service"},
+		Packages:     []string{"datadog-agent"},
+	},
+	{
+		Name:         "newrelic",
+		ProcessNames: []string{"newrelic-infra", "newrelic-infra-service", "newrelic-infra.exe"},
+		ExecPaths:    []string{"/var/db/newrelic-infra/", "\\new relic\\"},
+		ServiceUnits: []string{"newrelic-infra.service"},
+		Packages:     []string{"newrelic-infra"},
+		PurgePaths:   []string{"/etc/newrelic-infra", "/etc/newrelic-infra.yml", "/var/db/newrelic-infra", "/var/log/newrelic-infra"},
+	},
+	{
+		Name:             "dynatrace",
+		ProcessNames:     []string{"oneagentwatchdog", "oneagentos", "oneagentnetwork", "oneagentplugin", "oneagenthelper"},
+		ExecPaths:        []string{"/opt/dynatrace/oneagent/", "\\dynatrace\\oneagent\\"},
+		ServiceUnits:     []string{"oneagent.service"},
+		UninstallCommand: []string{"/opt/dynatrace/oneagent/agent/uninstall.sh"},
+		PurgePaths:       []string{"/opt/dynatrace/oneagent", "/var/lib/dynatrace/oneagent", "/var/log/dynatrace/oneagent"},
+	},
+	{
+		Name:             "elastic",
+		ProcessNames:     []string{"elastic-agent", "elastic-endpoint", "elastic-agent.exe"},
+		ExecPaths:        []string{"/opt/elastic/agent/", "\\elastic\\agent\\"},
+		ServiceUnits:     []string{"elastic-agent.service"},
+		Packages:         []string{"elastic-agent"},
+		UninstallCommand: []string{"/opt/Elastic/Agent/elastic-agent", "uninstall", "--force"},
+		PurgePaths:       []string{"/opt/Elastic/Agent", "/etc/elastic-agent", "/var/lib/elastic-agent", "/var/log/elastic-agent"},
+	},
+	{
+		Name:         "splunk",
+		ProcessNames: []string{"splunkd", "splunkd.exe"},
+		ExecPaths:    []string{"/opt/splunkforwarder/", "\\splunkuniversalforwarder\\"},
+		ServiceUnits: []string{"SplunkForwarder.service", "splunk.service"},
+		Packages:     []string{"splunkforwarder"},
+	},
+	{
+		Name:         "zabbix",
+		ProcessNames: []string{"zabbix_agentd", "zabbix_agent2", "zabbix_agentd.exe", "zabbix_agent2.exe"},
+		ServiceUnits: []string{"zabbix-agent.service", "zabbix-agent2.service"},
+		Packages:     []string{"zabbix-agent", "zabbix-agent2"},
+		PurgePaths:   []string{"/etc/zabbix/zabbix_agentd.conf", "/etc/zabbix/zabbix_agentd.d", "/etc/zabbix/zabbix_agent2.conf", "/etc/zabbix/zabbix_agent2.d", "/var/log/zabbix"},
+	},
+	{
+		Name:         "appdynamics",
+		ProcessNames: []string{"machineagent", "appdynamics-machine-agent"},
+		ExecPaths:    []string{"/opt/appdynamics/", "\\appdynamics\\"},
+		ServiceUnits: []string{"appdynamics-machine-agent.service"},
+		Packages:     []string{"appdynamics-machine-agent"},
+	},
+	{
+		Name:         "cloudwatch",
+		ProcessNames: []string{"amazon-cloudwatch-agent", "amazon-cloudwatch-agent.exe"},
+		ExecPaths:    []string{"/opt/aws/amazon-cloudwatch-agent/", "\\amazon\\amazoncloudwatchagent\\"},
+		ServiceUnits: []string{"amazon-cloudwatch-agent.service"},
+		Packages:     []string{"amazon-cloudwatch-agent"},
+	},
+}
+
+// DetectVendor return vendor name for process name and exec path,
+// empty when the process not belong to known vendor
+func DetectVendor(name, execPath string) string {
+	lowerName := strings.ToLower(name)
+	lowerExecPath := strings.ToLower(execPath)
+	baseExecPath := strings.ToLower(filepath.Base(execPath))
+	for _, vendor := range KnownVendors {
+		for _, processName := range vendor.ProcessNames {
+			if lowerName == processName || (len(execPath) > 0 && baseExecPath == processName) {
+				return vendor.Name
+			}
+		}
+		if len(lowerExecPath) == 0 {
+			continue
+		}
+		for _, execPathVendor := range vendor.ExecPaths {
+			if strings.Contains(lowerExecPath, execPathVendor) {
+				return vendor.Name
+			}
+		}
+	}
+	return ""
+}
+
+// GetVendorDefinition return definition of known vendor by name
+func GetVendorDefinition(name string) (VendorDefinition, bool) {
+	for _, vendor := range KnownVendors {
+		if vendor.Name == name {
+			return vendor, true
+		}
+	}
+	return VendorDefinition{}, false
+}
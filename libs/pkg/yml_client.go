@@ -6,23 +6,87 @@ import (
 )
 
 // YmlClient is struct for yml functions
-type YmlClient struct{}
+type YmlClient struct {
+	secrets *SecretStore
+}
 
 // NewYmlClient return instance of yml client
 func NewYmlClient() *YmlClient {
 	return &YmlClient{}
 }
 
+// NewYmlClientWithSecrets return instance of yml client that keep
+// api key, access token and proxy password in secret store instead of yml
+func NewYmlClientWithSecrets(secrets *SecretStore) *YmlClient {
+	return &YmlClient{
+		secrets: secrets,
+	}
+}
+
 // Unmarshall execute parse the yml for data
 func (y *YmlClient) Unmarshall(data []byte, config *dto.ConfigAgent) error {
 	if err := yaml.Unmarshal(data, config); err != nil {
 		return err
 	}
+	if y.secrets == nil {
+		return nil
+	}
+	// values in plaintext have priority until migrated
+	if len(config.Agent.ApiKey) == 0 {
+		apiKey, err := y.secrets.Get(SECRET_NAME_API_KEY)
+		if err != nil {
+			return err
+		}
+		config.Agent.ApiKey = apiKey
+	}
+	if len(config.AccessToken) == 0 {
+		accessToken, err := y.secrets.Get(SECRET_NAME_ACCESS_TOKEN)
+		if err != nil {
+			return err
+		}
+		config.AccessToken = accessToken
+	}
+	if len(config.Network.ProxyPassword) == 0 {
+		proxyPassword, err := y.secrets.Get(SECRET_NAME_PROXY_PASSWORD)
+		if err != nil {
+			return err
+		}
+		config.Network.ProxyPassword = proxyPassword
+	}
 	return nil
 }
 
-// Marshall execute parse the data for yml
+// SaveSecrets execute save of api key, access token and proxy password
+// of config in secret store, empty values keep secrets already saved
+func (y *YmlClient) SaveSecrets(config *dto.ConfigAgent) error {
+	if y.secrets == nil {
+		return nil
+	}
+	for name, value := range map[string]string{
+		SECRET_NAME_API_KEY:        config.Agent.ApiKey,
+		SECRET_NAME_ACCESS_TOKEN:   config.AccessToken,
+		SECRET_NAME_PROXY_PASSWORD: config.Network.ProxyPassword,
+	} {
+		if len(value) == 0 {
+			continue
+		}
+		if err := y.secrets.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Marshall execute parse the data for yml, with secret store secrets
+// are not in yml and must be saved by SaveSecrets
 func (y *YmlClient) Marshall(config *dto.ConfigAgent) ([]byte, error) {
+	if y.secrets != nil {
+		withoutSecrets := *config
+		withoutSecrets.Agent.ApiKey = ""
+		withoutSecrets.AccessToken = ""
+		withoutSecrets.Network.ProxyPassword = ""
+		config = &withoutSecrets
+	}
 	data, err := yaml.Marshal(config)
 	if err != nil {
 		return nil, err
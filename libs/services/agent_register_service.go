@@ -23,42 +23,32 @@ type AgentRegisterService struct {
 	ymlClient      *pkg.YmlClient
 	configFilePath string
 	logger         interfaces.ILogger
-	client         *http.Client
+	config         dto.RuntimeConfig
+	client         *pkg.HttpClient
+	tokenManager   *TokenManager
 }
 
 // NewAgentRegisterService return instance of agent the register service
-func NewAgentRegisterService(logger interfaces.ILogger) *AgentRegisterService {
+func NewAgentRegisterService(logger interfaces.ILogger, config dto.RuntimeConfig) *AgentRegisterService {
 	return &AgentRegisterService{
-		logger: logger,
+		logger: logger.Named("agent_register_service"),
+		config: config,
 	}
 }
 
 // Setup execute configurations for register service
 func (ag *AgentRegisterService) Setup() error {
-	urlRegister, err := utils.GetDomainUrl()
-	if err != nil {
-		return err
-	}
-	ag.urlRegister = urlRegister
-	configFilePath, err := utils.GetConfigFilePath()
-	if err != nil {
-		return err
-	}
-
-	workDirPath, err := utils.GetWorkDirPath()
-	if err != nil {
-		return err
-	}
-
-	ag.configFilePath = configFilePath
-	ag.workDirPath = workDirPath
+	ag.urlRegister = ag.config.Domain
+	ag.configFilePath = ag.config.ConfigFilePath
+	ag.workDirPath = ag.config.WorkDirPath
 	fileSystem := pkg.NewFileSystem()
 	ag.fileSystem = fileSystem
-	yamlClient := pkg.NewYmlClient()
-	ag.ymlClient = yamlClient
-	client := &http.Client{
-		Timeout: time.Second * 90,
+	ag.ymlClient = utils.NewConfigYmlClient(ag.config.WorkDirPath)
+	httpClient, err := utils.NewOutboundHttpClient(ag.config, time.Second*90)
+	if err != nil {
+		return err
 	}
+	client := pkg.NewHttpClient(httpClient, pkg.DefaultRetryPolicy(), pkg.DefaultBreakerPolicy())
 	ag.client = client
 	return nil
 }
@@ -117,12 +107,24 @@ func (ag *AgentRegisterService) prepareToSendUpdate(metadata []byte) ([]byte, st
 		ag.logger.Error("error in prepare to send", "trace", "docp-agent-os-instance.agent_register_service.prepareToSend", "error", err.Error())
 		return nil, "", err
 	}
-	injectedMetadataBytes, apiKey, err := ag.InjectClientInfoUpdate(configFileBytes, metadata)
+	injectedMetadataBytes, accessToken, err := ag.InjectClientInfoUpdate(configFileBytes, metadata)
 	if err != nil {
 		ag.logger.Error("error in prepare to send", "trace", "docp-agent-os-instance.agent_register_service.prepareToSend", "error", err.Error())
 		return nil, "", err
 	}
-	return injectedMetadataBytes, apiKey, nil
+	if ag.tokenManager != nil {
+		accessToken, err = ag.tokenManager.GetToken()
+		if err != nil {
+			ag.logger.Error("error in get access token", "trace", "docp-agent-os-instance.agent_register_service.prepareToSendUpdate", "error", err.Error())
+			return nil, "", err
+		}
+	}
+	return injectedMetadataBytes, accessToken, nil
+}
+
+// SetTokenManager configure token manager used for access token
+func (ag *AgentRegisterService) SetTokenManager(tokenManager *TokenManager) {
+	ag.tokenManager = tokenManager
 }
 
 // GetConfigFileContent get config file content from local
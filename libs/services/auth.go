@@ -11,6 +11,7 @@ import (
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 )
 
@@ -18,26 +19,26 @@ import (
 type AuthService struct {
 	urlAuth string
 	logger  interfaces.ILogger
-	client  *http.Client
+	config  dto.RuntimeConfig
+	client  *pkg.HttpClient
 }
 
 // NewAuthService return instance the auth service
-func NewAuthService(logger interfaces.ILogger) *AuthService {
+func NewAuthService(logger interfaces.ILogger, config dto.RuntimeConfig) *AuthService {
 	return &AuthService{
-		logger: logger,
+		logger: logger.Named("auth"),
+		config: config,
 	}
 }
 
 // Setup execute configurations for auth service
 func (as *AuthService) Setup() error {
-	urlDomain, err := utils.GetDomainUrl()
+	as.urlAuth = as.config.Domain
+	httpClient, err := utils.NewOutboundHttpClient(as.config, time.Second*90)
 	if err != nil {
 		return err
 	}
-	as.urlAuth = urlDomain
-	client := &http.Client{
-		Timeout: time.Second * 90,
-	}
+	client := pkg.NewHttpClient(httpClient, pkg.DefaultRetryPolicy(), pkg.DefaultBreakerPolicy())
 	as.client = client
 	return nil
 }
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// ControlClient is struct for client of local control socket of manager
+type ControlClient struct {
+	logger     interfaces.ILogger
+	config     dto.RuntimeConfig
+	client     *http.Client
+	socketPath string
+}
+
+// NewControlClient return instance of control client
+func NewControlClient(logger interfaces.ILogger, config dto.RuntimeConfig) *ControlClient {
+	return &ControlClient{
+		logger: logger.Named("control_client"),
+		config: config,
+	}
+}
+
+// Setup configure control client
+func (c *ControlClient) Setup() error {
+	c.socketPath = utils.GetControlSocketPath(c.config.WorkDirPath)
+	c.client = &http.Client{
+		Timeout: time.Second * 30,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", c.socketPath)
+			},
+		},
+	}
+	return nil
+}
+
+// call execute request to control socket and decode response in out
+func (c *ControlClient) call(method, route string, payload any, out any) error {
+	c.logger.Debug("control call", "trace", "docp-agent-os-instance.control_client.call", "method", method, "route", route)
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payloadBytes)
+	}
+	req, err := http.NewRequest(method, "http://docp-manager"+route, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", pkg.ErrControlUnavailable, err.Error())
+	}
+	defer res.Body.Close()
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		var controlResponse dto.ControlResponse
+		if err := json.Unmarshal(content, &controlResponse); err == nil && len(controlResponse.Message) > 0 {
+			return fmt.Errorf("control %s %s: %s", method, route, controlResponse.Message)
+		}
+		return fmt.Errorf("control %s %s: status code %d", method, route, res.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(content, out)
+}
+
+// Status return status of manager
+func (c *ControlClient) Status() (dto.ControlStatus, error) {
+	var status dto.ControlStatus
+	err := c.call(http.MethodGet, pkg.CONTROL_ROUTE_STATUS, nil, &status)
+	return status, err
+}
+
+// PlanActions return actions that manager would execute now
+func (c *ControlClient) PlanActions() ([]dto.StateAction, error) {
+	var actions []dto.StateAction
+	err := c.call(http.MethodGet, pkg.CONTROL_ROUTE_PLAN, nil, &actions)
+	return actions, err
+}
+
+// Update execute request of update agent for version or constraint,
+// downgrade is only applied when allowed
+func (c *ControlClient) Update(version string, allowDowngrade bool) (dto.ControlResponse, error) {
+	var controlResponse dto.ControlResponse
+	err := c.call(http.MethodPost, pkg.CONTROL_ROUTE_UPDATE, dto.ControlUpdateRequest{Version: version, AllowDowngrade: allowDowngrade}, &controlResponse)
+	return controlResponse, err
+}
+
+// Rollback execute request of rollback agent for release retained,
+// to empty is previous healthy release
+func (c *ControlClient) Rollback(to string) (dto.ControlResponse, error) {
+	var controlResponse dto.ControlResponse
+	err := c.call(http.MethodPost, pkg.CONTROL_ROUTE_ROLLBACK, dto.ControlRollbackRequest{To: to}, &controlResponse)
+	return controlResponse, err
+}
+
+// Tasks return tasks in flight in manager
+func (c *ControlClient) Tasks() ([]dto.ControlTask, error) {
+	var tasks []dto.ControlTask
+	err := c.call(http.MethodGet, pkg.CONTROL_ROUTE_TASKS, nil, &tasks)
+	return tasks, err
+}
+
+// Errors return last errors received in manager
+func (c *ControlClient) Errors() ([]dto.ControlError, error) {
+	var controlErrors []dto.ControlError
+	err := c.call(http.MethodGet, pkg.CONTROL_ROUTE_ERRORS, nil, &controlErrors)
+	return controlErrors, err
+}
+
+// command execute request of command without payload
+func (c *ControlClient) command(route string) (dto.ControlResponse, error) {
+	var controlResponse dto.ControlResponse
+	err := c.call(http.MethodPost, route, nil, &controlResponse)
+	return controlResponse, err
+}
+
+// ForcePoll execute request of poll of state check now
+func (c *ControlClient) ForcePoll() (dto.ControlResponse, error) {
+	return c.command(pkg.CONTROL_ROUTE_POLL)
+}
+
+// ForceMetadataSync execute request of send metadata now
+func (c *ControlClient) ForceMetadataSync() (dto.ControlResponse, error) {
+	return c.command(pkg.CONTROL_ROUTE_METADATA_SYNC)
+}
+
+// Pause execute request of pause of reconciliation
+func (c *ControlClient) Pause() (dto.ControlResponse, error) {
+	return c.command(pkg.CONTROL_ROUTE_PAUSE)
+}
+
+// Resume execute request of resume of reconciliation
+func (c *ControlClient) Resume() (dto.ControlResponse, error) {
+	return c.command(pkg.CONTROL_ROUTE_RESUME)
+}
+
+// Diagnostics execute request of upload of diagnostics bundle
+func (c *ControlClient) Diagnostics() (dto.ControlResponse, error) {
+	return c.command(pkg.CONTROL_ROUTE_DIAGNOSTICS)
+}
+
+// LogLevels return levels of loggers in manager
+func (c *ControlClient) LogLevels() (map[string]string, error) {
+	var levels map[string]string
+	err := c.call(http.MethodGet, pkg.CONTROL_ROUTE_LOG_LEVELS, nil, &levels)
+	return levels, err
+}
+
+// SetLogLevel execute change of level of logger in manager
+func (c *ControlClient) SetLogLevel(name string, level string) (map[string]string, error) {
+	var levels map[string]string
+	err := c.call(http.MethodPost, pkg.CONTROL_ROUTE_LOG_LEVELS, dto.ControlLogLevelRequest{Name: name, Level: level}, &levels)
+	return levels, err
+}
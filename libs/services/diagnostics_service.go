@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// DiagnosticsService is struct for collect and upload of diagnostics bundle
+type DiagnosticsService struct {
+	logger         interfaces.ILogger
+	config         dto.RuntimeConfig
+	diagnosticsUrl string
+	workDirPath    string
+	configFilePath string
+	fileSystem     *pkg.FileSystem
+	redactor       *utils.Redactor
+	client         *pkg.HttpClient
+	tokenManager   *TokenManager
+}
+
+// NewDiagnosticsService return instance of diagnostics service
+func NewDiagnosticsService(logger interfaces.ILogger, config dto.RuntimeConfig) *DiagnosticsService {
+	return &DiagnosticsService{
+		logger: logger.Named("diagnostics_service"),
+		config: config,
+	}
+}
+
+// Setup configure diagnostics service
+func (s *DiagnosticsService) Setup() error {
+	httpClient, err := utils.NewOutboundHttpClient(s.config, time.Second*120)
+	if err != nil {
+		return err
+	}
+	s.client = pkg.NewHttpClient(httpClient, pkg.DefaultRetryPolicy(), pkg.DefaultBreakerPolicy())
+	s.diagnosticsUrl = fmt.Sprintf("%s/compute/v1/diagnostics", s.config.Domain)
+	s.workDirPath = s.config.WorkDirPath
+	s.configFilePath = s.config.ConfigFilePath
+	s.fileSystem = pkg.NewFileSystem()
+	s.redactor = utils.NewConfiguredRedactor()
+	return nil
+}
+
+// SetTokenManager configure token manager used for access token
+func (s *DiagnosticsService) SetTokenManager(tokenManager *TokenManager) {
+	s.tokenManager = tokenManager
+}
+
+// commandOutput return output of command with timeout
+func (s *DiagnosticsService) commandOutput(command string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*pkg.DIAGNOSTICS_COMMAND_TIMEOUT)
+	defer cancel()
+	return exec.CommandContext(ctx, command, args...).CombinedOutput()
+}
+
+// datadogAgentBinary return path of binary of datadog agent
+func (s *DiagnosticsService) datadogAgentBinary() string {
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("ProgramFiles"), "Datadog", "Datadog Agent", "bin", "agent.exe")
+	case "linux":
+		if _, err := exec.LookPath("datadog-agent"); err != nil {
+			return filepath.Join(string(filepath.Separator), "opt", "datadog-agent", "bin", "agent", "agent")
+		}
+	}
+	return "datadog-agent"
+}
+
+// redactedConfig return content of config file with secrets redacted
+func (s *DiagnosticsService) redactedConfig() ([]byte, error) {
+	content, err := s.fileSystem.GetFileContent(s.configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[any]any
+	if err := yaml.Unmarshal(content, &tree); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(s.redactor.RedactTree(tree))
+}
+
+// redactedState return content of state file with secrets redacted
+func (s *DiagnosticsService) redactedState(name string) ([]byte, error) {
+	content, err := s.fileSystem.GetFileContent(filepath.Join(s.workDirPath, "state", name))
+	if err != nil {
+		return nil, err
+	}
+	return s.redactor.RedactJSON(content)
+}
+
+// addFile execute add of content in bundle, registering error of collect
+func (s *DiagnosticsService) addFile(bundle *pkg.DiagnosticsBundle, name string, content []byte, err error) error {
+	if err != nil {
+		s.logger.Warn("failed collect diagnostics file", "trace", "docp-agent-os-instance.diagnostics_service.addFile", "name", name, "error", err.Error())
+		bundle.AddError(name, err)
+		if len(content) == 0 {
+			return nil
+		}
+	}
+	return bundle.Add(name, content)
+}
+
+// Collect return tar gzip with logs, config and state redacted,
+// status of services and output of datadog agent status
+func (s *DiagnosticsService) Collect(requestId string, statuses map[string]string) ([]byte, error) {
+	s.logger.Debug("collect diagnostics", "trace", "docp-agent-os-instance.diagnostics_service.Collect", "requestId", requestId)
+	bundle := pkg.NewDiagnosticsBundle(requestId, pkg.DIAGNOSTICS_MAX_BUNDLE_SIZE, pkg.DIAGNOSTICS_MAX_FILE_SIZE)
+	statusesContent, err := json.MarshalIndent(statuses, "", "  ")
+	if err := s.addFile(bundle, "services.json", statusesContent, err); err != nil {
+		return nil, err
+	}
+	configContent, err := s.redactedConfig()
+	if err := s.addFile(bundle, "config.yml", configContent, err); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"received", "current"} {
+		stateContent, err := s.redactedState(name)
+		if err := s.addFile(bundle, filepath.ToSlash(filepath.Join("state", name)), stateContent, err); err != nil {
+			return nil, err
+		}
+	}
+	datadogStatus, err := s.commandOutput(s.datadogAgentBinary(), "status")
+	if err := s.addFile(bundle, "datadog/status.txt", []byte(s.redactor.RedactString(string(datadogStatus))), err); err != nil {
+		return nil, err
+	}
+	loggingConfig, err := utils.GetLoggingConfig(s.configFilePath)
+	if err != nil {
+		bundle.AddError("logs", err)
+	}
+	for _, service := range []string{"manager", "agent", "updater"} {
+		logPath := utils.GetServiceLogFilePath(service, s.workDirPath, loggingConfig.File)
+		if _, err := os.Stat(logPath); err != nil {
+			continue
+		}
+		// one byte over limit keeps log marked as truncated in bundle
+		logContent, err := s.fileSystem.GetFileTail(logPath, pkg.DIAGNOSTICS_MAX_FILE_SIZE+1)
+		if err := s.addFile(bundle, "logs/"+service+".log", logContent, err); err != nil {
+			return nil, err
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if journalctl, err := exec.LookPath("journalctl"); err == nil {
+			for _, service := range []string{"manager", "agent", "datadog"} {
+				journal, err := s.commandOutput(journalctl, "-u", utils.ChoiceNameService(service), "-n", fmt.Sprintf("%d", pkg.DIAGNOSTICS_JOURNAL_LINES), "--no-pager")
+				if err := s.addFile(bundle, "journald/"+service+".log", []byte(s.redactor.RedactString(string(journal))), err); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return bundle.Bytes()
+}
+
+// Upload execute send of bundle to control plane with id of transaction,
+// access token is refreshed once when not authorized
+func (s *DiagnosticsService) Upload(transaction dto.TransactionStatus, requestId string, bundle []byte) ([]byte, int, error) {
+	respBytes, statusCode, err := s.upload(transaction, requestId, bundle)
+	if err != nil || (statusCode != http.StatusUnauthorized && statusCode != http.StatusForbidden) {
+		return respBytes, statusCode, err
+	}
+	s.logger.Debug("refresh access token after upload diagnostics not authorized", "trace", "docp-agent-os-instance.diagnostics_service.Upload", "statusCode", statusCode)
+	if _, err := s.tokenManager.ForceRefresh(); err != nil {
+		return nil, 0, err
+	}
+	return s.upload(transaction, requestId, bundle)
+}
+
+// upload execute request of upload of diagnostics bundle
+func (s *DiagnosticsService) upload(transaction dto.TransactionStatus, requestId string, bundle []byte) ([]byte, int, error) {
+	s.logger.Debug("upload diagnostics", "trace", "docp-agent-os-instance.diagnostics_service.upload", "requestId", requestId, "size", len(bundle))
+	if s.tokenManager == nil {
+		return nil, 0, pkg.ErrNotAuthorized
+	}
+	accessToken, err := s.tokenManager.GetToken()
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*120)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.diagnosticsUrl, bytes.NewReader(bundle))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	req.Header.Set("X-Transaction-Id", transaction.ID)
+	req.Header.Set("X-Request-Id", requestId)
+	res, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Error("error in execute request", "trace", "docp-agent-os-instance.diagnostics_service.upload", "error", err.Error())
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+	respBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return respBytes, res.StatusCode, nil
+}
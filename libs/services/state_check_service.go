@@ -19,43 +19,39 @@ import (
 // StateCheckService is struct for state check service
 type StateCheckService struct {
 	logger        interfaces.ILogger
+	config        dto.RuntimeConfig
 	stateCheckUrl string
 	workDirPath   string
 	fileSystem    *pkg.FileSystem
 	hostStats     *pkg.HostStats
 	ymlClient     *pkg.YmlClient
-	client        *http.Client
+	client        *pkg.HttpClient
+	tokenManager  *TokenManager
 }
 
 // NewStateCheckService return instance of state check service
-func NewStateCheckService(logger interfaces.ILogger) *StateCheckService {
+func NewStateCheckService(logger interfaces.ILogger, config dto.RuntimeConfig) *StateCheckService {
 	return &StateCheckService{
-		logger: logger,
+		logger: logger.Named("state_check_service"),
+		config: config,
 	}
 }
 
 // Setup configure state check
 func (s *StateCheckService) Setup() error {
-	client := &http.Client{
-		Timeout: time.Second * 90,
-	}
-	s.client = client
-	domainUrl, err := utils.GetDomainUrl()
-	if err != nil {
-		return err
-	}
-	s.stateCheckUrl = domainUrl
-	workDirPath, err := utils.GetWorkDirPath()
+	httpClient, err := utils.NewOutboundHttpClient(s.config, time.Second*90)
 	if err != nil {
 		return err
 	}
-	s.workDirPath = workDirPath
+	client := pkg.NewHttpClient(httpClient, pkg.DefaultRetryPolicy(), pkg.DefaultBreakerPolicy())
+	s.client = client
+	s.stateCheckUrl = s.config.Domain
+	s.workDirPath = s.config.WorkDirPath
 	fileSystem := pkg.NewFileSystem()
 	s.fileSystem = fileSystem
 	hostStatus := pkg.NewHostStats()
 	s.hostStats = hostStatus
-	ymlClient := pkg.NewYmlClient()
-	s.ymlClient = ymlClient
+	s.ymlClient = utils.NewConfigYmlClient(s.config.WorkDirPath)
 	return nil
 }
 
@@ -80,9 +76,21 @@ func (s *StateCheckService) getContentConfigFile() ([]byte, error) {
 	return content, nil
 }
 
+// SetTokenManager configure token manager used for access token
+func (s *StateCheckService) SetTokenManager(tokenManager *TokenManager) {
+	s.tokenManager = tokenManager
+}
+
 // PreparePayload execute prepare the payload
 func (s *StateCheckService) PreparePayload() ([]byte, string, error) {
 	s.logger.Debug("prepare payload", "trace", "docp-agent-os-instance.state_check_service.PreparePayload")
+	if s.tokenManager != nil {
+		accessToken, err := s.tokenManager.GetToken()
+		if err != nil {
+			return nil, "", err
+		}
+		return nil, accessToken, nil
+	}
 	content, err := s.getContentConfigFile()
 	if err != nil {
 		return nil, "", err
@@ -98,21 +106,17 @@ func (s *StateCheckService) PreparePayload() ([]byte, string, error) {
 // PreparePayloadStatus execute prepare the payload for status
 func (s *StateCheckService) PreparePayloadStatus(transaction dto.TransactionStatus) ([]byte, string, error) {
 	s.logger.Debug("prepare payload status", "trace", "docp-agent-os-instance.state_check_service.PreparePayloadStatus", "transaction", transaction)
-	content, err := s.getContentConfigFile()
+	_, accessToken, err := s.PreparePayload()
 	if err != nil {
 		return nil, "", err
 	}
-	var config dto.ConfigAgent
-	if err := s.ymlClient.Unmarshall(content, &config); err != nil {
-		return nil, "", err
-	}
 
 	bStateCheckPayloadStatus, err := s.marshaller(&transaction)
 	if err != nil {
 		return nil, "", err
 	}
 
-	return bStateCheckPayloadStatus, config.AccessToken, nil
+	return bStateCheckPayloadStatus, accessToken, nil
 }
 
 // GetState return state from state check api
@@ -147,12 +151,27 @@ func (s *StateCheckService) GetState() ([]byte, int, error) {
 	return respBytes, res.StatusCode, nil
 }
 
-// SendStatus execute send status for state check api
+// SendStatus execute send status for state check api, ulid of event is
+// idempotency key so event is retried without duplicate, not authorized
+// refresh access token and send once again
 func (s *StateCheckService) SendStatus(transaction dto.TransactionStatus) ([]byte, int, error) {
-	s.logger.Debug("execute send status state check", "trace", "docp-agent-os-instance.state_check_service.SendStatus", "transaction", transaction)
+	respBytes, statusCode, err := s.sendStatus(transaction)
+	if err != nil || s.tokenManager == nil || (statusCode != http.StatusUnauthorized && statusCode != http.StatusForbidden) {
+		return respBytes, statusCode, err
+	}
+	s.logger.Debug("refresh access token after send status not authorized", "trace", "docp-agent-os-instance.state_check_service.SendStatus", "statusCode", statusCode)
+	if _, err := s.tokenManager.ForceRefresh(); err != nil {
+		return nil, 0, err
+	}
+	return s.sendStatus(transaction)
+}
+
+// sendStatus execute request of status for state check api
+func (s *StateCheckService) sendStatus(transaction dto.TransactionStatus) ([]byte, int, error) {
+	s.logger.Debug("execute send status state check", "trace", "docp-agent-os-instance.state_check_service.sendStatus", "transaction", transaction)
 	payload, acessToken, err := s.PreparePayloadStatus(transaction)
 	if err != nil {
-		s.logger.Error("error in prepare payload", "trace", "docp-agent-os-instance.state_check_service.SendStatus", "error", err.Error())
+		s.logger.Error("error in prepare payload", "trace", "docp-agent-os-instance.state_check_service.sendStatus", "error", err.Error())
 		return nil, 0, err
 	}
 	urlStateCheckStatus := fmt.Sprintf("%s/compute/transaction", s.stateCheckUrl)
@@ -160,20 +179,21 @@ func (s *StateCheckService) SendStatus(transaction dto.TransactionStatus) ([]byt
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStateCheckStatus, bytes.NewBuffer(payload))
 	if err != nil {
-		s.logger.Error("error in create request", "trace", "docp-agent-os-instance.state_check_service.SendStatus", "error", err.Error())
+		s.logger.Error("error in create request", "trace", "docp-agent-os-instance.state_check_service.sendStatus", "error", err.Error())
 		return nil, 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", acessToken))
+	req.Header.Set(pkg.HEADER_IDEMPOTENCY_KEY, transaction.UlidEvent)
 	res, err := s.client.Do(req)
 	if err != nil {
-		s.logger.Error("error in execute request", "trace", "docp-agent-os-instance.state_check_service.SendStatus", "error", err.Error())
+		s.logger.Error("error in execute request", "trace", "docp-agent-os-instance.state_check_service.sendStatus", "error", err.Error())
 		return nil, 0, err
 	}
 	defer res.Body.Close()
 	respBytes, err := io.ReadAll(res.Body)
 	if err != nil {
-		s.logger.Error("error in read body response", "trace", "docp-agent-os-instance.state_check_service.SendStatus", "error", err.Error())
+		s.logger.Error("error in read body response", "trace", "docp-agent-os-instance.state_check_service.sendStatus", "error", err.Error())
 		return nil, 0, err
 	}
 	return respBytes, res.StatusCode, nil
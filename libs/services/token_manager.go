@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// tokenRefresh is struct for refresh in flight,
+// shared by concurrent callers
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// TokenManager is struct for manage the access token lifecycle,
+// caching the token in memory and refreshing before expiry
+type TokenManager struct {
+	logger      interfaces.ILogger
+	auth        *AuthService
+	fileSystem  *pkg.FileSystem
+	ymlClient   *pkg.YmlClient
+	config      dto.RuntimeConfig
+	workDirPath string
+	skew        time.Duration
+	mu          sync.Mutex
+	token       string
+	expiresAt   time.Time
+	inflight    *tokenRefresh
+}
+
+// NewTokenManager return instance of token manager
+func NewTokenManager(logger interfaces.ILogger, config dto.RuntimeConfig) *TokenManager {
+	return &TokenManager{
+		logger: logger.Named("token_manager"),
+		config: config,
+		skew:   time.Minute * 5,
+	}
+}
+
+// Setup configure token manager
+func (t *TokenManager) Setup() error {
+	t.workDirPath = t.config.WorkDirPath
+	authService := NewAuthService(t.logger, t.config)
+	if err := authService.Setup(); err != nil {
+		return err
+	}
+	t.auth = authService
+	t.fileSystem = pkg.NewFileSystem()
+	t.ymlClient = utils.NewConfigYmlClient(t.config.WorkDirPath)
+	return nil
+}
+
+// getConfigAgent return config agent from config file
+func (t *TokenManager) getConfigAgent() (dto.ConfigAgent, error) {
+	var configAgent dto.ConfigAgent
+	content, err := t.fileSystem.GetFileContent(filepath.Join(t.workDirPath, "config.yml"))
+	if err != nil {
+		return configAgent, err
+	}
+	if err := t.ymlClient.Unmarshall(content, &configAgent); err != nil {
+		return configAgent, err
+	}
+	return configAgent, nil
+}
+
+// expired return if token expire inside skew,
+// tokens without exp claim never expire
+func (t *TokenManager) expired(now time.Time) bool {
+	return !t.expiresAt.IsZero() && !now.Add(t.skew).Before(t.expiresAt)
+}
+
+// SetToken execute set token in cache with expiry from claims
+func (t *TokenManager) SetToken(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setToken(token)
+}
+
+// setToken execute set token in cache, caller must hold lock
+func (t *TokenManager) setToken(token string) {
+	t.token = token
+	t.expiresAt = time.Time{}
+	if len(token) == 0 {
+		return
+	}
+	claims, err := utils.DecodeJwt(token)
+	if err != nil {
+		t.logger.Warn("failed decode access token", "trace", "docp-agent-os-instance.token_manager.setToken", "error", err.Error())
+		return
+	}
+	if claims.ExpiresAt > 0 {
+		t.expiresAt = time.Unix(claims.ExpiresAt, 0)
+	}
+}
+
+// GetToken return access token valid, refreshing when
+// expire inside skew, empty when agent not registered
+func (t *TokenManager) GetToken() (string, error) {
+	t.mu.Lock()
+	if len(t.token) == 0 {
+		configAgent, err := t.getConfigAgent()
+		if err != nil {
+			t.mu.Unlock()
+			return "", err
+		}
+		t.setToken(configAgent.AccessToken)
+	}
+	token := t.token
+	expired := t.expired(time.Now())
+	t.mu.Unlock()
+	if len(token) == 0 || !expired {
+		return token, nil
+	}
+	t.logger.Debug("access token near expiry", "trace", "docp-agent-os-instance.token_manager.GetToken")
+	return t.refresh()
+}
+
+// ForceRefresh execute refresh of access token ignoring cache
+func (t *TokenManager) ForceRefresh() (string, error) {
+	return t.refresh()
+}
+
+// refresh execute refresh of access token, concurrent
+// callers wait and share result of same auth call
+func (t *TokenManager) refresh() (string, error) {
+	t.mu.Lock()
+	if call := t.inflight; call != nil {
+		t.mu.Unlock()
+		<-call.done
+		return call.token, call.err
+	}
+	call := &tokenRefresh{done: make(chan struct{})}
+	t.inflight = call
+	t.mu.Unlock()
+
+	call.token, call.err = t.requestToken()
+
+	t.mu.Lock()
+	if call.err == nil {
+		t.setToken(call.token)
+	}
+	t.inflight = nil
+	t.mu.Unlock()
+	close(call.done)
+	return call.token, call.err
+}
+
+// requestToken execute auth call and save access token in config file
+func (t *TokenManager) requestToken() (string, error) {
+	t.logger.Debug("request access token", "trace", "docp-agent-os-instance.token_manager.requestToken")
+	configAgent, err := t.getConfigAgent()
+	if err != nil {
+		return "", err
+	}
+	authPayload := dto.AuthPayload{
+		ApiKey:    configAgent.Agent.ApiKey,
+		ComputeId: configAgent.ComputeId,
+	}
+	resp, statusCode, err := t.auth.AuthCall(authPayload)
+	if err != nil {
+		return "", err
+	}
+	if statusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: auth status code %d", pkg.ErrNotAuthorized, statusCode)
+	}
+	var authResponse dto.AuthResponse
+	if err := json.Unmarshal(resp, &authResponse); err != nil {
+		return "", err
+	}
+	unlock, err := t.fileSystem.LockFile(utils.GetConfigLockPath(t.workDirPath))
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	// config read again for not overwrite changes written during auth
+	configAgent, err = t.getConfigAgent()
+	if err != nil {
+		return "", err
+	}
+	configAgent.AccessToken = authResponse.AccessToken
+	if err := t.ymlClient.SaveSecrets(&configAgent); err != nil {
+		return "", err
+	}
+	configBytes, err := t.ymlClient.Marshall(&configAgent)
+	if err != nil {
+		return "", err
+	}
+	if err := t.fileSystem.WriteFileContent(filepath.Join(t.workDirPath, "config.yml"), configBytes); err != nil {
+		return "", err
+	}
+	return authResponse.AccessToken, nil
+}
@@ -10,27 +10,32 @@ import (
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 )
 
 // UtilityService provides utility functions for the application.
 type UtilityService struct {
-	client *http.Client
+	client *pkg.HttpClient
 	logger interfaces.ILogger
+	config dto.RuntimeConfig
 }
 
 // NewUtilityService creates a new instance of UtilityService.
-func NewUtilityService(logger interfaces.ILogger) *UtilityService {
+func NewUtilityService(logger interfaces.ILogger, config dto.RuntimeConfig) *UtilityService {
 	return &UtilityService{
-		logger: logger,
+		logger: logger.Named("utility_service"),
+		config: config,
 	}
 }
 
 // Setup configure the utility service.
 func (u *UtilityService) Setup() error {
-	client := &http.Client{
-		Timeout: time.Second * 90,
+	httpClient, err := utils.NewOutboundHttpClient(u.config, time.Second*90)
+	if err != nil {
+		return err
 	}
+	client := pkg.NewHttpClient(httpClient, pkg.DefaultRetryPolicy(), pkg.DefaultBreakerPolicy())
 	u.client = client
 
 	return nil
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// ExecutorAction is struct for action executed in queue of component
+type ExecutorAction struct {
+	Component string
+	Name      string
+	Run       func()
+}
+
+// actionQueue is struct for queue of one component
+type actionQueue struct {
+	pending   []ExecutorAction
+	current   string
+	startedAt time.Time
+	wake      chan struct{}
+}
+
+// ActionExecutor is struct for execute actions with one queue by
+// component, actions of same component run one at time in order
+// and actions of different components run in parallel
+type ActionExecutor struct {
+	ctx        context.Context
+	mu         sync.Mutex
+	queues     map[string]*actionQueue
+	chanErrors chan<- dto.ManagerChanErrors
+}
+
+// NewActionExecutor return instance of action executor, queues
+// stop when ctx is done and pending actions are discarded, panic
+// of action is sent to chanErrors
+func NewActionExecutor(ctx context.Context, chanErrors chan<- dto.ManagerChanErrors) *ActionExecutor {
+	return &ActionExecutor{
+		ctx:        ctx,
+		queues:     make(map[string]*actionQueue),
+		chanErrors: chanErrors,
+	}
+}
+
+// Submit execute enqueue of action in queue of component, return
+// name of pending actions removed because superseded by action
+func (e *ActionExecutor) Submit(action ExecutorAction) []string {
+	if e.ctx.Err() != nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	queue, ok := e.queues[action.Component]
+	if !ok {
+		queue = &actionQueue{wake: make(chan struct{}, 1)}
+		e.queues[action.Component] = queue
+		go e.worker(queue)
+	}
+	var coalesced []string
+	pending := queue.pending[:0]
+	for _, previous := range queue.pending {
+		if supersedes(action.Name, previous.Name) {
+			coalesced = append(coalesced, previous.Name)
+			continue
+		}
+		pending = append(pending, previous)
+	}
+	queue.pending = append(pending, action)
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+	}
+	return coalesced
+}
+
+// supersedes return if pending action has no effect after next action,
+// like install followed by uninstall
+func supersedes(next, pending string) bool {
+	switch next {
+	case pkg.ACTION_INSTALL:
+		return pending == pkg.ACTION_INSTALL || pending == pkg.ACTION_UNINSTALL
+	case pkg.ACTION_UNINSTALL:
+		return pending == pkg.ACTION_INSTALL || pending == pkg.ACTION_UNINSTALL || pending == pkg.ACTION_UPDATE || pending == pkg.ACTION_UPGRADE || pending == pkg.ACTION_INTEGRATIONS
+	default:
+		return next == pending
+	}
+}
+
+// worker execute actions of queue until ctx is done
+func (e *ActionExecutor) worker(queue *actionQueue) {
+	for {
+		select {
+		case <-queue.wake:
+		case <-e.ctx.Done():
+			return
+		}
+		for {
+			if e.ctx.Err() != nil {
+				return
+			}
+			e.mu.Lock()
+			if len(queue.pending) == 0 {
+				e.mu.Unlock()
+				break
+			}
+			action := queue.pending[0]
+			queue.pending = queue.pending[1:]
+			queue.current = action.Name
+			queue.startedAt = time.Now()
+			e.mu.Unlock()
+
+			e.run(action)
+
+			e.mu.Lock()
+			queue.current = ""
+			queue.startedAt = time.Time{}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// run execute action, panic of action is recovered so queue
+// continue with next actions
+func (e *ActionExecutor) run(action ExecutorAction) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		err := fmt.Errorf("%w: %s %s: %v", pkg.ErrActionPanic, action.Component, action.Name, recovered)
+		select {
+		case e.chanErrors <- dto.ManagerChanErrors{From: "actionExecutor", Priority: dto.ErrLevelHigh, Err: err}:
+		case <-e.ctx.Done():
+		}
+	}()
+	action.Run()
+}
+
+// Status return current action and pending actions of each queue
+func (e *ActionExecutor) Status() []dto.ControlQueue {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	queues := make([]dto.ControlQueue, 0, len(e.queues))
+	for component, queue := range e.queues {
+		pending := make([]string, 0, len(queue.pending))
+		for _, action := range queue.pending {
+			pending = append(pending, action.Name)
+		}
+		queues = append(queues, dto.ControlQueue{
+			Component: component,
+			Current:   queue.current,
+			StartedAt: queue.startedAt,
+			Pending:   pending,
+			Depth:     len(pending),
+		})
+	}
+	sort.Slice(queues, func(i, j int) bool {
+		return queues[i].Component < queues[j].Component
+	})
+	return queues
+}
@@ -6,13 +6,15 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 )
 
 // GetBinary return bytes the binary
-func GetBinary(urlBinary string) ([]byte, int, error) {
-	client := &http.Client{
-		Timeout: time.Second * 90,
+func GetBinary(config dto.RuntimeConfig, urlBinary string) ([]byte, int, error) {
+	client, err := NewOutboundHttpClient(config, time.Second*90)
+	if err != nil {
+		return nil, 0, err
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
 	defer cancel()
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"reflect"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// DiffConfig return changes between config applied and config loaded
+// again from config file, fields that only apply with restart of
+// process are returned in restart
+func DiffConfig(previous, current dto.ConfigAgent, previousRuntime, currentRuntime dto.RuntimeConfig) dto.ConfigChanges {
+	changes := dto.ConfigChanges{
+		Tags:       !reflect.DeepEqual(previous.Agent.Tags, current.Agent.Tags),
+		ApiKey:     previous.Agent.ApiKey != current.Agent.ApiKey,
+		LogLevels:  previous.Logging.Level != current.Logging.Level || !reflect.DeepEqual(previous.Logging.Levels, current.Logging.Levels),
+		Intervals:  previousRuntime.Intervals != currentRuntime.Intervals,
+		ErrorLevel: previousRuntime.ErrorLevel != currentRuntime.ErrorLevel,
+	}
+	for _, field := range []struct {
+		name    string
+		changed bool
+	}{
+		{"logging.output", previous.Logging.Output != current.Logging.Output},
+		{"logging.file", previous.Logging.File != current.Logging.File},
+		{"network", !reflect.DeepEqual(previous.Network, current.Network)},
+		{"runtime.domain", previousRuntime.Domain != currentRuntime.Domain},
+		{"runtime.workdir_path", previousRuntime.WorkDirPath != currentRuntime.WorkDirPath},
+		{"runtime.agent_port", previousRuntime.AgentPort != currentRuntime.AgentPort},
+		{"runtime.datadog_conf_path", previousRuntime.DatadogConfPath != currentRuntime.DatadogConfPath},
+	} {
+		if field.changed {
+			changes.Restart = append(changes.Restart, field.name)
+		}
+	}
+	return changes
+}
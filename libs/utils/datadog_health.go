@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// DatadogHealth return health of datadog agent from status of agent,
+// new errors of forwarder are counted from previous health, without
+// previous health errors before manager start are not new
+func DatadogHealth(status dto.DatadogAgentStatus, previous *dto.DatadogHealth) dto.DatadogHealth {
+	transactions := status.ForwarderStats.Transactions
+	health := dto.DatadogHealth{
+		Version: status.Version,
+		APIKey:  datadogAPIKeyStatus(status.ForwarderStats),
+		Forwarder: dto.DatadogForwarderHealth{
+			Success:    transactions.Success,
+			Errors:     transactions.Errors,
+			HTTPErrors: transactions.HTTPErrors,
+		},
+		Logs:      dto.DatadogLogsHealth{State: pkg.DATADOG_LOGS_AGENT_NOT_RUNNING, Errors: status.LogsStats.Errors},
+		CheckedAt: time.Now(),
+	}
+	for errorType, count := range transactions.ErrorsByType {
+		if count == 0 {
+			continue
+		}
+		if health.Forwarder.ErrorsByType == nil {
+			health.Forwarder.ErrorsByType = make(map[string]int64)
+		}
+		health.Forwarder.ErrorsByType[errorType] = count
+	}
+	if previous != nil {
+		health.Forwarder.NewErrors = transactions.Errors - previous.Forwarder.Errors
+		// counters are reset when agent restart
+		if health.Forwarder.NewErrors < 0 {
+			health.Forwarder.NewErrors = transactions.Errors
+		}
+	}
+	if status.LogsStats.IsRunning {
+		health.Logs.State = pkg.DATADOG_LOGS_AGENT_RUNNING
+	}
+	for _, integration := range DatadogIntegrationsStatus(status, nil) {
+		if integration.Status == pkg.DATADOG_INTEGRATION_STATUS_ERROR {
+			health.CheckErrors = append(health.CheckErrors, integration.Name)
+		}
+	}
+
+	health.Status = pkg.DATADOG_HEALTH_HEALTHY
+	if health.APIKey == pkg.DATADOG_API_KEY_INVALID {
+		health.Status = pkg.DATADOG_HEALTH_UNHEALTHY
+		health.Issues = append(health.Issues, "api key invalid")
+	}
+	// count of errors is not in issue so issues change only when state change
+	if health.Forwarder.NewErrors > 0 {
+		health.Issues = append(health.Issues, "forwarder transaction errors")
+	}
+	if len(health.CheckErrors) > 0 {
+		health.Issues = append(health.Issues, "check errors: "+strings.Join(health.CheckErrors, ", "))
+	}
+	if len(health.Logs.Errors) > 0 {
+		health.Issues = append(health.Issues, "logs agent errors: "+strings.Join(health.Logs.Errors, ", "))
+	}
+	if health.Status == pkg.DATADOG_HEALTH_HEALTHY && len(health.Issues) > 0 {
+		health.Status = pkg.DATADOG_HEALTH_DEGRADED
+	}
+	return health
+}
+
+// DatadogHealthUnavailable return health of datadog agent when status
+// of agent can not be read, agent is not answering
+func DatadogHealthUnavailable(err error) dto.DatadogHealth {
+	return dto.DatadogHealth{
+		Status:    pkg.DATADOG_HEALTH_UNHEALTHY,
+		APIKey:    pkg.DATADOG_API_KEY_UNKNOWN,
+		Logs:      dto.DatadogLogsHealth{State: pkg.DATADOG_LOGS_AGENT_NOT_RUNNING},
+		Issues:    []string{"status of agent unavailable: " + err.Error()},
+		CheckedAt: time.Now(),
+	}
+}
+
+// DatadogHealthChanged return if status or issues of health changed
+// from previous health
+func DatadogHealthChanged(previous *dto.DatadogHealth, health dto.DatadogHealth) bool {
+	if previous == nil {
+		return true
+	}
+	return previous.Status != health.Status || !slices.Equal(previous.Issues, health.Issues)
+}
+
+// datadogAPIKeyStatus return status of api keys of forwarder, any key
+// invalid is invalid
+func datadogAPIKeyStatus(stats dto.DatadogForwarderStats) string {
+	if len(stats.APIKeyFailure) > 0 {
+		return pkg.DATADOG_API_KEY_INVALID
+	}
+	status := pkg.DATADOG_API_KEY_UNKNOWN
+	for _, keyStatus := range stats.APIKeyStatus {
+		keyStatus = strings.ToLower(keyStatus)
+		switch {
+		case strings.Contains(keyStatus, "invalid"):
+			return pkg.DATADOG_API_KEY_INVALID
+		case strings.Contains(keyStatus, "valid"):
+			status = pkg.DATADOG_API_KEY_VALID
+		}
+	}
+	return status
+}
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"gopkg.in/yaml.v2"
+)
+
+// datadogIntegrationName is expression of name of integration, used as
+// name of dir in conf.d
+var datadogIntegrationName = regexp.MustCompile(`^[a-z0-9][a-z0-9_]*$`)
+
+// GetDatadogIntegrationsFilePath return path of file with integrations
+// applied by docp agent in work dir
+func GetDatadogIntegrationsFilePath(workDirPath string) string {
+	return filepath.Join(workDirPath, "state", "datadog", pkg.DATADOG_INTEGRATIONS_FILE_NAME)
+}
+
+// LoadDatadogIntegrations return integrations applied saved in file,
+// empty when file not exists
+func LoadDatadogIntegrations(filePath string) ([]dto.DatadogManagedIntegration, error) {
+	var integrations []dto.DatadogManagedIntegration
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return integrations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &integrations); err != nil {
+		return nil, err
+	}
+	return integrations, nil
+}
+
+// SaveDatadogIntegrations execute write of integrations applied in file
+func SaveDatadogIntegrations(filePath string, integrations []dto.DatadogManagedIntegration) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(integrations, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath+".tmp", content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(filePath+".tmp", filePath)
+}
+
+// ValidateDatadogIntegration return error when name of integration
+// can not be used as dir of conf.d or instances are missing
+func ValidateDatadogIntegration(integration dto.DatadogIntegration) error {
+	if !datadogIntegrationName.MatchString(integration.Name) {
+		return fmt.Errorf("%w: name %q", pkg.ErrIntegrationInvalid, integration.Name)
+	}
+	if len(integration.Instances) == 0 {
+		return fmt.Errorf("%w: %s without instances", pkg.ErrIntegrationInvalid, integration.Name)
+	}
+	return nil
+}
+
+// DatadogIntegrationPackage return package of third party integration,
+// like datadog-redis-sentinel for redis_sentinel
+func DatadogIntegrationPackage(name string) string {
+	return "datadog-" + strings.ReplaceAll(name, "_", "-")
+}
+
+// RenderDatadogIntegrationConfig return content of conf.yaml of
+// integration, keys are sorted so the same integration have same content
+func RenderDatadogIntegrationConfig(integration dto.DatadogIntegration) ([]byte, error) {
+	initConfig := integration.InitConfig
+	if initConfig == nil {
+		initConfig = map[string]interface{}{}
+	}
+	return yaml.Marshal(yaml.MapSlice{
+		{Key: "init_config", Value: initConfig},
+		{Key: "instances", Value: integration.Instances},
+	})
+}
+
+// ParseDatadogIntegrationFreeze return version of third party integrations
+// by name from output of integration freeze, like datadog-ping==1.0.2
+func ParseDatadogIntegrationFreeze(output string) map[string]string {
+	installed := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		packageName, version, ok := strings.Cut(strings.TrimSpace(line), "==")
+		if !ok || !strings.HasPrefix(packageName, "datadog-") {
+			continue
+		}
+		name := strings.ReplaceAll(strings.TrimPrefix(packageName, "datadog-"), "-", "_")
+		installed[name] = strings.TrimSpace(version)
+	}
+	return installed
+}
+
+// RemovedDatadogIntegrations return integrations applied that are not
+// in integrations of signal
+func RemovedDatadogIntegrations(integrations []dto.DatadogIntegration, managed []dto.DatadogManagedIntegration) []dto.DatadogManagedIntegration {
+	var removed []dto.DatadogManagedIntegration
+	for _, integration := range managed {
+		if !slices.ContainsFunc(integrations, func(desired dto.DatadogIntegration) bool {
+			return desired.Name == integration.Name
+		}) {
+			removed = append(removed, integration)
+		}
+	}
+	return removed
+}
+
+// ParseDatadogStatus return status of datadog agent from output of
+// status in json, lines before json are ignored
+func ParseDatadogStatus(output []byte) (dto.DatadogAgentStatus, error) {
+	var status dto.DatadogAgentStatus
+	start := bytes.IndexByte(output, '{')
+	if start < 0 {
+		return status, errors.New("status of datadog agent without json")
+	}
+	if err := json.Unmarshal(output[start:], &status); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+// DatadogIntegrationsStatus return status of integrations of datadog
+// agent by checks running, checks with errors of load or config and
+// integrations applied by docp agent, ordered by name
+func DatadogIntegrationsStatus(status dto.DatadogAgentStatus, managed []dto.DatadogManagedIntegration) []dto.DatadogIntegrationStatus {
+	integrations := make(map[string]*dto.DatadogIntegrationStatus)
+	get := func(name string) *dto.DatadogIntegrationStatus {
+		if integration, ok := integrations[name]; ok {
+			return integration
+		}
+		integration := &dto.DatadogIntegrationStatus{Name: name, Status: pkg.DATADOG_INTEGRATION_STATUS_NOT_RUNNING}
+		integrations[name] = integration
+		return integration
+	}
+	for name, instances := range status.RunnerStats.Checks {
+		integration := get(name)
+		integration.Status = pkg.DATADOG_INTEGRATION_STATUS_OK
+		for _, instance := range instances {
+			integration.Instances++
+			integration.Version = instance.CheckVersion
+			switch {
+			case len(instance.LastError) > 0:
+				integration.Status = pkg.DATADOG_INTEGRATION_STATUS_ERROR
+				integration.Error = datadogCheckErrorMessage(instance.LastError)
+			case len(instance.LastWarnings) > 0 && integration.Status == pkg.DATADOG_INTEGRATION_STATUS_OK:
+				integration.Status = pkg.DATADOG_INTEGRATION_STATUS_WARNING
+				integration.Error = instance.LastWarnings[0]
+			}
+		}
+	}
+	for name, loaders := range status.CheckSchedulerStats.LoaderErrors {
+		integration := get(name)
+		if integration.Instances > 0 {
+			continue
+		}
+		integration.Status = pkg.DATADOG_INTEGRATION_STATUS_ERROR
+		loaderNames := make([]string, 0, len(loaders))
+		for loader := range loaders {
+			loaderNames = append(loaderNames, loader)
+		}
+		sort.Strings(loaderNames)
+		if len(loaderNames) > 0 {
+			integration.Error = fmt.Sprintf("%s: %s", loaderNames[0], strings.TrimSpace(loaders[loaderNames[0]]))
+		}
+	}
+	for name, configError := range status.AutoConfigStats.ConfigErrors {
+		integration := get(name)
+		integration.Status = pkg.DATADOG_INTEGRATION_STATUS_ERROR
+		integration.Error = configError
+	}
+	for _, applied := range managed {
+		integration := get(applied.Name)
+		integration.Managed = true
+		if len(applied.Version) > 0 {
+			integration.Version = applied.Version
+		}
+	}
+	result := make([]dto.DatadogIntegrationStatus, 0, len(integrations))
+	for _, integration := range integrations {
+		result = append(result, *integration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// datadogCheckErrorMessage return message of last error of check, error
+// is list in json with message and traceback
+func datadogCheckErrorMessage(lastError string) string {
+	var errs []struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(lastError), &errs); err == nil && len(errs) > 0 {
+		return errs[0].Message
+	}
+	return lastError
+}
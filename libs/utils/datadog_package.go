@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"gopkg.in/yaml.v2"
+)
+
+// GetDatadogInstallConfig return config of install of datadog agent
+// from config file, installer empty is package and install script is
+// used only when configured, other installer return error
+func GetDatadogInstallConfig(configFilePath string) (dto.DatadogInstallConfig, error) {
+	var config dto.ConfigAgent
+	content, err := os.ReadFile(configFilePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return dto.DatadogInstallConfig{}, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(content, &config); err != nil {
+			return dto.DatadogInstallConfig{}, err
+		}
+	}
+	switch config.Datadog.Installer {
+	case "":
+		config.Datadog.Installer = pkg.DATADOG_INSTALLER_PACKAGE
+	case pkg.DATADOG_INSTALLER_PACKAGE, pkg.DATADOG_INSTALLER_SCRIPT:
+	default:
+		return dto.DatadogInstallConfig{}, fmt.Errorf("%w: %q", pkg.ErrDatadogInstallerInvalid, config.Datadog.Installer)
+	}
+	for _, keyUrl := range config.Datadog.GpgKeyUrls {
+		if err := ValidateDatadogKeyUrl(keyUrl); err != nil {
+			return dto.DatadogInstallConfig{}, err
+		}
+	}
+	if len(config.Datadog.LocalPackage) > 0 && len(config.Datadog.LocalPackageSha256) == 0 {
+		return dto.DatadogInstallConfig{}, fmt.Errorf("%w: local_package_sha256 required for %s", pkg.ErrPackageChecksum, config.Datadog.LocalPackage)
+	}
+	return config.Datadog, nil
+}
+
+// DatadogGpgKeyUrls return keys of repository of datadog, keys of
+// config replace keys of datadog
+func DatadogGpgKeyUrls(config dto.DatadogInstallConfig, rpm bool) []string {
+	if len(config.GpgKeyUrls) > 0 {
+		return config.GpgKeyUrls
+	}
+	if rpm {
+		return pkg.DatadogRpmGpgKeyUrls
+	}
+	return pkg.DatadogAptGpgKeyUrls
+}
+
+// ValidateDatadogKeyUrl return error when url of key is not https or
+// file, key by http can be replaced in the way
+func ValidateDatadogKeyUrl(keyUrl string) error {
+	if strings.HasPrefix(keyUrl, "https://") || strings.HasPrefix(keyUrl, "file://") {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", pkg.ErrKeyUrlInsecure, keyUrl)
+}
+
+// DatadogAptSource return content of apt source of datadog repository
+// signed by keyring of datadog keys
+func DatadogAptSource(config dto.DatadogInstallConfig) string {
+	repositoryUrl := config.RepositoryUrl
+	if len(repositoryUrl) == 0 {
+		repositoryUrl = pkg.DATADOG_APT_REPOSITORY_URL
+	}
+	return fmt.Sprintf("deb [signed-by=%s] %s stable 7\n", pkg.DATADOG_APT_KEYRING, repositoryUrl)
+}
+
+// DatadogYumRepo return content of yum repo of datadog repository for
+// arch like x86_64 or aarch64, packages and metadata are verified by keys
+func DatadogYumRepo(config dto.DatadogInstallConfig, arch string) string {
+	repositoryUrl := config.RepositoryUrl
+	if len(repositoryUrl) == 0 {
+		repositoryUrl = pkg.DATADOG_YUM_REPOSITORY_URL
+	}
+	var builder strings.Builder
+	builder.WriteString("[datadog]\n")
+	builder.WriteString("name=Datadog, Inc.\n")
+	builder.WriteString(fmt.Sprintf("baseurl=%s/%s/\n", strings.TrimSuffix(repositoryUrl, "/"), arch))
+	builder.WriteString("enabled=1\n")
+	builder.WriteString("gpgcheck=1\n")
+	builder.WriteString("repo_gpgcheck=1\n")
+	builder.WriteString("gpgkey=" + strings.Join(DatadogGpgKeyUrls(config, true), "\n       ") + "\n")
+	return builder.String()
+}
+
+// DatadogRpmArch return arch of rpm repository for arch of go
+func DatadogRpmArch(goarch string) string {
+	switch goarch {
+	case "arm64":
+		return "aarch64"
+	case "amd64":
+		return "x86_64"
+	}
+	return goarch
+}
+
+// DatadogPackageSpec return package of datadog agent for install by apt
+// or yum, version pinned like 7.52 install any patch of minor
+func DatadogPackageSpec(rpm bool, version string) string {
+	pin, ok := ParseDatadogVersionPin(version)
+	if !ok {
+		return pkg.DATADOG_PACKAGE_NAME
+	}
+	packageVersion := pin.String() + "-1"
+	if !pin.Exact {
+		packageVersion = pin.String() + ".*"
+	}
+	if rpm {
+		return fmt.Sprintf("%s-%s", pkg.DATADOG_PACKAGE_NAME, packageVersion)
+	}
+	return fmt.Sprintf("%s=1:%s", pkg.DATADOG_PACKAGE_NAME, packageVersion)
+}
+
+// MergeDatadogConfig return content of datadog.yaml with api key and
+// site, lines of keys are replaced keeping comments of file and keys
+// commented are enabled, changed false when content is the same
+func MergeDatadogConfig(content []byte, apiKey, site string) ([]byte, bool, error) {
+	merged := string(content)
+	for _, value := range [][2]string{{"api_key", apiKey}, {"site", site}} {
+		if len(value[1]) == 0 {
+			continue
+		}
+		merged = setDatadogConfigValue(merged, value[0], value[1])
+	}
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(merged), &parsed); err != nil {
+		return nil, false, err
+	}
+	return []byte(merged), merged != string(content), nil
+}
+
+// setDatadogConfigValue return content with top level key set to value,
+// key is appended when not found in content
+func setDatadogConfigValue(content, key, value string) string {
+	line := fmt.Sprintf("%s: %s", key, value)
+	for _, pattern := range []string{`(?m)^%s:.*$`, `(?m)^#\s?%s:.*$`} {
+		expression := regexp.MustCompile(fmt.Sprintf(pattern, regexp.QuoteMeta(key)))
+		if location := expression.FindStringIndex(content); location != nil {
+			return content[:location[0]] + line + content[location[1]:]
+		}
+	}
+	if len(content) > 0 && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + line + "\n"
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DatadogVersionPin is struct for version of datadog agent pinned
+// in signal, pin of minor like 7.52 accept any patch
+type DatadogVersionPin struct {
+	Major int
+	Minor int
+	Patch int
+	Exact bool
+}
+
+// ParseDatadogVersionPin return pin of version like 7.52 or 7.52.1,
+// latest or only major is not pinned
+func ParseDatadogVersionPin(version string) (DatadogVersionPin, bool) {
+	semVersion, parts, err := parsePartialVersion(version)
+	if err != nil || parts < 2 || len(semVersion.Prerelease) > 0 {
+		return DatadogVersionPin{}, false
+	}
+	return DatadogVersionPin{Major: semVersion.Major, Minor: semVersion.Minor, Patch: semVersion.Patch, Exact: parts == 3}, true
+}
+
+// String return version of pin
+func (p DatadogVersionPin) String() string {
+	if p.Exact {
+		return fmt.Sprintf("%d.%d.%d", p.Major, p.Minor, p.Patch)
+	}
+	return fmt.Sprintf("%d.%d", p.Major, p.Minor)
+}
+
+// Envs return envs of install script of datadog for version of pin
+func (p DatadogVersionPin) Envs() []string {
+	minor := fmt.Sprintf("%d", p.Minor)
+	if p.Exact {
+		minor = fmt.Sprintf("%d.%d", p.Minor, p.Patch)
+	}
+	return []string{
+		fmt.Sprintf("DD_AGENT_MAJOR_VERSION=%d", p.Major),
+		fmt.Sprintf("DD_AGENT_MINOR_VERSION=%s", minor),
+	}
+}
+
+// Compare return -1, 0 or 1 when version of package installed is
+// lower, matching or greater than pin, like 1:7.52.1-1 of dpkg
+// or 7.52.1-1 of rpm
+func (p DatadogVersionPin) Compare(installed string) (int, error) {
+	version := installed
+	if _, withoutEpoch, ok := strings.Cut(version, ":"); ok {
+		version = withoutEpoch
+	}
+	version, _, _ = strings.Cut(version, "-")
+	semVersion, err := ParseSemVersion(version)
+	if err != nil {
+		return 0, err
+	}
+	pinned := SemVersion{Major: p.Major, Minor: p.Minor, Patch: p.Patch}
+	if !p.Exact {
+		semVersion.Patch = 0
+	}
+	return semVersion.Compare(pinned), nil
+}
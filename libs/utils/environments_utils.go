@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
@@ -36,37 +37,42 @@ func GetCollectInterval() (time.Duration, error) {
 func GetConfigFilePath() (string, error) {
 	docp_config_file_path_env := os.Getenv("DOCP_CONFIG_FILE_PATH")
 	if len(docp_config_file_path_env) == 0 {
-		if runtime.GOOS == "windows" {
-			programFiles := os.Getenv("ProgramFiles")
-			return filepath.Join(programFiles, "DocpAgent", "config.yml"), nil
-		} else {
-
-			return filepath.Join(string(filepath.Separator), "opt", "docp-agent", "config.yml"), nil
-		}
+		return defaultConfigFilePath(), nil
 	}
 	return docp_config_file_path_env, nil
 }
 
-// GetLogFilePath return path the log file
-func GetLogFilePath() (string, error) {
-	if runtime.GOOS == "windows" {
-		programFiles := os.Getenv("ProgramFiles")
-		return filepath.Join(programFiles, "DocpAgent", "logs", "log.txt"), nil
-	} else {
-		return filepath.Join(string(filepath.Separator), "opt", "docp-agent", "logs", "log.txt"), nil
+// GetConfigLockPath return path of lock file taken by writers of
+// config file in all processes
+func GetConfigLockPath(workDirPath string) string {
+	return filepath.Join(workDirPath, pkg.CONFIG_LOCK_FILE_NAME)
+}
+
+// GetLogFilePath return path the log file of service in work dir
+func GetLogFilePath(workDirPath, service string) string {
+	return filepath.Join(workDirPath, "logs", service+".log")
+}
+
+// GetLogOutput return output of logs from env,
+// with default file and stdout where journald is not available
+func GetLogOutput(configOutput string) string {
+	if logOutput := os.Getenv("LOG_OUTPUT"); len(logOutput) > 0 {
+		return strings.ToLower(logOutput)
+	}
+	if len(configOutput) > 0 {
+		return strings.ToLower(configOutput)
 	}
+	if runtime.GOOS == "linux" {
+		return pkg.LOG_OUTPUT_STDOUT
+	}
+	return pkg.LOG_OUTPUT_BOTH
 }
 
 // GetWorkDirPath return path the work dir from env
 func GetWorkDirPath() (string, error) {
 	docp_workdir_path_env := os.Getenv("DOCP_WORKDIR_PATH")
 	if len(docp_workdir_path_env) == 0 {
-		if runtime.GOOS == "windows" {
-			programFiles := os.Getenv("ProgramFiles")
-			return filepath.Join(programFiles, "DocpAgent"), nil
-		} else {
-			return filepath.Join(string(filepath.Separator), "opt", "docp-agent"), nil
-		}
+		return defaultWorkDirPath(), nil
 	}
 	return docp_workdir_path_env, nil
 }
@@ -89,3 +95,23 @@ func GetPortAgentApi() (string, error) {
 	}
 	return docp_agent_port, nil
 }
+
+// GetSecretsDirPath return path the secrets dir in work dir
+func GetSecretsDirPath(workDirPath string) string {
+	return filepath.Join(workDirPath, "secrets")
+}
+
+// GetControlSocketPath return path the control socket of manager from env,
+// with default in run dir of work dir
+func GetControlSocketPath(workDirPath string) string {
+	docp_control_socket_env := os.Getenv("DOCP_CONTROL_SOCKET")
+	if len(docp_control_socket_env) != 0 {
+		return docp_control_socket_env
+	}
+	return filepath.Join(workDirPath, "run", pkg.CONTROL_SOCKET_FILE_NAME)
+}
+
+// GetReleasesDirPath return path of dir with releases of agent in work dir
+func GetReleasesDirPath(workDirPath string) string {
+	return filepath.Join(workDirPath, "bin", "releases")
+}
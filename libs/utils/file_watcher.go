@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileWatcher is struct for watch changes of file, with
+// fsnotify or polling of file when fsnotify not available
+type FileWatcher struct {
+	path         string
+	pollInterval time.Duration
+	debounce     time.Duration
+	chanClose    chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewFileWatcher return instance of file watcher
+func NewFileWatcher(path string, pollInterval, debounce time.Duration) *FileWatcher {
+	return &FileWatcher{
+		path:         filepath.Clean(path),
+		pollInterval: pollInterval,
+		debounce:     debounce,
+		chanClose:    make(chan struct{}),
+	}
+}
+
+// Close execute stop of watch
+func (w *FileWatcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.chanClose)
+	})
+}
+
+// WatchNotify execute call of onChange when file change, dir of file
+// is watched for receive the replace of file done by editors.
+// Return error when fsnotify not available
+func (w *FileWatcher) WatchNotify(onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		return err
+	}
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return errors.New("watcher of file closed")
+			}
+			if filepath.Clean(event.Name) != w.path || event.Op == fsnotify.Chmod {
+				continue
+			}
+			debounce = time.After(w.debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return errors.New("watcher of file closed")
+			}
+			return err
+		case <-debounce:
+			debounce = nil
+			onChange()
+		case <-w.chanClose:
+			return nil
+		}
+	}
+}
+
+// WatchPoll execute call of onChange when time of modification
+// or size of file change
+func (w *FileWatcher) WatchPoll(onChange func()) {
+	lastModTime, lastSize := w.stat()
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			modTime, size := w.stat()
+			if modTime.Equal(lastModTime) && size == lastSize {
+				continue
+			}
+			lastModTime, lastSize = modTime, size
+			onChange()
+		case <-w.chanClose:
+			return
+		}
+	}
+}
+
+// stat return time of modification and size of file,
+// zero values when file not exists
+func (w *FileWatcher) stat() (time.Time, int64) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
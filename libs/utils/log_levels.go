@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"gopkg.in/yaml.v2"
+)
+
+// LOG_LEVEL_DEFAULT_NAME is name used for default level of loggers
+const LOG_LEVEL_DEFAULT_NAME = "default"
+
+// LogLevels is struct for levels of loggers by name,
+// changeable at runtime
+type LogLevels struct {
+	mu           sync.RWMutex
+	defaultLevel slog.Level
+	levels       map[string]slog.Level
+	min          slog.Level
+}
+
+// logLevels is levels shared by all loggers of process
+var logLevels = NewLogLevels(getLogLevel())
+
+// NewLogLevels return instance of log levels with default level
+func NewLogLevels(defaultLevel slog.Level) *LogLevels {
+	return &LogLevels{
+		defaultLevel: defaultLevel,
+		levels:       make(map[string]slog.Level),
+		min:          defaultLevel,
+	}
+}
+
+// ParseLogLevel return level of slog from name
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level %s", level)
+}
+
+// updateMin execute update of lowest level, caller must hold lock
+func (l *LogLevels) updateMin() {
+	l.min = l.defaultLevel
+	for _, level := range l.levels {
+		if level < l.min {
+			l.min = level
+		}
+	}
+}
+
+// Level return level of logger by name
+func (l *LogLevels) Level(name string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.levels[name]; ok {
+		return level
+	}
+	return l.defaultLevel
+}
+
+// Enabled return if level is enabled for logger by name
+func (l *LogLevels) Enabled(name string, level slog.Level) bool {
+	return level >= l.Level(name)
+}
+
+// Min return lowest level of all loggers
+func (l *LogLevels) Min() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.min
+}
+
+// Set execute change of level for logger by name,
+// name default change level of loggers without own level
+func (l *LogLevels) Set(name string, level string) error {
+	parsed, err := ParseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(name) == 0 || name == LOG_LEVEL_DEFAULT_NAME {
+		l.defaultLevel = parsed
+	} else {
+		l.levels[name] = parsed
+	}
+	l.updateMin()
+	return nil
+}
+
+// Load execute replace of all levels
+func (l *LogLevels) Load(defaultLevel string, levels map[string]string) error {
+	parsedDefault := slog.LevelInfo
+	if len(defaultLevel) > 0 {
+		parsed, err := ParseLogLevel(defaultLevel)
+		if err != nil {
+			return err
+		}
+		parsedDefault = parsed
+	}
+	parsedLevels := make(map[string]slog.Level)
+	for name, level := range levels {
+		parsed, err := ParseLogLevel(level)
+		if err != nil {
+			return err
+		}
+		parsedLevels[name] = parsed
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.defaultLevel = parsedDefault
+	l.levels = parsedLevels
+	l.updateMin()
+	return nil
+}
+
+// Snapshot return levels by name, with default level
+func (l *LogLevels) Snapshot() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	snapshot := map[string]string{LOG_LEVEL_DEFAULT_NAME: strings.ToLower(l.defaultLevel.String())}
+	for name, level := range l.levels {
+		snapshot[name] = strings.ToLower(level.String())
+	}
+	return snapshot
+}
+
+// SetLogLevel execute change of level for logger by name
+func SetLogLevel(name string, level string) error {
+	return logLevels.Set(name, level)
+}
+
+// GetLogLevels return levels of loggers by name
+func GetLogLevels() map[string]string {
+	return logLevels.Snapshot()
+}
+
+// parseLogLevelsEnv return levels from env LOG_LEVELS
+// in format name=level separated by comma
+func parseLogLevelsEnv(value string) map[string]string {
+	levels := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		name, level, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if ok && len(name) > 0 {
+			levels[strings.TrimSpace(name)] = strings.TrimSpace(level)
+		}
+	}
+	return levels
+}
+
+// GetLoggingConfig return logging section of config file,
+// empty when config file not exists
+func GetLoggingConfig(configFilePath string) (dto.LoggingConfig, error) {
+	var config dto.ConfigAgent
+	content, err := os.ReadFile(configFilePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return config.Logging, nil
+		}
+		return config.Logging, err
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return config.Logging, err
+	}
+	return config.Logging, nil
+}
+
+// ReloadLogLevels execute load of levels from logging section of
+// config file, with envs LOG_LEVEL and LOG_LEVELS overriding the file
+func ReloadLogLevels(configFilePath string) error {
+	config, err := GetLoggingConfig(configFilePath)
+	if err != nil {
+		return err
+	}
+	defaultLevel := config.Level
+	if envLevel := os.Getenv("LOG_LEVEL"); len(envLevel) > 0 {
+		defaultLevel = envLevel
+	}
+	levels := make(map[string]string)
+	for name, level := range config.Levels {
+		levels[name] = level
+	}
+	for name, level := range parseLogLevelsEnv(os.Getenv("LOG_LEVELS")) {
+		levels[name] = level
+	}
+	return logLevels.Load(defaultLevel, levels)
+}
+
+// loggerNameFromTrace return name of logger and func from trace
+// in format docp-agent-os-instance.<name>.<func>
+func loggerNameFromTrace(trace string) (string, string) {
+	parts := strings.Split(trace, ".")
+	if len(parts) < 3 {
+		return "", trace
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// loggerNameFromArgs return name of logger from trace in args,
+// when logger not have own name
+func loggerNameFromArgs(name string, args ...any) string {
+	if len(name) > 0 {
+		return name
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		if key, ok := args[i].(string); ok && key == "trace" {
+			traceName, _ := loggerNameFromTrace(fmt.Sprint(args[i+1]))
+			return traceName
+		}
+	}
+	return ""
+}
+
+// LevelHandler is handler of slog that filter records by level
+// of logger name and turn trace in logger and func attributes
+type LevelHandler struct {
+	next slog.Handler
+	name string
+}
+
+// NewLevelHandler return instance of level handler
+func NewLevelHandler(next slog.Handler, name string) *LevelHandler {
+	return &LevelHandler{next: next, name: name}
+}
+
+// Enabled return if level is enabled for any logger
+func (h *LevelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= logLevels.Min()
+}
+
+// Handle execute filter of record by level of logger and send to next handler
+func (h *LevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	name := h.name
+	var funcName string
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == "trace" {
+			traceName, traceFunc := loggerNameFromTrace(attr.Value.String())
+			if len(name) == 0 {
+				name = traceName
+			}
+			funcName = traceFunc
+			return true
+		}
+		attrs = append(attrs, attr)
+		return true
+	})
+	if !logLevels.Enabled(name, record.Level) {
+		return nil
+	}
+	named := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	if len(name) > 0 {
+		named.AddAttrs(slog.String("logger", name))
+	}
+	if len(funcName) > 0 {
+		named.AddAttrs(slog.String("func", funcName))
+	}
+	named.AddAttrs(attrs...)
+	return h.next.Handle(ctx, named)
+}
+
+// WithAttrs return handler with attributes
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewLevelHandler(h.next.WithAttrs(attrs), h.name)
+}
+
+// WithGroup return handler with group
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return NewLevelHandler(h.next.WithGroup(name), h.name)
+}
+
+// WithName return handler for logger with name
+func (h *LevelHandler) WithName(name string) *LevelHandler {
+	return NewLevelHandler(h.next, name)
+}
@@ -6,9 +6,13 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -20,7 +24,9 @@ const (
 
 // DocpLogger is struct for logger the docp
 type DocpLogger struct {
-	logger *slog.Logger
+	logger  *slog.Logger
+	handler *LevelHandler
+	closer  io.Closer
 }
 
 func getLogLevel() slog.Level {
@@ -41,20 +47,94 @@ func getLogLevel() slog.Level {
 // NewDocpLoggerJSON return instance of docp logger
 // with formatter json
 func NewDocpLoggerJSON(writter io.Writer) *DocpLogger {
-	return &DocpLogger{
-		logger: slog.New(slog.NewJSONHandler(writter, &slog.HandlerOptions{
-			Level: getLogLevel(),
-		})),
-	}
+	return newDocpLogger(slog.NewJSONHandler(writter, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
 }
 
 // NewDocpLogger return instance of docp logger
 // with formatter text
 func NewDocpLoggerText(writter io.Writer) *DocpLogger {
+	return newDocpLogger(slog.NewTextHandler(writter, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+}
+
+// NewDocpLoggerService return instance of docp logger json for service,
+// writing in stdout, in rotated file or both as logging section of config
+func NewDocpLoggerService(service string, runtimeConfig dto.RuntimeConfig) (*DocpLogger, error) {
+	config, err := GetLoggingConfig(runtimeConfig.ConfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	output := GetLogOutput(config.Output)
+	if output == pkg.LOG_OUTPUT_STDOUT {
+		return NewDocpLoggerJSON(os.Stdout), nil
+	}
+	if output != pkg.LOG_OUTPUT_FILE && output != pkg.LOG_OUTPUT_BOTH {
+		return nil, fmt.Errorf("invalid log output %s", output)
+	}
+	logPath := GetServiceLogFilePath(service, runtimeConfig.WorkDirPath, config.File)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, err
+	}
+	logFile := newLogFile(logPath, config.File)
+	var writter io.Writer = logFile
+	if output == pkg.LOG_OUTPUT_BOTH {
+		writter = io.MultiWriter(logFile, os.Stdout)
+	}
+	logger := NewDocpLoggerJSON(writter)
+	logger.closer = logFile
+	return logger, nil
+}
+
+// GetServiceLogFilePath return path of log file of service,
+// in dir of config when informed, else in work dir
+func GetServiceLogFilePath(service, workDirPath string, config dto.LogFileConfig) string {
+	if len(config.Dir) > 0 {
+		return filepath.Join(config.Dir, service+".log")
+	}
+	return GetLogFilePath(workDirPath, service)
+}
+
+// newLogFile return rotated log file, with defaults for
+// values not informed in config
+func newLogFile(logPath string, config dto.LogFileConfig) *lumberjack.Logger {
+	logFile := &lumberjack.Logger{
+		Filename:   logPath,
+		MaxSize:    config.MaxSizeMB,
+		MaxAge:     config.MaxAgeDays,
+		MaxBackups: config.MaxBackups,
+		Compress:   config.Compress,
+	}
+	if logFile.MaxSize <= 0 {
+		logFile.MaxSize = pkg.LOG_FILE_DEFAULT_MAX_SIZE
+	}
+	if logFile.MaxAge <= 0 {
+		logFile.MaxAge = pkg.LOG_FILE_DEFAULT_MAX_AGE
+	}
+	if logFile.MaxBackups <= 0 {
+		logFile.MaxBackups = pkg.LOG_FILE_DEFAULT_MAX_BACKUP
+	}
+	return logFile
+}
+
+// newDocpLogger return instance of docp logger with levels by
+// logger name, base handler must accept all levels
+func newDocpLogger(handler slog.Handler) *DocpLogger {
+	levelHandler := NewLevelHandler(newLogHandler(handler), "")
+	return &DocpLogger{
+		logger:  slog.New(levelHandler),
+		handler: levelHandler,
+	}
+}
+
+// Named return child logger with name, used for level by logger
+func (d *DocpLogger) Named(name string) interfaces.ILogger {
+	handler := d.handler.WithName(name)
 	return &DocpLogger{
-		logger: slog.New(slog.NewTextHandler(writter, &slog.HandlerOptions{
-			Level: getLogLevel(),
-		})),
+		logger:  slog.New(handler),
+		handler: handler,
 	}
 }
 
@@ -78,30 +158,20 @@ func (d *DocpLogger) Error(msg string, args ...any) {
 	d.logger.Error(msg, args...)
 }
 
-// Close close logger
+// Close close logger and log file when exists
 func (d *DocpLogger) Close() error {
+	if d.closer != nil {
+		return d.closer.Close()
+	}
 	return nil
 }
 
 // DocpLoggerWindows is struct for logger the docp on windows
 type DocpLoggerWindows struct {
-	logger *log.Logger
-	level  int
-	mutex  sync.Mutex
-}
-
-func getLogLevelWindows() int {
-	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
-
-	case "debug":
-		return DEBUG
-	case "info":
-		return INFO
-	case "warn":
-		return WARN
-	default:
-		return INFO
-	}
+	logger   *log.Logger
+	name     string
+	mutex    *sync.Mutex
+	redactor *Redactor
 }
 
 // NewDocpLoggerFileText return instance of docp logger redirect for file
@@ -118,12 +188,37 @@ func NewDocpLoggerWindowsFileText(logPath string) *DocpLoggerWindows {
 	multiWriter := io.MultiWriter(logFile, os.Stdout)
 	logger := log.New(multiWriter, "", log.LstdFlags)
 	return &DocpLoggerWindows{
-		logger: logger,
-		level:  getLogLevelWindows(),
+		logger:   logger,
+		mutex:    &sync.Mutex{},
+		redactor: NewRedactorFromEnv(),
 	}
 }
 
+// Named return child logger with name, used for level by logger
+func (d *DocpLoggerWindows) Named(name string) interfaces.ILogger {
+	return &DocpLoggerWindows{
+		logger:   d.logger,
+		name:     name,
+		mutex:    d.mutex,
+		redactor: d.redactor,
+	}
+}
+
+// print execute logging when level is enabled for logger
+func (d *DocpLoggerWindows) print(level slog.Level, msg string, args ...any) {
+	if !logLevels.Enabled(loggerNameFromArgs(d.name, args...), level) {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.logger.Print(d.formatArgs(msg, args...))
+}
+
 func (d *DocpLoggerWindows) formatArgs(msg string, args ...any) string {
+	if d.redactor != nil {
+		msg = d.redactor.RedactString(msg)
+		args = d.redactor.RedactArgs(args...)
+	}
 	format := msg
 	for range args {
 		format += ", %v"
@@ -134,36 +229,22 @@ func (d *DocpLoggerWindows) formatArgs(msg string, args ...any) string {
 
 // Debug execute logging the debug
 func (d *DocpLoggerWindows) Debug(msg string, args ...any) {
-	if d.level == DEBUG {
-		d.mutex.Lock()
-		defer d.mutex.Unlock()
-		d.logger.Printf(d.formatArgs(msg, args...))
-	}
+	d.print(slog.LevelDebug, msg, args...)
 }
 
 // Info execute logging the info
 func (d *DocpLoggerWindows) Info(msg string, args ...any) {
-	if d.level == INFO {
-		d.mutex.Lock()
-		defer d.mutex.Unlock()
-		d.logger.Printf(d.formatArgs(msg, args...))
-	}
+	d.print(slog.LevelInfo, msg, args...)
 }
 
 // Warn execute logging the warning
 func (d *DocpLoggerWindows) Warn(msg string, args ...any) {
-	if d.level == WARN {
-		d.mutex.Lock()
-		defer d.mutex.Unlock()
-		d.logger.Printf(d.formatArgs(msg, args...))
-	}
+	d.print(slog.LevelWarn, msg, args...)
 }
 
 // Error execut logging the error
 func (d *DocpLoggerWindows) Error(msg string, args ...any) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-	d.logger.Printf(d.formatArgs(msg, args...))
+	d.print(slog.LevelError, msg, args...)
 }
 
 // Close close logger
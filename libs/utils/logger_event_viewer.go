@@ -5,6 +5,7 @@ package utils
 import (
 	"fmt"
 
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 	"golang.org/x/sys/windows/svc/eventlog"
 )
 
@@ -81,6 +82,11 @@ func (d *DocpLoggerEventViewer) Error(msg string, args ...any) {
 	elog.Error(3, formattedArgs)
 }
 
+// Named return same logger, event viewer not have levels by logger
+func (d *DocpLoggerEventViewer) Named(name string) interfaces.ILogger {
+	return d
+}
+
 // Close close viewer event
 func (d *DocpLoggerEventViewer) Close() error {
 	return nil
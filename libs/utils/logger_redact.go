@@ -0,0 +1,329 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// REDACTED_VALUE is value written in place of secrets
+const REDACTED_VALUE = "***"
+
+// defaultRedactKeys is keys redacted by default, compared
+// in lower case and without separators
+var defaultRedactKeys = []string{
+	"apikey",
+	"appkey",
+	"ddapikey",
+	"ddappkey",
+	"accesstoken",
+	"token",
+	"password",
+	"proxypassword",
+	"secret",
+	"authorization",
+	"clientkey",
+}
+
+// redactPatterns is patterns of known token formats redacted in any
+// key, hex values like hashes are kept, keys of datadog are redacted
+// by name of key
+var redactPatterns = []*regexp.Regexp{
+	// json web token
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	// authorization header
+	regexp.MustCompile(`(?i)(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`),
+	// github and aws access keys
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+}
+
+// redactKeyValuePattern is pattern of secrets inside
+// json, yaml and env content
+var redactKeyValuePattern = regexp.MustCompile(`(?i)("?[\w-]*(?:api[-_]?key|app[-_]?key|token|password|secret)"?\s*[:=]\s*"?)([^"\s,}]+)`)
+
+// redactUrlPasswordPattern is pattern of password in url, like
+// proxy url with user and password
+var redactUrlPasswordPattern = regexp.MustCompile(`(://[^:/\s@]+:)([^@\s/]+)(@)`)
+
+// Redactor is struct for mask secrets in log attributes
+type Redactor struct {
+	denyKeys  map[string]struct{}
+	allowKeys map[string]struct{}
+}
+
+// normalizeRedactKey return key in lower case and without separators
+func normalizeRedactKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer("-", "", "_", "", ".", "", " ", "").Replace(key)
+}
+
+// splitRedactKeys return keys normalized from list separated by comma
+func splitRedactKeys(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = normalizeRedactKey(key); len(key) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// NewRedactor return instance of redactor with default keys,
+// extra deny keys and allow keys that are never redacted
+func NewRedactor(denyKeys, allowKeys []string) *Redactor {
+	r := &Redactor{
+		denyKeys:  make(map[string]struct{}),
+		allowKeys: make(map[string]struct{}),
+	}
+	for _, key := range defaultRedactKeys {
+		r.denyKeys[key] = struct{}{}
+	}
+	for _, key := range denyKeys {
+		r.denyKeys[normalizeRedactKey(key)] = struct{}{}
+	}
+	for _, key := range allowKeys {
+		r.allowKeys[normalizeRedactKey(key)] = struct{}{}
+	}
+	return r
+}
+
+// NewRedactorFromEnv return redactor configured by envs LOG_REDACT_DENY
+// and LOG_REDACT_ALLOW, nil when disabled by LOG_REDACT=false
+func NewRedactorFromEnv() *Redactor {
+	if strings.EqualFold(os.Getenv("LOG_REDACT"), "false") {
+		return nil
+	}
+	return NewConfiguredRedactor()
+}
+
+// NewConfiguredRedactor return redactor with keys of envs LOG_REDACT_DENY
+// and LOG_REDACT_ALLOW, never disabled, for content sent out of host
+func NewConfiguredRedactor() *Redactor {
+	return NewRedactor(splitRedactKeys(os.Getenv("LOG_REDACT_DENY")), splitRedactKeys(os.Getenv("LOG_REDACT_ALLOW")))
+}
+
+// IsSensitiveKey return if value of key must be redacted
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	key = normalizeRedactKey(key)
+	if _, ok := r.allowKeys[key]; ok {
+		return false
+	}
+	if _, ok := r.denyKeys[key]; ok {
+		return true
+	}
+	for deny := range r.denyKeys {
+		// keys like ddApiKey or agent.api_key
+		if len(deny) > len("token") && strings.HasSuffix(key, deny) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString return text with secrets masked
+func (r *Redactor) RedactString(value string) string {
+	value = redactKeyValuePattern.ReplaceAllString(value, "${1}"+REDACTED_VALUE)
+	value = redactUrlPasswordPattern.ReplaceAllString(value, "${1}"+REDACTED_VALUE+"${3}")
+	for _, pattern := range redactPatterns {
+		value = pattern.ReplaceAllString(value, REDACTED_VALUE)
+	}
+	return value
+}
+
+// redactText return text with secrets masked, text with json object
+// or array is redacted by name of keys like structs
+func (r *Redactor) redactText(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if redacted, err := r.RedactJSON([]byte(trimmed)); err == nil {
+			return r.RedactString(string(redacted))
+		}
+	}
+	return r.RedactString(value)
+}
+
+// RedactValue return value with secrets masked, structs
+// and maps are converted in json before redaction
+func (r *Redactor) RedactValue(value slog.Value) slog.Value {
+	value = value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.StringValue(r.redactText(value.String()))
+	case slog.KindGroup:
+		attrs := value.Group()
+		redacted := make([]slog.Attr, 0, len(attrs))
+		for _, attr := range attrs {
+			redacted = append(redacted, r.RedactAttr(attr))
+		}
+		return slog.GroupValue(redacted...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case nil:
+			return value
+		case error:
+			return slog.StringValue(r.RedactString(v.Error()))
+		case []byte:
+			return slog.StringValue(r.redactText(string(v)))
+		case fmt.Stringer:
+			return slog.StringValue(r.RedactString(v.String()))
+		default:
+			content, err := json.Marshal(v)
+			if err != nil {
+				return slog.StringValue(r.RedactString(fmt.Sprintf("%v", v)))
+			}
+			// keys of structs and maps are redacted by name
+			if redacted, err := r.RedactJSON(content); err == nil {
+				return slog.AnyValue(json.RawMessage(redacted))
+			}
+			return slog.AnyValue(json.RawMessage(r.RedactString(string(content))))
+		}
+	}
+	return value
+}
+
+// RedactAttr return attribute with secrets masked
+func (r *Redactor) RedactAttr(attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() != slog.KindGroup && r.IsSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, REDACTED_VALUE)
+	}
+	return slog.Attr{Key: attr.Key, Value: r.RedactValue(attr.Value)}
+}
+
+// RedactArgs return args in pairs of key and value with secrets masked
+func (r *Redactor) RedactArgs(args ...any) []any {
+	redacted := make([]any, len(args))
+	for i := 0; i < len(args); i++ {
+		key, isKey := args[i].(string)
+		if isKey && i+1 < len(args) && r.IsSensitiveKey(key) {
+			redacted[i] = key
+			redacted[i+1] = REDACTED_VALUE
+			i++
+			continue
+		}
+		value := r.RedactValue(slog.AnyValue(args[i]))
+		if value.Kind() == slog.KindAny {
+			if raw, ok := value.Any().(json.RawMessage); ok {
+				redacted[i] = string(raw)
+				continue
+			}
+		}
+		redacted[i] = value.Any()
+	}
+	return redacted
+}
+
+// RedactHandler is struct for slog handler that mask
+// secrets before write the record
+type RedactHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+// NewRedactHandler return instance of redact handler
+func NewRedactHandler(next slog.Handler, redactor *Redactor) *RedactHandler {
+	return &RedactHandler{
+		next:     next,
+		redactor: redactor,
+	}
+}
+
+// Enabled return if level is enabled in next handler
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle execute redaction of record and send to next handler
+func (h *RedactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.RedactString(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.RedactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs return handler with attributes redacted
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		redacted = append(redacted, h.redactor.RedactAttr(attr))
+	}
+	return NewRedactHandler(h.next.WithAttrs(redacted), h.redactor)
+}
+
+// WithGroup return handler with group
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return NewRedactHandler(h.next.WithGroup(name), h.redactor)
+}
+
+// newLogHandler return handler wrapped by redaction when enabled
+func newLogHandler(handler slog.Handler) slog.Handler {
+	redactor := NewRedactorFromEnv()
+	if redactor == nil {
+		return handler
+	}
+	return NewRedactHandler(handler, redactor)
+}
+
+// RedactTree return value decoded from json or yaml
+// with values of sensitive keys and secrets masked
+func (r *Redactor) RedactTree(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		// envs like {"name": "DD_API_KEY", "value": "..."}
+		sensitiveEntry := r.isSensitiveEntry(v["name"])
+		for key, item := range v {
+			if r.IsSensitiveKey(key) || (sensitiveEntry && key == "value") {
+				redacted[key] = REDACTED_VALUE
+				continue
+			}
+			redacted[key] = r.RedactTree(item)
+		}
+		return redacted
+	case map[any]any:
+		redacted := make(map[any]any, len(v))
+		sensitiveEntry := r.isSensitiveEntry(v["name"])
+		for key, item := range v {
+			if r.IsSensitiveKey(fmt.Sprintf("%v", key)) || (sensitiveEntry && key == "value") {
+				redacted[key] = REDACTED_VALUE
+				continue
+			}
+			redacted[key] = r.RedactTree(item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = r.RedactTree(item)
+		}
+		return redacted
+	case string:
+		return r.RedactString(v)
+	}
+	return value
+}
+
+// isSensitiveEntry return if name of entry of name and value is
+// sensitive key
+func (r *Redactor) isSensitiveEntry(name any) bool {
+	key, ok := name.(string)
+	return ok && r.IsSensitiveKey(key)
+}
+
+// RedactJSON return json content with secrets masked
+func (r *Redactor) RedactJSON(content []byte) ([]byte, error) {
+	if len(content) == 0 {
+		return content, nil
+	}
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r.RedactTree(value))
+}
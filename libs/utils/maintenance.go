@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// maxMaintenanceSearch is max time searched for start of next window
+const maxMaintenanceSearch = time.Hour * 24 * 366
+
+// weekdayNames is names accepted for weekdays of windows
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// maintenanceWindow is interface for window parsed from config
+type maintenanceWindow interface {
+	active(now time.Time) bool
+	next(now time.Time) (time.Time, bool)
+}
+
+// MaintenanceSchedule is struct for maintenance windows in timezone
+type MaintenanceSchedule struct {
+	location *time.Location
+	windows  []maintenanceWindow
+}
+
+// NewMaintenanceSchedule return instance of schedule from config,
+// return error with all windows invalid
+func NewMaintenanceSchedule(config dto.MaintenanceConfig) (*MaintenanceSchedule, error) {
+	location := time.Local
+	if len(config.Timezone) > 0 {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: timezone %s", pkg.ErrMaintenanceInvalid, config.Timezone)
+		}
+		location = loc
+	}
+	schedule := &MaintenanceSchedule{location: location}
+	var errs []string
+	for i, config := range config.Windows {
+		window, err := parseMaintenanceWindow(config)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("windows[%d]: %s", i, err.Error()))
+			continue
+		}
+		schedule.windows = append(schedule.windows, window)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s", pkg.ErrMaintenanceInvalid, strings.Join(errs, "; "))
+	}
+	return schedule, nil
+}
+
+// Active return if now is inside of any window, schedule
+// without windows is always active
+func (m *MaintenanceSchedule) Active(now time.Time) bool {
+	if len(m.windows) == 0 {
+		return true
+	}
+	now = now.In(m.location)
+	for _, window := range m.windows {
+		if window.active(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next return start of next window after now
+func (m *MaintenanceSchedule) Next(now time.Time) (time.Time, bool) {
+	now = now.In(m.location)
+	var next time.Time
+	for _, window := range m.windows {
+		start, ok := window.next(now)
+		if ok && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// IsDisruptiveAction return if action is deferred outside of
+// maintenance window, actions that install, remove or restart agents
+func IsDisruptiveAction(action string) bool {
+	switch action {
+	case pkg.ACTION_INSTALL, pkg.ACTION_UNINSTALL, pkg.ACTION_UPGRADE,
+		pkg.ACTION_UPDATE, pkg.ACTION_ROLLBACK, pkg.ACTION_INTEGRATIONS:
+		return true
+	}
+	return false
+}
+
+// parseMaintenanceWindow return window by cron or by weekdays
+func parseMaintenanceWindow(config dto.MaintenanceWindow) (maintenanceWindow, error) {
+	if len(config.Cron) > 0 {
+		return parseCronWindow(config.Cron, config.Duration)
+	}
+	return parseWeekdayWindow(config.Weekdays, config.Start, config.End)
+}
+
+// cronWindow is struct for window starting when cron expression
+// match and open during duration
+type cronWindow struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	anyDay, anyWeekday                     bool
+	duration                               time.Duration
+}
+
+// parseCronWindow return window from cron expression with five fields,
+// minute hour day month weekday, and duration
+func parseCronWindow(expression, duration string) (*cronWindow, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q must have 5 fields", expression)
+	}
+	window := &cronWindow{
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+	var err error
+	for _, field := range []struct {
+		target   *map[int]bool
+		value    string
+		min, max int
+	}{
+		{&window.minutes, fields[0], 0, 59},
+		{&window.hours, fields[1], 0, 23},
+		{&window.days, fields[2], 1, 31},
+		{&window.months, fields[3], 1, 12},
+		{&window.weekdays, fields[4], 0, 7},
+	} {
+		if *field.target, err = parseCronField(field.value, field.min, field.max); err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expression, err)
+		}
+	}
+	// 7 is sunday like 0
+	if window.weekdays[7] {
+		window.weekdays[0] = true
+	}
+	window.duration, err = time.ParseDuration(duration)
+	if err != nil || window.duration < time.Minute {
+		return nil, fmt.Errorf("duration %q must be at least 1m", duration)
+	}
+	return window, nil
+}
+
+// parseCronField return values of field with lists, ranges and steps
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if base, value, ok := strings.Cut(part, "/"); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			part, step = base, parsed
+		}
+		start, end := min, max
+		if part != "*" {
+			first, last, isRange := strings.Cut(part, "-")
+			var err error
+			if start, err = strconv.Atoi(first); err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(last); err != nil {
+					return nil, fmt.Errorf("invalid range %q", part)
+				}
+			}
+		}
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// match return if cron match minute of time
+func (c *cronWindow) match(t time.Time) bool {
+	if !c.minutes[t.Minute()] || !c.hours[t.Hour()] || !c.months[int(t.Month())] {
+		return false
+	}
+	day, weekday := c.days[t.Day()], c.weekdays[int(t.Weekday())]
+	// like cron, day and weekday restricted match any of both
+	if !c.anyDay && !c.anyWeekday {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// active return if window started inside of duration before now
+func (c *cronWindow) active(now time.Time) bool {
+	minute := now.Truncate(time.Minute)
+	for start := minute; now.Sub(start) < c.duration; start = start.Add(-time.Minute) {
+		if c.match(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// next return first minute matching cron after now
+func (c *cronWindow) next(now time.Time) (time.Time, bool) {
+	limit := now.Add(maxMaintenanceSearch)
+	for start := now.Truncate(time.Minute).Add(time.Minute); start.Before(limit); start = start.Add(time.Minute) {
+		if c.match(start) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// weekdayWindow is struct for window between start and end time on
+// weekdays, end before start finish in next day
+type weekdayWindow struct {
+	weekdays   map[time.Weekday]bool
+	start, end time.Duration
+}
+
+// parseWeekdayWindow return window from weekdays and times in format
+// 15:04, weekdays empty is every day
+func parseWeekdayWindow(weekdays []string, start, end string) (*weekdayWindow, error) {
+	window := &weekdayWindow{weekdays: make(map[time.Weekday]bool)}
+	for _, name := range weekdays {
+		weekday, ok := weekdayNames[strings.ToLower(name)[:min(3, len(name))]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", name)
+		}
+		window.weekdays[weekday] = true
+	}
+	if len(window.weekdays) == 0 {
+		for _, weekday := range weekdayNames {
+			window.weekdays[weekday] = true
+		}
+	}
+	var err error
+	if window.start, err = parseClock(start); err != nil {
+		return nil, err
+	}
+	if window.end, err = parseClock(end); err != nil {
+		return nil, err
+	}
+	if window.end <= window.start {
+		window.end += time.Hour * 24
+	}
+	return window, nil
+}
+
+// parseClock return time of day in format 15:04 as duration
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// startOfDay return window start for day of time
+func (w *weekdayWindow) startOfDay(t time.Time, offsetDays int) time.Time {
+	year, month, day := t.Date()
+	midnight := time.Date(year, month, day+offsetDays, 0, 0, 0, 0, t.Location())
+	return midnight.Add(w.start)
+}
+
+// active return if now is inside of window started today or yesterday
+func (w *weekdayWindow) active(now time.Time) bool {
+	for _, offset := range []int{0, -1} {
+		start := w.startOfDay(now, offset)
+		end := start.Add(w.end - w.start)
+		if w.weekdays[start.Weekday()] && !now.Before(start) && now.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// next return start of window in next days
+func (w *weekdayWindow) next(now time.Time) (time.Time, bool) {
+	for offset := 0; offset <= 7; offset++ {
+		start := w.startOfDay(now, offset)
+		if w.weekdays[start.Weekday()] && start.After(now) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// GetNetworkConfig return network config from config file,
+// with envs overriding the values of file
+func GetNetworkConfig(runtimeConfig dto.RuntimeConfig) (dto.NetworkConfig, error) {
+	var config dto.ConfigAgent
+	content, err := os.ReadFile(runtimeConfig.ConfigFilePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return dto.NetworkConfig{}, err
+	}
+	if err == nil {
+		// proxy password is kept in secret store
+		ymlClient := NewConfigYmlClient(runtimeConfig.WorkDirPath)
+		if err := ymlClient.Unmarshall(content, &config); err != nil {
+			return dto.NetworkConfig{}, err
+		}
+	}
+	network := config.Network
+	envs := map[string]*string{
+		"DOCP_PROXY_URL":      &network.ProxyUrl,
+		"DOCP_PROXY_USER":     &network.ProxyUser,
+		"DOCP_PROXY_PASSWORD": &network.ProxyPassword,
+		"DOCP_NO_PROXY":       &network.NoProxy,
+		"DOCP_CA_BUNDLE":      &network.CABundle,
+		"DOCP_CLIENT_CERT":    &network.ClientCert,
+		"DOCP_CLIENT_KEY":     &network.ClientKey,
+	}
+	for env, value := range envs {
+		if envValue := os.Getenv(env); len(envValue) > 0 {
+			*value = envValue
+		}
+	}
+	return network, nil
+}
+
+// NewOutboundHttpClient return http client for outbound connections
+// with proxy, ca bundle and mtls for docp domain
+func NewOutboundHttpClient(config dto.RuntimeConfig, timeout time.Duration) (*http.Client, error) {
+	network, err := GetNetworkConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := url.Parse(config.Domain)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := pkg.NewTransport(network, domain.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}, nil
+}
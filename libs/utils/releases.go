@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// ReleaseManifest is struct for releases of agent in releases dir,
+// saved in manifest file of dir
+type ReleaseManifest struct {
+	dir      string
+	Releases []dto.AgentRelease `json:"releases"`
+}
+
+// LoadReleaseManifest return manifest of releases dir, empty
+// when manifest file not exists
+func LoadReleaseManifest(dir string) (*ReleaseManifest, error) {
+	manifest := &ReleaseManifest{dir: dir}
+	content, err := os.ReadFile(filepath.Join(dir, pkg.RELEASES_MANIFEST_FILE_NAME))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Save execute write of manifest in releases dir
+func (m *ReleaseManifest) Save() error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(m.dir, pkg.RELEASES_MANIFEST_FILE_NAME)
+	if err := os.WriteFile(manifestPath+".tmp", content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(manifestPath+".tmp", manifestPath)
+}
+
+// List return releases ordered by install, most recent first
+func (m *ReleaseManifest) List() []dto.AgentRelease {
+	releases := slices.Clone(m.Releases)
+	slices.SortStableFunc(releases, func(a, b dto.AgentRelease) int {
+		return b.InstalledAt.Compare(a.InstalledAt)
+	})
+	return releases
+}
+
+// Get return release of version
+func (m *ReleaseManifest) Get(version string) (dto.AgentRelease, bool) {
+	index := m.index(version)
+	if index < 0 {
+		return dto.AgentRelease{}, false
+	}
+	return m.Releases[index], true
+}
+
+// index return position of release of version, -1 when not registered
+func (m *ReleaseManifest) index(version string) int {
+	return slices.IndexFunc(m.Releases, func(release dto.AgentRelease) bool {
+		return release.Version == version
+	})
+}
+
+// Record execute register of release installed now with health
+// pending, release already registered is installed again
+func (m *ReleaseManifest) Record(version string, now time.Time) {
+	release := dto.AgentRelease{Version: version, InstalledAt: now, Health: pkg.RELEASE_HEALTH_PENDING}
+	if index := m.index(version); index >= 0 {
+		m.Releases[index] = release
+		return
+	}
+	m.Releases = append(m.Releases, release)
+}
+
+// Adopt execute register of release installed before of manifest,
+// install time is time of release dir, release registered not change
+func (m *ReleaseManifest) Adopt(version, health string) {
+	if !validReleaseVersion(version) || m.index(version) >= 0 {
+		return
+	}
+	info, err := os.Stat(filepath.Join(m.dir, version))
+	if err != nil || !info.IsDir() {
+		return
+	}
+	m.Releases = append(m.Releases, dto.AgentRelease{Version: version, InstalledAt: info.ModTime(), Health: health, CheckedAt: info.ModTime()})
+}
+
+// SetHealth execute change of health of release after verify
+func (m *ReleaseManifest) SetHealth(version, health string, now time.Time) {
+	if index := m.index(version); index >= 0 {
+		m.Releases[index].Health = health
+		m.Releases[index].CheckedAt = now
+	}
+}
+
+// Retained return if release is registered, not failed and
+// binaries of release exists in releases dir
+func (m *ReleaseManifest) Retained(version string) bool {
+	release, ok := m.Get(version)
+	if !ok || !validReleaseVersion(version) || release.Health == pkg.RELEASE_HEALTH_FAILED {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(m.dir, version))
+	return err == nil && info.IsDir()
+}
+
+// Previous return most recent healthy release different of current
+func (m *ReleaseManifest) Previous(current string) (string, bool) {
+	for _, release := range m.List() {
+		if release.Version != current && release.Health == pkg.RELEASE_HEALTH_HEALTHY && m.Retained(release.Version) {
+			return release.Version, true
+		}
+	}
+	return "", false
+}
+
+// Prune execute remove of releases out of retention, current and
+// pending releases are kept with last keep healthy releases, only
+// dirs of releases registered in manifest are removed, release not
+// removed stay in manifest for next prune, return versions removed
+func (m *ReleaseManifest) Prune(current string, keep int) ([]string, error) {
+	if keep <= 0 {
+		keep = pkg.RELEASES_DEFAULT_KEEP
+	}
+	var outOfRetention []string
+	healthy := 0
+	for _, release := range m.List() {
+		switch {
+		case release.Version == current:
+			if release.Health == pkg.RELEASE_HEALTH_HEALTHY {
+				healthy++
+			}
+			continue
+		case release.Health == pkg.RELEASE_HEALTH_PENDING:
+			continue
+		case release.Health == pkg.RELEASE_HEALTH_HEALTHY && healthy < keep:
+			healthy++
+			continue
+		}
+		outOfRetention = append(outOfRetention, release.Version)
+	}
+	var removed []string
+	var errs []error
+	for _, version := range outOfRetention {
+		if validReleaseVersion(version) {
+			if err := os.RemoveAll(filepath.Join(m.dir, version)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		removed = append(removed, version)
+	}
+	m.Releases = slices.DeleteFunc(m.Releases, func(release dto.AgentRelease) bool {
+		return slices.Contains(removed, release.Version)
+	})
+	return removed, errors.Join(errs...)
+}
+
+// validReleaseVersion return if version can be used as name of release dir
+func validReleaseVersion(version string) bool {
+	return len(version) > 0 && version != "." && version != ".." && filepath.Base(version) == version
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"hash/fnv"
+	"slices"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// RolloutBucket return bucket of host between 0 and 99 from compute id,
+// the same compute id is always in the same bucket
+func RolloutBucket(computeId string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(computeId))
+	return int(hash.Sum32() % 100)
+}
+
+// ResolveAgentUpdate return ring of host and version of update allowed
+// now, version of signal is latest or constraint resolved in versions
+// released to ring, pin of host replace version of signal and hold
+// block any update
+func ResolveAgentUpdate(versions dto.AgentVersions, computeId string, docpAgent dto.StateCheckDocpAgent, now time.Time) (dto.AgentUpdateRing, error) {
+	ring := dto.AgentUpdateRing{
+		Bucket: RolloutBucket(computeId),
+		Hold:   docpAgent.Hold,
+		Pin:    docpAgent.Pin,
+	}
+	rollout := versions.Rollout
+	eligible := true
+	switch {
+	case rollout == nil:
+		ring.Ring = "all"
+	case len(rollout.Rings) > 0:
+		ring.Ring = "unassigned"
+		eligible = false
+		for _, rolloutRing := range rollout.Rings {
+			if ring.Bucket < rolloutRing.Percentage {
+				ring.Ring = rolloutRing.Name
+				eligible = !now.Before(rolloutRing.StartAt)
+				break
+			}
+		}
+	case ring.Bucket < rollout.Percentage:
+		ring.Ring = "rollout"
+	default:
+		ring.Ring = "waiting"
+		eligible = false
+	}
+	if ring.Hold {
+		return ring, nil
+	}
+	requested := docpAgent.Version
+	if len(ring.Pin) > 0 {
+		// pin of host is not staged by rollout
+		requested, eligible = ring.Pin, true
+	}
+	if len(requested) == 0 || requested == "latest" {
+		if rollout == nil {
+			ring.Version = versions.LatestVersion
+		} else if eligible {
+			ring.Version = rolloutVersion(versions)
+		}
+		return ring, nil
+	}
+	candidates := versions.Versions
+	if len(versions.LatestVersion) > 0 && !slices.Contains(candidates, versions.LatestVersion) {
+		candidates = append(slices.Clone(candidates), versions.LatestVersion)
+	}
+	if rollout != nil && !eligible {
+		withheld := rolloutVersion(versions)
+		candidates = slices.DeleteFunc(slices.Clone(candidates), func(version string) bool {
+			return version == withheld
+		})
+	}
+	version, err := ResolveVersion(requested, candidates, docpAgent.AllowPrerelease)
+	if err != nil {
+		return ring, err
+	}
+	ring.Version = version
+	return ring, nil
+}
+
+// rolloutVersion return version of rollout, latest when not informed
+func rolloutVersion(versions dto.AgentVersions) string {
+	if len(versions.Rollout.Version) > 0 {
+		return versions.Rollout.Version
+	}
+	return versions.LatestVersion
+}
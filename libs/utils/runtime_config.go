@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultWorkDirPath return work dir by os
+func defaultWorkDirPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramFiles"), "DocpAgent")
+	}
+	return filepath.Join(string(filepath.Separator), "opt", "docp-agent")
+}
+
+// defaultConfigFilePath return path of config file by os
+func defaultConfigFilePath() string {
+	return filepath.Join(defaultWorkDirPath(), "config.yml")
+}
+
+// DefaultRuntimeConfig return configuration with default values
+func DefaultRuntimeConfig() dto.RuntimeConfig {
+	return dto.RuntimeConfig{
+		ConfigFilePath: defaultConfigFilePath(),
+		Domain:         pkg.DOCP_DOMAIN,
+		WorkDirPath:    defaultWorkDirPath(),
+		AgentPort:      pkg.DOCP_AGENT_PORT,
+		ErrorLevel:     "high",
+		Intervals: dto.RuntimeIntervals{
+			Collect:       time.Hour * 24,
+			StateCheck:    time.Minute * 1,
+			Tasks:         time.Second * 20,
+			AutoUpdate:    time.Hour * 12,
+			Metadata:      time.Hour * 12,
+			DatadogHealth: time.Minute * 5,
+		},
+	}
+}
+
+// runtimeFlags is struct for flags of command line of binaries
+type runtimeFlags struct {
+	flags *flag.FlagSet
+	dto.RuntimeConfig
+}
+
+// newRuntimeFlags return flags of command line of binaries
+func newRuntimeFlags() *runtimeFlags {
+	r := &runtimeFlags{flags: flag.NewFlagSet("docp", flag.ContinueOnError)}
+	r.flags.SetOutput(io.Discard)
+	r.flags.StringVar(&r.ConfigFilePath, "config", "", "path of config file")
+	r.flags.StringVar(&r.PidFile, "p", "", "path of pid file")
+	r.flags.StringVar(&r.Domain, "domain", "", "url of docp api")
+	r.flags.StringVar(&r.WorkDirPath, "workdir", "", "path of work dir")
+	r.flags.StringVar(&r.AgentPort, "agent-port", "", "port of agent api")
+	r.flags.StringVar(&r.ErrorLevel, "error-level", "", "level of errors: high, medium or low")
+	r.flags.StringVar(&r.DatadogConfPath, "dd-conf-path", "", "path of datadog config dir")
+	r.flags.DurationVar(&r.Intervals.Collect, "collect-interval", 0, "interval of collect of metadata")
+	r.flags.DurationVar(&r.Intervals.StateCheck, "state-check-interval", 0, "interval of check of state")
+	r.flags.DurationVar(&r.Intervals.Tasks, "tasks-interval", 0, "interval of reconciliation tasks")
+	r.flags.DurationVar(&r.Intervals.AutoUpdate, "auto-update-interval", 0, "interval of auto update")
+	r.flags.DurationVar(&r.Intervals.Metadata, "metadata-interval", 0, "interval of send of metadata")
+	r.flags.DurationVar(&r.Intervals.DatadogHealth, "datadog-health-interval", 0, "interval of check of health of datadog agent")
+	return r
+}
+
+// parse execute parse of args, positional args like run are ignored
+func (r *runtimeFlags) parse(args []string) error {
+	for len(args) > 0 {
+		if err := r.flags.Parse(args); err != nil {
+			return fmt.Errorf("invalid flags: %w", err)
+		}
+		args = r.flags.Args()
+		if len(args) > 0 {
+			args = args[1:]
+		}
+	}
+	return nil
+}
+
+// mergeRuntimeConfig execute override of config with values informed in from
+func mergeRuntimeConfig(config *dto.RuntimeConfig, from dto.RuntimeConfig) {
+	for _, field := range []struct {
+		to   *string
+		from string
+	}{
+		{&config.Domain, from.Domain},
+		{&config.WorkDirPath, from.WorkDirPath},
+		{&config.AgentPort, from.AgentPort},
+		{&config.ErrorLevel, from.ErrorLevel},
+		{&config.DatadogConfPath, from.DatadogConfPath},
+		{&config.PidFile, from.PidFile},
+	} {
+		if len(field.from) > 0 {
+			*field.to = field.from
+		}
+	}
+	for _, field := range []struct {
+		to   *time.Duration
+		from time.Duration
+	}{
+		{&config.Intervals.Collect, from.Intervals.Collect},
+		{&config.Intervals.StateCheck, from.Intervals.StateCheck},
+		{&config.Intervals.Tasks, from.Intervals.Tasks},
+		{&config.Intervals.AutoUpdate, from.Intervals.AutoUpdate},
+		{&config.Intervals.Metadata, from.Intervals.Metadata},
+		{&config.Intervals.DatadogHealth, from.Intervals.DatadogHealth},
+	} {
+		if field.from != 0 {
+			*field.to = field.from
+		}
+	}
+}
+
+// runtimeConfigFromFile return runtime section of config file,
+// empty when config file not exists
+func runtimeConfigFromFile(configFilePath string) (dto.RuntimeConfig, error) {
+	var config dto.ConfigAgent
+	content, err := os.ReadFile(configFilePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return config.Runtime, nil
+		}
+		return config.Runtime, err
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return config.Runtime, fmt.Errorf("invalid config file %s: %w", configFilePath, err)
+	}
+	return config.Runtime, nil
+}
+
+// runtimeConfigFromEnv return config from envs, intervals
+// invalid return error with name of env
+func runtimeConfigFromEnv() (dto.RuntimeConfig, error) {
+	config := dto.RuntimeConfig{
+		Domain:          os.Getenv("DOCP_DOMAIN"),
+		WorkDirPath:     os.Getenv("DOCP_WORKDIR_PATH"),
+		AgentPort:       os.Getenv("DOCP_AGENT_PORT"),
+		ErrorLevel:      os.Getenv("ERROR_LEVEL"),
+		DatadogConfPath: os.Getenv("DD_CONF_PATH"),
+	}
+	var errs []error
+	for _, env := range []struct {
+		name string
+		to   *time.Duration
+	}{
+		{"DOCP_COLLECT_INTERVAL", &config.Intervals.Collect},
+		{"DOCP_STATE_CHECK_INTERVAL", &config.Intervals.StateCheck},
+		{"DOCP_TASKS_INTERVAL", &config.Intervals.Tasks},
+		{"DOCP_AUTO_UPDATE_INTERVAL", &config.Intervals.AutoUpdate},
+		{"DOCP_METADATA_INTERVAL", &config.Intervals.Metadata},
+		{"DOCP_DATADOG_HEALTH_INTERVAL", &config.Intervals.DatadogHealth},
+	} {
+		value := os.Getenv(env.name)
+		if len(value) == 0 {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env %s: invalid duration %q", env.name, value))
+			continue
+		}
+		*env.to = duration
+	}
+	return config, errors.Join(errs...)
+}
+
+// ValidateRuntimeConfig return errors of all invalid values of config
+func ValidateRuntimeConfig(config dto.RuntimeConfig) error {
+	var errs []error
+	domainUrl, err := url.Parse(config.Domain)
+	if err != nil || (domainUrl.Scheme != "http" && domainUrl.Scheme != "https") || len(domainUrl.Host) == 0 {
+		errs = append(errs, fmt.Errorf("domain: must be url with http or https, got %q", config.Domain))
+	}
+	if len(config.WorkDirPath) == 0 || !filepath.IsAbs(config.WorkDirPath) {
+		errs = append(errs, fmt.Errorf("workdir_path: must be absolute path, got %q", config.WorkDirPath))
+	}
+	if port, err := strconv.Atoi(config.AgentPort); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("agent_port: must be port between 1 and 65535, got %q", config.AgentPort))
+	}
+	switch config.ErrorLevel {
+	case "high", "medium", "low":
+	default:
+		errs = append(errs, fmt.Errorf("error_level: must be high, medium or low, got %q", config.ErrorLevel))
+	}
+	for _, interval := range []struct {
+		name     string
+		value    time.Duration
+		minValue time.Duration
+	}{
+		{"intervals.collect", config.Intervals.Collect, time.Minute},
+		{"intervals.state_check", config.Intervals.StateCheck, time.Second * 10},
+		{"intervals.tasks", config.Intervals.Tasks, time.Second * 5},
+		{"intervals.auto_update", config.Intervals.AutoUpdate, time.Minute * 10},
+		{"intervals.metadata", config.Intervals.Metadata, time.Minute},
+		{"intervals.datadog_health", config.Intervals.DatadogHealth, time.Minute},
+	} {
+		if interval.value < interval.minValue {
+			errs = append(errs, fmt.Errorf("%s: must be at least %s, got %s", interval.name, interval.minValue, interval.value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LoadRuntimeConfig return configuration loaded from defaults, then runtime
+// section of config file, then envs, then flags of args, validated.
+// Args are kept in config for load again on change of config file
+func LoadRuntimeConfig(args []string) (dto.RuntimeConfig, error) {
+	config := DefaultRuntimeConfig()
+	config.Args = args
+	flags := newRuntimeFlags()
+	if err := flags.parse(args); err != nil {
+		return config, err
+	}
+	if configFilePath := os.Getenv("DOCP_CONFIG_FILE_PATH"); len(configFilePath) > 0 {
+		config.ConfigFilePath = configFilePath
+	}
+	if len(flags.ConfigFilePath) > 0 {
+		config.ConfigFilePath = flags.ConfigFilePath
+	}
+	fileConfig, err := runtimeConfigFromFile(config.ConfigFilePath)
+	if err != nil {
+		return config, err
+	}
+	mergeRuntimeConfig(&config, fileConfig)
+	envConfig, err := runtimeConfigFromEnv()
+	if err != nil {
+		return config, err
+	}
+	mergeRuntimeConfig(&config, envConfig)
+	mergeRuntimeConfig(&config, flags.RuntimeConfig)
+	if err := ValidateRuntimeConfig(config); err != nil {
+		return config, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return config, nil
+}
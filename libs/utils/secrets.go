@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"os"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"gopkg.in/yaml.v2"
+)
+
+// NewSecretStore return secret store of agent in work dir
+func NewSecretStore(workDirPath string) *pkg.SecretStore {
+	return pkg.NewSecretStore(GetSecretsDirPath(workDirPath))
+}
+
+// NewConfigYmlClient return yml client for config file, keeping api key,
+// access token and proxy password in secret store of work dir
+func NewConfigYmlClient(workDirPath string) *pkg.YmlClient {
+	return pkg.NewYmlClientWithSecrets(NewSecretStore(workDirPath))
+}
+
+// MigrateConfigSecrets execute move of api key, access token and
+// proxy password in plaintext from config file to secret store of work dir
+func MigrateConfigSecrets(configFilePath, workDirPath string) (bool, error) {
+	unlock, err := pkg.NewFileSystem().LockFile(GetConfigLockPath(workDirPath))
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	content, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return false, err
+	}
+	var plainConfig dto.ConfigAgent
+	if err := yaml.Unmarshal(content, &plainConfig); err != nil {
+		return false, err
+	}
+	if len(plainConfig.Agent.ApiKey) == 0 && len(plainConfig.AccessToken) == 0 && len(plainConfig.Network.ProxyPassword) == 0 {
+		return false, nil
+	}
+	ymlClient := NewConfigYmlClient(workDirPath)
+	var config dto.ConfigAgent
+	if err := ymlClient.Unmarshall(content, &config); err != nil {
+		return false, err
+	}
+	if err := ymlClient.SaveSecrets(&config); err != nil {
+		return false, err
+	}
+	configBytes, err := ymlClient.Marshall(&config)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(configFilePath)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(configFilePath, configBytes, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
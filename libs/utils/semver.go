@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// SemVersion is struct for semantic version
+type SemVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	original   string
+}
+
+// ParseSemVersion return semantic version from text like 1.2.3,
+// v1.2.3 or 1.2.3-rc.1, build metadata is ignored
+func ParseSemVersion(value string) (SemVersion, error) {
+	version, parts, err := parsePartialVersion(value)
+	if err != nil {
+		return SemVersion{}, err
+	}
+	if parts != 3 {
+		return SemVersion{}, fmt.Errorf("%w: %s", pkg.ErrInvalidVersion, value)
+	}
+	return version, nil
+}
+
+// parsePartialVersion return version and number of parts
+// informed, like 1 or 1.2, parts missing are zero
+func parsePartialVersion(value string) (SemVersion, int, error) {
+	text := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	text, _, _ = strings.Cut(text, "+")
+	version := SemVersion{original: value}
+	text, version.Prerelease, _ = strings.Cut(text, "-")
+	fields := strings.Split(text, ".")
+	if len(fields) > 3 || len(text) == 0 {
+		return SemVersion{}, 0, fmt.Errorf("%w: %s", pkg.ErrInvalidVersion, value)
+	}
+	numbers := []*int{&version.Major, &version.Minor, &version.Patch}
+	for i, field := range fields {
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 0 {
+			return SemVersion{}, 0, fmt.Errorf("%w: %s", pkg.ErrInvalidVersion, value)
+		}
+		*numbers[i] = number
+	}
+	return version, len(fields), nil
+}
+
+// String return version as received
+func (v SemVersion) String() string {
+	return v.original
+}
+
+// Compare return -1, 0 or 1 when version is lower, equal
+// or greater than other, pre-release is lower than release
+func (v SemVersion) Compare(other SemVersion) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// comparePrerelease return order of pre-release by identifiers,
+// numeric identifiers compared as numbers
+func comparePrerelease(a, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numberA, errA := strconv.Atoi(partsA[i])
+		numberB, errB := strconv.Atoi(partsB[i])
+		switch {
+		case errA == nil && errB == nil && numberA != numberB:
+			if numberA < numberB {
+				return -1
+			}
+			return 1
+		case errA == nil && errB != nil:
+			return -1
+		case errA != nil && errB == nil:
+			return 1
+		case partsA[i] != partsB[i]:
+			return strings.Compare(partsA[i], partsB[i])
+		}
+	}
+	switch {
+	case len(partsA) < len(partsB):
+		return -1
+	case len(partsA) > len(partsB):
+		return 1
+	}
+	return 0
+}
+
+// comparator is struct for one comparison of constraint
+type comparator struct {
+	operator string
+	version  SemVersion
+}
+
+// check return if version satisfy comparator
+func (c comparator) check(version SemVersion) bool {
+	result := version.Compare(c.version)
+	switch c.operator {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return result == 0
+}
+
+// VersionConstraint is struct for constraint of versions, groups
+// separated by || where all comparators of group must match
+type VersionConstraint struct {
+	groups [][]comparator
+}
+
+// ParseVersionConstraint return constraint from text like ~0.3,
+// ^1.2.0, >=1.0.0 <2.0.0, 1.2.x or exact version
+func ParseVersionConstraint(value string) (*VersionConstraint, error) {
+	constraint := &VersionConstraint{}
+	for _, group := range strings.Split(value, "||") {
+		var comparators []comparator
+		for _, field := range strings.Fields(group) {
+			parsed, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", pkg.ErrInvalidVersion, value)
+			}
+			comparators = append(comparators, parsed...)
+		}
+		if len(comparators) == 0 {
+			return nil, fmt.Errorf("%w: %s", pkg.ErrInvalidVersion, value)
+		}
+		constraint.groups = append(constraint.groups, comparators)
+	}
+	return constraint, nil
+}
+
+// parseComparator return comparators for one field of constraint,
+// tilde, caret and partial versions turn in range
+func parseComparator(field string) ([]comparator, error) {
+	operator := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(field, prefix) {
+			operator = prefix
+			field = strings.TrimPrefix(field, prefix)
+			break
+		}
+	}
+	if field == "*" || field == "x" {
+		return []comparator{{">=", SemVersion{}}}, nil
+	}
+	field = strings.TrimSuffix(strings.TrimSuffix(field, ".x"), ".*")
+	version, parts, err := parsePartialVersion(field)
+	if err != nil {
+		return nil, err
+	}
+	switch operator {
+	case "~":
+		upper := SemVersion{Major: version.Major + 1}
+		if parts > 1 {
+			upper = SemVersion{Major: version.Major, Minor: version.Minor + 1}
+		}
+		return []comparator{{">=", version}, {"<", upper}}, nil
+	case "^":
+		upper := SemVersion{Major: version.Major + 1}
+		if version.Major == 0 && parts > 1 {
+			upper = SemVersion{Minor: version.Minor + 1}
+			if version.Minor == 0 && parts > 2 {
+				upper = SemVersion{Patch: version.Patch + 1}
+			}
+		}
+		return []comparator{{">=", version}, {"<", upper}}, nil
+	case "", "=":
+		if parts == 3 {
+			return []comparator{{"=", version}}, nil
+		}
+		upper := SemVersion{Major: version.Major + 1}
+		if parts == 2 {
+			upper = SemVersion{Major: version.Major, Minor: version.Minor + 1}
+		}
+		return []comparator{{">=", version}, {"<", upper}}, nil
+	}
+	return []comparator{{operator, version}}, nil
+}
+
+// Check return if version satisfy constraint
+func (c *VersionConstraint) Check(version SemVersion) bool {
+	for _, group := range c.groups {
+		matched := true
+		for _, comparator := range group {
+			if !comparator.check(version) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExactVersion return if value is one semantic version and not constraint
+func IsExactVersion(value string) bool {
+	_, err := ParseSemVersion(value)
+	return err == nil
+}
+
+// ResolveVersion return highest version satisfying constraint,
+// pre-releases are excluded unless allowed or requested exactly
+func ResolveVersion(constraint string, versions []string, allowPrerelease bool) (string, error) {
+	if IsExactVersion(constraint) {
+		for _, version := range versions {
+			if version == constraint {
+				return version, nil
+			}
+		}
+		return "", fmt.Errorf("%w: %s", pkg.ErrAgentVersionNotFound, constraint)
+	}
+	parsed, err := ParseVersionConstraint(constraint)
+	if err != nil {
+		return "", err
+	}
+	var candidates []SemVersion
+	for _, version := range versions {
+		semVersion, err := ParseSemVersion(version)
+		if err != nil {
+			continue
+		}
+		if len(semVersion.Prerelease) > 0 && !allowPrerelease {
+			continue
+		}
+		if parsed.Check(semVersion) {
+			candidates = append(candidates, semVersion)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%w: %s", pkg.ErrAgentVersionNotFound, constraint)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Compare(candidates[j]) > 0
+	})
+	return candidates[0].String(), nil
+}
+
+// IsDowngrade return if target version is lower than current,
+// versions not semantic are never downgrade
+func IsDowngrade(current, target string) bool {
+	currentVersion, err := ParseSemVersion(current)
+	if err != nil {
+		return false
+	}
+	targetVersion, err := ParseSemVersion(target)
+	if err != nil {
+		return false
+	}
+	return targetVersion.Compare(currentVersion) < 0
+}
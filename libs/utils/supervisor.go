@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// Supervisor is struct for run of tasks sharing one context, like
+// errgroup the first task failing or in panic cancel all tasks
+type Supervisor struct {
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]int
+	err     error
+}
+
+// NewSupervisor return instance of supervisor with context from parent
+func NewSupervisor(parent context.Context) *Supervisor {
+	ctx, cancel := context.WithCancelCause(parent)
+	return &Supervisor{
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]int),
+	}
+}
+
+// Context return context of tasks, done when parent is done or task fail
+func (s *Supervisor) Context() context.Context {
+	return s.ctx
+}
+
+// Go execute task in goroutine, error different of cancel
+// of context stop all tasks
+func (s *Supervisor) Go(name string, task func(ctx context.Context) error) {
+	s.mu.Lock()
+	s.running[name]++
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.run(task)
+		s.mu.Lock()
+		s.running[name]--
+		if s.running[name] == 0 {
+			delete(s.running, name)
+		}
+		failed := err != nil && !errors.Is(err, context.Canceled)
+		if failed && s.err == nil {
+			s.err = fmt.Errorf("task %s: %w", name, err)
+		}
+		s.mu.Unlock()
+		if failed {
+			s.cancel(err)
+		}
+	}()
+}
+
+// run execute task turning panic in error
+func (s *Supervisor) run(task func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return task(s.ctx)
+}
+
+// Stop execute cancel of context of tasks
+func (s *Supervisor) Stop() {
+	s.cancel(context.Canceled)
+}
+
+// Running return name of tasks still running
+func (s *Supervisor) Running() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.running))
+	for name := range s.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Err return error of first task failed
+func (s *Supervisor) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Wait execute wait of tasks until timeout, return error
+// with name of tasks still running after timeout
+func (s *Supervisor) Wait(timeout time.Duration) error {
+	if !WaitTimeout(&s.wg, timeout) {
+		return fmt.Errorf("%w: %s", pkg.ErrShutdownTimeout, strings.Join(s.Running(), ","))
+	}
+	return nil
+}
+
+// WaitTimeout execute wait of wait group until timeout,
+// return false when timeout is reached
+func WaitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// TaskTracker is struct for register tasks in flight
+type TaskTracker struct {
+	mu          sync.Mutex
+	nextId      uint64
+	tasks       map[uint64]dto.ControlTask
+	interrupted []dto.ControlTask
+}
+
+// NewTaskTracker return instance of task tracker
+func NewTaskTracker() *TaskTracker {
+	return &TaskTracker{
+		tasks: make(map[uint64]dto.ControlTask),
+	}
+}
+
+// Track execute register of task and return func for finish it
+func (t *TaskTracker) Track(name string) func() {
+	t.mu.Lock()
+	t.nextId++
+	id := t.nextId
+	t.tasks[id] = dto.ControlTask{Name: name, StartedAt: time.Now()}
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		delete(t.tasks, id)
+		t.mu.Unlock()
+	}
+}
+
+// List return tasks in flight ordered by start
+func (t *TaskTracker) List() []dto.ControlTask {
+	t.mu.Lock()
+	tasks := make([]dto.ControlTask, 0, len(t.tasks))
+	for _, task := range t.tasks {
+		tasks = append(tasks, task)
+	}
+	t.mu.Unlock()
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartedAt.Before(tasks[j].StartedAt)
+	})
+	return tasks
+}
+
+// Interrupt execute register of task in flight stopped
+// before complete, like on shutdown of process
+func (t *TaskTracker) Interrupt(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	interrupted := dto.ControlTask{Name: name, StartedAt: time.Now()}
+	for _, task := range t.tasks {
+		if task.Name == name && task.StartedAt.Before(interrupted.StartedAt) {
+			interrupted = task
+		}
+	}
+	t.interrupted = append(t.interrupted, interrupted)
+}
+
+// Pending return tasks interrupted and tasks still in flight
+func (t *TaskTracker) Pending() []dto.ControlTask {
+	t.mu.Lock()
+	pending := append([]dto.ControlTask{}, t.interrupted...)
+	t.mu.Unlock()
+	return append(pending, t.List()...)
+}
+
+// ErrorHistory is struct for keep last errors received
+type ErrorHistory struct {
+	mu     sync.Mutex
+	size   int
+	errors []dto.ControlError
+}
+
+// NewErrorHistory return instance of error history with max size
+func NewErrorHistory(size int) *ErrorHistory {
+	return &ErrorHistory{
+		size: size,
+	}
+}
+
+// Add execute register of error, discarding oldest when full
+func (e *ErrorHistory) Add(from string, priority int, err error) {
+	if err == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errors = append(e.errors, dto.ControlError{From: from, Priority: priority, Error: err.Error(), Time: time.Now()})
+	if len(e.errors) > e.size {
+		e.errors = e.errors[len(e.errors)-e.size:]
+	}
+}
+
+// List return errors from oldest to newest
+func (e *ErrorHistory) List() []dto.ControlError {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	errors := make([]dto.ControlError, len(e.errors))
+	copy(errors, e.errors)
+	return errors
+}
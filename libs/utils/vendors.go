@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// ExpandRemoveVendors return vendors to remove of config, all is every
+// vendor detected except datadog, datadog is removed only when listed
+// because it is uninstalled by datadog actions
+func ExpandRemoveVendors(configured, detected []string) []string {
+	vendors := RemoveItemFromSlice(configured, "all")
+	if len(vendors) == len(configured) {
+		return vendors
+	}
+	for _, vendor := range detected {
+		if vendor == "datadog" || slices.Contains(vendors, vendor) {
+			continue
+		}
+		vendors = append(vendors, vendor)
+	}
+	return vendors
+}
+
+// WaitVendorsRemoved execute verify of vendors detected each interval until
+// all vendors are not detected, removed is called once for each vendor
+// not detected, return vendors pending when timeout or ctx done
+func WaitVendorsRemoved(ctx context.Context, vendors []string, detect func() ([]string, error), interval, timeout time.Duration, removed func(vendor string)) ([]string, error) {
+	vendorsRemoved := make(map[string]bool)
+	pending := func() []string {
+		var pendingVendors []string
+		for _, vendor := range vendors {
+			if !vendorsRemoved[vendor] {
+				pendingVendors = append(pendingVendors, vendor)
+			}
+		}
+		return pendingVendors
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(vendorsRemoved) < len(vendors) {
+		select {
+		case <-ticker.C:
+			detected, err := detect()
+			if err != nil {
+				continue
+			}
+			for _, vendor := range vendors {
+				if vendorsRemoved[vendor] || slices.Contains(detected, vendor) {
+					continue
+				}
+				vendorsRemoved[vendor] = true
+				removed(vendor)
+			}
+		case <-timer.C:
+			return pending(), pkg.ErrVendorUninstallTimeout
+		case <-ctx.Done():
+			return pending(), ctx.Err()
+		}
+	}
+	return nil, nil
+}
@@ -2,18 +2,20 @@ package operators
 
 import (
 	"os"
-	"path/filepath"
-	"runtime"
+	"os/signal"
 	"sync"
+	"syscall"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/api"
 	adapters "github.com/DelfiaProducts/docp-agent-os-instance/libs/adapters"
+	libdto "github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
 	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 )
 
 // AgentOperator is struct for operator the linux
 type AgentOperator struct {
+	config       libdto.RuntimeConfig
 	chanMetadata chan []byte
 	chanErrors   chan error
 	adapter      *adapters.AgentAdapter
@@ -22,9 +24,10 @@ type AgentOperator struct {
 	wg           *sync.WaitGroup
 }
 
-// NewAgentOperator return instance of linux operator
-func NewAgentOperator() *AgentOperator {
+// NewAgentOperator return instance of linux operator with configuration
+func NewAgentOperator(config libdto.RuntimeConfig) *AgentOperator {
 	return &AgentOperator{
+		config:       config,
 		chanMetadata: make(chan []byte, 1),
 		chanErrors:   make(chan error, 1),
 		wg:           &sync.WaitGroup{},
@@ -33,29 +36,21 @@ func NewAgentOperator() *AgentOperator {
 
 // Setup configure operator
 func (l *AgentOperator) Setup() error {
-	port, err := libutils.GetPortAgentApi()
+	logger, err := libutils.NewDocpLoggerService("agent", l.config)
 	if err != nil {
-		return err
-	}
-	var logger libinterfaces.ILogger
-	if runtime.GOOS == "windows" {
-		workdir, err := libutils.GetWorkDirPath()
-		if err != nil {
-			return err
-		}
-		logPath := filepath.Join(workdir, "logs", "agent.log")
-		loggerFile := libutils.NewDocpLoggerWindowsFileText(logPath)
-		logger = loggerFile
-	} else {
 		logger = libutils.NewDocpLoggerJSON(os.Stdout)
+		logger.Warn("failed create logger of service, logging in stdout", "trace", "docp-agent-os-instance.agent_operator.Setup", "error", err.Error())
 	}
-	l.logger = logger
-	api := api.NewDocpApi(port, l.logger)
+	l.logger = logger.Named("agent_operator")
+	if err := libutils.ReloadLogLevels(l.config.ConfigFilePath); err != nil {
+		l.logger.Warn("failed load log levels", "trace", "docp-agent-os-instance.agent_operator.Setup", "error", err.Error())
+	}
+	api := api.NewDocpApi(l.config, l.logger)
 	if err := api.Setup(); err != nil {
 		return err
 	}
 	l.api = api
-	adapterAgent := adapters.NewAgentAdapter(l.logger)
+	adapterAgent := adapters.NewAgentAdapter(l.logger, l.config)
 	if err := adapterAgent.Prepare(); err != nil {
 		return err
 	}
@@ -90,6 +85,23 @@ func (l *AgentOperator) apiListen() {
 	}
 }
 
+// reloadLogLevelsOnSignal execute reload of log levels
+// from config file when receive SIGHUP
+func (l *AgentOperator) reloadLogLevelsOnSignal() {
+	l.logger.Debug("reload log levels on signal", "trace", "docp-agent-os-instance.agent_operator.reloadLogLevelsOnSignal")
+	defer l.wg.Done()
+	chanSignal := make(chan os.Signal, 1)
+	signal.Notify(chanSignal, syscall.SIGHUP)
+	defer signal.Stop(chanSignal)
+	for range chanSignal {
+		if err := libutils.ReloadLogLevels(l.config.ConfigFilePath); err != nil {
+			l.chanErrors <- err
+			continue
+		}
+		l.logger.Info("log levels reloaded", "trace", "docp-agent-os-instance.agent_operator.reloadLogLevelsOnSignal", "levels", libutils.GetLogLevels())
+	}
+}
+
 // Run execut loop the operator
 func (l *AgentOperator) Run() error {
 	if err := l.Setup(); err != nil {
@@ -98,10 +110,11 @@ func (l *AgentOperator) Run() error {
 	l.logger.Debug("execute run", "trace", "docp-agent-os-instance.agent_operator.Run")
 	l.logger.Info("execute agent")
 	defer l.logger.Close()
-	l.wg.Add(3)
+	l.wg.Add(4)
 	go l.comunicateSCM()
 	go l.consumerErrors()
 	go l.apiListen()
+	go l.reloadLogLevelsOnSignal()
 	l.wg.Wait()
 	return nil
 }
@@ -0,0 +1,102 @@
+package operators
+
+import (
+	"context"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// runtimeConfig return configuration applied in operator
+func (l *ManagerOperator) runtimeConfig() dto.RuntimeConfig {
+	l.configMu.RLock()
+	defer l.configMu.RUnlock()
+	return l.config
+}
+
+// watchIntervals return channel closed when intervals change
+func (l *ManagerOperator) watchIntervals() <-chan struct{} {
+	l.configMu.RLock()
+	defer l.configMu.RUnlock()
+	return l.intervalsChanged
+}
+
+// applyRuntimeConfig execute change of values safe for apply
+// without restart and notify loops when intervals change
+func (l *ManagerOperator) applyRuntimeConfig(config dto.RuntimeConfig) {
+	l.configMu.Lock()
+	defer l.configMu.Unlock()
+	previous := l.config
+	l.config.ErrorLevel = config.ErrorLevel
+	l.config.Intervals = config.Intervals
+	if previous.Intervals != l.config.Intervals {
+		close(l.intervalsChanged)
+		l.intervalsChanged = make(chan struct{})
+	}
+}
+
+// watchConfigFile execute reload of config when config file change, until ctx is done
+func (l *ManagerOperator) watchConfigFile(ctx context.Context) error {
+	l.logger.Debug("watch config file", "trace", "docp-agent-os-instance.manager_config_reload.watchConfigFile", "path", l.filePath)
+	watcher := libutils.NewFileWatcher(l.filePath, time.Second*pkg.CONFIG_WATCH_POLL_INTERVAL, time.Millisecond*pkg.CONFIG_WATCH_DEBOUNCE)
+	stopClose := context.AfterFunc(ctx, watcher.Close)
+	defer stopClose()
+	if err := watcher.WatchNotify(l.reloadConfig); err != nil {
+		l.logger.Warn("failed watch config file, using polling", "trace", "docp-agent-os-instance.manager_config_reload.watchConfigFile", "error", err.Error())
+		watcher.WatchPoll(l.reloadConfig)
+	}
+	return nil
+}
+
+// reloadConfig execute apply of changes of config file, changes
+// that need restart are only logged
+func (l *ManagerOperator) reloadConfig() {
+	l.logger.Debug("reload config", "trace", "docp-agent-os-instance.manager_config_reload.reloadConfig")
+	if _, err := libutils.MigrateConfigSecrets(l.filePath, l.runtimeConfig().WorkDirPath); err != nil {
+		l.logger.Warn("failed migrate secrets from config file", "trace", "docp-agent-os-instance.manager_config_reload.reloadConfig", "error", err.Error())
+	}
+	configAgent, err := l.adapter.GetConfigAgent()
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "reloadConfig", Priority: dto.ErrLevelMedium, Err: err}
+		return
+	}
+	current := l.runtimeConfig()
+	config, err := libutils.LoadRuntimeConfig(current.Args)
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "reloadConfig", Priority: dto.ErrLevelMedium, Err: err}
+		return
+	}
+	changes := libutils.DiffConfig(l.configAgent, configAgent, current, config)
+	l.configAgent = configAgent
+	for _, field := range changes.Restart {
+		l.logger.Warn("config changed, restart required for apply", "trace", "docp-agent-os-instance.manager_config_reload.reloadConfig", "field", field)
+	}
+	if changes.LogLevels {
+		if err := libutils.ReloadLogLevels(l.filePath); err != nil {
+			l.chanErrors <- dto.ManagerChanErrors{From: "reloadConfig", Priority: dto.ErrLevelLow, Err: err}
+		} else {
+			l.logger.Info("log levels reloaded", "trace", "docp-agent-os-instance.manager_config_reload.reloadConfig", "levels", libutils.GetLogLevels())
+		}
+	}
+	if changes.Intervals || changes.ErrorLevel {
+		l.applyRuntimeConfig(config)
+		l.logger.Info("runtime config reloaded", "trace", "docp-agent-os-instance.manager_config_reload.reloadConfig", "errorLevel", config.ErrorLevel, "intervals", config.Intervals)
+		if current.Intervals.Collect != config.Intervals.Collect {
+			l.adapter.SetCollectInterval(config.Intervals.Collect)
+		}
+	}
+	if changes.ApiKey {
+		l.logger.Info("api key changed, refreshing access token", "trace", "docp-agent-os-instance.manager_config_reload.reloadConfig")
+		if _, err := l.adapter.TokenManager().ForceRefresh(); err != nil {
+			l.chanErrors <- dto.ManagerChanErrors{From: "reloadConfig", Priority: dto.ErrLevelMedium, Err: err}
+		}
+	}
+	if changes.Tags {
+		l.logger.Info("tags changed, sending metadata", "trace", "docp-agent-os-instance.manager_config_reload.reloadConfig")
+		if err := l.ControlForceMetadataSync(); err != nil {
+			l.chanErrors <- dto.ManagerChanErrors{From: "reloadConfig", Priority: dto.ErrLevelMedium, Err: err}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package operators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/api"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// runControlApi execute running the local control socket until ctx is done
+func (l *ManagerOperator) runControlApi(ctx context.Context) error {
+	l.logger.Debug("run control api", "trace", "docp-agent-os-instance.manager_control.runControlApi")
+	socketPath := libutils.GetControlSocketPath(l.runtimeConfig().WorkDirPath)
+	controlApi := api.NewControlApi(socketPath, l, l.logger)
+	if err := controlApi.Setup(); err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "runControlApi", Priority: dto.ErrLevelLow, Err: err}
+		return nil
+	}
+	stopClose := context.AfterFunc(ctx, func() {
+		controlApi.Close()
+	})
+	defer stopClose()
+	if err := controlApi.Run(); err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "runControlApi", Priority: dto.ErrLevelLow, Err: err}
+	}
+	return nil
+}
+
+// ControlStatus return status of services, versions and last signal
+func (l *ManagerOperator) ControlStatus() (dto.ControlStatus, error) {
+	status := dto.ControlStatus{
+		Services:      make(map[string]string),
+		PendingEvents: l.adapter.PendingEventsCount(),
+		Paused:        l.paused.Load(),
+		Queues:        l.executor.Status(),
+	}
+	for _, service := range []string{"manager", "agent", "datadog"} {
+		state, err := l.adapter.Status(service)
+		if err != nil {
+			state = "unknown"
+		}
+		status.Services[service] = state
+	}
+	version, err := l.adapter.GetAgentVersion()
+	if err != nil {
+		return status, err
+	}
+	rollbackVersion, err := l.adapter.GetAgentRollbackVersion()
+	if err != nil {
+		return status, err
+	}
+	status.Versions = dto.ControlVersions{Agent: version, Rollback: rollbackVersion}
+	if releases, err := l.adapter.LoadReleases(); err == nil {
+		status.Releases = releases.List()
+	}
+	if receivedAt, err := l.adapter.GetStateReceivedAt(); err == nil {
+		status.LastSignalAt = receivedAt
+	}
+	if received, err := l.adapter.GetStateReceived(); err == nil && len(received) > 0 {
+		status.LastSignalHash = libutils.GenerateMd5Hash(received)
+	}
+	return status, nil
+}
+
+// ControlPlanActions return actions that would be executed now
+func (l *ManagerOperator) ControlPlanActions() ([]dto.StateAction, error) {
+	return l.adapter.PlanActions()
+}
+
+// ControlTasks return tasks in flight
+func (l *ManagerOperator) ControlTasks() []dto.ControlTask {
+	return l.tasks.List()
+}
+
+// ControlErrors return last errors received in consumer errors
+func (l *ManagerOperator) ControlErrors() []dto.ControlError {
+	return l.errorHistory.List()
+}
+
+// ControlForcePoll execute poll of state check now and
+// execute actions when reconciliation is not paused
+func (l *ManagerOperator) ControlForcePoll() error {
+	l.logger.Info("force state poll requested", "trace", "docp-agent-os-instance.manager_control.ControlForcePoll")
+	if err := l.GetSignalFromStateCheck(); err != nil && !errors.Is(err, libutils.ErrSignalAlreadyExists()) {
+		return err
+	}
+	l.wg.Add(1)
+	go l.collectGetActions()
+	return nil
+}
+
+// ControlForceMetadataSync execute collect and send metadata now
+func (l *ManagerOperator) ControlForceMetadataSync() error {
+	l.logger.Info("force metadata sync requested", "trace", "docp-agent-os-instance.manager_control.ControlForceMetadataSync")
+	// clear hash for metadata be sent even without changes
+	if err := l.adapter.SetStore("metadata.hash", ""); err != nil {
+		return err
+	}
+	l.adapter.CollectNow()
+	return nil
+}
+
+// ControlPause execute pause of reconciliation
+func (l *ManagerOperator) ControlPause() {
+	l.logger.Info("reconciliation paused", "trace", "docp-agent-os-instance.manager_control.ControlPause")
+	l.paused.Store(true)
+}
+
+// ControlResume execute resume of reconciliation
+func (l *ManagerOperator) ControlResume() {
+	l.logger.Info("reconciliation resumed", "trace", "docp-agent-os-instance.manager_control.ControlResume")
+	l.paused.Store(false)
+}
+
+// ControlUpdate execute update of agent for version or constraint
+// of versions available in repository
+func (l *ManagerOperator) ControlUpdate(version string, allowDowngrade bool) error {
+	l.logger.Info("update requested", "trace", "docp-agent-os-instance.manager_control.ControlUpdate", "version", version, "allowDowngrade", allowDowngrade)
+	if err := l.controlDeferral(time.Now()); err != nil {
+		return err
+	}
+	agentVersions, err := l.adapter.FetchAgentVersions()
+	if err != nil {
+		return err
+	}
+	if version == "latest" {
+		version = agentVersions.LatestVersion
+	}
+	candidates := agentVersions.Versions
+	if !slices.Contains(candidates, agentVersions.LatestVersion) {
+		candidates = append(candidates, agentVersions.LatestVersion)
+	}
+	resolved, err := libutils.ResolveVersion(version, candidates, false)
+	if err != nil {
+		return err
+	}
+	if agentVersion, err := l.adapter.GetAgentVersion(); err == nil && !allowDowngrade && libutils.IsDowngrade(agentVersion, resolved) {
+		return fmt.Errorf("%w: %s to %s", pkg.ErrAgentDowngrade, agentVersion, resolved)
+	}
+	l.submitUpdateAgent(resolved, allowDowngrade)
+	return nil
+}
+
+// ControlRollback execute update of agent for release retained,
+// to empty is previous healthy release
+func (l *ManagerOperator) ControlRollback(to string) (string, error) {
+	l.logger.Info("rollback requested", "trace", "docp-agent-os-instance.manager_control.ControlRollback", "to", to)
+	if err := l.controlDeferral(time.Now()); err != nil {
+		return "", err
+	}
+	return l.rollbackAgent(to)
+}
+
+// controlDeferral return error when update or rollback requested by
+// control socket is outside of maintenance window, request is not
+// queued and must be requested again
+func (l *ManagerOperator) controlDeferral(now time.Time) error {
+	allowed, _, next := l.maintenanceWindow(now)
+	if allowed {
+		return nil
+	}
+	if next.IsZero() {
+		return fmt.Errorf("%w, no maintenance window scheduled", pkg.ErrActionDeferred)
+	}
+	return fmt.Errorf("%w, next window at %s", pkg.ErrActionDeferred, next.Format(time.RFC3339))
+}
+
+// ControlLogLevels return levels of loggers
+func (l *ManagerOperator) ControlLogLevels() map[string]string {
+	return libutils.GetLogLevels()
+}
+
+// ControlSetLogLevel execute change of level of logger by name,
+// valid until next reload of config
+func (l *ManagerOperator) ControlSetLogLevel(name string, level string) error {
+	l.logger.Info("log level change requested", "trace", "docp-agent-os-instance.manager_control.ControlSetLogLevel", "name", name, "level", level)
+	return libutils.SetLogLevel(name, level)
+}
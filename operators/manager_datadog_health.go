@@ -0,0 +1,77 @@
+package operators
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// periodicDatadogHealth execute periodic check of health of datadog
+// agent, checked even with reconciliation paused
+func (l *ManagerOperator) periodicDatadogHealth(ctx context.Context) error {
+	l.logger.Debug("periodic datadog health", "trace", "docp-agent-os-instance.manager_datadog_health.periodicDatadogHealth")
+
+	ticker := time.NewTicker(l.runtimeConfig().Intervals.DatadogHealth)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.wg.Add(1)
+			go l.checkDatadogHealth()
+		case <-l.watchIntervals():
+			ticker.Reset(l.runtimeConfig().Intervals.DatadogHealth)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// checkDatadogHealth execute check of health of datadog agent by status
+// of agent, transaction is open while agent is not healthy and updated
+// when issues change, only one check is executed at time
+func (l *ManagerOperator) checkDatadogHealth() {
+	l.logger.Debug("check datadog health", "trace", "docp-agent-os-instance.manager_datadog_health.checkDatadogHealth")
+	defer l.wg.Done()
+	if !l.datadogHealthRunning.CompareAndSwap(false, true) {
+		l.logger.Debug("datadog health already running", "trace", "docp-agent-os-instance.manager_datadog_health.checkDatadogHealth")
+		return
+	}
+	defer l.datadogHealthRunning.Store(false)
+	defer l.tasks.Track("checkDatadogHealth")()
+	if !l.adapter.VerifyDatadogInstalled() {
+		l.datadogHealthMu.Lock()
+		defer l.datadogHealthMu.Unlock()
+		if l.datadogHealthTransaction != nil {
+			go l.adapter.NotifyStatus("health_docp_vendor_uninstalled", pkg.TransactionEventClose, "datadog agent uninstalled", l.datadogHealthTransaction)
+			l.datadogHealthTransaction = nil
+		}
+		return
+	}
+	health, previous := l.adapter.CheckDatadogHealth()
+	if !libutils.DatadogHealthChanged(previous, health) {
+		return
+	}
+	l.logger.Info("datadog health changed", "trace", "docp-agent-os-instance.manager_datadog_health.checkDatadogHealth", "status", health.Status, "issues", strings.Join(health.Issues, "; "))
+
+	l.datadogHealthMu.Lock()
+	defer l.datadogHealthMu.Unlock()
+	if health.Status == pkg.DATADOG_HEALTH_HEALTHY {
+		if l.datadogHealthTransaction != nil {
+			go l.adapter.NotifyStatus("health_docp_vendor_healthy", pkg.TransactionEventClose, "datadog agent healthy", l.datadogHealthTransaction)
+			l.datadogHealthTransaction = nil
+		}
+		return
+	}
+	message := "datadog agent " + health.Status + ": " + strings.Join(health.Issues, "; ")
+	typeEvent := pkg.TransactionEventUpdate
+	if l.datadogHealthTransaction == nil {
+		l.datadogHealthTransaction = context.WithValue(context.Background(), dto.ContextTransactionStatus, libutils.NewTransactionStatus())
+		typeEvent = pkg.TransactionEventOpen
+	}
+	go l.adapter.NotifyStatus("health_docp_vendor_"+health.Status, typeEvent, message, l.datadogHealthTransaction)
+}
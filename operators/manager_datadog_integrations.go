@@ -0,0 +1,19 @@
+package operators
+
+import (
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+)
+
+// applyIntegrationsDatadog execute call to api docp agent to install,
+// configure and remove integrations of datadog agent as in signal
+func (l *ManagerOperator) applyIntegrationsDatadog(integrations []dto.DatadogIntegration) {
+	l.logger.Debug("apply integrations datadog", "trace", "docp-agent-os-instance.manager_datadog_integrations.applyIntegrationsDatadog", "integrations", len(integrations))
+	defer l.wg.Done()
+	defer l.tasks.Track("applyIntegrationsDatadog")()
+	result, err := l.adapter.DocpAgentApiApplyIntegrationsDatadog(integrations)
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "applyIntegrationsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+		return
+	}
+	l.chanResultsApi <- result
+}
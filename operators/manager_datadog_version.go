@@ -0,0 +1,180 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// datadogUpgradeFailure is struct for upgrade of datadog agent failed
+// for version, retried after backoff by attempts
+type datadogUpgradeFailure struct {
+	version  string
+	attempts int
+	retryAt  time.Time
+}
+
+// datadogVersionDrift return pin of signal and direction of change
+// needed for package installed, direction empty is without drift
+func (l *ManagerOperator) datadogVersionDrift(version string) (libutils.DatadogVersionPin, string, string, error) {
+	pin, ok := libutils.ParseDatadogVersionPin(version)
+	if !ok {
+		return pin, "", "", nil
+	}
+	installed := l.adapter.DatadogInstalledVersion()
+	if len(installed) == 0 {
+		return pin, installed, "", nil
+	}
+	result, err := pin.Compare(installed)
+	if err != nil {
+		return pin, installed, "", err
+	}
+	switch {
+	case result < 0:
+		return pin, installed, "upgrade", nil
+	case result > 0:
+		return pin, installed, "downgrade", nil
+	}
+	return pin, installed, "", nil
+}
+
+// checkDatadogVersionDrift execute verify of version of datadog agent
+// installed against version pinned in signal, enqueue change of
+// version when drift is found
+func (l *ManagerOperator) checkDatadogVersionDrift() {
+	l.logger.Debug("check datadog version drift", "trace", "docp-agent-os-instance.manager_datadog_version.checkDatadogVersionDrift")
+	defer l.wg.Done()
+	defer l.tasks.Track("checkDatadogVersionDrift")()
+	datadogAgent, err := l.adapter.GetDatadogAgentFromReceived()
+	if err != nil {
+		return
+	}
+	pin, installed, direction, err := l.datadogVersionDrift(datadogAgent.Version)
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "checkDatadogVersionDrift", Priority: dto.ErrLevelLow, Err: err}
+		return
+	}
+	if len(direction) == 0 {
+		return
+	}
+	if retryAt, waiting := l.datadogUpgradeBackoff(datadogAgent.Version, time.Now()); waiting {
+		l.logger.Debug("datadog version drift waiting retry", "trace", "docp-agent-os-instance.manager_datadog_version.checkDatadogVersionDrift", "pinned", pin.String(), "retryAt", retryAt)
+		return
+	}
+	l.logger.Info("datadog version drift", "trace", "docp-agent-os-instance.manager_datadog_version.checkDatadogVersionDrift", "installed", installed, "pinned", pin.String(), "direction", direction)
+	if !l.allowDisruptiveActions() {
+		return
+	}
+	l.submitUpgradeAgentDatadog(datadogAgent.Version)
+}
+
+// submitUpgradeAgentDatadog execute enqueue of upgrade of datadog agent
+// to version, drift and signal use same action so upgrade is coalesced
+func (l *ManagerOperator) submitUpgradeAgentDatadog(version string) {
+	l.submitAction(pkg.ACTION_COMPONENT_DATADOG_AGENT, pkg.ACTION_UPGRADE, func() {
+		l.wg.Add(1)
+		l.upgradeAgentDatadog(version)
+	})
+}
+
+// datadogUpgradeBackoff return time of retry of upgrade for version and
+// if it is not reached, only last version failed is waiting
+func (l *ManagerOperator) datadogUpgradeBackoff(version string, now time.Time) (time.Time, bool) {
+	l.datadogUpgradeMu.Lock()
+	defer l.datadogUpgradeMu.Unlock()
+	failure := l.datadogUpgradeFailure
+	if failure.version != version {
+		return time.Time{}, false
+	}
+	return failure.retryAt, now.Before(failure.retryAt)
+}
+
+// recordDatadogUpgradeFailure execute register of upgrade failed for
+// version, delay of retry double by attempt of same version
+func (l *ManagerOperator) recordDatadogUpgradeFailure(version string) {
+	l.datadogUpgradeMu.Lock()
+	defer l.datadogUpgradeMu.Unlock()
+	if l.datadogUpgradeFailure.version != version {
+		l.datadogUpgradeFailure = datadogUpgradeFailure{version: version}
+	}
+	delay := time.Minute * pkg.DATADOG_UPGRADE_RETRY_MAX
+	if attempt := l.datadogUpgradeFailure.attempts; attempt < 10 {
+		if exp := time.Minute * pkg.DATADOG_UPGRADE_RETRY_BASE << uint(attempt); exp < delay {
+			delay = exp
+		}
+	}
+	l.datadogUpgradeFailure.attempts++
+	l.datadogUpgradeFailure.retryAt = time.Now().Add(delay)
+	l.logger.Info("datadog upgrade failed, retry delayed", "trace", "docp-agent-os-instance.manager_datadog_version.recordDatadogUpgradeFailure", "version", version, "attempts", l.datadogUpgradeFailure.attempts, "retryAt", l.datadogUpgradeFailure.retryAt)
+}
+
+// resetDatadogUpgradeFailure execute clean of upgrade failed
+func (l *ManagerOperator) resetDatadogUpgradeFailure() {
+	l.datadogUpgradeMu.Lock()
+	defer l.datadogUpgradeMu.Unlock()
+	l.datadogUpgradeFailure = datadogUpgradeFailure{}
+}
+
+// upgradeAgentDatadog execute upgrade or downgrade of datadog agent
+// to version pinned and wait package installed match the version
+func (l *ManagerOperator) upgradeAgentDatadog(version string) {
+	l.logger.Debug("upgrade agent datadog", "trace", "docp-agent-os-instance.manager_datadog_version.upgradeAgentDatadog", "version", version)
+	defer l.wg.Done()
+	defer l.tasks.Track("upgradeAgentDatadog")()
+	pin, installed, direction, err := l.datadogVersionDrift(version)
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "upgradeAgentDatadog", Priority: dto.ErrLevelMedium, Err: err}
+		return
+	}
+	if len(direction) == 0 {
+		return
+	}
+
+	transaction := libutils.NewTransactionStatus()
+	ctx := context.WithValue(context.Background(), dto.ContextTransactionStatus, transaction)
+	message := fmt.Sprintf("%s datadog agent from %s to %s", direction, installed, pin.String())
+
+	go l.adapter.NotifyStatus("update_docp_vendor_received", pkg.TransactionEventOpen, message, ctx)
+	time.Sleep(l.delay)
+
+	if _, err := l.adapter.DocpAgentApiUpgradeDatadog(pin.String(), transaction.ID); err != nil {
+		go l.adapter.NotifyStatus("update_docp_vendor_error", pkg.TransactionEventClose, "failed "+message, ctx)
+		l.recordDatadogUpgradeFailure(version)
+		l.chanErrors <- dto.ManagerChanErrors{From: "upgradeAgentDatadog", Priority: dto.ErrLevelHigh, Err: err}
+		return
+	}
+	go l.adapter.NotifyStatus("update_docp_vendor_processing", pkg.TransactionEventUpdate, message, ctx)
+
+	waitCtx, cancel := context.WithTimeout(l.ctx, time.Minute*pkg.DATADOG_UPGRADE_TIMEOUT)
+	defer cancel()
+	ticker := time.NewTicker(time.Second * pkg.DATADOG_UPGRADE_POLL_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-waitCtx.Done():
+			if l.ctx.Err() != nil {
+				l.tasks.Interrupt("upgradeAgentDatadog")
+				return
+			}
+			go l.adapter.NotifyStatus("update_docp_vendor_error", pkg.TransactionEventClose, "timeout "+message, ctx)
+			l.recordDatadogUpgradeFailure(version)
+			l.chanErrors <- dto.ManagerChanErrors{From: "upgradeAgentDatadog", Priority: dto.ErrLevelHigh, Err: fmt.Errorf("timeout waiting datadog agent version %s", pin.String())}
+			return
+		case <-ticker.C:
+			current := l.adapter.DatadogInstalledVersion()
+			if len(current) == 0 {
+				continue
+			}
+			if result, err := pin.Compare(current); err == nil && result == 0 {
+				l.logger.Info("datadog agent version changed", "trace", "docp-agent-os-instance.manager_datadog_version.upgradeAgentDatadog", "from", installed, "to", current)
+				go l.adapter.NotifyStatus("update_docp_vendor_completed", pkg.TransactionEventClose, message, ctx)
+				l.resetDatadogUpgradeFailure()
+				return
+			}
+		}
+	}
+}
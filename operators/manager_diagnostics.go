@@ -0,0 +1,54 @@
+package operators
+
+import (
+	"errors"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// collectDiagnosticsRequest execute upload of diagnostics
+// when requested in state received
+func (l *ManagerOperator) collectDiagnosticsRequest() {
+	l.logger.Debug("collect diagnostics request", "trace", "docp-agent-os-instance.manager_diagnostics.collectDiagnosticsRequest")
+	defer l.wg.Done()
+	requestId, err := l.adapter.GetDiagnosticsRequest()
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "collectDiagnosticsRequest", Priority: dto.ErrLevelLow, Err: err}
+		return
+	}
+	if len(requestId) == 0 {
+		return
+	}
+	l.wg.Add(1)
+	l.uploadDiagnostics(requestId, true)
+}
+
+// uploadDiagnostics execute upload of diagnostics bundle,
+// only one upload is executed at time, requests of signal are
+// retried and requests of local control are not
+func (l *ManagerOperator) uploadDiagnostics(requestId string, retry bool) {
+	defer l.wg.Done()
+	if !l.diagnosticsRunning.CompareAndSwap(false, true) {
+		l.logger.Debug("diagnostics already running", "trace", "docp-agent-os-instance.manager_diagnostics.uploadDiagnostics", "requestId", requestId)
+		return
+	}
+	defer l.diagnosticsRunning.Store(false)
+	defer l.tasks.Track("uploadDiagnostics")()
+	if err := l.adapter.UploadDiagnostics(requestId, retry); err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "uploadDiagnostics", Priority: dto.ErrLevelMedium, Err: err}
+	}
+}
+
+// ControlDiagnostics execute upload of diagnostics requested
+// by local control, returning id of request
+func (l *ManagerOperator) ControlDiagnostics() (string, error) {
+	l.logger.Info("diagnostics requested", "trace", "docp-agent-os-instance.manager_diagnostics.ControlDiagnostics")
+	if l.diagnosticsRunning.Load() {
+		return "", errors.New("diagnostics already running")
+	}
+	requestId := libutils.GetUlid()
+	l.wg.Add(1)
+	go l.uploadDiagnostics(requestId, false)
+	return requestId, nil
+}
@@ -0,0 +1,58 @@
+package operators
+
+import (
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// submitAction execute enqueue of action in queue of component,
+// pending actions superseded by action are discarded
+func (l *ManagerOperator) submitAction(component, name string, run func()) {
+	l.logger.Debug("submit action", "trace", "docp-agent-os-instance.manager_executor.submitAction", "component", component, "action", name)
+	coalesced := l.executor.Submit(libutils.ExecutorAction{Component: component, Name: name, Run: run})
+	for _, previous := range coalesced {
+		l.logger.Info("pending action superseded", "trace", "docp-agent-os-instance.manager_executor.submitAction", "component", component, "action", previous, "by", name)
+	}
+}
+
+// submitUpdateAgent execute enqueue of update of agent docp for version
+func (l *ManagerOperator) submitUpdateAgent(version string, allowDowngrade bool) {
+	l.submitAction(pkg.ACTION_COMPONENT_DOCP_AGENT, pkg.ACTION_UPDATE, func() {
+		l.wg.Add(1)
+		l.updateAgent(version, allowDowngrade)
+	})
+}
+
+// submitSignalUpdateAgent execute enqueue of update of agent docp for
+// version of signal, resolved when update execute for ring of host
+func (l *ManagerOperator) submitSignalUpdateAgent() {
+	l.submitAction(pkg.ACTION_COMPONENT_DOCP_AGENT, pkg.ACTION_UPDATE, func() {
+		docpAgent, ring, err := l.planAgentUpdate()
+		if err != nil {
+			l.chanErrors <- dto.ManagerChanErrors{From: "submitSignalUpdateAgent", Priority: dto.ErrLevelMedium, Err: err}
+			return
+		}
+		if len(ring.Version) == 0 {
+			l.logger.Info("update of agent not allowed for ring", "trace", "docp-agent-os-instance.manager_executor.submitSignalUpdateAgent", "ring", ring.Ring, "hold", ring.Hold)
+			return
+		}
+		l.wg.Add(1)
+		l.updateAgent(ring.Version, docpAgent.AllowDowngrade)
+	})
+}
+
+// datadogActionComponent return component of queue for action of datadog,
+// tracer with tracing library have one queue by language and install
+// with apm single step change the agent
+func datadogActionComponent(act dto.ManagerStateAction) string {
+	if act.Component == "tracer" && act.Mode == "tracing_library" {
+		for _, env := range act.ComponentEnvs {
+			if env.Name == "language" {
+				return pkg.ACTION_COMPONENT_DATADOG_TRACER + "-" + env.Value
+			}
+		}
+		return pkg.ACTION_COMPONENT_DATADOG_TRACER
+	}
+	return pkg.ACTION_COMPONENT_DATADOG_AGENT
+}
@@ -0,0 +1,97 @@
+package operators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// allowDisruptiveActions return if actions that install, remove or
+// restart agents can execute now, outside of maintenance window the deferral
+// is notified once, called only when a disruptive action is pending
+func (l *ManagerOperator) allowDisruptiveActions() bool {
+	allowed, urgent, next := l.maintenanceWindow(time.Now())
+	if allowed {
+		l.releaseDeferral(urgent)
+		return true
+	}
+	l.notifyDeferral(next)
+	return false
+}
+
+// maintenanceWindow return if disruptive actions can execute now, if
+// they are urgent and start of next window, schedule and next window
+// are cached while config of maintenance not change
+func (l *ManagerOperator) maintenanceWindow(now time.Time) (bool, bool, time.Time) {
+	// without config readable no window is known
+	config, err := l.adapter.GetMaintenanceConfig()
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "maintenanceWindow", Priority: dto.ErrLevelLow, Err: err}
+		return true, false, time.Time{}
+	}
+	configBytes, err := l.marshaller(&config)
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "maintenanceWindow", Priority: dto.ErrLevelLow, Err: err}
+		return true, false, time.Time{}
+	}
+	hash := libutils.GenerateMd5Hash(configBytes)
+
+	l.maintenanceMu.Lock()
+	defer l.maintenanceMu.Unlock()
+	if hash != l.maintenanceHash {
+		l.maintenanceHash = hash
+		l.maintenanceSchedule, l.maintenanceErr = libutils.NewMaintenanceSchedule(config)
+		l.maintenanceNext = time.Time{}
+	}
+	// windows invalid defer actions until config is fixed
+	if l.maintenanceErr != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "maintenanceWindow", Priority: dto.ErrLevelMedium, Err: l.maintenanceErr}
+		return false, false, time.Time{}
+	}
+	if config.Urgent || l.maintenanceSchedule.Active(now) {
+		return true, config.Urgent, time.Time{}
+	}
+	// next window is searched again only after it started
+	if l.maintenanceNext.IsZero() || !l.maintenanceNext.After(now) {
+		l.maintenanceNext, _ = l.maintenanceSchedule.Next(now)
+	}
+	return false, false, l.maintenanceNext
+}
+
+// notifyDeferral execute open of transaction of actions deferred
+// until next window, when not already open
+func (l *ManagerOperator) notifyDeferral(next time.Time) {
+	l.deferralMu.Lock()
+	defer l.deferralMu.Unlock()
+	if l.deferral != nil {
+		return
+	}
+	message := "actions deferred, no maintenance window scheduled"
+	if !next.IsZero() {
+		message = fmt.Sprintf("actions deferred until maintenance window at %s", next.Format(time.RFC3339))
+	}
+	l.logger.Info("actions deferred outside of maintenance window", "trace", "docp-agent-os-instance.manager_maintenance.notifyDeferral", "next", next)
+	l.deferral = context.WithValue(context.Background(), dto.ContextTransactionStatus, libutils.NewTransactionStatus())
+	go l.adapter.NotifyStatus("actions_deferred", pkg.TransactionEventOpen, message, l.deferral)
+}
+
+// releaseDeferral execute close of transaction of actions deferred
+// when window open or actions are urgent
+func (l *ManagerOperator) releaseDeferral(urgent bool) {
+	l.deferralMu.Lock()
+	defer l.deferralMu.Unlock()
+	if l.deferral == nil {
+		return
+	}
+	message := "maintenance window open, executing actions"
+	if urgent {
+		message = "urgent actions, executing outside of maintenance window"
+	}
+	l.logger.Info("actions deferred released", "trace", "docp-agent-os-instance.manager_maintenance.releaseDeferral", "urgent", urgent)
+	go l.adapter.NotifyStatus("actions_released", pkg.TransactionEventClose, message, l.deferral)
+	l.deferral = nil
+}
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
-	"path/filepath"
-	"runtime"
+	"os/signal"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
@@ -26,13 +29,15 @@ import (
 
 // ManagerOperator is struct for manager the operator
 type ManagerOperator struct {
+	config               libdto.RuntimeConfig
 	filePath             string
 	logger               libinterfaces.ILogger
 	adapter              *adapters.ManagerAdapter
 	register             *services.AgentRegisterService
 	stateCheck           *services.StateCheckService
 	wg                   *sync.WaitGroup
-	done                 chan struct{}
+	ctx                  context.Context
+	cancel               context.CancelFunc
 	chanErrors           chan dto.ManagerChanErrors
 	chanMetadata         chan []byte
 	chanResultsApi       chan []byte
@@ -42,61 +47,111 @@ type ManagerOperator struct {
 	retryRegister        int
 	maxRetry             int
 	delay                time.Duration
+	// vendorUninstallTimeout is max time waiting other vendors
+	// uninstall before fail the docp uninstall
+	vendorUninstallTimeout time.Duration
+	tasks                  *libutils.TaskTracker
+	errorHistory           *libutils.ErrorHistory
+	// executor run actions in order with one queue by component
+	executor *libutils.ActionExecutor
+	// paused is reconciliation paused by local control
+	paused atomic.Bool
+	// diagnosticsRunning is upload of diagnostics in flight
+	diagnosticsRunning atomic.Bool
+	// datadogHealthRunning is check of health of datadog agent in flight
+	datadogHealthRunning atomic.Bool
+	// configMu protect config changed by reload of config file
+	configMu sync.RWMutex
+	// intervalsChanged is closed when intervals of config change
+	intervalsChanged chan struct{}
+	// configAgent is last config file applied
+	configAgent dto.ConfigAgent
+	// deferral is transaction of actions deferred outside of
+	// maintenance window, nil when no actions deferred
+	deferral   context.Context
+	deferralMu sync.Mutex
+	// maintenance is schedule of windows cached by hash of config
+	// and start of next window searched
+	maintenanceHash     string
+	maintenanceSchedule *libutils.MaintenanceSchedule
+	maintenanceErr      error
+	maintenanceNext     time.Time
+	maintenanceMu       sync.Mutex
+	// datadogHealthTransaction is transaction of datadog agent not
+	// healthy, nil when agent is healthy
+	datadogHealthTransaction context.Context
+	datadogHealthMu          sync.Mutex
+	// datadogUpgradeFailure is last upgrade of datadog agent failed,
+	// drift to same version is not retried before retryAt
+	datadogUpgradeFailure datadogUpgradeFailure
+	datadogUpgradeMu      sync.Mutex
 }
 
-// NewManagerOperator return instance of manager operator
-func NewManagerOperator() *ManagerOperator {
+// NewManagerOperator return instance of manager operator with configuration
+func NewManagerOperator(config libdto.RuntimeConfig) *ManagerOperator {
+	ctx, cancel := context.WithCancel(context.Background())
+	chanErrors := make(chan dto.ManagerChanErrors, 1)
 	return &ManagerOperator{
-		wg:                   &sync.WaitGroup{},
-		done:                 make(chan struct{}),
-		chanErrors:           make(chan dto.ManagerChanErrors, 1),
-		chanMetadata:         make(chan []byte, 1),
-		chanResultsApi:       make(chan []byte, 1),
-		chanDocpAgent:        make(chan dto.ManagerStateAction, 1),
-		chanDocpAgentDatadog: make(chan dto.ManagerStateAction, 1),
-		validateIsComplete:   false,
-		retryRegister:        0,
-		maxRetry:             10,
-		delay:                time.Second * 1,
+		config:                 config,
+		wg:                     &sync.WaitGroup{},
+		ctx:                    ctx,
+		cancel:                 cancel,
+		chanErrors:             chanErrors,
+		chanMetadata:           make(chan []byte, 1),
+		chanResultsApi:         make(chan []byte, 1),
+		chanDocpAgent:          make(chan dto.ManagerStateAction, 1),
+		chanDocpAgentDatadog:   make(chan dto.ManagerStateAction, 1),
+		validateIsComplete:     false,
+		retryRegister:          0,
+		maxRetry:               10,
+		delay:                  time.Second * 1,
+		vendorUninstallTimeout: time.Minute * 30,
+		tasks:                  libutils.NewTaskTracker(),
+		executor:               libutils.NewActionExecutor(ctx, chanErrors),
+		errorHistory:           libutils.NewErrorHistory(50),
+		intervalsChanged:       make(chan struct{}),
 	}
 }
 
 // Setup configure operator
 func (l *ManagerOperator) Setup() error {
-	var logger libinterfaces.ILogger
-	if runtime.GOOS == "windows" {
-		workdir, err := libutils.GetWorkDirPath()
-		if err != nil {
-			return err
-		}
-		logPath := filepath.Join(workdir, "logs", "manager.log")
-		loggerFile := libutils.NewDocpLoggerWindowsFileText(logPath)
-		logger = loggerFile
-	} else {
+	logger, err := libutils.NewDocpLoggerService("manager", l.config)
+	if err != nil {
 		logger = libutils.NewDocpLoggerJSON(os.Stdout)
+		logger.Warn("failed create logger of service, logging in stdout", "trace", "docp-agent-os-instance.manager_operator.Setup", "error", err.Error())
+	}
+	l.logger = logger.Named("manager_operator")
+	if err := libutils.ReloadLogLevels(l.config.ConfigFilePath); err != nil {
+		l.logger.Warn("failed load log levels", "trace", "docp-agent-os-instance.manager_operator.Setup", "error", err.Error())
 	}
-	l.logger = logger
-	adapterManager := adapters.NewManagerAdapter(l.logger)
+	adapterManager := adapters.NewManagerAdapter(l.logger, l.config)
 	if err := adapterManager.Prepare(); err != nil {
 		return err
 	}
 	l.adapter = adapterManager
-	stateCheck := services.NewStateCheckService(l.logger)
+	stateCheck := services.NewStateCheckService(l.logger, l.config)
 	if err := stateCheck.Setup(); err != nil {
 		return err
 	}
+	stateCheck.SetTokenManager(adapterManager.TokenManager())
 	l.stateCheck = stateCheck
-	serviceRegister := services.NewAgentRegisterService(l.logger)
+	serviceRegister := services.NewAgentRegisterService(l.logger, l.config)
 	if err := serviceRegister.Setup(); err != nil {
 		return err
 	}
+	serviceRegister.SetTokenManager(adapterManager.TokenManager())
 	l.register = serviceRegister
 
-	filePath, err := libutils.GetConfigFilePath()
+	l.filePath = l.config.ConfigFilePath
+	migrated, err := libutils.MigrateConfigSecrets(l.config.ConfigFilePath, l.config.WorkDirPath)
 	if err != nil {
-		return err
+		l.logger.Warn("failed migrate secrets from config file", "trace", "docp-agent-os-instance.manager_operator.Setup", "error", err.Error())
+	} else if migrated {
+		l.logger.Info("secrets migrated from config file to secret store", "trace", "docp-agent-os-instance.manager_operator.Setup")
+	}
+	if configAgent, err := adapterManager.GetConfigAgent(); err == nil {
+		l.configAgent = configAgent
 	}
-	l.filePath = filePath
 	return nil
 }
 
@@ -136,8 +191,7 @@ func (l *ManagerOperator) unmarshaller(content []byte, inner any) error {
 
 func (l *ManagerOperator) getLevelError() int {
 	l.logger.Debug("get level error", "trace", "docp-agent-os-instance.manager_operator.getLevelError")
-	envLevelError := os.Getenv("ERROR_LEVEL")
-	switch envLevelError {
+	switch l.runtimeConfig().ErrorLevel {
 	case "high":
 		return dto.ErrLevelHigh
 	case "medium":
@@ -149,16 +203,17 @@ func (l *ManagerOperator) getLevelError() int {
 	}
 }
 
-// consumerErrors execute consumer for errors
+// consumerErrors execute consumer for errors, running until exit
+// of process for tasks in shutdown not block on send of errors
 func (l *ManagerOperator) consumerErrors() {
 	l.logger.Debug("execute consumer errors", "trace", "docp-agent-os-instance.manager_operator.consumerErrors")
-	defer l.wg.Done()
 	for {
 		select {
 		case managerErr, ok := <-l.chanErrors:
 			if !ok {
 				return
 			}
+			l.errorHistory.Add(managerErr.From, managerErr.Priority, managerErr.Err)
 			if managerErr.Err != nil && managerErr.Priority <= l.getLevelError() {
 				l.logger.Error("error received in consumer errors", "from", managerErr.From, "error", managerErr.Err.Error())
 			}
@@ -177,9 +232,15 @@ func (l *ManagerOperator) executeAuthCall() error {
 
 // retryHandlerMetadata execute retry the create initial data
 func (l *ManagerOperator) retryHandlerMetadata() error {
-	l.logger.Debug("retry handler register", "timestamp", time.Now())
 	l.retryRegister += 1
-	time.Sleep(time.Minute * time.Duration(l.retryRegister))
+	delay := pkg.Backoff(l.retryRegister, time.Second*30, time.Minute*30)
+	l.logger.Debug("retry handler register", "timestamp", time.Now(), "attempt", l.retryRegister, "delay", delay.String())
+	select {
+	case <-time.After(delay):
+	case <-l.ctx.Done():
+		return l.ctx.Err()
+	}
+	l.wg.Add(1)
 	l.handleMetadata()
 	return nil
 }
@@ -279,6 +340,8 @@ func (l *ManagerOperator) sendMetadataUpdate() {
 
 				if err := l.adapter.UpdateConfigAgent(configAgent); err != nil {
 					l.chanErrors <- dto.ManagerChanErrors{From: "sendMetadataUpdate", Priority: dto.ErrLevelMedium, Err: err}
+				} else if len(agentRegisterResponse.AccessToken) > 0 {
+					l.adapter.TokenManager().SetToken(agentRegisterResponse.AccessToken)
 				}
 
 			case 401:
@@ -368,7 +431,10 @@ func (l *ManagerOperator) getMetadata() {
 		isChangedMetadata := l.verifyChangeMetadata(metadata)
 		l.logger.Debug("execute get metadata", "trace", "docp-agent-os-instance.linux_manager_operator.getMetadata", "isChangedMetadata", isChangedMetadata)
 		if isChangedMetadata {
-			l.chanMetadata <- metadata
+			select {
+			case l.chanMetadata <- metadata:
+			case <-l.ctx.Done():
+			}
 		}
 	}
 	close(l.chanMetadata)
@@ -378,12 +444,7 @@ func (l *ManagerOperator) getMetadata() {
 // Close execute close the operator loop
 func (l *ManagerOperator) Close() error {
 	l.logger.Debug("execute close", "trace", "docp-agent-os-instance.linux_manager_operator.Close")
-	l.wg.Add(1)
-	go func() {
-		defer l.wg.Done()
-		l.adapter.Close()
-	}()
-	return nil
+	return l.adapter.Close()
 }
 
 // extractDDApiKeyAndDDSiteFromEnvs return envs for install datadog agent
@@ -454,6 +515,7 @@ func (l *ManagerOperator) extractApmTracingLibrayEnvs(envs []dto.ManagerStateAct
 func (l *ManagerOperator) installAgent() {
 	l.logger.Debug("install the agent docp", "trace", "docp-agent-os-instance.manager_operator.installAgent")
 	defer l.wg.Done()
+	defer l.tasks.Track("installAgent")()
 	status, err := l.adapter.Status("agent")
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "installAgent", Priority: dto.ErrLevelHigh, Err: err}
@@ -475,10 +537,17 @@ func (l *ManagerOperator) installAgent() {
 	return
 }
 
-// UpdateAgent execute update the agent docp
+// UpdateAgent execute update the agent docp, downgrade is not allowed
 func (l *ManagerOperator) UpdateAgent(version string) error {
-	l.logger.Debug("update the agent docp", "trace", "docp-agent-os-instance.manager_operator.updateAgent")
+	return l.updateAgent(version, false)
+}
+
+// updateAgent execute update the agent docp for version, version
+// lower than installed is only applied when allow downgrade
+func (l *ManagerOperator) updateAgent(version string, allowDowngrade bool) error {
+	l.logger.Debug("update the agent docp", "trace", "docp-agent-os-instance.manager_operator.updateAgent", "version", version, "allowDowngrade", allowDowngrade)
 	defer l.wg.Done()
+	defer l.tasks.Track("UpdateAgent")()
 	statusManager, err := l.adapter.Status("manager")
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "updateAgent", Priority: dto.ErrLevelHigh, Err: err}
@@ -492,7 +561,8 @@ func (l *ManagerOperator) UpdateAgent(version string) error {
 	}
 
 	if statusAgent != "active" {
-		go l.installAgent()
+		l.wg.Add(1)
+		l.installAgent()
 		return nil
 	}
 	l.logger.Info("auto update agent version", "statusManager", statusManager, "statusAgent", statusAgent)
@@ -503,6 +573,11 @@ func (l *ManagerOperator) UpdateAgent(version string) error {
 			return err
 		}
 		l.logger.Info("auto update agent version", "version", version, "agentVersion", agentVersion)
+		if !allowDowngrade && libutils.IsDowngrade(agentVersion, version) {
+			err := fmt.Errorf("%w: %s to %s", pkg.ErrAgentDowngrade, agentVersion, version)
+			l.chanErrors <- dto.ManagerChanErrors{From: "updateAgent", Priority: dto.ErrLevelMedium, Err: err}
+			return err
+		}
 		if version != agentVersion {
 
 			transaction := libutils.NewTransactionStatus()
@@ -514,6 +589,12 @@ func (l *ManagerOperator) UpdateAgent(version string) error {
 			go l.adapter.NotifyStatus("update_docp_initiate", pkg.TransactionEventUpdate, "update docp initialized", ctx)
 			time.Sleep(l.delay)
 
+			if err := l.adapter.SaveUpdateTarget(version); err != nil {
+				go l.adapter.NotifyStatus("update_docp_error", pkg.TransactionEventClose, "failed save update target", ctx)
+				l.chanErrors <- dto.ManagerChanErrors{From: "updateAgent", Priority: dto.ErrLevelHigh, Err: err}
+				return err
+			}
+
 			if err := l.adapter.UpdateAgent(version); err != nil {
 				go l.adapter.NotifyStatus("update_docp_error", pkg.TransactionEventClose, "failed update agent version", ctx)
 				l.chanErrors <- dto.ManagerChanErrors{From: "updateAgent", Priority: dto.ErrLevelHigh, Err: err}
@@ -543,16 +624,17 @@ func (l *ManagerOperator) UpdateAgent(version string) error {
 
 // installAgentDatadog execute call to api docp agent
 // to install datadog agent
-func (l *ManagerOperator) installAgentDatadog(ddApiKey, ddSite string) {
-	l.logger.Debug("install agent datadog", "trace", "docp-agent-os-instance.manager_operator.installAgentDatadog", "ddApiKey", ddApiKey, "ddSite", ddSite)
+func (l *ManagerOperator) installAgentDatadog(ddApiKey, ddSite, version string) {
+	l.logger.Debug("install agent datadog", "trace", "docp-agent-os-instance.manager_operator.installAgentDatadog", "ddApiKey", ddApiKey, "ddSite", ddSite, "version", version)
 	defer l.wg.Done()
+	defer l.tasks.Track("installAgentDatadog")()
 	status, err := l.adapter.Status("datadog")
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "installAgentDatadog", Priority: dto.ErrLevelHigh, Err: err}
 		return
 	}
 	if status != "active" {
-		result, err := l.adapter.DocpAgentApiInstallDatadog(ddApiKey, ddSite)
+		result, err := l.adapter.DocpAgentApiInstallDatadog(ddApiKey, ddSite, version)
 		if err != nil {
 			l.chanErrors <- dto.ManagerChanErrors{From: "installAgentDatadog", Priority: dto.ErrLevelHigh, Err: err}
 			return
@@ -566,8 +648,9 @@ func (l *ManagerOperator) installAgentDatadog(ddApiKey, ddSite string) {
 func (l *ManagerOperator) handlerUpdateAgentDatadogAfterInstall(files []dto.ManagerStateActionFiles) {
 	l.logger.Debug("install and update agent datadog", "trace", "docp-agent-os-instance.manager_operator.installAndUpdateAgentDatadog")
 	defer l.wg.Done()
+	defer l.tasks.Track("handlerUpdateAgentDatadogAfterInstall")()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	ctx, cancel := context.WithTimeout(l.ctx, 10*time.Minute)
 	defer cancel()
 
 	ticker := time.NewTicker(20 * time.Second)
@@ -577,6 +660,10 @@ loopinstalldatadog:
 	for {
 		select {
 		case <-ctx.Done():
+			if l.ctx.Err() != nil {
+				l.tasks.Interrupt("handlerUpdateAgentDatadogAfterInstall")
+				return
+			}
 			l.logger.Error("Context timeout or cancellation reached")
 			l.chanErrors <- dto.ManagerChanErrors{From: "installAndUpdateAgentDatadog", Priority: dto.ErrLevelMedium, Err: fmt.Errorf("installation process timed out")}
 			return
@@ -598,7 +685,12 @@ loopinstalldatadog:
 	}
 
 	// delay for datadog agent configure all files terminated
-	time.Sleep(time.Minute * 1)
+	select {
+	case <-time.After(time.Minute * 1):
+	case <-l.ctx.Done():
+		l.tasks.Interrupt("handlerUpdateAgentDatadogAfterInstall")
+		return
+	}
 
 	// update configurations datadog
 	for _, fls := range files {
@@ -609,19 +701,21 @@ loopinstalldatadog:
 		}
 
 		l.wg.Add(1)
-		go l.updateAgentDatadog(flsBytes)
+		l.updateAgentDatadog(flsBytes)
 	}
 }
 
 func (l *ManagerOperator) handlerInstallDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries string) {
 	l.logger.Debug("handle install datadog agent with APM single step", "trace", "docp-agent-os-instance.manager_operator.handlerInstallDatadogWithApmSingleStep", "ddApiKey", ddApiKey, "ddSite", ddSite, "ddApmInstrumentationEnabled", ddApmInstrumentationEnabled, "ddEnv", ddEnv, "ddApmInstrumentationLibraries", ddApmInstrumentationLibraries)
 	defer l.wg.Done()
+	defer l.tasks.Track("handlerInstallDatadogWithApmSingleStep")()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(l.ctx, 5*time.Minute)
 	defer cancel()
 
+	// uninstall finish before polling for install with apm
 	l.wg.Add(1)
-	go l.uninstallAgentDatadog()
+	l.uninstallAgentDatadog()
 
 	ticker := time.NewTicker(20 * time.Second)
 	defer ticker.Stop()
@@ -629,6 +723,10 @@ func (l *ManagerOperator) handlerInstallDatadogWithApmSingleStep(ddApiKey, ddSit
 	for {
 		select {
 		case <-ctx.Done():
+			if l.ctx.Err() != nil {
+				l.tasks.Interrupt("handlerInstallDatadogWithApmSingleStep")
+				return
+			}
 			l.logger.Error("Context timeout or cancellation reached")
 			l.chanErrors <- dto.ManagerChanErrors{From: "handlerInstallDatadogWithApmSingleStep", Priority: dto.ErrLevelMedium, Err: fmt.Errorf("installation process timed out")}
 			return
@@ -668,6 +766,7 @@ func (l *ManagerOperator) handlerInstallDatadogWithApmSingleStep(ddApiKey, ddSit
 func (l *ManagerOperator) installAgentDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries string) {
 	l.logger.Debug("install agent datadog with apm single step", "trace", "docp-agent-os-instance.manager_operator.installAgentDatadogWithApmSingleStep", "ddApiKey", ddApiKey, "ddSite", ddSite, "ddApmInstrumentationEnabled", ddApmInstrumentationEnabled, "ddEnv", ddEnv, "ddApmInstrumentationLibraries", ddApmInstrumentationLibraries)
 	defer l.wg.Done()
+	defer l.tasks.Track("installAgentDatadogWithApmSingleStep")()
 	status, err := l.adapter.Status("datadog")
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "installAgentDatadogWithApmSingleStep", Priority: dto.ErrLevelMedium, Err: err}
@@ -689,7 +788,7 @@ func (l *ManagerOperator) installAgentDatadogWithApmSingleStep(ddApiKey, ddSite,
 		return
 	} else if status == "active" && !alreadyTracer {
 		l.wg.Add(1)
-		go l.handlerInstallDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries)
+		l.handlerInstallDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries)
 		return
 	}
 }
@@ -699,6 +798,7 @@ func (l *ManagerOperator) installAgentDatadogWithApmSingleStep(ddApiKey, ddSite,
 func (l *ManagerOperator) installDatadogTracerWithTracingLibrary(ddApiKey, ddSite, language, pathTracer, version string) {
 	l.logger.Debug("install datadog tracer", "trace", "docp-agent-os-instance.manager_operator.installDatadogTracerWithTracingLibrary", "ddApiKey", ddApiKey, "ddSite", ddSite, "language", language, "pathTracer", pathTracer, "version", version)
 	defer l.wg.Done()
+	defer l.tasks.Track("installDatadogTracerWithTracingLibrary")()
 	status, err := l.adapter.Status("datadog")
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "installDatadogTracerWithTracingLibrary", Priority: dto.ErrLevelMedium, Err: err}
@@ -720,8 +820,12 @@ func (l *ManagerOperator) installDatadogTracerWithTracingLibrary(ddApiKey, ddSit
 			l.chanErrors <- dto.ManagerChanErrors{From: "installDatadogTracerWithTracingLibrary", Priority: dto.ErrLevelMedium, Err: err}
 			return
 		}
-		l.wg.Add(1)
-		go l.adapter.DocpAgentApiInstallDatadogWithApmTracingLibrary(ddApiKey, ddSite, language, pathTracer, version)
+		result, err := l.adapter.DocpAgentApiInstallDatadogWithApmTracingLibrary(ddApiKey, ddSite, language, pathTracer, version)
+		if err != nil {
+			l.chanErrors <- dto.ManagerChanErrors{From: "installDatadogTracerWithTracingLibrary", Priority: dto.ErrLevelMedium, Err: err}
+			return
+		}
+		l.chanResultsApi <- result
 		return
 	}
 }
@@ -731,6 +835,7 @@ func (l *ManagerOperator) installDatadogTracerWithTracingLibrary(ddApiKey, ddSit
 func (l *ManagerOperator) uninstallAgentDatadog() {
 	l.logger.Debug("uninstall agent datadog", "trace", "docp-agent-os-instance.manager_operator.uninstallAgentDatadog")
 	defer l.wg.Done()
+	defer l.tasks.Track("uninstallAgentDatadog")()
 
 	result, err := l.adapter.DocpAgentApiUninstallDatadog()
 	if err != nil {
@@ -750,6 +855,7 @@ func (l *ManagerOperator) uninstallAgentDatadog() {
 func (l *ManagerOperator) updateAgentDatadog(content []byte) {
 	l.logger.Debug("update agent datadog", "trace", "docp-agent-os-instance.manager_operator.updateAgentDatadog", "content", string(content))
 	defer l.wg.Done()
+	defer l.tasks.Track("updateAgentDatadog")()
 
 	newHash := utils.GenerateMd5Hash(content)
 	existsDatadogHash := l.adapter.GetStore("update.datadog.hash")
@@ -785,9 +891,7 @@ func (l *ManagerOperator) updateAgentDatadog(content []byte) {
 func (l *ManagerOperator) autoUninstallWithOtherVendors() {
 	l.logger.Debug("auto uninstall with other vendors the manager", "trace", "docp-agent-os-instance.manager_operator.autoUninstallWithOtherVendors")
 	defer l.wg.Done()
-
-	removeDatadog := false
-	vendorsRemoved := make(map[string]bool)
+	defer l.tasks.Track("autoUninstallWithOtherVendors")()
 
 	allVendors, err := l.adapter.GetRemoveOtherVendors()
 	if err != nil {
@@ -795,58 +899,67 @@ func (l *ManagerOperator) autoUninstallWithOtherVendors() {
 		return
 	}
 
-	removeOtherVendors := utils.RemoveItemFromSlice(allVendors, "all")
+	detectedVendors, err := l.adapter.GetDetectedVendors()
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "autoUninstallWithOtherVendors", Priority: dto.ErrLevelMedium, Err: err}
+		return
+	}
+	removeOtherVendors := libutils.ExpandRemoveVendors(allVendors, detectedVendors)
 
+	transaction := libutils.NewTransactionStatus()
+	ctx := context.WithValue(context.Background(), libdto.ContextTransactionStatus, transaction)
+
+	go l.adapter.NotifyStatus("uninstall_docp_received", pkg.TransactionEventOpen, "uninstall docp received", ctx)
+	time.Sleep(l.delay)
+
+	vendorsCtx := make(map[string]context.Context)
 	for _, vendor := range removeOtherVendors {
-		if vendor == "datadog" {
-			removeDatadog = true
+		vendorsCtx[vendor] = context.WithValue(context.Background(), libdto.ContextTransactionStatus, libutils.NewTransactionStatus())
+		go l.adapter.NotifyStatus("uninstall_vendor_received", pkg.TransactionEventOpen, fmt.Sprintf("uninstall vendor %s received", vendor), vendorsCtx[vendor])
+		time.Sleep(l.delay)
+	}
+	// closeVendors execute close of transactions of vendors not removed
+	closeVendors := func(vendors []string, reason string) {
+		for _, vendor := range vendors {
+			go l.adapter.NotifyStatus("uninstall_vendor_error", pkg.TransactionEventClose, fmt.Sprintf("failed uninstall vendor %s: %s", vendor, reason), vendorsCtx[vendor])
+			time.Sleep(l.delay)
 		}
 	}
 
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-loopuninstall:
-	for {
-		select {
-		case <-ticker.C:
-			l.logger.Debug("auto uninstall with other vendors the manager", "vendors", removeOtherVendors)
-			// validate and remove agent datadog
-			if removeDatadog {
-
-				statusDatadog, err := l.adapter.Status("datadog")
-				if err != nil {
-					l.chanErrors <- dto.ManagerChanErrors{From: "autoUninstallWithOtherVendors", Priority: dto.ErrLevelMedium, Err: err}
-					return
-				}
-
-				l.logger.Debug("auto uninstall with other vendors the manager", "statusDatadog", statusDatadog)
-				if statusDatadog != "active" {
-					ok := vendorsRemoved["datadog"]
-					if !ok {
-						vendorsRemoved["datadog"] = false
-					}
-					vendorsRemoved["datadog"] = true
-				}
-			}
+	// execute uninstall recipe for each vendor,
+	// datadog is uninstalled by datadog actions
+	for index, vendor := range removeOtherVendors {
+		if vendor == "datadog" {
+			continue
+		}
+		go l.adapter.NotifyStatus("uninstall_vendor_processing", pkg.TransactionEventUpdate, fmt.Sprintf("uninstall vendor %s processing", vendor), vendorsCtx[vendor])
+		time.Sleep(l.delay)
+		if err := l.adapter.UninstallOtherVendor(vendor); err != nil {
+			closeVendors([]string{vendor}, err.Error())
+			closeVendors(slices.Delete(slices.Clone(removeOtherVendors), index, index+1), "canceled, vendor "+vendor+" not removed")
+			go l.adapter.NotifyStatus("uninstall_docp_error", pkg.TransactionEventClose, fmt.Sprintf("failed uninstall docp, vendor %s not removed", vendor), ctx)
+			l.chanErrors <- dto.ManagerChanErrors{From: "autoUninstallWithOtherVendors", Priority: dto.ErrLevelHigh, Err: err}
+			return
+		}
+	}
 
-			l.logger.Debug("auto uninstall with other vendors the manager", "lenght removeOtherVendors", len(removeOtherVendors), "lenght vendorsRemoved", len(vendorsRemoved))
-			// validate all vendors uninstalled
-			if len(removeOtherVendors) == len(vendorsRemoved) {
-				break loopuninstall
-			}
-		case <-l.done:
-			close(l.done)
+	// validate vendors not discovered on host anymore
+	pendingVendors, err := libutils.WaitVendorsRemoved(l.ctx, removeOtherVendors, l.adapter.GetDetectedVendors, time.Minute, l.vendorUninstallTimeout, func(vendor string) {
+		go l.adapter.NotifyStatus("uninstall_vendor_completed", pkg.TransactionEventClose, fmt.Sprintf("uninstall vendor %s completed", vendor), vendorsCtx[vendor])
+		time.Sleep(l.delay)
+	})
+	if err != nil {
+		if l.ctx.Err() != nil {
+			l.tasks.Interrupt("autoUninstallWithOtherVendors")
 			return
 		}
+		closeVendors(pendingVendors, "timeout")
+		go l.adapter.NotifyStatus("uninstall_docp_error", pkg.TransactionEventClose, fmt.Sprintf("failed uninstall docp, vendors not removed: %s", strings.Join(pendingVendors, ",")), ctx)
+		l.chanErrors <- dto.ManagerChanErrors{From: "autoUninstallWithOtherVendors", Priority: dto.ErrLevelHigh, Err: err}
+		return
 	}
 
 	// execute autouninstall for docp agent
-	transaction := libutils.NewTransactionStatus()
-	ctx := context.WithValue(context.Background(), libdto.ContextTransactionStatus, transaction)
-
-	go l.adapter.NotifyStatus("uninstall_docp_received", pkg.TransactionEventOpen, "uninstall docp received", ctx)
-	time.Sleep(l.delay)
-
 	go l.adapter.NotifyStatus("uninstall_docp_processing", pkg.TransactionEventUpdate, "uninstall docp processing", ctx)
 	time.Sleep(l.delay)
 	if err := l.adapter.AutoUninstall(); err != nil {
@@ -861,6 +974,8 @@ loopuninstall:
 // autoUninstallAgent execute auto uninstall the manager
 func (l *ManagerOperator) autoUninstall() {
 	l.logger.Debug("auto uninstall the manager", "trace", "docp-agent-os-instance.manager_operator.autoUninstall")
+	defer l.wg.Done()
+	defer l.tasks.Track("autoUninstall")()
 
 	// validate if exists other vendors and execute autoUninstallWithOtherVendors
 	existsOtherVendor, err := l.adapter.ExisteOtherVendors()
@@ -869,7 +984,7 @@ func (l *ManagerOperator) autoUninstall() {
 	}
 	if existsOtherVendor {
 		l.wg.Add(1)
-		go l.autoUninstallWithOtherVendors()
+		l.autoUninstallWithOtherVendors()
 	} else {
 		transaction := libutils.NewTransactionStatus()
 		ctx := context.WithValue(context.Background(), libdto.ContextTransactionStatus, transaction)
@@ -877,8 +992,6 @@ func (l *ManagerOperator) autoUninstall() {
 		go l.adapter.NotifyStatus("uninstall_docp_received", pkg.TransactionEventOpen, "uninstall docp received", ctx)
 		time.Sleep(l.delay)
 
-		defer l.wg.Done()
-
 		go l.adapter.NotifyStatus("uninstall_docp_processing", pkg.TransactionEventUpdate, "uninstall docp processing", ctx)
 		time.Sleep(l.delay)
 
@@ -893,155 +1006,179 @@ func (l *ManagerOperator) autoUninstall() {
 }
 
 // consumerResultsFromApiDocpAgent execute consume the result
-// from api docp agent
+// from api docp agent, running until exit of process for
+// actions in shutdown not block on send of results
 func (l *ManagerOperator) consumerResultsFromApiDocpAgent() {
 	l.logger.Debug("consumer results from api docp agent", "trace", "docp-agent-os-instance.manager_operator.consumerResultsFromApiDocpAgent")
-	defer l.wg.Done()
 	for res := range l.chanResultsApi {
 		l.logger.Debug("consumer results from api docp agent", "trace", "docp-agent-os-instance.manager_operator.consumerResultsFromApiDocpAgent", "result", string(res))
 	}
 }
 
 // consumeActionsDocpAgent execute consume the actions the agent
-func (l *ManagerOperator) consumeActionsDocpAgent() {
+func (l *ManagerOperator) consumeActionsDocpAgent(ctx context.Context) error {
 	l.logger.Debug("consume actions docp agent", "trace", "docp-agent-os-instance.manager_operator.consumeActionsDocpAgent")
-	defer l.wg.Done()
-	for act := range l.chanDocpAgent {
-		if act.Action == "update" {
-			l.wg.Add(1)
-			go l.UpdateAgent(act.Version)
-		} else if act.Action == "uninstall" {
-			l.wg.Add(1)
-			go l.autoUninstall()
-		} else {
-			continue
+	for {
+		select {
+		case act := <-l.chanDocpAgent:
+			if act.Action == "update" {
+				l.submitSignalUpdateAgent()
+			} else if act.Action == "rollback" {
+				if _, err := l.rollbackAgent(act.Version); err != nil {
+					l.chanErrors <- dto.ManagerChanErrors{From: "consumeActionsDocpAgent", Priority: dto.ErrLevelMedium, Err: err}
+				}
+			} else if act.Action == "uninstall" {
+				l.submitAction(pkg.ACTION_COMPONENT_DOCP_AGENT, pkg.ACTION_UNINSTALL, func() {
+					l.wg.Add(1)
+					l.autoUninstall()
+				})
+			}
+		case <-ctx.Done():
+			return nil
 		}
 	}
 }
 
 // consumerActionsDatadog execute consume the actions
 // for datadog agent
-func (l *ManagerOperator) consumerActionsDatadog() {
+func (l *ManagerOperator) consumerActionsDatadog(ctx context.Context) error {
 	l.logger.Debug("consumer actions datadog", "trace", "docp-agent-os-instance.manager_operator.consumerActionsDatadog")
-	defer l.wg.Done()
-
-	// get actions for datadog
-	for act := range l.chanDocpAgentDatadog {
-		// verify if datadog already installed
-		datadogAlreadyInstalled, err := l.adapter.AlreadyInstalled("datadog")
-		if err != nil {
-			l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
-			return
+	for {
+		select {
+		case act := <-l.chanDocpAgentDatadog:
+			l.submitAction(datadogActionComponent(act), act.Action, func() {
+				l.handleActionDatadog(act)
+			})
+		case <-ctx.Done():
+			return nil
 		}
-		l.logger.Debug("consumer actions datadog", "trace", "docp-agent-os-instance.manager_operator.consumerActionsDatadog", "action", act)
-		// action update configurations datadog
-		if act.Action == "update" {
-			for _, fls := range act.Files {
-				flsBytes, err := l.marshaller(&fls)
-				if err != nil {
-					l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
-					return
-				}
+	}
+}
 
-				// if agent already installed execute update configurations
-				if datadogAlreadyInstalled {
-					l.wg.Add(1)
-					go l.updateAgentDatadog(flsBytes)
-				}
+// handleActionDatadog execute action for datadog agent, called
+// by queue of component so actions run one at time
+func (l *ManagerOperator) handleActionDatadog(act dto.ManagerStateAction) {
+	// verify if datadog already installed
+	datadogAlreadyInstalled, err := l.adapter.AlreadyInstalled("datadog")
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+		return
+	}
+	l.logger.Debug("consumer actions datadog", "trace", "docp-agent-os-instance.manager_operator.consumerActionsDatadog", "action", act)
+	// action update configurations datadog
+	if act.Action == "update" {
+		for _, fls := range act.Files {
+			flsBytes, err := l.marshaller(&fls)
+			if err != nil {
+				l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+				return
+			}
+
+			// if agent already installed execute update configurations
+			if datadogAlreadyInstalled {
+				l.wg.Add(1)
+				l.updateAgentDatadog(flsBytes)
 			}
 		}
+	}
 
-		l.logger.Debug("consumer actions datadog", "trace", "docp-agent-os-instance.manager_operator.consumerActionsDatadog", "datadogAlreadyInstalled", datadogAlreadyInstalled)
+	l.logger.Debug("consumer actions datadog", "trace", "docp-agent-os-instance.manager_operator.consumerActionsDatadog", "datadogAlreadyInstalled", datadogAlreadyInstalled)
+	if err != nil {
+		l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+	}
+	if act.Action == "install" {
+		ddApiKey, ddSite, err := l.extractDDApiKeyAndDDSiteFromEnvs(act.Envs)
 		if err != nil {
 			l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+			return
 		}
-		if act.Action == "install" {
-			ddApiKey, ddSite, err := l.extractDDApiKeyAndDDSiteFromEnvs(act.Envs)
-			if err != nil {
-				l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
-				return
-			}
-			if act.Component == "tracer" {
-				if act.Mode == "single_step" {
-					if datadogAlreadyInstalled {
-						ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries, err := l.extractApmSingleStepEnvs(act.ComponentEnvs)
-						if err != nil {
-							l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
-							return
-						}
-						l.wg.Add(1)
-						go l.handlerInstallDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries)
-					} else {
-						l.wg.Add(1)
-						ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries, err := l.extractApmSingleStepEnvs(act.ComponentEnvs)
-						if err != nil {
-							l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
-							return
-						}
-						go l.installAgentDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries)
+		if act.Component == "tracer" {
+			if act.Mode == "single_step" {
+				if datadogAlreadyInstalled {
+					ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries, err := l.extractApmSingleStepEnvs(act.ComponentEnvs)
+					if err != nil {
+						l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+						return
 					}
-				} else if act.Mode == "tracing_library" {
-					if datadogAlreadyInstalled {
-						language, pathTracer, version, err := l.extractApmTracingLibrayEnvs(act.ComponentEnvs)
-						if err != nil {
-							l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
-							return
-						}
-						l.wg.Add(1)
-						go l.installDatadogTracerWithTracingLibrary(ddApiKey, ddSite, language, pathTracer, version)
+					l.wg.Add(1)
+					l.handlerInstallDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries)
+				} else {
+					ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries, err := l.extractApmSingleStepEnvs(act.ComponentEnvs)
+					if err != nil {
+						l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+						return
 					}
+					l.wg.Add(1)
+					l.installAgentDatadogWithApmSingleStep(ddApiKey, ddSite, ddApmInstrumentationEnabled, ddEnv, ddApmInstrumentationLibraries)
 				}
-			} else if act.Component == "agent" {
-				if !datadogAlreadyInstalled {
-					if len(act.Files) > 0 {
-						l.wg.Add(2)
-						go l.installAgentDatadog(ddApiKey, ddSite)
-						go l.handlerUpdateAgentDatadogAfterInstall(act.Files)
-					} else {
-						l.wg.Add(1)
-						go l.installAgentDatadog(ddApiKey, ddSite)
+			} else if act.Mode == "tracing_library" {
+				if datadogAlreadyInstalled {
+					language, pathTracer, version, err := l.extractApmTracingLibrayEnvs(act.ComponentEnvs)
+					if err != nil {
+						l.chanErrors <- dto.ManagerChanErrors{From: "consumerActionsDatadog", Priority: dto.ErrLevelMedium, Err: err}
+						return
 					}
+					l.wg.Add(1)
+					l.installDatadogTracerWithTracingLibrary(ddApiKey, ddSite, language, pathTracer, version)
 				}
 			}
-
-		} else if act.Action == "uninstall" {
-			if datadogAlreadyInstalled {
+		} else if act.Component == "agent" {
+			if !datadogAlreadyInstalled {
 				l.wg.Add(1)
-				go l.uninstallAgentDatadog()
+				l.installAgentDatadog(ddApiKey, ddSite, act.Version)
+				if len(act.Files) > 0 {
+					l.wg.Add(1)
+					l.handlerUpdateAgentDatadogAfterInstall(act.Files)
+				}
+			} else if _, pinned := libutils.ParseDatadogVersionPin(act.Version); pinned {
+				// new signal retry the upgrade without wait backoff
+				l.resetDatadogUpgradeFailure()
+				l.submitUpgradeAgentDatadog(act.Version)
 			}
-		} else {
-			continue
 		}
-	}
-}
 
-func (l *ManagerOperator) consumeAllActions() {
-	l.logger.Debug("consume all actions", "trace", "docp-agent-os-instance.manager_operator.consumeAllActions")
-	defer l.wg.Done()
-	l.wg.Add(2)
-	go l.consumeActionsDocpAgent()
-	go l.consumerActionsDatadog()
+	} else if act.Action == "uninstall" {
+		if datadogAlreadyInstalled {
+			l.wg.Add(1)
+			l.uninstallAgentDatadog()
+		}
+	} else if act.Action == pkg.ACTION_INTEGRATIONS {
+		if datadogAlreadyInstalled {
+			l.wg.Add(1)
+			l.applyIntegrationsDatadog(act.Integrations)
+		}
+	}
 }
 
 // managerActions execut segment actions by type
 func (l *ManagerOperator) managerActions(arrActions []dto.ManagerStateAction) {
 	l.logger.Debug("manager actions", "trace", "docp-agent-os-instance.manager_operator.managerActions", "arrActions", arrActions)
+	defer l.wg.Done()
+	defer l.tasks.Track("managerActions")()
 	for _, act := range arrActions {
+		var chanAction chan dto.ManagerStateAction
 		switch act.Type {
 		case "docp-agent":
-			l.chanDocpAgent <- act
+			chanAction = l.chanDocpAgent
 		case "datadog":
-			l.chanDocpAgentDatadog <- act
+			chanAction = l.chanDocpAgentDatadog
+		default:
+			continue
+		}
+		select {
+		case chanAction <- act:
+		case <-l.ctx.Done():
+			l.tasks.Interrupt("managerActions")
+			return
 		}
 	}
-	defer l.wg.Done()
 }
 
 // GetActions get action for execute in manager
 func (l *ManagerOperator) GetActions() ([]byte, error) {
 	l.logger.Debug("get actions", "trace", "docp-agent-os-instance.manager_operator.GetActions")
 	actions, err := l.adapter.GetState()
-	l.logger.Debug("get actions", "trace", "docp-agent-os-instance.manager_operator.GetActions", "actions", string(actions))
+	l.logger.Debug("get actions", "trace", "docp-agent-os-instance.manager_operator.GetActions", "size", len(actions))
 	if err != nil {
 		return nil, err
 	}
@@ -1051,36 +1188,32 @@ func (l *ManagerOperator) GetActions() ([]byte, error) {
 // Stop execute stoping the ManagerOperator
 func (l *ManagerOperator) Stop() {
 	l.logger.Debug("stop", "trace", "docp-agent-os-instance.manager_operator.Stop")
-	l.done <- struct{}{}
+	l.cancel()
 }
 
-// AutoUpdateAgentVersion execute auto update agent version
+// AutoUpdateAgentVersion execute auto update agent version for
+// latest or constraint of signal, with version allowed to ring
+// of host in rollout
 func (l *ManagerOperator) AutoUpdateAgentVersion() error {
-	received, err := l.adapter.GetStateReceived()
+	docpAgent, ring, err := l.planAgentUpdate()
 	if err != nil {
 		return err
 	}
-	l.logger.Info("auto update agent version", "received", string(received))
-	applyVersion, err := l.adapter.GetAgentVersionFromSignalBytes(received)
-	if err != nil {
-		return err
+	l.logger.Info("auto update agent version", "applyVersion", docpAgent.Version, "ring", ring)
+	// exact version is applied by action of signal
+	if libutils.IsExactVersion(docpAgent.Version) && len(docpAgent.Pin) == 0 {
+		l.logger.Info("auto update agent version not latest", "applyVersion", docpAgent.Version)
+		return nil
 	}
-	l.logger.Info("auto update agent version", "applyVersion", applyVersion)
-	if applyVersion != "latest" {
-		l.logger.Info("auto update agent version not latest", "applyVersion", applyVersion)
+	if len(ring.Version) == 0 {
+		l.logger.Info("auto update agent version not allowed for ring", "ring", ring.Ring, "bucket", ring.Bucket, "hold", ring.Hold)
 		return nil
 	}
-	//fetch agent versions
-	agentVersions, err := l.adapter.FetchAgentVersions()
-	if err != nil {
-		return err
+	if agentVersion, err := l.adapter.GetAgentVersion(); err == nil && agentVersion == ring.Version {
+		return nil
 	}
-	l.logger.Info("auto update agent version", "agentVersions", agentVersions)
-	latestVersion := agentVersions.LatestVersion
-	l.logger.Info("auto update agent version", "latestVersion", latestVersion)
-	if len(latestVersion) > 0 {
-		l.wg.Add(1)
-		go l.UpdateAgent(latestVersion)
+	if l.allowDisruptiveActions() {
+		l.submitSignalUpdateAgent()
 	}
 
 	return nil
@@ -1112,6 +1245,7 @@ func (l *ManagerOperator) verifyChangeMetadata(metadata []byte) bool {
 func (l *ManagerOperator) compareState() {
 	l.logger.Debug("compare state", "trace", "docp-agent-os-instance.manager_operator.compareState")
 	defer l.wg.Done()
+	defer l.tasks.Track("compareState")()
 	equals, err := l.adapter.CompareState()
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "compareState", Priority: dto.ErrLevelMedium, Err: err}
@@ -1130,6 +1264,7 @@ func (l *ManagerOperator) compareState() {
 func (l *ManagerOperator) validateState() {
 	l.logger.Debug("validate state", "trace", "docp-agent-os-instance.manager_operator.validateState")
 	defer l.wg.Done()
+	defer l.tasks.Track("validateState")()
 	if err := l.adapter.Validate(); err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "validateState", Priority: dto.ErrLevelMedium, Err: err}
 		return
@@ -1141,6 +1276,7 @@ func (l *ManagerOperator) validateState() {
 func (l *ManagerOperator) validateDocpAgentInstalled() {
 	l.logger.Debug("validate if docp agent is installed", "trace", "docp-agent-os-instance.manager_operator.validateDocpAgentInstalled")
 	defer l.wg.Done()
+	defer l.tasks.Track("validateDocpAgentInstalled")()
 	installed, err := l.adapter.ValidateDocpInstalled()
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "validateDocpAgentInstalled", Priority: dto.ErrLevelMedium, Err: err}
@@ -1161,6 +1297,7 @@ func (l *ManagerOperator) validateDocpAgentInstalled() {
 func (l *ManagerOperator) validateDatadogAgentInstalled() {
 	l.logger.Debug("validate if datadog agent is installed", "trace", "docp-agent-os-instance.manager_operator.validateDatadogAgentInstalled")
 	defer l.wg.Done()
+	defer l.tasks.Track("validateDatadogAgentInstalled")()
 	installed, err := l.adapter.ValidateDatadogInstalled()
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "validateDatadogAgentInstalled", Priority: dto.ErrLevelMedium, Err: err}
@@ -1181,6 +1318,7 @@ func (l *ManagerOperator) validateDatadogAgentInstalled() {
 func (l *ManagerOperator) validateDatadogAgentUpdateConfigs() {
 	l.logger.Debug("validate if datadog agent is update configs", "trace", "docp-agent-os-instance.manager_operator.validateDatadogAgentUpdateConfigs")
 	defer l.wg.Done()
+	defer l.tasks.Track("validateDatadogAgentUpdateConfigs")()
 	equals, err := l.adapter.CompareState()
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "validateDatadogAgentUpdateConfigs", Priority: dto.ErrLevelMedium, Err: err}
@@ -1195,6 +1333,7 @@ func (l *ManagerOperator) validateDatadogAgentUpdateConfigs() {
 func (l *ManagerOperator) collectGetState() {
 	l.logger.Debug("collect get actions", "trace", "docp-agent-os-instance.manager_operator.collectGetActions")
 	defer l.wg.Done()
+	defer l.tasks.Track("collectGetState")()
 	err := l.GetSignalFromStateCheck()
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "collectGetState", Priority: dto.ErrLevelMedium, Err: err}
@@ -1207,8 +1346,23 @@ func (l *ManagerOperator) collectGetState() {
 func (l *ManagerOperator) collectGetActions() {
 	l.logger.Debug("collect get actions", "trace", "docp-agent-os-instance.manager_operator.collectGetActions")
 	defer l.wg.Done()
+	defer l.tasks.Track("collectGetActions")()
+	if l.paused.Load() {
+		l.logger.Debug("reconciliation paused", "trace", "docp-agent-os-instance.manager_operator.collectGetActions")
+		return
+	}
+	// disruptive actions outside of window are not registered as
+	// executed, they are returned again when window open
+	allowed, urgent, next := l.maintenanceWindow(time.Now())
 	var arrActions []dto.ManagerStateAction
-	actions, err := l.GetActions()
+	actions, deferred, err := l.adapter.GetStateDeferring(func(action dto.StateAction) bool {
+		return !allowed && libutils.IsDisruptiveAction(action.Action)
+	})
+	if len(deferred) > 0 {
+		l.notifyDeferral(next)
+	} else if allowed {
+		l.releaseDeferral(urgent)
+	}
 	if err != nil {
 		l.chanErrors <- dto.ManagerChanErrors{From: "collectGetActions", Priority: dto.ErrLevelMedium, Err: err}
 		return
@@ -1222,66 +1376,79 @@ func (l *ManagerOperator) collectGetActions() {
 }
 
 // periodicGetActions execute periodic get actions the state
-func (l *ManagerOperator) periodicTasks() {
+func (l *ManagerOperator) periodicTasks(ctx context.Context) error {
 	l.logger.Debug("periodic tasks", "trace", "docp-agent-os-instance.manager_operator.periodicTasks")
-	defer l.wg.Done()
 
-	ticker := time.NewTicker(20 * time.Second)
+	ticker := time.NewTicker(l.runtimeConfig().Intervals.Tasks)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			l.wg.Add(3)
+			// diagnostics are collected even with reconciliation paused
+			l.wg.Add(1)
+			go l.collectDiagnosticsRequest()
+			if l.paused.Load() {
+				l.logger.Debug("reconciliation paused", "trace", "docp-agent-os-instance.manager_operator.periodicTasks")
+				continue
+			}
+			l.wg.Add(4)
 			go l.collectGetActions()
 			go l.validateState()
 			go l.compareState()
-		case <-l.done:
-			close(l.done)
-			return
+			go l.checkDatadogVersionDrift()
+		case <-l.watchIntervals():
+			ticker.Reset(l.runtimeConfig().Intervals.Tasks)
+		case <-ctx.Done():
+			return nil
 		}
 	}
 }
 
 // periodicAutoUpdate execute periodic auto update
-func (l *ManagerOperator) periodicAutoUpdate() {
+func (l *ManagerOperator) periodicAutoUpdate(ctx context.Context) error {
 	l.logger.Debug("periodic auto update", "trace", "docp-agent-os-instance.manager_operator.periodicAutoUpdate")
-	defer l.wg.Done()
 
-	ticker := time.NewTicker(12 * time.Hour)
+	// ring of host is reported in metadata before first auto update
+	if _, _, err := l.planAgentUpdate(); err != nil {
+		l.logger.Debug("plan agent update not resolved", "trace", "docp-agent-os-instance.manager_operator.periodicAutoUpdate", "error", err.Error())
+	}
+
+	ticker := time.NewTicker(l.runtimeConfig().Intervals.AutoUpdate)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			l.wg.Add(1)
 			if err := l.AutoUpdateAgentVersion(); err != nil {
 				l.logger.Error("error executing auto update agent version", "error", err.Error())
 			}
-		case <-l.done:
-			close(l.done)
-			return
+		case <-l.watchIntervals():
+			ticker.Reset(l.runtimeConfig().Intervals.AutoUpdate)
+		case <-ctx.Done():
+			return nil
 		}
 	}
 }
 
 // periodicHandlerMetadata execute periodic handler metadata
-func (l *ManagerOperator) periodicHandlerMetadata() {
+func (l *ManagerOperator) periodicHandlerMetadata(ctx context.Context) error {
 	l.logger.Debug("periodic handler metadata", "trace", "docp-agent-os-instance.manager_operator.periodicHandlerMetadata")
-	defer l.wg.Done()
 
-	ticker := time.NewTicker(12 * time.Hour)
+	ticker := time.NewTicker(l.runtimeConfig().Intervals.Metadata)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			l.wg.Add(2)
-			go l.getMetadata()
+			// metadata collected is sent by getMetadata started in Run
+			l.adapter.CollectNow()
+			l.wg.Add(1)
 			go l.handleMetadata()
-		case <-l.done:
-			close(l.done)
-			return
+		case <-l.watchIntervals():
+			ticker.Reset(l.runtimeConfig().Intervals.Metadata)
+		case <-ctx.Done():
+			return nil
 		}
 	}
 }
@@ -1301,7 +1468,8 @@ func (l *ManagerOperator) persistLastSignalHashToStore() error {
 	return nil
 }
 
-// Run execute loop the manager
+// Run execute loop the manager until stop by signal, by service
+// manager of os or by failure of task, then execute shutdown
 func (l *ManagerOperator) Run() error {
 	if err := l.Setup(); err != nil {
 		return err
@@ -1309,34 +1477,89 @@ func (l *ManagerOperator) Run() error {
 	l.logger.Info("execute manager")
 	l.logger.Debug("execute running", "trace", "docp-agent-os-instance.manager_operator.Run")
 	defer l.logger.Close()
-	l.wg.Add(14)
-	go l.persistLastSignalHashToStore()
-	go l.comunicateSCM()
-	go l.Profiling()
-	go l.Start()
+	ctx, stop := signal.NotifyContext(l.ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	supervisor := libutils.NewSupervisor(ctx)
+
 	go l.consumerErrors()
 	go l.consumerResultsFromApiDocpAgent()
+	l.reportInterruptedTasks()
+	if err := l.persistLastSignalHashToStore(); err != nil {
+		l.logger.Debug("last signal hash not persisted", "trace", "docp-agent-os-instance.manager_operator.Run", "error", err.Error())
+	}
+
+	// scm of windows wait shutdown of manager before report service stopped
+	scmStopped := make(chan struct{})
+	scmDone := make(chan struct{})
+	l.adapter.SetStopHandler(l.Stop, scmStopped)
+	go func() {
+		defer close(scmDone)
+		l.comunicateSCM()
+	}()
+
+	supervisor.Go("runControlApi", l.runControlApi)
+	supervisor.Go("watchConfigFile", l.watchConfigFile)
+	supervisor.Go("reloadLogLevelsOnSignal", l.reloadLogLevelsOnSignal)
+	supervisor.Go("Profiling", l.Profiling)
+	supervisor.Go("start", l.start)
+	supervisor.Go("consumeActionsDocpAgent", l.consumeActionsDocpAgent)
+	supervisor.Go("consumerActionsDatadog", l.consumerActionsDatadog)
+	supervisor.Go("periodicHandlerMetadata", l.periodicHandlerMetadata)
+	supervisor.Go("periodicAutoUpdate", l.periodicAutoUpdate)
+	supervisor.Go("periodicTasks", l.periodicTasks)
+	supervisor.Go("periodicDatadogHealth", l.periodicDatadogHealth)
+	l.wg.Add(4)
 	go l.collectGetState()
 	go l.collectGetActions()
-	go l.consumeAllActions()
 	go l.handleMetadata()
-	go l.periodicHandlerMetadata()
-	go l.periodicAutoUpdate()
-	go l.periodicTasks()
 	go l.getMetadata()
-	l.wg.Wait()
-	return nil
+
+	<-supervisor.Context().Done()
+	err := l.shutdown(supervisor)
+	close(scmStopped)
+	select {
+	case <-scmDone:
+	case <-time.After(time.Second * 5):
+	}
+	return err
+}
+
+// reloadLogLevelsOnSignal execute reload of log levels
+// from config file when receive SIGHUP
+func (l *ManagerOperator) reloadLogLevelsOnSignal(ctx context.Context) error {
+	l.logger.Debug("reload log levels on signal", "trace", "docp-agent-os-instance.manager_operator.reloadLogLevelsOnSignal")
+	chanSignal := make(chan os.Signal, 1)
+	signal.Notify(chanSignal, syscall.SIGHUP)
+	defer signal.Stop(chanSignal)
+	for {
+		select {
+		case <-chanSignal:
+			if err := libutils.ReloadLogLevels(l.filePath); err != nil {
+				l.chanErrors <- dto.ManagerChanErrors{From: "reloadLogLevelsOnSignal", Priority: dto.ErrLevelLow, Err: err}
+				continue
+			}
+			l.logger.Info("log levels reloaded", "trace", "docp-agent-os-instance.manager_operator.reloadLogLevelsOnSignal", "levels", libutils.GetLogLevels())
+		case <-ctx.Done():
+			return nil
+		}
+	}
 }
 
 // Start execute mathod for running in manager operator
 func (l *ManagerOperator) Start() {
+	l.start(l.ctx)
+}
+
+// start execute install of agent and periodic check of state until ctx is done
+func (l *ManagerOperator) start(ctx context.Context) error {
 	l.logger.Debug("start tasks", "trace", "docp-agent-os-instance.manager_operator.Start")
-	defer l.wg.Done()
 
-	l.wg.Add(1)
-	go l.installAgent()
+	l.submitAction(pkg.ACTION_COMPONENT_DOCP_AGENT, pkg.ACTION_INSTALL, func() {
+		l.wg.Add(1)
+		l.installAgent()
+	})
 
-	ticker := time.NewTicker(1 * time.Minute)
+	ticker := time.NewTicker(l.runtimeConfig().Intervals.StateCheck)
 	defer ticker.Stop()
 
 	for {
@@ -1348,9 +1571,10 @@ func (l *ManagerOperator) Start() {
 			go l.validateDatadogAgentInstalled()
 			go l.validateDatadogAgentUpdateConfigs()
 
-		case <-l.done:
-			close(l.done)
-			return
+		case <-l.watchIntervals():
+			ticker.Reset(l.runtimeConfig().Intervals.StateCheck)
+		case <-ctx.Done():
+			return nil
 		}
 	}
 }
@@ -1378,18 +1602,23 @@ func (l *ManagerOperator) CheckHealth() {
 	}
 }
 
-func (l *ManagerOperator) Profiling() {
+// Profiling execute server of pprof until ctx is done
+func (l *ManagerOperator) Profiling(ctx context.Context) error {
 	l.logger.Debug("profiling task", "trace", "docp-agent-os-instance.manager_operator.Profiling")
-	defer l.wg.Done()
-	if err := http.ListenAndServe(":4040", nil); err != nil {
+	srv := &http.Server{Addr: ":4040"}
+	stopShutdown := context.AfterFunc(ctx, func() {
+		srv.Close()
+	})
+	defer stopShutdown()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		l.chanErrors <- dto.ManagerChanErrors{From: "Profiling", Priority: dto.ErrLevelLow, Err: err}
 	}
+	return nil
 }
 
-func (l *ManagerOperator) comunicateSCM() error {
-	defer l.wg.Done()
+// comunicateSCM execute handler of scm, returning when scm request stop
+func (l *ManagerOperator) comunicateSCM() {
 	if err := l.adapter.HandlerSCMManager(); err != nil {
-		return err
+		l.chanErrors <- dto.ManagerChanErrors{From: "comunicateSCM", Priority: dto.ErrLevelLow, Err: err}
 	}
-	return nil
 }
@@ -0,0 +1,51 @@
+package operators
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+// rollbackAgent execute enqueue of update of agent for release
+// retained in host, to empty is previous healthy release
+func (l *ManagerOperator) rollbackAgent(to string) (string, error) {
+	l.logger.Debug("rollback agent", "trace", "docp-agent-os-instance.manager_releases.rollbackAgent", "to", to)
+	version, err := l.resolveRollbackVersion(to)
+	if err != nil {
+		return "", err
+	}
+	l.logger.Info("rollback of agent", "trace", "docp-agent-os-instance.manager_releases.rollbackAgent", "version", version)
+	l.submitUpdateAgent(version, true)
+	return version, nil
+}
+
+// resolveRollbackVersion return version of release for rollback,
+// rollback version of config when manifest not have releases
+func (l *ManagerOperator) resolveRollbackVersion(to string) (string, error) {
+	releases, err := l.adapter.LoadReleases()
+	if err != nil {
+		return "", err
+	}
+	if len(to) > 0 {
+		if !releases.Retained(to) {
+			return "", fmt.Errorf("%w: %s", pkg.ErrReleaseNotRetained, to)
+		}
+		return to, nil
+	}
+	current, err := l.adapter.GetAgentVersion()
+	if err != nil {
+		return "", err
+	}
+	if version, ok := releases.Previous(current); ok {
+		return version, nil
+	}
+	rollbackVersion, err := l.adapter.GetAgentRollbackVersion()
+	if err != nil {
+		return "", err
+	}
+	if len(rollbackVersion) == 0 {
+		return "", errors.New("rollback version not available")
+	}
+	return rollbackVersion, nil
+}
@@ -0,0 +1,33 @@
+package operators
+
+import (
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// planAgentUpdate return docp agent from signal and ring of host in
+// rollout of agent versions, ring is saved for report in metadata
+func (l *ManagerOperator) planAgentUpdate() (dto.StateCheckDocpAgent, dto.AgentUpdateRing, error) {
+	received, err := l.adapter.GetStateReceived()
+	if err != nil {
+		return dto.StateCheckDocpAgent{}, dto.AgentUpdateRing{}, err
+	}
+	docpAgent, err := l.adapter.GetDocpAgentFromSignalBytes(received)
+	if err != nil {
+		return dto.StateCheckDocpAgent{}, dto.AgentUpdateRing{}, err
+	}
+	agentVersions, err := l.adapter.FetchAgentVersions()
+	if err != nil {
+		return docpAgent, dto.AgentUpdateRing{}, err
+	}
+	configAgent, err := l.adapter.GetConfigAgent()
+	if err != nil {
+		return docpAgent, dto.AgentUpdateRing{}, err
+	}
+	ring, err := libutils.ResolveAgentUpdate(agentVersions, configAgent.ComputeId, docpAgent, time.Now())
+	l.logger.Debug("plan agent update", "trace", "docp-agent-os-instance.manager_rollout.planAgentUpdate", "ring", ring)
+	l.adapter.SetUpdateRing(ring)
+	return docpAgent, ring, err
+}
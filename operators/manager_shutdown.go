@@ -0,0 +1,52 @@
+package operators
+
+import (
+	"context"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	libutils "github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// shutdown execute stop of tasks of manager, wait actions in
+// progress and save tasks interrupted for next start
+func (l *ManagerOperator) shutdown(supervisor *libutils.Supervisor) error {
+	l.logger.Info("shutting down manager", "trace", "docp-agent-os-instance.manager_shutdown.shutdown", "cause", context.Cause(supervisor.Context()))
+	l.cancel()
+	if err := supervisor.Wait(time.Second * pkg.SHUTDOWN_TASKS_TIMEOUT); err != nil {
+		l.logger.Warn("tasks not stopped on shutdown", "trace", "docp-agent-os-instance.manager_shutdown.shutdown", "error", err.Error())
+	}
+	if err := l.adapter.Close(); err != nil {
+		l.logger.Warn("failed close adapter on shutdown", "trace", "docp-agent-os-instance.manager_shutdown.shutdown", "error", err.Error())
+	}
+	if !libutils.WaitTimeout(l.wg, time.Second*pkg.SHUTDOWN_ACTIONS_TIMEOUT) {
+		l.logger.Warn("actions not finished on shutdown", "trace", "docp-agent-os-instance.manager_shutdown.shutdown", "running", l.tasks.List())
+	}
+	for _, queue := range l.executor.Status() {
+		if queue.Depth > 0 {
+			l.logger.Warn("pending actions discarded on shutdown", "trace", "docp-agent-os-instance.manager_shutdown.shutdown", "component", queue.Component, "actions", queue.Pending)
+		}
+	}
+	if pending := l.tasks.Pending(); len(pending) > 0 {
+		if err := l.adapter.SaveInterruptedTasks(pending); err != nil {
+			l.logger.Warn("failed save interrupted tasks", "trace", "docp-agent-os-instance.manager_shutdown.shutdown", "error", err.Error())
+		} else {
+			l.logger.Info("interrupted tasks saved", "trace", "docp-agent-os-instance.manager_shutdown.shutdown", "tasks", pending)
+		}
+	}
+	l.logger.Info("manager stopped", "trace", "docp-agent-os-instance.manager_shutdown.shutdown")
+	return supervisor.Err()
+}
+
+// reportInterruptedTasks execute log of tasks interrupted in last
+// shutdown, actions are planned again from state received
+func (l *ManagerOperator) reportInterruptedTasks() {
+	tasks, err := l.adapter.TakeInterruptedTasks()
+	if err != nil {
+		l.logger.Warn("failed read interrupted tasks", "trace", "docp-agent-os-instance.manager_shutdown.reportInterruptedTasks", "error", err.Error())
+		return
+	}
+	for _, task := range tasks {
+		l.logger.Warn("task interrupted in last shutdown", "trace", "docp-agent-os-instance.manager_shutdown.reportInterruptedTasks", "task", task.Name, "startedAt", task.StartedAt)
+	}
+}
@@ -3,11 +3,10 @@ package operators
 import (
 	_ "net/http/pprof"
 	"os"
-	"path/filepath"
-	"runtime"
 	"time"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 
 	adapters "github.com/DelfiaProducts/docp-agent-os-instance/libs/adapters"
 	libinterfaces "github.com/DelfiaProducts/docp-agent-os-instance/libs/interfaces"
@@ -16,16 +15,19 @@ import (
 
 // UpdaterOperator is struct for updater the operator
 type UpdaterOperator struct {
+	config          dto.RuntimeConfig
 	logger          libinterfaces.ILogger
 	adapter         *adapters.UpdaterAdapter
 	delay           time.Duration
 	maxRetry        int
 	executeRollback bool
+	version         string
 }
 
-// NewUpdaterOperator return instance of updater operator
-func NewUpdaterOperator() *UpdaterOperator {
+// NewUpdaterOperator return instance of updater operator with configuration
+func NewUpdaterOperator(config dto.RuntimeConfig) *UpdaterOperator {
 	return &UpdaterOperator{
+		config:          config,
 		delay:           time.Second * 1,
 		maxRetry:        3,
 		executeRollback: false,
@@ -34,20 +36,13 @@ func NewUpdaterOperator() *UpdaterOperator {
 
 // Setup configure operator
 func (l *UpdaterOperator) Setup() error {
-	var logger libinterfaces.ILogger
-	if runtime.GOOS == "windows" {
-		workdir, err := libutils.GetWorkDirPath()
-		if err != nil {
-			return err
-		}
-		logPath := filepath.Join(workdir, "logs", "manager.log")
-		loggerFile := libutils.NewDocpLoggerWindowsFileText(logPath)
-		logger = loggerFile
-	} else {
+	logger, err := libutils.NewDocpLoggerService("updater", l.config)
+	if err != nil {
 		logger = libutils.NewDocpLoggerJSON(os.Stdout)
+		logger.Warn("failed create logger of service, logging in stdout", "trace", "docp-agent-os-instance.updater_operator.Setup", "error", err.Error())
 	}
-	l.logger = logger
-	adapterUpdater := adapters.NewUpdaterAdapter(l.logger)
+	l.logger = logger.Named("updater_operator")
+	adapterUpdater := adapters.NewUpdaterAdapter(l.logger, l.config)
 	if err := adapterUpdater.Prepare(); err != nil {
 		return err
 	}
@@ -57,8 +52,7 @@ func (l *UpdaterOperator) Setup() error {
 
 func (l *UpdaterOperator) getLevelError() int {
 	l.logger.Debug("get level error", "trace", "docp-agent-os-instance.updater_operator.getLevelError")
-	envLevelError := os.Getenv("ERROR_LEVEL")
-	switch envLevelError {
+	switch l.config.ErrorLevel {
 	case "high":
 		return dto.ErrLevelHigh
 	case "medium":
@@ -99,9 +93,18 @@ func (l *UpdaterOperator) RecoverServices() error {
 	return nil
 }
 
-// ExecuteUpdate execute update
+// ExecuteUpdate execute update, version resolved by manager
+// is applied before version of signal
 func (l *UpdaterOperator) ExecuteUpdate() error {
 	l.logger.Info("execute update", "timestamp", time.Now())
+	target, err := l.adapter.GetUpdateTarget()
+	if err != nil {
+		return err
+	}
+	if len(target) > 0 {
+		l.logger.Info("update target received", "version", target)
+		return l.applyRelease(target)
+	}
 	received, err := l.adapter.GetContentReceived()
 	if err != nil {
 		return err
@@ -128,14 +131,70 @@ func (l *UpdaterOperator) ExecuteUpdate() error {
 			} else {
 				applyVersion = version
 			}
-			if err := l.adapter.ExecuteUpdateVersion(applyVersion); err != nil {
-				return err
-			}
+			return l.applyRelease(applyVersion)
 		}
 	}
 	return nil
 }
 
+// applyRelease execute install of release and register in
+// manifest of releases with health pending until verified
+func (l *UpdaterOperator) applyRelease(version string) error {
+	releases, err := l.adapter.LoadReleases()
+	if err != nil {
+		return err
+	}
+	// releases installed before manifest were running, so healthy
+	if currentVersion, err := l.adapter.GetAgentVersion(); err == nil {
+		releases.Adopt(currentVersion, pkg.RELEASE_HEALTH_HEALTHY)
+	}
+	if rollbackVersion, err := l.adapter.GetAgentRollbackVersion(); err == nil {
+		releases.Adopt(rollbackVersion, pkg.RELEASE_HEALTH_HEALTHY)
+	}
+	if err := l.adapter.ExecuteUpdateVersion(version); err != nil {
+		return err
+	}
+	l.version = version
+	releases.Record(version, time.Now())
+	return releases.Save()
+}
+
+// finishRelease execute register of health of release verified and
+// remove of releases out of retention
+func (l *UpdaterOperator) finishRelease(current string) {
+	releases, err := l.adapter.LoadReleases()
+	if err != nil {
+		l.logger.Error("error loading releases", "error", err.Error())
+		return
+	}
+	health := pkg.RELEASE_HEALTH_HEALTHY
+	if l.executeRollback {
+		health = pkg.RELEASE_HEALTH_FAILED
+	}
+	releases.SetHealth(l.version, health, time.Now())
+	removed, err := releases.Prune(current, l.adapter.GetReleasesKeep())
+	if err != nil {
+		l.logger.Error("error removing releases", "error", err.Error())
+	}
+	if len(removed) > 0 {
+		l.logger.Info("releases removed", "versions", removed)
+	}
+	if err := releases.Save(); err != nil {
+		l.logger.Error("error saving releases", "error", err.Error())
+	}
+}
+
+// rollbackVersion return most recent healthy release retained,
+// rollback version of config when manifest not have release
+func (l *UpdaterOperator) rollbackVersion() (string, error) {
+	if releases, err := l.adapter.LoadReleases(); err == nil {
+		if version, ok := releases.Previous(l.version); ok {
+			return version, nil
+		}
+	}
+	return l.adapter.GetAgentRollbackVersion()
+}
+
 // Run execute loop the manager
 func (l *UpdaterOperator) Run() error {
 	if err := l.Setup(); err != nil {
@@ -170,6 +229,7 @@ func (l *UpdaterOperator) Start() error {
 	}
 
 	attempt := 1
+	current := l.version
 
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -202,7 +262,7 @@ loopvalidate:
 	//validate if need rollback
 	if l.executeRollback {
 		l.logger.Debug("executing roolback", "timestamp", time.Now())
-		rollbackVersion, err := l.adapter.GetAgentRollbackVersion()
+		rollbackVersion, err := l.rollbackVersion()
 		if err != nil {
 			l.logger.Error("error getting rollback version", "error", err.Error())
 			return err
@@ -212,6 +272,10 @@ loopvalidate:
 			l.logger.Error("error executing rollback version", "error", err.Error())
 			return err
 		}
+		current = rollbackVersion
+	}
+	if len(l.version) > 0 {
+		l.finishRelease(current)
 	}
 
 	//auto uninstall updater
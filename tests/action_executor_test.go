@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// waitQueueIdle return status of queues after all actions finished
+func waitQueueIdle(executor *utils.ActionExecutor) []dto.ControlQueue {
+	deadline := time.Now().Add(time.Second * 5)
+	for time.Now().Before(deadline) {
+		idle := true
+		queues := executor.Status()
+		for _, queue := range queues {
+			if len(queue.Current) > 0 || queue.Depth > 0 {
+				idle = false
+			}
+		}
+		if idle {
+			return queues
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return executor.Status()
+}
+
+func TestActionExecutor(t *testing.T) {
+	bdd.Feature(t, "TestActionExecutor", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve executar ações do mesmo componente em ordem e descartar ações substituídas", func(s *bdd.Scenario) {
+			var executor *utils.ActionExecutor
+			var mu sync.Mutex
+			var executed []string
+			var coalesced []string
+			var queues []dto.ControlQueue
+			release := make(chan struct{})
+			record := func(name string) func() {
+				return func() {
+					mu.Lock()
+					executed = append(executed, name)
+					mu.Unlock()
+				}
+			}
+			s.Given("executor com ação em execução", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				executor = utils.NewActionExecutor(ctx, make(chan dto.ManagerChanErrors, 1))
+				executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DATADOG_AGENT, Name: pkg.ACTION_UPDATE, Run: func() {
+					<-release
+					record("first-update")()
+				}})
+				time.Sleep(time.Millisecond * 50)
+			})
+			s.When("install e uninstall são enfileirados", func() {
+				executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DATADOG_AGENT, Name: pkg.ACTION_INSTALL, Run: record("install")})
+				executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DATADOG_AGENT, Name: pkg.ACTION_UPDATE, Run: record("update")})
+				queues = executor.Status()
+				coalesced = executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DATADOG_AGENT, Name: pkg.ACTION_UNINSTALL, Run: record("uninstall")})
+				close(release)
+				waitQueueIdle(executor)
+			})
+			s.Then("somente a ação final deve executar após a atual", func(t *testing.T) {
+				bdd.AssertEqual(t, 1, len(queues), "uma fila")
+				bdd.AssertEqual(t, pkg.ACTION_UPDATE, queues[0].Current, "ação em execução")
+				bdd.AssertEqual(t, 2, queues[0].Depth, "ações pendentes")
+				bdd.AssertEqual(t, 2, len(coalesced), "ações substituídas")
+				mu.Lock()
+				defer mu.Unlock()
+				bdd.AssertEqual(t, 2, len(executed), "ações executadas")
+				bdd.AssertEqual(t, "first-update", executed[0], "ação em execução não é descartada")
+				bdd.AssertEqual(t, "uninstall", executed[1], "ação final")
+			})
+		})
+
+		Scenario("Deve executar componentes diferentes em paralelo", func(s *bdd.Scenario) {
+			var executor *utils.ActionExecutor
+			release := make(chan struct{})
+			done := make(chan struct{})
+			s.Given("fila do agente bloqueada", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				executor = utils.NewActionExecutor(ctx, make(chan dto.ManagerChanErrors, 1))
+				executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DATADOG_AGENT, Name: pkg.ACTION_INSTALL, Run: func() { <-release }})
+			})
+			s.When("ação de outro componente é enfileirada", func() {
+				executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DOCP_AGENT, Name: pkg.ACTION_UPDATE, Run: func() { close(done) }})
+			})
+			s.Then("ação deve executar sem esperar a outra fila", func(t *testing.T) {
+				defer close(release)
+				select {
+				case <-done:
+				case <-time.After(time.Second * 5):
+					t.Fatal("ação de outro componente não executou")
+				}
+			})
+		})
+
+		Scenario("Deve recuperar panic de ação e continuar a fila", func(s *bdd.Scenario) {
+			var executor *utils.ActionExecutor
+			var managerErr dto.ManagerChanErrors
+			chanErrors := make(chan dto.ManagerChanErrors, 1)
+			executed := make(chan struct{})
+			s.Given("executor com canal de erros", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				executor = utils.NewActionExecutor(ctx, chanErrors)
+			})
+			s.When("ação com panic é seguida de outra ação", func() {
+				executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DATADOG_AGENT, Name: pkg.ACTION_INSTALL, Run: func() {
+					panic("install failed")
+				}})
+				executor.Submit(utils.ExecutorAction{Component: pkg.ACTION_COMPONENT_DATADOG_AGENT, Name: pkg.ACTION_UPDATE, Run: func() {
+					close(executed)
+				}})
+				select {
+				case managerErr = <-chanErrors:
+				case <-time.After(time.Second * 5):
+				}
+			})
+			s.Then("panic deve ser reportado e próxima ação executada", func(t *testing.T) {
+				bdd.AssertTrue(t, errors.Is(managerErr.Err, pkg.ErrActionPanic), "panic reportado")
+				bdd.AssertErrorContains(t, managerErr.Err, "install failed", "valor do panic")
+				bdd.AssertEqual(t, dto.ErrLevelHigh, managerErr.Priority, "prioridade alta")
+				select {
+				case <-executed:
+				case <-time.After(time.Second * 5):
+					t.Fatal("próxima ação não executada")
+				}
+			})
+		})
+	})
+}
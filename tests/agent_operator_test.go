@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 	"github.com/DelfiaProducts/docp-agent-os-instance/operators"
 )
 
@@ -11,7 +12,7 @@ func TestNewAgentOperator(t *testing.T) {
 	bdd.Feature(t, "Criar novo AgentOperator", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
 		Scenario("Operador não deve ser nulo", func(s *bdd.Scenario) {
 			s.Given("um AgentOperator é criado", func() {
-				linux := operators.NewAgentOperator()
+				linux := operators.NewAgentOperator(utils.DefaultRuntimeConfig())
 				s.Then("o operador não deve ser nulo", func(t *testing.T) {
 					if linux == nil {
 						t.Errorf("Esperado operador não nulo")
@@ -27,7 +28,7 @@ func TestAgentOperatorSetup(t *testing.T) {
 		Scenario("Setup não deve retornar erro", func(s *bdd.Scenario) {
 			var linux *operators.AgentOperator
 			s.Given("um AgentOperator válido", func() {
-				linux = operators.NewAgentOperator()
+				linux = operators.NewAgentOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err := linux.Setup()
@@ -46,7 +47,7 @@ func TestFeatureAgentOperatorRun(t *testing.T) {
 		Scenario("Run e Setup não devem retornar erro", func(s *bdd.Scenario) {
 			var linux *operators.AgentOperator
 			s.Given("um AgentOperator válido", func() {
-				linux = operators.NewAgentOperator()
+				linux = operators.NewAgentOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup e Run são chamados", func() {
 				errSetup := linux.Setup()
@@ -16,7 +16,7 @@ func TestNewAgentRegisterService(t *testing.T) {
 	bdd.Feature(t, "Criar novo AgentRegisterService", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
 		Scenario("Service não deve ser nulo", func(s *bdd.Scenario) {
 			s.Given("um AgentRegisterService é criado", func() {
-				agRegister := services.NewAgentRegisterService(logger)
+				agRegister := services.NewAgentRegisterService(logger, testRuntimeConfig())
 				s.Then("o service não deve ser nulo", func(t *testing.T) {
 					bdd.AssertIsNotNil(t, agRegister, "Service deve ser diferente de nil")
 				})
@@ -30,7 +30,7 @@ func TestAgentRegisterServiceSetup(t *testing.T) {
 		Scenario("Setup não deve retornar erro", func(s *bdd.Scenario) {
 			var agRegister *services.AgentRegisterService
 			s.Given("um AgentRegisterService válido", func() {
-				agRegister = services.NewAgentRegisterService(logger)
+				agRegister = services.NewAgentRegisterService(logger, testRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err := agRegister.Setup()
@@ -49,7 +49,7 @@ func TestAgentRegisterServiceGetConfigFileContent(t *testing.T) {
 			var configFileContent []byte
 			var err error
 			s.Given("um AgentRegisterService válido e setup executado", func() {
-				agRegister = services.NewAgentRegisterService(logger)
+				agRegister = services.NewAgentRegisterService(logger, testRuntimeConfig())
 				err = agRegister.Setup()
 			})
 			s.When("GetConfigFileContent é chamado", func() {
@@ -71,7 +71,7 @@ func TestAgentRegisterServiceInjectClientInfo(t *testing.T) {
 			var contentBytes []byte
 			var err error
 			s.Given("um AgentRegisterService válido e setup executado", func() {
-				agRegister = services.NewAgentRegisterService(logger)
+				agRegister = services.NewAgentRegisterService(logger, testRuntimeConfig())
 				err = agRegister.Setup()
 			})
 			s.When("InjectClientInfoUpdate é chamado", func() {
@@ -115,7 +115,7 @@ func TestAgentRegisterServiceSendMetadataCreate(t *testing.T) {
 			var resp any
 			var err error
 			s.Given("um AgentRegisterService válido e setup executado", func() {
-				agRegister = services.NewAgentRegisterService(logger)
+				agRegister = services.NewAgentRegisterService(logger, testRuntimeConfig())
 				err = agRegister.Setup()
 			})
 			s.When("SendMetadataCreate é chamado", func() {
@@ -145,7 +145,7 @@ func TestAgentRegisterServiceSendMetadataUpdate(t *testing.T) {
 			var resp any
 			var err error
 			s.Given("um AgentRegisterService válido e setup executado", func() {
-				agRegister = services.NewAgentRegisterService(logger)
+				agRegister = services.NewAgentRegisterService(logger, testRuntimeConfig())
 				err = agRegister.Setup()
 			})
 			s.When("SendMetadataUpdate é chamado", func() {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/builders"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 )
 
 func TestAgentBuilder(t *testing.T) {
@@ -14,7 +15,7 @@ func TestAgentBuilder(t *testing.T) {
 			var agent any
 			s.Given("nenhum agente criado", func() {})
 			s.When("AgentBuilder é chamado", func() {
-				agent = builders.AgentBuilder()
+				agent = builders.AgentBuilder(utils.DefaultRuntimeConfig())
 			})
 			s.Then("agent não deve ser nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, agent, "Agent deve ser diferente de nil")
@@ -17,7 +17,7 @@ func TestGetBinary(t *testing.T) {
 			s.Given("uma URL válida de binário", func() {})
 			s.When("GetBinary é chamado", func() {
 				url := "https://test-docp-agent-data.s3.amazonaws.com/manager/latest/linux_amd64"
-				resp, statusCode, err = utils.GetBinary(url)
+				resp, statusCode, err = utils.GetBinary(testRuntimeConfig(), url)
 			})
 			s.Then("não deve retornar erro e deve retornar resposta e statusCode", func(t *testing.T) {
 				bdd.AssertNoError(t, err, "GetBinary não deve retornar erro")
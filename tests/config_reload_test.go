@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// waitFileChange return if change of file was notified before timeout
+func waitFileChange(chanChange <-chan struct{}) bool {
+	select {
+	case <-chanChange:
+		return true
+	case <-time.After(time.Second * 5):
+		return false
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	bdd.Feature(t, "TestFileWatcher", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		for _, mode := range []string{"notify", "poll"} {
+			Scenario("Deve notificar alteração do arquivo com "+mode, func(s *bdd.Scenario) {
+				var path string
+				var watcher *utils.FileWatcher
+				chanChange := make(chan struct{}, 1)
+				s.Given("arquivo observado", func() {
+					path = filepath.Join(t.TempDir(), "config.yml")
+					if err := os.WriteFile(path, []byte("agent: {}\n"), 0644); err != nil {
+						t.Fatal(err)
+					}
+					watcher = utils.NewFileWatcher(path, time.Millisecond*50, time.Millisecond*50)
+					onChange := func() {
+						select {
+						case chanChange <- struct{}{}:
+						default:
+						}
+					}
+					if mode == "notify" {
+						go watcher.WatchNotify(onChange)
+					} else {
+						go watcher.WatchPoll(onChange)
+					}
+					time.Sleep(time.Millisecond * 100)
+				})
+				s.When("arquivo é substituído", func() {
+					tmp := path + ".tmp"
+					if err := os.WriteFile(tmp, []byte("agent:\n  tags:\n    env: prod\n"), 0644); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.Rename(tmp, path); err != nil {
+						t.Fatal(err)
+					}
+				})
+				s.Then("alteração deve ser notificada", func(t *testing.T) {
+					defer watcher.Close()
+					bdd.AssertTrue(t, waitFileChange(chanChange), "alteração notificada")
+				})
+			})
+		}
+	})
+}
+
+func TestDiffConfig(t *testing.T) {
+	bdd.Feature(t, "TestDiffConfig", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve separar alterações aplicáveis e que exigem restart", func(s *bdd.Scenario) {
+			var changes dto.ConfigChanges
+			s.When("config é alterada", func() {
+				previous := dto.ConfigAgent{Agent: dto.Agent{ApiKey: "key", Tags: map[string]interface{}{"env": "dev"}}}
+				current := dto.ConfigAgent{
+					Agent:   dto.Agent{ApiKey: "key", Tags: map[string]interface{}{"env": "prod"}},
+					Logging: dto.LoggingConfig{Level: "debug"},
+				}
+				previousRuntime := utils.DefaultRuntimeConfig()
+				currentRuntime := utils.DefaultRuntimeConfig()
+				currentRuntime.Intervals.Tasks = time.Minute
+				currentRuntime.WorkDirPath = "/srv/docp"
+				changes = utils.DiffConfig(previous, current, previousRuntime, currentRuntime)
+			})
+			s.Then("alterações devem ser classificadas", func(t *testing.T) {
+				bdd.AssertTrue(t, changes.Tags, "tags alteradas")
+				bdd.AssertFalse(t, changes.ApiKey, "api key sem alteração")
+				bdd.AssertTrue(t, changes.LogLevels, "níveis de log alterados")
+				bdd.AssertTrue(t, changes.Intervals, "intervalos alterados")
+				bdd.AssertEqual(t, 1, len(changes.Restart), "campos que exigem restart")
+				bdd.AssertEqual(t, "runtime.workdir_path", changes.Restart[0], "workdir exige restart")
+			})
+		})
+	})
+}
@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/api"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/services"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// fakeManagerControl is fake of manager control for tests
+type fakeManagerControl struct {
+	paused    bool
+	polls     int
+	version   string
+	logLevels map[string]string
+}
+
+func (f *fakeManagerControl) ControlStatus() (dto.ControlStatus, error) {
+	return dto.ControlStatus{
+		Services:       map[string]string{"manager": "active"},
+		Versions:       dto.ControlVersions{Agent: "1.2.0", Rollback: "1.1.0"},
+		LastSignalHash: "abc",
+		PendingEvents:  2,
+		Paused:         f.paused,
+	}, nil
+}
+
+func (f *fakeManagerControl) ControlPlanActions() ([]dto.StateAction, error) {
+	return []dto.StateAction{{Type: "docp-agent", Action: "update", Version: "1.3.0"}}, nil
+}
+
+func (f *fakeManagerControl) ControlTasks() []dto.ControlTask {
+	return []dto.ControlTask{{Name: "collectGetState", StartedAt: time.Now()}}
+}
+
+func (f *fakeManagerControl) ControlErrors() []dto.ControlError {
+	return []dto.ControlError{{From: "GetState", Error: "timeout"}}
+}
+
+func (f *fakeManagerControl) ControlForcePoll() error {
+	f.polls++
+	return nil
+}
+
+func (f *fakeManagerControl) ControlForceMetadataSync() error { return nil }
+
+func (f *fakeManagerControl) ControlPause() { f.paused = true }
+
+func (f *fakeManagerControl) ControlResume() { f.paused = false }
+
+func (f *fakeManagerControl) ControlUpdate(version string, allowDowngrade bool) error {
+	f.version = version
+	return nil
+}
+
+func (f *fakeManagerControl) ControlRollback(to string) (string, error) {
+	if len(to) > 0 {
+		return to, nil
+	}
+	return "1.1.0", nil
+}
+
+func (f *fakeManagerControl) ControlLogLevels() map[string]string {
+	levels := map[string]string{"default": "info"}
+	for name, level := range f.logLevels {
+		levels[name] = level
+	}
+	return levels
+}
+
+func (f *fakeManagerControl) ControlSetLogLevel(name string, level string) error {
+	if _, err := utils.ParseLogLevel(level); err != nil {
+		return err
+	}
+	if f.logLevels == nil {
+		f.logLevels = make(map[string]string)
+	}
+	f.logLevels[name] = level
+	return nil
+}
+
+func (f *fakeManagerControl) ControlDiagnostics() (string, error) {
+	return "01J0DIAGNOSTICS", nil
+}
+
+func TestControlApi(t *testing.T) {
+	bdd.Feature(t, "TestControlApi", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve expor controle do manager em socket local", func(s *bdd.Scenario) {
+			control := &fakeManagerControl{}
+			logger := utils.NewDocpLoggerText(os.Stdout)
+			var client *services.ControlClient
+			var controlApi *api.ControlApi
+			var socketPath string
+			s.Given("api de controle rodando em socket", func() {
+				dir, err := os.MkdirTemp("", "docp")
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { os.RemoveAll(dir) })
+				socketPath = filepath.Join(dir, "run", "manager.sock")
+				t.Setenv("DOCP_CONTROL_SOCKET", socketPath)
+				controlApi = api.NewControlApi(socketPath, control, logger)
+				if err := controlApi.Setup(); err != nil {
+					t.Fatal(err)
+				}
+				go controlApi.Run()
+				t.Cleanup(func() { controlApi.Close() })
+				for i := 0; i < 50; i++ {
+					if _, err := os.Stat(socketPath); err == nil {
+						break
+					}
+					time.Sleep(time.Millisecond * 20)
+				}
+				client = services.NewControlClient(logger, testRuntimeConfig())
+				if err := client.Setup(); err != nil {
+					t.Fatal(err)
+				}
+			})
+			s.Then("socket deve ser acessível somente pelo dono", func(t *testing.T) {
+				info, err := os.Stat(socketPath)
+				bdd.AssertNoError(t, err, "socket deve existir")
+				bdd.AssertEqual(t, os.FileMode(0600), info.Mode().Perm(), "permissão do socket")
+			})
+			s.Then("deve retornar status e plano", func(t *testing.T) {
+				status, err := client.Status()
+				bdd.AssertNoError(t, err, "status não deve retornar erro")
+				bdd.AssertEqual(t, "1.2.0", status.Versions.Agent, "versão do agent")
+				bdd.AssertEqual(t, 2, status.PendingEvents, "eventos pendentes")
+				actions, err := client.PlanActions()
+				bdd.AssertNoError(t, err, "plano não deve retornar erro")
+				bdd.AssertEqual(t, 1, len(actions), "quantidade de ações")
+				tasks, err := client.Tasks()
+				bdd.AssertNoError(t, err, "tasks não deve retornar erro")
+				bdd.AssertEqual(t, "collectGetState", tasks[0].Name, "task em execução")
+				controlErrors, err := client.Errors()
+				bdd.AssertNoError(t, err, "erros não deve retornar erro")
+				bdd.AssertEqual(t, "GetState", controlErrors[0].From, "origem do erro")
+			})
+			s.Then("deve executar comandos", func(t *testing.T) {
+				_, err := client.Pause()
+				bdd.AssertNoError(t, err, "pause não deve retornar erro")
+				bdd.AssertTrue(t, control.paused, "reconciliação pausada")
+				_, err = client.Resume()
+				bdd.AssertNoError(t, err, "resume não deve retornar erro")
+				bdd.AssertFalse(t, control.paused, "reconciliação retomada")
+				_, err = client.ForcePoll()
+				bdd.AssertNoError(t, err, "poll não deve retornar erro")
+				bdd.AssertEqual(t, 1, control.polls, "quantidade de polls")
+				res, err := client.Update("1.3.0", false)
+				bdd.AssertNoError(t, err, "update não deve retornar erro")
+				bdd.AssertEqual(t, "1.3.0", control.version, "versão solicitada")
+				bdd.AssertEqual(t, "accepted", res.Status, "status da resposta")
+				_, err = client.Update("", false)
+				bdd.AssertErrorContains(t, err, "version is required", "versão obrigatória")
+				res, err = client.Rollback("1.0.0")
+				bdd.AssertNoError(t, err, "rollback não deve retornar erro")
+				bdd.AssertTrue(t, strings.Contains(res.Message, "1.0.0"), "versão do rollback")
+				res, err = client.Diagnostics()
+				bdd.AssertNoError(t, err, "diagnostics não deve retornar erro")
+				bdd.AssertTrue(t, strings.Contains(res.Message, "01J0DIAGNOSTICS"), "id da requisição")
+			})
+			s.Then("deve alterar nível de log", func(t *testing.T) {
+				levels, err := client.SetLogLevel("manager_adapter", "debug")
+				bdd.AssertNoError(t, err, "alteração de nível não deve retornar erro")
+				bdd.AssertEqual(t, "debug", levels["manager_adapter"], "nível do logger")
+				_, err = client.SetLogLevel("manager_adapter", "verbose")
+				bdd.AssertErrorContains(t, err, "invalid log level", "nível inválido")
+				levels, err = client.LogLevels()
+				bdd.AssertNoError(t, err, "níveis não deve retornar erro")
+				bdd.AssertEqual(t, "info", levels["default"], "nível padrão")
+			})
+		})
+
+		Scenario("Deve restringir dir do socket antes de escutar", func(s *bdd.Scenario) {
+			logger := utils.NewDocpLoggerText(os.Stdout)
+			var runDir, sharedDir string
+			var errShared error
+			s.Given("dir run existente aberto e dir compartilhado", func() {
+				dir, err := os.MkdirTemp("", "docp")
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { os.RemoveAll(dir) })
+				runDir = filepath.Join(dir, "run")
+				sharedDir = filepath.Join(dir, "shared")
+				for path, mode := range map[string]os.FileMode{runDir: 0755, sharedDir: 0777 | os.ModeSticky} {
+					if err := os.Mkdir(path, 0700); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.Chmod(path, mode); err != nil {
+						t.Fatal(err)
+					}
+				}
+			})
+			s.When("api de controle é iniciada nos dirs", func() {
+				controlApi := api.NewControlApi(filepath.Join(runDir, "manager.sock"), &fakeManagerControl{}, logger)
+				if err := controlApi.Setup(); err != nil {
+					t.Fatal(err)
+				}
+				go controlApi.Run()
+				t.Cleanup(func() { controlApi.Close() })
+				for i := 0; i < 50; i++ {
+					if _, err := os.Stat(filepath.Join(runDir, "manager.sock")); err == nil {
+						break
+					}
+					time.Sleep(time.Millisecond * 20)
+				}
+				sharedApi := api.NewControlApi(filepath.Join(sharedDir, "manager.sock"), &fakeManagerControl{}, logger)
+				if err := sharedApi.Setup(); err != nil {
+					t.Fatal(err)
+				}
+				errShared = sharedApi.Run()
+			})
+			s.Then("dir run deve ser do dono e dir compartilhado rejeitado", func(t *testing.T) {
+				info, err := os.Stat(runDir)
+				bdd.AssertNoError(t, err, "dir run deve existir")
+				bdd.AssertEqual(t, os.FileMode(0700), info.Mode().Perm(), "permissão do dir run")
+				bdd.AssertTrue(t, errors.Is(errShared, pkg.ErrControlDirShared), "dir compartilhado")
+				_, err = os.Stat(filepath.Join(sharedDir, "manager.sock"))
+				bdd.AssertTrue(t, errors.Is(err, os.ErrNotExist), "socket fora do dir compartilhado")
+			})
+		})
+	})
+}
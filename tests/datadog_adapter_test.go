@@ -3,6 +3,7 @@ package tests
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/adapters"
@@ -20,7 +21,7 @@ func TestNewDatadogAdapter(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.When("NewDatadogAdapter é chamado", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 			})
 			s.Then("DatadogAdapter não deve ser nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, datadogLinux, "DatadogAdapter deve ser diferente de nil")
@@ -39,7 +40,7 @@ func TestDatadogAdapterSetup(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.Given("DatadogAdapter válido", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				if datadogLinux != nil {
@@ -64,7 +65,7 @@ func TestDatadogAdapterIsActive(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.Given("DatadogAdapter válido e setup executado", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 				err = datadogLinux.Setup()
 			})
 			s.When("IsActive é chamado", func() {
@@ -87,13 +88,16 @@ func TestDatadogAdapterInstallAgent(t *testing.T) {
 			s.When("logger é criado", func() {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
-			s.Given("DatadogAdapter válido e setup executado", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+			s.Given("DatadogAdapter válido com install script configurado", func() {
+				configPath := filepath.Join(t.TempDir(), "config.yml")
+				_ = os.WriteFile(configPath, []byte("datadog:\n  installer: script\n"), 0600)
+				t.Setenv("DOCP_CONFIG_FILE_PATH", configPath)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 				err = datadogLinux.Setup()
 			})
 			s.When("InstallAgent é chamado", func() {
 				if err == nil {
-					err = datadogLinux.InstallAgent("datadoghq.com", "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX")
+					err = datadogLinux.InstallAgent("datadoghq.com", "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX", "", "")
 				}
 			})
 			s.Then("não deve retornar erro", func(t *testing.T) {
@@ -113,7 +117,7 @@ func TestDatadogAdapterUninstallAgent(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.Given("DatadogAdapter válido e setup executado", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 				err = datadogLinux.Setup()
 			})
 			s.When("UninstallAgent é chamado", func() {
@@ -139,7 +143,7 @@ func TestDatadogAdapterDiscoverDatadogConfigPath(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.Given("DatadogAdapter válido e setup executado", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 				err = datadogLinux.Setup()
 			})
 			s.When("DiscoverDatadogConfigPath é chamado", func() {
@@ -164,7 +168,7 @@ func TestDatadogAdapterDecodeBase64(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.Given("DatadogAdapter válido e setup executado", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 				err = datadogLinux.Setup()
 			})
 			s.When("DecodeBase64 é chamado", func() {
@@ -189,7 +193,7 @@ func TestDatadogAdapterUpdateConfigFileDatadog(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.Given("DatadogAdapter válido e setup executado", func() {
-				datadogLinux = adapters.NewDatadogAdapter(logger)
+				datadogLinux = adapters.NewDatadogAdapter(logger, testRuntimeConfig())
 				err = datadogLinux.Setup()
 
 			})
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+const datadogHealthStatusJson = `{
+  "version": "7.52.1",
+  "runnerStats": {"Checks": {"cpu": {"cpu": {"CheckName": "cpu", "TotalRuns": 10}}}},
+  "forwarderStats": {
+    "Transactions": {"Success": 120, "Errors": 3, "HTTPErrors": 3, "ErrorsByType": {"ConnectionErrors": 0, "DNSErrors": 0, "SentRequestErrors": 3}},
+    "APIKeyStatus": {"API key ending with abcde": "API Key valid"},
+    "APIKeyFailure": {}
+  },
+  "logsStats": {"is_running": true, "errors": [], "warnings": []}
+}`
+
+func TestDatadogHealth(t *testing.T) {
+	bdd.Feature(t, "TestDatadogHealth", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve resumir saúde do agent pelo status", func(s *bdd.Scenario) {
+			var first, second dto.DatadogHealth
+			var err error
+			s.When("status do agent é verificado duas vezes", func() {
+				var status dto.DatadogAgentStatus
+				status, err = utils.ParseDatadogStatus([]byte(datadogHealthStatusJson))
+				first = utils.DatadogHealth(status, nil)
+				status.ForwarderStats.Transactions.Errors = 5
+				second = utils.DatadogHealth(status, &first)
+			})
+			s.Then("erros novos do forwarder devem degradar a saúde", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "status sem erro")
+				bdd.AssertEqual(t, pkg.DATADOG_HEALTH_HEALTHY, first.Status, "erros antes do manager não são novos")
+				bdd.AssertEqual(t, pkg.DATADOG_API_KEY_VALID, first.APIKey, "api key válida")
+				bdd.AssertEqual(t, pkg.DATADOG_LOGS_AGENT_RUNNING, first.Logs.State, "logs agent rodando")
+				bdd.AssertEqual(t, int64(3), first.Forwarder.ErrorsByType["SentRequestErrors"], "erros por tipo")
+				bdd.AssertEqual(t, 1, len(first.Forwarder.ErrorsByType), "tipos sem erros ignorados")
+				bdd.AssertEqual(t, pkg.DATADOG_HEALTH_DEGRADED, second.Status, "degradado")
+				bdd.AssertEqual(t, int64(2), second.Forwarder.NewErrors, "erros desde a última verificação")
+				bdd.AssertTrue(t, utils.DatadogHealthChanged(&first, second), "saúde mudou")
+			})
+		})
+
+		Scenario("Deve reportar agent sem saúde por api key, checks e logs", func(s *bdd.Scenario) {
+			var health, unavailable dto.DatadogHealth
+			s.When("status tem api key inválida e erros", func() {
+				status, _ := utils.ParseDatadogStatus([]byte(datadogStatusJson))
+				status.ForwarderStats.APIKeyStatus = map[string]string{"API key ending with abcde": "API Key invalid"}
+				status.LogsStats = dto.DatadogLogsStats{Errors: []string{"invalid endpoint"}}
+				health = utils.DatadogHealth(status, nil)
+				unavailable = utils.DatadogHealthUnavailable(errors.New("connection refused"))
+			})
+			s.Then("saúde deve ter os problemas encontrados", func(t *testing.T) {
+				bdd.AssertEqual(t, pkg.DATADOG_HEALTH_UNHEALTHY, health.Status, "api key inválida")
+				bdd.AssertEqual(t, pkg.DATADOG_LOGS_AGENT_NOT_RUNNING, health.Logs.State, "logs agent parado")
+				bdd.AssertEqual(t, 3, len(health.CheckErrors), "checks com erro")
+				bdd.AssertEqual(t, 3, len(health.Issues), "api key, checks e logs")
+				bdd.AssertEqual(t, pkg.DATADOG_HEALTH_UNHEALTHY, unavailable.Status, "status indisponível")
+				bdd.AssertFalse(t, utils.DatadogHealthChanged(&health, health), "mesma saúde")
+			})
+		})
+	})
+}
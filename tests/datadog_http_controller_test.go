@@ -19,7 +19,7 @@ func TestNewDatadogHttpController(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.When("NewDatadogHttpController é chamado", func() {
-				datadogController = controllers.NewDatadogHttpController(logger)
+				datadogController = controllers.NewDatadogHttpController(logger, testRuntimeConfig())
 			})
 			s.Then("DatadogHttpController não deve ser nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, datadogController, "DatadogHttpController deve ser diferente de nil")
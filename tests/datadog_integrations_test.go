@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+const datadogStatusJson = `Getting the status from the agent.
+{
+  "version": "7.52.1",
+  "runnerStats": {
+    "Checks": {
+      "cpu": {"cpu": {"CheckName": "cpu", "CheckVersion": "", "TotalRuns": 10, "LastError": "", "LastWarnings": []}},
+      "nginx": {"nginx:abc": {"CheckName": "nginx", "CheckVersion": "6.2.0", "TotalRuns": 4, "TotalErrors": 4, "LastError": "[{\"message\": \"connection refused\", \"traceback\": \"Traceback...\"}]"}},
+      "ping": {"ping:1": {"CheckName": "ping", "CheckVersion": "1.0.2", "TotalRuns": 2, "LastWarnings": ["host unreachable"]}}
+    }
+  },
+  "checkSchedulerStats": {"LoaderErrors": {"redis_sentinel": {"python": "unable to import module"}}},
+  "autoConfigStats": {"ConfigErrors": {"postgres": "yaml: line 3: did not find expected key"}}
+}`
+
+func TestDatadogIntegrations(t *testing.T) {
+	bdd.Feature(t, "TestDatadogIntegrations", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve gerar conf.yaml determinístico da integração", func(s *bdd.Scenario) {
+			integration := dto.DatadogIntegration{
+				Name:      "nginx",
+				Instances: []map[string]interface{}{{"nginx_status_url": "http://localhost:81/status", "tags": []interface{}{"env:prod"}}},
+			}
+			var content, again []byte
+			var errRender, errName, errInstances error
+			s.When("integração é renderizada e validada", func() {
+				content, errRender = utils.RenderDatadogIntegrationConfig(integration)
+				again, _ = utils.RenderDatadogIntegrationConfig(integration)
+				errName = utils.ValidateDatadogIntegration(dto.DatadogIntegration{Name: "../nginx", Instances: integration.Instances})
+				errInstances = utils.ValidateDatadogIntegration(dto.DatadogIntegration{Name: "nginx"})
+			})
+			s.Then("conteúdo deve ter init_config e instances", func(t *testing.T) {
+				bdd.AssertNoError(t, errRender, "render sem erro")
+				bdd.AssertEqual(t, "init_config: {}\ninstances:\n- nginx_status_url: http://localhost:81/status\n  tags:\n  - env:prod\n", string(content), "conf.yaml")
+				bdd.AssertEqual(t, string(content), string(again), "mesmo conteúdo")
+				bdd.AssertErrorContains(t, errName, "invalid datadog integration", "nome inválido")
+				bdd.AssertErrorContains(t, errInstances, "without instances", "sem instâncias")
+			})
+		})
+
+		Scenario("Deve identificar integrações instaladas e removidas", func(s *bdd.Scenario) {
+			var installed map[string]string
+			var removed []dto.DatadogManagedIntegration
+			var loaded []dto.DatadogManagedIntegration
+			var err error
+			s.When("freeze e integrações aplicadas são lidos", func() {
+				installed = utils.ParseDatadogIntegrationFreeze("datadog-ping==1.0.2\ndatadog-redis-sentinel==2.1.0\nrequests==2.31.0\n")
+				managed := []dto.DatadogManagedIntegration{{Name: "ping", Version: "1.0.2"}, {Name: "nginx"}}
+				filePath := filepath.Join(t.TempDir(), "state", "integrations.json")
+				err = utils.SaveDatadogIntegrations(filePath, managed)
+				if err == nil {
+					loaded, err = utils.LoadDatadogIntegrations(filePath)
+				}
+				removed = utils.RemovedDatadogIntegrations([]dto.DatadogIntegration{{Name: "nginx"}}, loaded)
+			})
+			s.Then("integração fora do sinal deve ser removida", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "state sem erro")
+				bdd.AssertEqual(t, 2, len(installed), "somente pacotes datadog")
+				bdd.AssertEqual(t, "2.1.0", installed["redis_sentinel"], "nome com underscore")
+				bdd.AssertEqual(t, "datadog-redis-sentinel", utils.DatadogIntegrationPackage("redis_sentinel"), "pacote da integração")
+				bdd.AssertEqual(t, 1, len(removed), "uma removida")
+				bdd.AssertEqual(t, "ping", removed[0].Name, "ping removida")
+			})
+		})
+
+		Scenario("Deve reportar status dos checks pelo status do agent", func(s *bdd.Scenario) {
+			var integrations []dto.DatadogIntegrationStatus
+			var err error
+			s.When("status do agent é interpretado", func() {
+				var status dto.DatadogAgentStatus
+				status, err = utils.ParseDatadogStatus([]byte(datadogStatusJson))
+				integrations = utils.DatadogIntegrationsStatus(status, []dto.DatadogManagedIntegration{{Name: "ping", Version: "1.0.2", AppliedAt: time.Now()}, {Name: "mysql"}})
+			})
+			s.Then("cada integração deve ter seu status", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "status sem erro")
+				byName := make(map[string]dto.DatadogIntegrationStatus)
+				for _, integration := range integrations {
+					byName[integration.Name] = integration
+				}
+				bdd.AssertEqual(t, 6, len(integrations), "integrações reportadas")
+				bdd.AssertEqual(t, "cpu", integrations[0].Name, "ordenado por nome")
+				bdd.AssertEqual(t, pkg.DATADOG_INTEGRATION_STATUS_OK, byName["cpu"].Status, "cpu ok")
+				bdd.AssertEqual(t, pkg.DATADOG_INTEGRATION_STATUS_ERROR, byName["nginx"].Status, "nginx com erro")
+				bdd.AssertEqual(t, "connection refused", byName["nginx"].Error, "mensagem do erro")
+				bdd.AssertEqual(t, pkg.DATADOG_INTEGRATION_STATUS_WARNING, byName["ping"].Status, "ping com warning")
+				bdd.AssertTrue(t, byName["ping"].Managed, "ping aplicada pelo docp")
+				bdd.AssertEqual(t, pkg.DATADOG_INTEGRATION_STATUS_ERROR, byName["redis_sentinel"].Status, "erro de load")
+				bdd.AssertEqual(t, pkg.DATADOG_INTEGRATION_STATUS_ERROR, byName["postgres"].Status, "erro de config")
+				bdd.AssertEqual(t, pkg.DATADOG_INTEGRATION_STATUS_NOT_RUNNING, byName["mysql"].Status, "aplicada sem check")
+			})
+		})
+	})
+}
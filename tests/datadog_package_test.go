@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestDatadogPackage(t *testing.T) {
+	bdd.Feature(t, "TestDatadogPackage", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve gerar repositório assinado e pacote da versão", func(s *bdd.Scenario) {
+			var aptSource, yumRepo, mirrorRepo string
+			specs := make(map[string]string)
+			s.When("repositório e pacote são gerados", func() {
+				aptSource = utils.DatadogAptSource(dto.DatadogInstallConfig{})
+				yumRepo = utils.DatadogYumRepo(dto.DatadogInstallConfig{}, utils.DatadogRpmArch("arm64"))
+				mirrorRepo = utils.DatadogYumRepo(dto.DatadogInstallConfig{RepositoryUrl: "https://mirror.local/datadog/", GpgKeyUrls: []string{"file:///opt/keys/datadog.public"}}, "x86_64")
+				specs["apt exata"] = utils.DatadogPackageSpec(false, "7.52.1")
+				specs["apt minor"] = utils.DatadogPackageSpec(false, "7.52")
+				specs["yum exata"] = utils.DatadogPackageSpec(true, "7.52.1")
+				specs["yum minor"] = utils.DatadogPackageSpec(true, "7.52")
+				specs["latest"] = utils.DatadogPackageSpec(true, "latest")
+			})
+			s.Then("repositório deve verificar chaves gpg", func(t *testing.T) {
+				bdd.AssertEqual(t, "deb [signed-by=/usr/share/keyrings/datadog-archive-keyring.gpg] https://apt.datadoghq.com/ stable 7\n", aptSource, "source apt")
+				bdd.AssertTrue(t, strings.Contains(yumRepo, "baseurl=https://yum.datadoghq.com/stable/7/aarch64/"), "baseurl yum")
+				bdd.AssertTrue(t, strings.Contains(yumRepo, "repo_gpgcheck=1"), "metadata verificada")
+				bdd.AssertTrue(t, strings.Contains(yumRepo, "DATADOG_RPM_KEY_CURRENT.public"), "chave atual")
+				bdd.AssertTrue(t, strings.Contains(mirrorRepo, "baseurl=https://mirror.local/datadog/x86_64/"), "baseurl mirror")
+				bdd.AssertTrue(t, strings.Contains(mirrorRepo, "gpgkey=file:///opt/keys/datadog.public\n"), "chave do mirror")
+			})
+			s.Then("pacote deve fixar versão", func(t *testing.T) {
+				bdd.AssertEqual(t, "datadog-agent=1:7.52.1-1", specs["apt exata"], "apt exata")
+				bdd.AssertEqual(t, "datadog-agent=1:7.52.*", specs["apt minor"], "apt minor")
+				bdd.AssertEqual(t, "datadog-agent-7.52.1-1", specs["yum exata"], "yum exata")
+				bdd.AssertEqual(t, "datadog-agent-7.52.*", specs["yum minor"], "yum minor")
+				bdd.AssertEqual(t, "datadog-agent", specs["latest"], "sem versão")
+			})
+		})
+
+		Scenario("Deve instalar por pacote por padrão e validar config", func(s *bdd.Scenario) {
+			configs := make(map[string]dto.DatadogInstallConfig)
+			errs := make(map[string]error)
+			s.When("config de install é lido", func() {
+				configPath := filepath.Join(t.TempDir(), "config.yml")
+				t.Setenv("DOCP_CONFIG_FILE_PATH", configPath)
+				for name, content := range map[string]string{
+					"vazio":        "version: 1.0.0\n",
+					"script":       "datadog:\n  installer: script\n",
+					"desconhecido": "datadog:\n  installer: pkg\n",
+					"chave http":   "datadog:\n  gpg_key_urls: [\"http://keys.local/datadog.public\"]\n",
+					"sem sha256":   "datadog:\n  local_package: /tmp/datadog-agent.deb\n",
+				} {
+					_ = os.WriteFile(configPath, []byte(content), 0600)
+					configs[name], errs[name] = utils.GetDatadogInstallConfig(configPath)
+				}
+			})
+			s.Then("script somente quando configurado e valores inválidos rejeitados", func(t *testing.T) {
+				bdd.AssertNoError(t, errs["vazio"], "config vazio")
+				bdd.AssertEqual(t, pkg.DATADOG_INSTALLER_PACKAGE, configs["vazio"].Installer, "pacote por padrão")
+				bdd.AssertEqual(t, pkg.DATADOG_INSTALLER_SCRIPT, configs["script"].Installer, "script explícito")
+				bdd.AssertErrorContains(t, errs["desconhecido"], "package or script", "installer desconhecido")
+				bdd.AssertErrorContains(t, errs["chave http"], "https or file", "chave sem https")
+				bdd.AssertErrorContains(t, errs["sem sha256"], "local_package_sha256 required", "pacote local sem checksum")
+			})
+		})
+
+		Scenario("Deve configurar datadog.yaml somente quando houver mudança", func(s *bdd.Scenario) {
+			example := "## @param api_key - string - required\napi_key:\n\n## @param site - string - optional\n# site: datadoghq.com\n\nlogs_enabled: true\n"
+			var merged, again, empty []byte
+			var changed, changedAgain, changedEmpty bool
+			var err error
+			s.When("api key e site são aplicados duas vezes", func() {
+				merged, changed, err = utils.MergeDatadogConfig([]byte(example), "XXXX", "datadoghq.eu")
+				if err == nil {
+					again, changedAgain, err = utils.MergeDatadogConfig(merged, "XXXX", "datadoghq.eu")
+				}
+				if err == nil {
+					empty, changedEmpty, err = utils.MergeDatadogConfig(nil, "XXXX", "")
+				}
+			})
+			s.Then("valores devem ser substituídos mantendo o arquivo", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "merge sem erro")
+				bdd.AssertTrue(t, changed, "primeira aplicação altera")
+				bdd.AssertFalse(t, changedAgain, "segunda aplicação não altera")
+				bdd.AssertEqual(t, string(merged), string(again), "conteúdo idempotente")
+				bdd.AssertEqual(t, "## @param api_key - string - required\napi_key: XXXX\n\n## @param site - string - optional\nsite: datadoghq.eu\n\nlogs_enabled: true\n", string(merged), "conteúdo com comentários")
+				bdd.AssertTrue(t, changedEmpty, "arquivo vazio altera")
+				bdd.AssertEqual(t, "api_key: XXXX\n", string(empty), "arquivo criado")
+			})
+		})
+	})
+}
@@ -21,7 +21,7 @@ func TestNewDatadogRoutes(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.When("NewDatadogRoutes é chamado", func() {
-				datadogRouter = api.NewDatadogRoutes(logger)
+				datadogRouter = api.NewDatadogRoutes(logger, testRuntimeConfig())
 			})
 			s.Then("DatadogRoutes não deve ser nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, datadogRouter, "DatadogRoutes deve ser diferente de nil")
@@ -40,7 +40,7 @@ func TestDatadogRoutesSetup(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.Given("DatadogRoutes válido", func() {
-				datadogRouter = api.NewDatadogRoutes(logger)
+				datadogRouter = api.NewDatadogRoutes(logger, testRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				if datadogRouter != nil {
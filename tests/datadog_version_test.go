@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestDatadogVersionPin(t *testing.T) {
+	bdd.Feature(t, "TestDatadogVersionPin", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve gerar envs do script de instalação para versão fixada", func(s *bdd.Scenario) {
+			var exact, minor utils.DatadogVersionPin
+			var exactOk, minorOk, latestOk, majorOk bool
+			s.When("versões são interpretadas", func() {
+				exact, exactOk = utils.ParseDatadogVersionPin("7.52.1")
+				minor, minorOk = utils.ParseDatadogVersionPin("7.52")
+				_, latestOk = utils.ParseDatadogVersionPin("latest")
+				_, majorOk = utils.ParseDatadogVersionPin("7")
+			})
+			s.Then("somente versões com minor devem ser fixadas", func(t *testing.T) {
+				bdd.AssertTrue(t, exactOk, "versão exata")
+				bdd.AssertTrue(t, minorOk, "versão minor")
+				bdd.AssertFalse(t, latestOk, "latest não fixa versão")
+				bdd.AssertFalse(t, majorOk, "major não fixa versão")
+				bdd.AssertEqual(t, "DD_AGENT_MAJOR_VERSION=7,DD_AGENT_MINOR_VERSION=52.1", strings.Join(exact.Envs(), ","), "envs versão exata")
+				bdd.AssertEqual(t, "DD_AGENT_MAJOR_VERSION=7,DD_AGENT_MINOR_VERSION=52", strings.Join(minor.Envs(), ","), "envs versão minor")
+			})
+		})
+
+		Scenario("Deve detectar divergência do pacote instalado", func(s *bdd.Scenario) {
+			exact, _ := utils.ParseDatadogVersionPin("7.52.1")
+			minor, _ := utils.ParseDatadogVersionPin("7.52")
+			results := make(map[string]int)
+			var err error
+			s.When("versões de pacote são comparadas", func() {
+				results["dpkg igual"], _ = exact.Compare("1:7.52.1-1")
+				results["rpm maior"], _ = exact.Compare("7.53.0-1")
+				results["dpkg menor"], _ = exact.Compare("1:7.50.3-1")
+				results["minor patch diferente"], _ = minor.Compare("1:7.52.4-1")
+				results["minor menor"], _ = minor.Compare("1:7.51.0-1")
+				_, err = exact.Compare("unknown")
+			})
+			s.Then("direção da mudança deve ser identificada", func(t *testing.T) {
+				bdd.AssertEqual(t, 0, results["dpkg igual"], "sem divergência")
+				bdd.AssertEqual(t, 1, results["rpm maior"], "downgrade necessário")
+				bdd.AssertEqual(t, -1, results["dpkg menor"], "upgrade necessário")
+				bdd.AssertEqual(t, 0, results["minor patch diferente"], "qualquer patch do minor")
+				bdd.AssertEqual(t, -1, results["minor menor"], "upgrade do minor")
+				bdd.AssertErrorContains(t, err, "invalid version", "versão instalada inválida")
+			})
+		})
+	})
+}
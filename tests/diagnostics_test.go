@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/services"
+)
+
+// readDiagnosticsBundle return files of tar gzip by name
+func readDiagnosticsBundle(t *testing.T, content []byte) map[string]string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	files := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileContent, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(fileContent)
+	}
+	return files
+}
+
+func TestDiagnosticsBundle(t *testing.T) {
+	bdd.Feature(t, "TestDiagnosticsBundle", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve limitar tamanho do bundle", func(s *bdd.Scenario) {
+			var files map[string]string
+			s.When("arquivos maiores que o limite são adicionados", func() {
+				bundle := pkg.NewDiagnosticsBundle("req-1", 12, 8)
+				bundle.Add("a.log", []byte("0123456789"))
+				bundle.Add("b.log", []byte("abcdef"))
+				bundle.Add("c.log", []byte("xyz"))
+				content, err := bundle.Bytes()
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = readDiagnosticsBundle(t, content)
+			})
+			s.Then("deve manter final dos arquivos e pular excedentes", func(t *testing.T) {
+				bdd.AssertEqual(t, "23456789", files["a.log"], "final do arquivo truncado")
+				bdd.AssertEqual(t, "cdef", files["b.log"], "arquivo limitado ao restante")
+				_, exists := files["c.log"]
+				bdd.AssertFalse(t, exists, "arquivo pulado")
+				var manifest pkg.DiagnosticsBundleManifest
+				bdd.AssertNoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest), "manifest em json")
+				bdd.AssertEqual(t, "req-1", manifest.RequestId, "id da requisição")
+				bdd.AssertTrue(t, manifest.Files[0].Truncated, "arquivo marcado como truncado")
+				bdd.AssertEqual(t, "c.log", manifest.Skipped[0], "arquivo pulado no manifest")
+			})
+		})
+
+		Scenario("Deve coletar config e estado sem segredos", func(s *bdd.Scenario) {
+			var files map[string]string
+			s.Given("workdir com config e estado", func() {
+				dir := t.TempDir()
+				t.Setenv("DOCP_WORKDIR_PATH", dir)
+				t.Setenv("DOCP_CONFIG_FILE_PATH", filepath.Join(dir, "config.yml"))
+				os.MkdirAll(filepath.Join(dir, "state"), 0755)
+				os.MkdirAll(filepath.Join(dir, "logs"), 0755)
+				os.WriteFile(filepath.Join(dir, "config.yml"), []byte("version: 1.0.0\nagent:\n  apiKey: my-secret-key\n"), 0644)
+				os.WriteFile(filepath.Join(dir, "state", "received"), []byte(`{"signal":{"agents":{"datadog-agent":{"api-key":"dd-secret"}}}}`), 0644)
+				os.WriteFile(filepath.Join(dir, "logs", "manager.log"), []byte(`{"msg":"manager started"}`), 0644)
+			})
+			s.When("diagnostics é coletado", func() {
+				diagnostics := services.NewDiagnosticsService(logger, testRuntimeConfig())
+				if err := diagnostics.Setup(); err != nil {
+					t.Fatal(err)
+				}
+				content, err := diagnostics.Collect("req-2", map[string]string{"manager": "active"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = readDiagnosticsBundle(t, content)
+			})
+			s.Then("bundle não deve conter segredos", func(t *testing.T) {
+				bdd.AssertTrue(t, strings.Contains(files["config.yml"], "version: 1.0.0"), "config coletado")
+				bdd.AssertFalse(t, strings.Contains(files["config.yml"], "my-secret-key"), "api key mascarada")
+				bdd.AssertFalse(t, strings.Contains(files["state/received"], "dd-secret"), "api key do estado mascarada")
+				bdd.AssertTrue(t, strings.Contains(files["logs/manager.log"], "manager started"), "log do manager coletado")
+				bdd.AssertTrue(t, strings.Contains(files["services.json"], "active"), "status dos serviços")
+			})
+		})
+
+		Scenario("Deve mascarar chaves configuradas para redação", func(s *bdd.Scenario) {
+			var files map[string]string
+			s.Given("config com chave configurada em LOG_REDACT_DENY", func() {
+				dir := t.TempDir()
+				t.Setenv("DOCP_WORKDIR_PATH", dir)
+				t.Setenv("DOCP_CONFIG_FILE_PATH", filepath.Join(dir, "config.yml"))
+				t.Setenv("LOG_REDACT_DENY", "customer_code")
+				os.MkdirAll(filepath.Join(dir, "state"), 0755)
+				os.WriteFile(filepath.Join(dir, "config.yml"), []byte("version: 1.0.0\ncustomer_code: customer-secret-code\n"), 0644)
+			})
+			s.When("diagnostics é coletado", func() {
+				diagnostics := services.NewDiagnosticsService(logger, testRuntimeConfig())
+				if err := diagnostics.Setup(); err != nil {
+					t.Fatal(err)
+				}
+				content, err := diagnostics.Collect("req-3", map[string]string{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = readDiagnosticsBundle(t, content)
+			})
+			s.Then("bundle não deve conter valor da chave configurada", func(t *testing.T) {
+				bdd.AssertTrue(t, strings.Contains(files["config.yml"], "version: 1.0.0"), "config coletado")
+				bdd.AssertFalse(t, strings.Contains(files["config.yml"], "customer-secret-code"), "chave configurada mascarada")
+			})
+		})
+
+		Scenario("Deve renovar token quando upload não é autorizado", func(s *bdd.Scenario) {
+			var uploads, auths int32
+			var statusCode int
+			var err error
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/compute/v1/diagnostics" {
+					atomic.AddInt32(&auths, 1)
+					fmt.Fprintf(w, `{"access_token":"%s"}`, signedToken(time.Now().Add(time.Hour)))
+					return
+				}
+				if atomic.AddInt32(&uploads, 1) == 1 {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+			s.Given("config com token recusado pelo servidor", func() {
+				dir := t.TempDir()
+				t.Setenv("DOCP_WORKDIR_PATH", dir)
+				t.Setenv("DOCP_CONFIG_FILE_PATH", filepath.Join(dir, "config.yml"))
+				t.Setenv("DOCP_DOMAIN", server.URL)
+				content := fmt.Sprintf("agent:\n  api_key: key\naccesstoken: %s\ncomputeid: compute-test\n", signedToken(time.Now().Add(time.Hour)))
+				os.WriteFile(filepath.Join(dir, "config.yml"), []byte(content), 0600)
+			})
+			s.When("bundle é enviado", func() {
+				config := testRuntimeConfig()
+				tokenManager := services.NewTokenManager(logger, config)
+				diagnostics := services.NewDiagnosticsService(logger, config)
+				if err = tokenManager.Setup(); err == nil {
+					err = diagnostics.Setup()
+				}
+				if err != nil {
+					return
+				}
+				diagnostics.SetTokenManager(tokenManager)
+				_, statusCode, err = diagnostics.Upload(dto.TransactionStatus{ID: "transaction-1"}, "req-4", []byte("bundle"))
+			})
+			s.Then("deve renovar token e reenviar uma vez", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "Upload não deve retornar erro")
+				bdd.AssertEqual(t, http.StatusOK, statusCode, "status do reenvio")
+				bdd.AssertEqual(t, int32(2), atomic.LoadInt32(&uploads), "quantidade de envios")
+				bdd.AssertEqual(t, int32(1), atomic.LoadInt32(&auths), "quantidade de autenticações")
+			})
+		})
+	})
+}
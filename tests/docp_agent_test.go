@@ -5,6 +5,7 @@ import (
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/agents"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 	"github.com/DelfiaProducts/docp-agent-os-instance/operators"
 )
 
@@ -14,7 +15,7 @@ func TestNewDocpAgent(t *testing.T) {
 			var operator *operators.AgentOperator
 			var agent *agents.DocpAgent
 			s.When("operator é criado", func() {
-				operator = operators.NewAgentOperator()
+				operator = operators.NewAgentOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("NewDocpAgent é chamado", func() {
 				agent = agents.NewDocpAgent(operator)
@@ -15,7 +15,7 @@ func TestNewDocpApi(t *testing.T) {
 			var docApi *api.DocpApi
 			s.When("NewDocpApi é chamado", func() {
 				logger := libutils.NewDocpLoggerJSON(os.Stdout)
-				docApi = api.NewDocpApi("3000", logger)
+				docApi = api.NewDocpApi(testRuntimeConfig(), logger)
 			})
 			s.Then("DocpApi não deve ser nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, docApi, "DocpApi deve ser diferente de nil")
@@ -31,7 +31,7 @@ func TestDocpApiSetup(t *testing.T) {
 			var err error
 			s.When("NewDocpApi é chamado", func() {
 				logger := libutils.NewDocpLoggerJSON(os.Stdout)
-				docApi = api.NewDocpApi("3000", logger)
+				docApi = api.NewDocpApi(testRuntimeConfig(), logger)
 			})
 			s.When("Setup é chamado", func() {
 				if docApi != nil {
@@ -52,7 +52,7 @@ func TestDocpApiRun(t *testing.T) {
 			var err error
 			s.When("NewDocpApi é chamado", func() {
 				logger := libutils.NewDocpLoggerJSON(os.Stdout)
-				docApi = api.NewDocpApi("3000", logger)
+				docApi = api.NewDocpApi(testRuntimeConfig(), logger)
 			})
 			s.Given("DocpApi válido", func() {
 				if docApi != nil {
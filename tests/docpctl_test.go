@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/api"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/cli"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// newDocpctlWithControl return docpctl configured with control api
+// fake in socket and config file in work dir of test
+func newDocpctlWithControl(t *testing.T, control *fakeManagerControl) (*cli.Docpctl, *bytes.Buffer) {
+	dir, err := os.MkdirTemp("", "docp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	configFilePath := filepath.Join(dir, "config.yml")
+	config := "version: 1.2.0\nrollback_version: 1.1.0\nagent:\n  apiKey: 0123456789abcdef0123456789abcdef\n"
+	if err := os.WriteFile(configFilePath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	socketPath := filepath.Join(dir, "run", "manager.sock")
+	t.Setenv("DOCP_WORKDIR_PATH", dir)
+	t.Setenv("DOCP_CONFIG_FILE_PATH", configFilePath)
+	t.Setenv("DOCP_CONTROL_SOCKET", socketPath)
+
+	logger := utils.NewDocpLoggerText(os.Stdout)
+	controlApi := api.NewControlApi(socketPath, control, logger)
+	if err := controlApi.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	go controlApi.Run()
+	t.Cleanup(func() { controlApi.Close() })
+	for i := 0; i < 50; i++ {
+		if _, err := os.Stat(socketPath); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+
+	out := &bytes.Buffer{}
+	docpctl := cli.NewDocpctl(out, logger)
+	if err := docpctl.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	return docpctl, out
+}
+
+func TestDocpctl(t *testing.T) {
+	bdd.Feature(t, "TestDocpctl", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve rejeitar linha de comando inválida", func(s *bdd.Scenario) {
+			var docpctl *cli.Docpctl
+			s.Given("docpctl com api de controle", func() {
+				docpctl, _ = newDocpctlWithControl(t, &fakeManagerControl{})
+			})
+			s.Then("comandos inválidos devem retornar erro de uso", func(t *testing.T) {
+				for _, args := range [][]string{
+					{},
+					{"unknown"},
+					{"state"},
+					{"state", "list"},
+					{"config"},
+					{"config", "set", "version"},
+					{"update"},
+					{"rollback", "1.0.0"},
+					{"poll", "now"},
+					{"status", "--unknown"},
+				} {
+					err := docpctl.Run(args)
+					bdd.AssertTrue(t, errors.Is(err, cli.ErrUsage), "uso inválido: "+strings.Join(args, " "))
+				}
+			})
+		})
+
+		Scenario("Deve mostrar status, plano, tasks e erros do manager", func(s *bdd.Scenario) {
+			var docpctl *cli.Docpctl
+			var out *bytes.Buffer
+			s.Given("docpctl com api de controle", func() {
+				docpctl, out = newDocpctlWithControl(t, &fakeManagerControl{paused: true})
+			})
+			s.Then("status deve ter versões, eventos e reconciliação", func(t *testing.T) {
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"status"}), "status sem erro")
+				bdd.AssertTrue(t, strings.Contains(out.String(), "1.2.0"), "versão do agent")
+				bdd.AssertTrue(t, strings.Contains(out.String(), "pending events  2"), "eventos pendentes")
+				bdd.AssertTrue(t, strings.Contains(out.String(), "paused"), "reconciliação pausada")
+			})
+			s.Then("status em json deve ser decodificável", func(t *testing.T) {
+				out.Reset()
+				var status dto.ControlStatus
+				bdd.AssertNoError(t, docpctl.Run([]string{"status", "--json"}), "status json sem erro")
+				bdd.AssertNoError(t, json.Unmarshal(out.Bytes(), &status), "json válido")
+				bdd.AssertEqual(t, "1.1.0", status.Versions.Rollback, "versão de rollback")
+			})
+			s.Then("plano deve listar ações em tabela", func(t *testing.T) {
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"actions", "plan"}), "plano sem erro")
+				lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+				bdd.AssertEqual(t, 2, len(lines), "cabeçalho e uma ação")
+				bdd.AssertTrue(t, strings.HasPrefix(lines[0], "TYPE"), "cabeçalho")
+				bdd.AssertEqual(t, "docp-agent update 1.3.0", strings.Join(strings.Fields(lines[1]), " "), "colunas da ação")
+			})
+			s.Then("tasks e erros devem ser listados", func(t *testing.T) {
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"tasks"}), "tasks sem erro")
+				bdd.AssertTrue(t, strings.Contains(out.String(), "collectGetState"), "task em execução")
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"errors"}), "erros sem erro")
+				bdd.AssertTrue(t, strings.Contains(out.String(), "GetState"), "origem do erro")
+				bdd.AssertTrue(t, strings.Contains(out.String(), "timeout"), "mensagem do erro")
+			})
+		})
+
+		Scenario("Deve enviar comandos ao manager", func(s *bdd.Scenario) {
+			control := &fakeManagerControl{}
+			var docpctl *cli.Docpctl
+			var out *bytes.Buffer
+			s.Given("docpctl com api de controle", func() {
+				docpctl, out = newDocpctlWithControl(t, control)
+			})
+			s.Then("flags devem ser aceitas antes e depois de argumentos", func(t *testing.T) {
+				bdd.AssertNoError(t, docpctl.Run([]string{"update", "--to", "^1.3.0", "--allow-downgrade"}), "update sem erro")
+				bdd.AssertEqual(t, "^1.3.0", control.version, "versão solicitada")
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"log-level", "manager_adapter", "debug"}), "log level sem erro")
+				bdd.AssertEqual(t, "debug", control.logLevels["manager_adapter"], "nível alterado")
+				bdd.AssertNoError(t, docpctl.Run([]string{"log-level", "--json"}), "níveis em json sem erro")
+				bdd.AssertTrue(t, strings.Contains(out.String(), `"default": "info"`), "nível padrão")
+			})
+			s.Then("pause, resume e poll devem chegar ao manager", func(t *testing.T) {
+				bdd.AssertNoError(t, docpctl.Run([]string{"pause"}), "pause sem erro")
+				bdd.AssertTrue(t, control.paused, "reconciliação pausada")
+				bdd.AssertNoError(t, docpctl.Run([]string{"resume"}), "resume sem erro")
+				bdd.AssertFalse(t, control.paused, "reconciliação retomada")
+				bdd.AssertNoError(t, docpctl.Run([]string{"poll"}), "poll sem erro")
+				bdd.AssertEqual(t, 1, control.polls, "quantidade de polls")
+			})
+		})
+
+		Scenario("Deve ler e alterar config sem expor segredos", func(s *bdd.Scenario) {
+			var docpctl *cli.Docpctl
+			var out *bytes.Buffer
+			s.Given("docpctl com config com api key", func() {
+				docpctl, out = newDocpctlWithControl(t, &fakeManagerControl{})
+			})
+			s.Then("api key deve ser mascarada", func(t *testing.T) {
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"config", "get", "agent.apiKey"}), "config get sem erro")
+				bdd.AssertEqual(t, utils.REDACTED_VALUE, strings.TrimSpace(out.String()), "api key mascarada")
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"config", "get", "--show-secrets", "agent.apiKey"}), "config get com segredos sem erro")
+				bdd.AssertEqual(t, "0123456789abcdef0123456789abcdef", strings.TrimSpace(out.String()), "api key em texto")
+			})
+			s.Then("config set deve validar chave e salvar valor", func(t *testing.T) {
+				err := docpctl.Run([]string{"config", "set", "unknown_key", "1"})
+				bdd.AssertErrorContains(t, err, "invalid config key unknown_key", "chave desconhecida")
+				bdd.AssertNoError(t, docpctl.Run([]string{"config", "set", "rollback_version", "1.0.0"}), "config set sem erro")
+				out.Reset()
+				bdd.AssertNoError(t, docpctl.Run([]string{"config", "get", "rollback_version"}), "config get sem erro")
+				bdd.AssertEqual(t, "1.0.0", strings.TrimSpace(out.String()), "valor salvo")
+			})
+			s.Then("config set deve aguardar lock do config", func(t *testing.T) {
+				unlock, err := pkg.NewFileSystem().LockFile(utils.GetConfigLockPath(os.Getenv("DOCP_WORKDIR_PATH")))
+				bdd.AssertNoError(t, err, "lock sem erro")
+				done := make(chan error, 1)
+				go func() {
+					done <- docpctl.Run([]string{"config", "set", "rollback_version", "0.9.0"})
+				}()
+				select {
+				case <-done:
+					t.Error("config set não deve escrever com lock de outro escritor")
+				case <-time.After(time.Millisecond * 200):
+				}
+				unlock()
+				bdd.AssertNoError(t, <-done, "config set sem erro após lock liberado")
+			})
+		})
+
+		Scenario("Deve mostrar últimas linhas de log grande", func(s *bdd.Scenario) {
+			var docpctl *cli.Docpctl
+			var out *bytes.Buffer
+			var err error
+			s.Given("log do manager maior que bloco de leitura", func() {
+				docpctl, out = newDocpctlWithControl(t, &fakeManagerControl{})
+				workDir := os.Getenv("DOCP_WORKDIR_PATH")
+				config := "version: 1.2.0\nlogging:\n  output: file\n"
+				if err := os.WriteFile(filepath.Join(workDir, "config.yml"), []byte(config), 0600); err != nil {
+					t.Fatal(err)
+				}
+				var content strings.Builder
+				for i := 0; i < 20000; i++ {
+					fmt.Fprintf(&content, "line %d of manager log\n", i)
+				}
+				os.MkdirAll(filepath.Join(workDir, "logs"), 0755)
+				if err := os.WriteFile(utils.GetLogFilePath(workDir, "manager"), []byte(content.String()), 0644); err != nil {
+					t.Fatal(err)
+				}
+			})
+			s.When("logs é solicitado com 3 linhas", func() {
+				out.Reset()
+				err = docpctl.Run([]string{"logs", "-n", "3"})
+			})
+			s.Then("deve mostrar somente as últimas linhas completas", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "logs sem erro")
+				bdd.AssertEqual(t, "line 19997 of manager log\nline 19998 of manager log\nline 19999 of manager log\n", out.String(), "últimas linhas")
+			})
+		})
+
+		Scenario("Deve mostrar status local sem socket de controle", func(s *bdd.Scenario) {
+			var out bytes.Buffer
+			var errStatus, errPlan error
+			var status dto.ControlStatus
+			s.Given("manager sem socket de controle", func() {
+				dir, err := os.MkdirTemp("", "docp")
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { os.RemoveAll(dir) })
+				configFilePath := filepath.Join(dir, "config.yml")
+				if err := os.WriteFile(configFilePath, []byte("version: 1.2.0\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("DOCP_WORKDIR_PATH", dir)
+				t.Setenv("DOCP_CONFIG_FILE_PATH", configFilePath)
+				t.Setenv("DOCP_CONTROL_SOCKET", filepath.Join(dir, "run", "manager.sock"))
+			})
+			s.When("status e plano são solicitados", func() {
+				docpctl := cli.NewDocpctl(&out, utils.NewDocpLoggerText(os.Stdout))
+				if err := docpctl.Setup(); err != nil {
+					t.Fatal(err)
+				}
+				errStatus = docpctl.Run([]string{"status", "--json"})
+				json.Unmarshal(out.Bytes(), &status)
+				errPlan = docpctl.Run([]string{"actions", "plan"})
+			})
+			s.Then("status usa arquivos locais e plano exige o manager", func(t *testing.T) {
+				bdd.AssertNoError(t, errStatus, "status sem erro")
+				bdd.AssertEqual(t, "1.2.0", status.Versions.Agent, "versão do config")
+				bdd.AssertEqual(t, -1, status.PendingEvents, "eventos desconhecidos")
+				bdd.AssertTrue(t, errors.Is(errPlan, pkg.ErrControlUnavailable), "socket indisponível")
+			})
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
@@ -60,6 +62,34 @@ func TestFileSystemGetFileContent(t *testing.T) {
 	})
 }
 
+func TestFileSystemGetFileTail(t *testing.T) {
+	bdd.Feature(t, "FileSystem", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
+		scenario("obter final de arquivo", func(s *bdd.Scenario) {
+			var fileSystem *pkg.FileSystem
+			var filePath string
+			var tail, small []byte
+			var errTail, errSmall error
+			s.Given("um arquivo maior que o limite", func() {
+				fileSystem = pkg.NewFileSystem()
+				filePath = filepath.Join(t.TempDir(), "manager.log")
+				if err := os.WriteFile(filePath, []byte("first line\nlast line\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			})
+			s.When("leio o final do arquivo", func() {
+				tail, errTail = fileSystem.GetFileTail(filePath, 10)
+				small, errSmall = fileSystem.GetFileTail(filePath, 1024)
+			})
+			s.Then("deve retornar somente os últimos bytes", func(t *testing.T) {
+				bdd.AssertNoError(t, errTail, "GetFileTail não deve retornar erro")
+				bdd.AssertEqual(t, "last line\n", string(tail), "final do arquivo")
+				bdd.AssertNoError(t, errSmall, "GetFileTail não deve retornar erro")
+				bdd.AssertEqual(t, "first line\nlast line\n", string(small), "arquivo menor que o limite")
+			})
+		})
+	})
+}
+
 func TestFileSystemWriteFileContent(t *testing.T) {
 	bdd.Feature(t, "FileSystem", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
 		scenario("escrever conteúdo em arquivo", func(s *bdd.Scenario) {
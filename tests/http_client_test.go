@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+func TestBackoff(t *testing.T) {
+	bdd.Feature(t, "TestBackoff", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve respeitar o limite máximo do backoff", func(s *bdd.Scenario) {
+			var delays []time.Duration
+			s.When("Backoff é chamado para várias tentativas", func() {
+				for attempt := 0; attempt < 64; attempt++ {
+					delays = append(delays, pkg.Backoff(attempt, time.Second, time.Second*10))
+				}
+			})
+			s.Then("os atrasos devem estar entre zero e o limite", func(t *testing.T) {
+				for _, delay := range delays {
+					bdd.AssertTrue(t, delay >= 0 && delay <= time.Second*10, "atraso fora do limite")
+				}
+			})
+		})
+	})
+}
+
+func TestHttpClientDo(t *testing.T) {
+	bdd.Feature(t, "TestHttpClientDo", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		retry := pkg.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond * 5}
+		Scenario("Deve repetir a requisição até obter sucesso", func(s *bdd.Scenario) {
+			var calls int32
+			var bodies []string
+			var res *http.Response
+			var err error
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if atomic.AddInt32(&calls, 1) < 3 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+			s.When("Do é chamado", func() {
+				client := pkg.NewHttpClient(server.Client(), retry, pkg.DefaultBreakerPolicy())
+				req, _ := http.NewRequest(http.MethodPost, server.URL+"/compute/transaction", bytes.NewBufferString("payload"))
+				req.Header.Set(pkg.HEADER_IDEMPOTENCY_KEY, "01J0000000000000000000000")
+				res, err = client.Do(req)
+			})
+			s.Then("deve retornar sucesso após as tentativas", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "Do não deve retornar erro")
+				bdd.AssertEqual(t, http.StatusOK, res.StatusCode, "status da resposta")
+				bdd.AssertEqual(t, int32(3), atomic.LoadInt32(&calls), "quantidade de chamadas")
+				for _, body := range bodies {
+					bdd.AssertEqual(t, "payload", body, "corpo deve ser reenviado")
+				}
+			})
+		})
+		Scenario("Não deve repetir requisição não idempotente", func(s *bdd.Scenario) {
+			var calls int32
+			var res *http.Response
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+			s.When("POST sem chave de idempotência é chamado", func() {
+				client := pkg.NewHttpClient(server.Client(), retry, pkg.DefaultBreakerPolicy())
+				req, _ := http.NewRequest(http.MethodPost, server.URL+"/agents/register", bytes.NewBufferString("payload"))
+				res, _ = client.Do(req)
+			})
+			s.Then("deve executar somente uma chamada", func(t *testing.T) {
+				bdd.AssertEqual(t, http.StatusServiceUnavailable, res.StatusCode, "status da resposta")
+				bdd.AssertEqual(t, int32(1), atomic.LoadInt32(&calls), "quantidade de chamadas")
+			})
+		})
+		Scenario("Não deve repetir requisições com erro do cliente", func(s *bdd.Scenario) {
+			var calls int32
+			var res *http.Response
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer server.Close()
+			s.When("Do é chamado", func() {
+				client := pkg.NewHttpClient(server.Client(), retry, pkg.DefaultBreakerPolicy())
+				req, _ := http.NewRequest(http.MethodGet, server.URL+"/compute/v1/status/info", nil)
+				res, _ = client.Do(req)
+			})
+			s.Then("deve executar somente uma chamada", func(t *testing.T) {
+				bdd.AssertEqual(t, http.StatusForbidden, res.StatusCode, "status da resposta")
+				bdd.AssertEqual(t, int32(1), atomic.LoadInt32(&calls), "quantidade de chamadas")
+			})
+		})
+		Scenario("Deve abrir o circuito após falhas consecutivas", func(s *bdd.Scenario) {
+			var calls int32
+			var err error
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			defer server.Close()
+			s.When("Do é chamado após o limite de falhas", func() {
+				client := pkg.NewHttpClient(server.Client(), pkg.RetryPolicy{MaxRetries: 0}, pkg.BreakerPolicy{FailureThreshold: 2, OpenTimeout: time.Minute})
+				for i := 0; i < 3; i++ {
+					req, _ := http.NewRequest(http.MethodGet, server.URL+"/agents/auth/api_key/token", nil)
+					var res *http.Response
+					res, err = client.Do(req)
+					if res != nil {
+						res.Body.Close()
+					}
+				}
+			})
+			s.Then("deve retornar circuito aberto sem chamar o servidor", func(t *testing.T) {
+				bdd.AssertTrue(t, errors.Is(err, pkg.ErrCircuitOpen), "erro deve ser circuito aberto")
+				bdd.AssertEqual(t, int32(2), atomic.LoadInt32(&calls), "quantidade de chamadas")
+			})
+		})
+	})
+}
@@ -21,7 +21,7 @@ func TestNewLinuxOperations(t *testing.T) {
 			})
 
 			s.Given("Criar linux operations", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 
 			s.Then("Operaotion deve ser criado com sucesso", func(t *testing.T) {
@@ -43,7 +43,7 @@ func TestLinuxOperationsSetup(t *testing.T) {
 			})
 
 			s.Given("Criar linux operations", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -67,7 +67,7 @@ func TestLinuxOperationsStatus(t *testing.T) {
 			})
 
 			s.Given("Criar linux operations", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -96,7 +96,7 @@ func TestLinuxOperationsAlreadyInstalled(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations com systemd mockado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -124,7 +124,7 @@ func TestLinuxOperationsDaemonReload(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations com program mockado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -151,7 +151,7 @@ func TestLinuxOperationsRestartService(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -178,7 +178,7 @@ func TestLinuxOperationsStopService(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -205,7 +205,7 @@ func TestLinuxOperationsInstallAgent(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -232,7 +232,7 @@ func TestLinuxOperationsInstallUpdater(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -259,7 +259,7 @@ func TestLinuxOperationsUninstallAgent(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -286,7 +286,7 @@ func TestLinuxOperationsUninstallUpdater(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -316,7 +316,7 @@ func TestLinuxOperationsUpdateAgent(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -344,7 +344,7 @@ func TestLinuxOperationsAutoUninstall(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
@@ -371,7 +371,7 @@ func TestLinuxOperationsExecute(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("LinuxOperations criado", func() {
-				ops = components.NewLinuxOperations(logger)
+				ops = components.NewLinuxOperations(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o operation", func() {
 				err = ops.Setup()
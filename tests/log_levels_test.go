@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestLogLevels(t *testing.T) {
+	bdd.Feature(t, "TestLogLevels", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve filtrar logs pelo nível de cada logger", func(s *bdd.Scenario) {
+			var output bytes.Buffer
+			t.Cleanup(func() { utils.ReloadLogLevels(testRuntimeConfig().ConfigFilePath) })
+			s.Given("níveis alterados em tempo de execução", func() {
+				t.Setenv("LOG_LEVEL", "warn")
+				t.Setenv("LOG_LEVELS", "state_check_service=debug")
+				if err := utils.ReloadLogLevels(testRuntimeConfig().ConfigFilePath); err != nil {
+					t.Fatal(err)
+				}
+			})
+			s.When("logs são escritos por loggers nomeados", func() {
+				logger := utils.NewDocpLoggerJSON(&output)
+				logger.Named("manager_adapter").Info("adapter info")
+				logger.Named("state_check_service").Debug("state check debug")
+				logger.Info("trace info", "trace", "docp-agent-os-instance.state_check_service.GetState")
+				logger.Info("other info", "trace", "docp-agent-os-instance.manager_operator.Run")
+			})
+			s.Then("somente logs habilitados devem aparecer", func(t *testing.T) {
+				content := output.String()
+				bdd.AssertFalse(t, strings.Contains(content, "adapter info"), "info do adapter filtrado")
+				bdd.AssertTrue(t, strings.Contains(content, "state check debug"), "debug do state check")
+				bdd.AssertTrue(t, strings.Contains(content, "trace info"), "nome obtido do trace")
+				bdd.AssertFalse(t, strings.Contains(content, "other info"), "info do operator filtrado")
+				bdd.AssertTrue(t, strings.Contains(content, `"logger":"state_check_service"`), "nome do logger")
+				bdd.AssertTrue(t, strings.Contains(content, `"func":"GetState"`), "função do trace")
+			})
+		})
+
+		Scenario("Deve alterar nível pelo nome", func(s *bdd.Scenario) {
+			t.Cleanup(func() { utils.ReloadLogLevels(testRuntimeConfig().ConfigFilePath) })
+			s.Then("nível inválido deve retornar erro", func(t *testing.T) {
+				bdd.AssertErrorContains(t, utils.SetLogLevel("manager_operator", "verbose"), "invalid log level", "nível inválido")
+			})
+			s.Then("nível deve ser alterado", func(t *testing.T) {
+				bdd.AssertNoError(t, utils.SetLogLevel("manager_operator", "error"), "alteração de nível")
+				bdd.AssertEqual(t, "error", utils.GetLogLevels()["manager_operator"], "nível do logger")
+			})
+		})
+	})
+}
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestDocpLoggerService(t *testing.T) {
+	bdd.Feature(t, "TestDocpLoggerService", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve escrever logs json em arquivo rotacionado", func(s *bdd.Scenario) {
+			var dir string
+			var logger *utils.DocpLogger
+			var err error
+			s.Given("config com saída em arquivo", func() {
+				dir = t.TempDir()
+				configPath := filepath.Join(dir, "config.yml")
+				config := "logging:\n  output: file\n  file:\n    dir: " + filepath.Join(dir, "custom") + "\n    max_size_mb: 1\n"
+				if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("DOCP_CONFIG_FILE_PATH", configPath)
+				t.Setenv("DOCP_WORKDIR_PATH", dir)
+			})
+			s.When("logger do manager é criado", func() {
+				logger, err = utils.NewDocpLoggerService("manager", testRuntimeConfig())
+				if err == nil {
+					logger.Named("manager_operator").Error("manager started")
+					logger.Close()
+				}
+			})
+			s.Then("arquivo deve conter log em json", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "logger não deve retornar erro")
+				content, err := os.ReadFile(filepath.Join(dir, "custom", "manager.log"))
+				bdd.AssertNoError(t, err, "arquivo de log deve existir")
+				var line map[string]any
+				bdd.AssertNoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(content))), &line), "linha em json")
+				bdd.AssertEqual(t, "manager started", line["msg"].(string), "mensagem do log")
+				bdd.AssertEqual(t, "manager_operator", line["logger"].(string), "nome do logger")
+			})
+		})
+
+		Scenario("Deve retornar erro para saída inválida", func(s *bdd.Scenario) {
+			var err error
+			s.Given("env com saída inválida", func() {
+				t.Setenv("DOCP_CONFIG_FILE_PATH", filepath.Join(t.TempDir(), "config.yml"))
+				t.Setenv("LOG_OUTPUT", "syslog")
+			})
+			s.When("logger é criado", func() {
+				_, err = utils.NewDocpLoggerService("agent", testRuntimeConfig())
+			})
+			s.Then("erro deve informar saída", func(t *testing.T) {
+				bdd.AssertErrorContains(t, err, "invalid log output", "saída inválida")
+			})
+		})
+	})
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestMaintenanceSchedule(t *testing.T) {
+	bdd.Feature(t, "TestMaintenanceSchedule", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve considerar janela por dias da semana atravessando meia-noite", func(s *bdd.Scenario) {
+			var schedule *utils.MaintenanceSchedule
+			var err error
+			var location *time.Location
+			s.Given("janela de sexta 22:00 até 04:00 em São Paulo", func() {
+				location, _ = time.LoadLocation("America/Sao_Paulo")
+				schedule, err = utils.NewMaintenanceSchedule(dto.MaintenanceConfig{
+					Timezone: "America/Sao_Paulo",
+					Windows:  []dto.MaintenanceWindow{{Weekdays: []string{"fri"}, Start: "22:00", End: "04:00"}},
+				})
+			})
+			s.Then("janela deve abrir somente no período", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "config válida")
+				// 2026-10-16 is friday
+				bdd.AssertTrue(t, schedule.Active(time.Date(2026, 10, 16, 23, 0, 0, 0, location)), "sexta 23:00")
+				bdd.AssertTrue(t, schedule.Active(time.Date(2026, 10, 17, 3, 59, 0, 0, location)), "sábado 03:59")
+				bdd.AssertFalse(t, schedule.Active(time.Date(2026, 10, 17, 4, 0, 0, 0, location)), "sábado 04:00")
+				bdd.AssertFalse(t, schedule.Active(time.Date(2026, 10, 15, 23, 0, 0, 0, location)), "quinta 23:00")
+				bdd.AssertFalse(t, schedule.Active(time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)), "sexta 23:00 UTC é 20:00 em São Paulo")
+				next, ok := schedule.Next(time.Date(2026, 10, 14, 12, 0, 0, 0, location))
+				bdd.AssertTrue(t, ok, "próxima janela encontrada")
+				bdd.AssertTrue(t, next.Equal(time.Date(2026, 10, 16, 22, 0, 0, 0, location)), "próxima janela na sexta")
+			})
+		})
+
+		Scenario("Deve considerar janela por cron com duração", func(s *bdd.Scenario) {
+			var schedule *utils.MaintenanceSchedule
+			var err error
+			s.Given("janela às 02:30 de dias úteis por 2h", func() {
+				schedule, err = utils.NewMaintenanceSchedule(dto.MaintenanceConfig{
+					Timezone: "UTC",
+					Windows:  []dto.MaintenanceWindow{{Cron: "30 2 * * 1-5", Duration: "2h"}},
+				})
+			})
+			s.Then("janela deve abrir durante a duração", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "config válida")
+				bdd.AssertTrue(t, schedule.Active(time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)), "segunda 03:00")
+				bdd.AssertFalse(t, schedule.Active(time.Date(2026, 10, 19, 4, 30, 0, 0, time.UTC)), "segunda 04:30")
+				bdd.AssertFalse(t, schedule.Active(time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)), "domingo 03:00")
+				next, ok := schedule.Next(time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+				bdd.AssertTrue(t, ok, "próxima janela encontrada")
+				bdd.AssertTrue(t, next.Equal(time.Date(2026, 10, 19, 2, 30, 0, 0, time.UTC)), "próxima janela na segunda")
+			})
+		})
+
+		Scenario("Deve permitir ações sem janelas e rejeitar janelas inválidas", func(s *bdd.Scenario) {
+			var empty *utils.MaintenanceSchedule
+			var err error
+			s.When("configs são carregadas", func() {
+				empty, _ = utils.NewMaintenanceSchedule(dto.MaintenanceConfig{})
+				_, err = utils.NewMaintenanceSchedule(dto.MaintenanceConfig{
+					Windows: []dto.MaintenanceWindow{
+						{Cron: "61 * * * *", Duration: "1h"},
+						{Weekdays: []string{"someday"}, Start: "22:00", End: "23:00"},
+					},
+				})
+			})
+			s.Then("sem janelas sempre ativo e erro lista janelas inválidas", func(t *testing.T) {
+				bdd.AssertTrue(t, empty.Active(time.Now()), "sem janelas")
+				bdd.AssertErrorContains(t, err, "windows[0]", "cron inválido")
+				bdd.AssertErrorContains(t, err, "windows[1]", "dia inválido")
+			})
+		})
+
+		Scenario("Deve adiar ações que instalam, removem ou reiniciam agents", func(s *bdd.Scenario) {
+			disruptive := map[string]bool{}
+			s.When("ações são verificadas", func() {
+				for _, action := range []string{pkg.ACTION_INSTALL, pkg.ACTION_UNINSTALL, pkg.ACTION_UPGRADE, pkg.ACTION_UPDATE, pkg.ACTION_ROLLBACK, pkg.ACTION_INTEGRATIONS, "restart"} {
+					disruptive[action] = utils.IsDisruptiveAction(action)
+				}
+			})
+			s.Then("update, rollback e integrations são adiados", func(t *testing.T) {
+				bdd.AssertTrue(t, disruptive[pkg.ACTION_INSTALL], "install adiado")
+				bdd.AssertTrue(t, disruptive[pkg.ACTION_UNINSTALL], "uninstall adiado")
+				bdd.AssertTrue(t, disruptive[pkg.ACTION_UPGRADE], "upgrade adiado")
+				bdd.AssertTrue(t, disruptive[pkg.ACTION_UPDATE], "update adiado")
+				bdd.AssertTrue(t, disruptive[pkg.ACTION_ROLLBACK], "rollback adiado")
+				bdd.AssertTrue(t, disruptive[pkg.ACTION_INTEGRATIONS], "integrations adiado")
+				bdd.AssertFalse(t, disruptive["restart"], "restart executa")
+			})
+		})
+	})
+}
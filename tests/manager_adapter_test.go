@@ -1,12 +1,20 @@
 package tests
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/adapters"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 )
 
 func TestNewManagerAdapter(t *testing.T) {
@@ -15,7 +23,7 @@ func TestNewManagerAdapter(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			s.Given("um logger válido", func() {})
 			s.When("instancio o manager adapter", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.Then("o manager adapter deve ser diferente de nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, manager, "manager deve ser diferente de nil")
@@ -30,7 +38,7 @@ func TestManagerAdapterPrepare(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter instanciado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = manager.Prepare()
@@ -49,7 +57,7 @@ func TestManagerAdapterCollect(t *testing.T) {
 			var err error
 			var metricsCount int
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo Collect", func() {
@@ -71,7 +79,7 @@ func TestManagerAdapterClose(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo Close em paralelo ao Collect", func() {
@@ -97,7 +105,7 @@ func TestManagerAdapterStatus(t *testing.T) {
 			var err error
 			var status any
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo Status para docp-agent.service", func() {
@@ -117,7 +125,7 @@ func TestManagerAdapterDaemonReloadService(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo DaemonReload", func() {
@@ -136,7 +144,7 @@ func TestManagerAdapterRestartService(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo RestartService para docp-manager.service", func() {
@@ -155,7 +163,7 @@ func TestManagerAdapterStopService(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo StopService para docp-manager.service", func() {
@@ -174,7 +182,7 @@ func TestManagerAdapterInstallAgent(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo InstallAgent", func() {
@@ -193,7 +201,7 @@ func TestManagerAdapterUninstallAgent(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo UninstallAgent", func() {
@@ -212,7 +220,7 @@ func TestManagerAdapterAutoUninstall(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo AutoUninstall", func() {
@@ -232,11 +240,11 @@ func TestManagerAdapterDocpAgentApiInstallDatadog(t *testing.T) {
 			var err error
 			var result []byte
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo DocpAgentApiInstallDatadog", func() {
-				result, err = manager.DocpAgentApiInstallDatadog("9ba8aefcaa347216ffa5a5e7b3156f54", "datadoghq.com")
+				result, err = manager.DocpAgentApiInstallDatadog("9ba8aefcaa347216ffa5a5e7b3156f54", "datadoghq.com", "")
 			})
 			s.Then("deve instalar datadog sem erro", func(t *testing.T) {
 				bdd.AssertNoError(t, err, "DocpAgentApiInstallDatadog não deve retornar erro")
@@ -246,6 +254,36 @@ func TestManagerAdapterDocpAgentApiInstallDatadog(t *testing.T) {
 	})
 }
 
+func TestManagerAdapterDocpAgentApiUpgradeDatadog(t *testing.T) {
+	bdd.Feature(t, "ManagerAdapter", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
+		scenario("atualizar datadog via API com transação", func(s *bdd.Scenario) {
+			var manager *adapters.ManagerAdapter
+			var server *httptest.Server
+			var received dto.DatadogInstallDTO
+			var err error
+			s.Given("um manager adapter apontando para api docp de teste", func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					json.NewDecoder(r.Body).Decode(&received)
+					w.WriteHeader(http.StatusAccepted)
+				}))
+				config := testRuntimeConfig()
+				config.AgentPort = server.URL[strings.LastIndex(server.URL, ":")+1:]
+				manager = adapters.NewManagerAdapter(logger, config)
+				err = manager.Prepare()
+			})
+			s.When("chamo DocpAgentApiUpgradeDatadog", func() {
+				defer server.Close()
+				_, err = manager.DocpAgentApiUpgradeDatadog("7.52.1", "01JTRANSACTIONUPGRADE")
+			})
+			s.Then("deve enviar versão e transação para api docp", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "DocpAgentApiUpgradeDatadog não deve retornar erro")
+				bdd.AssertEqual(t, "7.52.1", received.Version, "versão enviada")
+				bdd.AssertEqual(t, "01JTRANSACTIONUPGRADE", received.TransactionId, "transação enviada")
+			})
+		})
+	})
+}
+
 func TestManagerAdapterDocpAgentApiUninstallDatadog(t *testing.T) {
 	bdd.Feature(t, "ManagerAdapter", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
 		scenario("desinstalar datadog via API", func(s *bdd.Scenario) {
@@ -253,7 +291,7 @@ func TestManagerAdapterDocpAgentApiUninstallDatadog(t *testing.T) {
 			var err error
 			var result []byte
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo DocpAgentApiUninstallDatadog", func() {
@@ -274,7 +312,7 @@ func TestManagerAdapterSaveStateReceived(t *testing.T) {
 			var err error
 			var data = []byte(`{...}`) // Use o JSON real do teste original
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo SaveStateReceived", func() {
@@ -294,7 +332,7 @@ func TestManagerAdapterSaveStateCurrent(t *testing.T) {
 			var err error
 			var data = []byte(`{...}`) // Use o JSON real do teste original
 			s.Given("um manager adapter preparado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 				err = manager.Prepare()
 			})
 			s.When("chamo SaveStateCurrent", func() {
@@ -313,7 +351,7 @@ func TestManagerAdapterValidate(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter instanciado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("um manager adapter preparado", func() {
 				err = manager.Prepare()
@@ -337,7 +375,7 @@ func TestManagerAdapterFetchAgentVersions(t *testing.T) {
 			var err error
 
 			s.Given("um manager adapter instanciado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("um manager adapter preparado", func() {
 				err = manager.Prepare()
@@ -362,7 +400,7 @@ func TestManagerAdapterGetAgentVersion(t *testing.T) {
 			var version string
 			var err error
 			s.Given("um manager adapter instanciado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("um manager adapter preparado", func() {
 				err = manager.Prepare()
@@ -385,7 +423,7 @@ func TestManagerAdapterSaveAgentVersion(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter instanciado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("um manager adapter preparado", func() {
 				err = manager.Prepare()
@@ -398,6 +436,77 @@ func TestManagerAdapterSaveAgentVersion(t *testing.T) {
 				bdd.AssertNoError(t, err, "SaveAgentVersion não deve retornar erro")
 			})
 		})
+		scenario("Salvar versão do agent mantendo api key em texto puro no secret store", func(s *bdd.Scenario) {
+			var manager *adapters.ManagerAdapter
+			var dir, apiKey, content string
+			var err error
+			s.Given("config com api key nova em texto puro", func() {
+				dir = t.TempDir()
+				config := testRuntimeConfig()
+				config.WorkDirPath = dir
+				config.ConfigFilePath = filepath.Join(dir, "config.yml")
+				err = os.WriteFile(config.ConfigFilePath, []byte("version: 0.1.0\nagent:\n  apiKey: rotated-api-key\n"), 0600)
+				bdd.AssertNoError(t, err, "escrita do config não deve retornar erro")
+				if err = pkg.NewSecretStore(utils.GetSecretsDirPath(dir)).Set(pkg.SECRET_NAME_API_KEY, "old-api-key"); err != nil {
+					bdd.AssertNoError(t, err, "Set não deve retornar erro")
+				}
+				manager = adapters.NewManagerAdapter(logger, config)
+				err = manager.Prepare()
+				bdd.AssertNoError(t, err, "Prepare não deve retornar erro")
+			})
+			s.When("chamo SaveAgentVersion", func() {
+				if err = manager.SaveAgentVersion("0.1.1"); err == nil {
+					apiKey, err = pkg.NewSecretStore(utils.GetSecretsDirPath(dir)).Get(pkg.SECRET_NAME_API_KEY)
+				}
+				bytes, _ := os.ReadFile(filepath.Join(dir, "config.yml"))
+				content = string(bytes)
+			})
+			s.Then("secret store deve ter a api key nova", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "SaveAgentVersion não deve retornar erro")
+				bdd.AssertEqual(t, "rotated-api-key", apiKey, "api key no secret store")
+				bdd.AssertFalse(t, strings.Contains(content, "rotated-api-key"), "api key não deve ficar em texto puro")
+				bdd.AssertTrue(t, strings.Contains(content, "0.1.1"), "versão deve ser salva")
+			})
+		})
+	})
+}
+
+func TestManagerAdapterGetStateDeferring(t *testing.T) {
+	bdd.Feature(t, "ManagerAdapter", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
+		scenario("Deve adiar sinal de update do docp-agent fora da janela", func(s *bdd.Scenario) {
+			var manager *adapters.ManagerAdapter
+			var actions []byte
+			var deferred []dto.StateAction
+			var err error
+			s.Given("sinal de update do docp-agent recebido", func() {
+				dir := t.TempDir()
+				config := testRuntimeConfig()
+				config.WorkDirPath = dir
+				config.ConfigFilePath = filepath.Join(dir, "config.yml")
+				manager = adapters.NewManagerAdapter(logger, config)
+				if err = os.MkdirAll(filepath.Join(dir, "state"), 0755); err == nil {
+					err = manager.Prepare()
+				}
+				if err == nil {
+					err = manager.SaveStateReceived([]byte(`{"signal":{"type":"update","agents":{"docp-agent":{"version":"1.2.0"}}}}`))
+				}
+				bdd.AssertNoError(t, err, "preparação não deve retornar erro")
+			})
+			s.When("ações são buscadas fora da janela", func() {
+				actions, deferred, err = manager.GetStateDeferring(func(action dto.StateAction) bool {
+					return utils.IsDisruptiveAction(action.Action)
+				})
+			})
+			s.Then("update é adiado e não executado", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "GetStateDeferring não deve retornar erro")
+				bdd.AssertFalse(t, strings.Contains(string(actions), `"docp-agent"`), "update não deve executar")
+				found := false
+				for _, action := range deferred {
+					found = found || (action.Type == "docp-agent" && action.Action == pkg.ACTION_UPDATE && action.Version == "1.2.0")
+				}
+				bdd.AssertTrue(t, found, "update do docp-agent adiado")
+			})
+		})
 	})
 }
 
@@ -408,7 +517,7 @@ func TestManagerAdapterGetAgentRollbackVersion(t *testing.T) {
 			var version string
 			var err error
 			s.Given("um manager adapter instanciado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("um manager adapter preparado", func() {
 				err = manager.Prepare()
@@ -431,7 +540,7 @@ func TestManagerAdapterSaveAgentRollbackVersion(t *testing.T) {
 			var manager *adapters.ManagerAdapter
 			var err error
 			s.Given("um manager adapter instanciado", func() {
-				manager = adapters.NewManagerAdapter(logger)
+				manager = adapters.NewManagerAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("um manager adapter preparado", func() {
 				err = manager.Prepare()
@@ -446,3 +555,109 @@ func TestManagerAdapterSaveAgentRollbackVersion(t *testing.T) {
 		})
 	})
 }
+
+func TestManagerAdapterUploadDiagnostics(t *testing.T) {
+	bdd.Feature(t, "ManagerAdapter", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
+		// prepareDiagnosticsManager return manager adapter with work dir of
+		// test, signal requesting diagnostics and upload answering status
+		prepareDiagnosticsManager := func(t *testing.T, uploadStatus int) (*adapters.ManagerAdapter, string) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/compute/v1/diagnostics" {
+					w.WriteHeader(uploadStatus)
+				}
+			}))
+			t.Cleanup(server.Close)
+			dir := t.TempDir()
+			config := testRuntimeConfig()
+			config.Domain = server.URL
+			config.WorkDirPath = dir
+			config.ConfigFilePath = filepath.Join(dir, "config.yml")
+			os.MkdirAll(filepath.Join(dir, "state"), 0755)
+			os.WriteFile(config.ConfigFilePath, []byte("version: 1.0.0\n"), 0600)
+			manager := adapters.NewManagerAdapter(logger, config)
+			err := manager.Prepare()
+			if err == nil {
+				err = manager.SaveStateReceived([]byte(`{"signal":{"diagnostics":{"request_id":"req-1"}}}`))
+			}
+			bdd.AssertNoError(t, err, "preparação não deve retornar erro")
+			return manager, dir
+		}
+
+		scenario("Deve aguardar backoff após falha do servidor", func(s *bdd.Scenario) {
+			var manager *adapters.ManagerAdapter
+			var dir, requestId string
+			var uploadErr, err error
+			s.Given("servidor retornando erro interno", func() {
+				manager, dir = prepareDiagnosticsManager(t, http.StatusInternalServerError)
+			})
+			s.When("upload é executado", func() {
+				uploadErr = manager.UploadDiagnostics("req-1", true)
+				requestId, err = manager.GetDiagnosticsRequest()
+			})
+			s.Then("tentativa é registrada e requisição aguarda backoff", func(t *testing.T) {
+				bdd.AssertErrorContains(t, uploadErr, "500", "erro do upload")
+				bdd.AssertNoError(t, err, "GetDiagnosticsRequest não deve retornar erro")
+				bdd.AssertEqual(t, "", requestId, "requisição em backoff")
+				var attempts dto.DiagnosticsAttempts
+				content, _ := os.ReadFile(filepath.Join(dir, "state", "diagnostics_attempts"))
+				bdd.AssertNoError(t, json.Unmarshal(content, &attempts), "tentativas em json")
+				bdd.AssertEqual(t, 1, attempts.Attempts, "quantidade de tentativas")
+				bdd.AssertTrue(t, attempts.NextAt.After(time.Now()), "próxima tentativa no futuro")
+			})
+		})
+
+		scenario("Deve encerrar requisição com erro de cliente", func(s *bdd.Scenario) {
+			var manager *adapters.ManagerAdapter
+			var dir string
+			var uploadErr error
+			s.Given("servidor retornando requisição inválida", func() {
+				manager, dir = prepareDiagnosticsManager(t, http.StatusBadRequest)
+			})
+			s.When("upload é executado", func() {
+				uploadErr = manager.UploadDiagnostics("req-1", true)
+			})
+			s.Then("requisição é registrada como finalizada", func(t *testing.T) {
+				bdd.AssertErrorContains(t, uploadErr, "400", "erro do upload")
+				content, _ := os.ReadFile(filepath.Join(dir, "state", "diagnostics"))
+				bdd.AssertEqual(t, "req-1", string(content), "requisição finalizada")
+			})
+		})
+	})
+}
+
+func TestManagerAdapterExisteOtherVendors(t *testing.T) {
+	bdd.Feature(t, "ManagerAdapter", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
+		// existeOtherVendors return result for vendors requested in signal
+		existeOtherVendors := func(t *testing.T, vendors string) (bool, error) {
+			dir := t.TempDir()
+			config := testRuntimeConfig()
+			config.WorkDirPath = dir
+			config.ConfigFilePath = filepath.Join(dir, "config.yml")
+			os.MkdirAll(filepath.Join(dir, "state"), 0755)
+			manager := adapters.NewManagerAdapter(logger, config)
+			err := manager.Prepare()
+			if err == nil {
+				err = manager.SaveStateReceived([]byte(`{"signal":{"type":"uninstall","remove_other_vendors":` + vendors + `}}`))
+			}
+			if err != nil {
+				return false, err
+			}
+			return manager.ExisteOtherVendors()
+		}
+
+		scenario("Deve considerar vendors listados e all como vendors removidos", func(s *bdd.Scenario) {
+			var listed, datadog bool
+			var errListed, errDatadog error
+			s.When("vendors são verificados", func() {
+				listed, errListed = existeOtherVendors(t, `["newrelic"]`)
+				datadog, errDatadog = existeOtherVendors(t, `["datadog"]`)
+			})
+			s.Then("vendors listados existem para remoção", func(t *testing.T) {
+				bdd.AssertNoError(t, errListed, "ExisteOtherVendors não deve retornar erro")
+				bdd.AssertNoError(t, errDatadog, "ExisteOtherVendors não deve retornar erro")
+				bdd.AssertTrue(t, listed, "newrelic listado")
+				bdd.AssertTrue(t, datadog, "datadog listado")
+			})
+		})
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/agents"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 	"github.com/DelfiaProducts/docp-agent-os-instance/operators"
 )
 
@@ -13,7 +14,7 @@ func TestManagerAgentStart(t *testing.T) {
 		Scenario("Deve iniciar o ManagerAgent sem erro", func(s *bdd.Scenario) {
 			var err error
 			s.When("Start é chamado", func() {
-				operator := operators.NewManagerOperator()
+				operator := operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 				managerAgent := agents.NewManagerAgent(operator)
 				err = managerAgent.Start()
 			})
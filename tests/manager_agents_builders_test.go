@@ -6,6 +6,7 @@ import (
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/builders"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 )
 
 func TestManagerBuilder(t *testing.T) {
@@ -14,7 +15,7 @@ func TestManagerBuilder(t *testing.T) {
 			var agent any
 			s.Given("nenhum manager criado", func() {})
 			s.When("ManagerBuilder é chamado", func() {
-				agent = builders.ManagerBuilder()
+				agent = builders.ManagerBuilder(utils.DefaultRuntimeConfig())
 			})
 			s.Then("manager não deve ser nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, agent, "Manager deve ser diferente de nil")
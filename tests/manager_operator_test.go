@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 	"github.com/DelfiaProducts/docp-agent-os-instance/operators"
 )
 
@@ -12,7 +13,7 @@ func TestNewManagerOperator(t *testing.T) {
 		var linux *operators.ManagerOperator
 		Scenario("Operador não deve ser nulo", func(s *bdd.Scenario) {
 			s.Given("um ManagerOperator é criado", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 
 			})
 			s.Then("o operador não deve ser nulo", func(t *testing.T) {
@@ -28,7 +29,7 @@ func TestManagerOperatorSetup(t *testing.T) {
 			var linux *operators.ManagerOperator
 			var err error
 			s.Given("um ManagerOperator válido", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err = linux.Setup()
@@ -46,7 +47,7 @@ func TestManagerOperatorRun(t *testing.T) {
 			var linux *operators.ManagerOperator
 			var err error
 			s.Given("um ManagerOperator válido", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err = linux.Setup()
@@ -68,7 +69,7 @@ func TestManagerOperatorStart(t *testing.T) {
 			var linux *operators.ManagerOperator
 			var err error
 			s.Given("um ManagerOperator válido", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err = linux.Setup()
@@ -91,7 +92,7 @@ func TestManagerOperatorGetActions(t *testing.T) {
 			var actions []byte
 			var err error
 			s.Given("um ManagerOperator válido e setup executado", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err = linux.Setup()
@@ -114,7 +115,7 @@ func TestManagerOperatorCheckHealth(t *testing.T) {
 			var linux *operators.ManagerOperator
 			var err error
 			s.Given("um ManagerOperator válido", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err = linux.Setup()
@@ -134,7 +135,7 @@ func TestManagerOperatorUpdateAgent(t *testing.T) {
 			var err error
 
 			s.Given("um ManagerOperator válido", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 			})
 
 			s.When("Setup é chamado", func() {
@@ -161,7 +162,7 @@ func TestManagerOperatorAutoUpdateAgentVersion(t *testing.T) {
 			var linux *operators.ManagerOperator
 			var err error
 			s.Given("um ManagerOperator válido", func() {
-				linux = operators.NewManagerOperator()
+				linux = operators.NewManagerOperator(utils.DefaultRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err = linux.Setup()
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+func TestDetectVendor(t *testing.T) {
+	bdd.Feature(t, "TestDetectVendor", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve detectar vendor pelo nome e pelo caminho do executável", func(s *bdd.Scenario) {
+			var byName, byPath, unknown string
+			s.When("DetectVendor é chamado", func() {
+				byName = pkg.DetectVendor("newrelic-infra", "")
+				byPath = pkg.DetectVendor("agent", "/opt/datadog-agent/bin/agent/agent")
+				unknown = pkg.DetectVendor("nginx", "/usr/sbin/nginx")
+			})
+			s.Then("deve retornar o vendor correto", func(t *testing.T) {
+				bdd.AssertEqual(t, "newrelic", byName, "vendor pelo nome deve ser newrelic")
+				bdd.AssertEqual(t, "datadog", byPath, "vendor pelo caminho deve ser datadog")
+				bdd.AssertEqual(t, "", unknown, "processo desconhecido não deve ter vendor")
+			})
+		})
+	})
+}
+
+func TestProcessFilterFilter(t *testing.T) {
+	bdd.Feature(t, "TestProcessFilterFilter", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		processes := []dto.ProcessInfo{
+			{Name: "nginx", User: "www-data", ExecPath: "/usr/sbin/nginx", CreateTime: time.Now().Add(-time.Hour).UnixMilli()},
+			{Name: "bash", User: "root", ExecPath: "/usr/bin/bash", CreateTime: time.Now().Add(-time.Hour).UnixMilli()},
+			{Name: "sleep", User: "root", ExecPath: "/usr/bin/sleep", CreateTime: time.Now().UnixMilli()},
+			{Name: "agent", User: "dd-agent", ExecPath: "/opt/datadog-agent/bin/agent/agent", Vendor: "datadog", CreateTime: time.Now().UnixMilli()},
+		}
+		Scenario("Deve aplicar regras de exclusão e tempo mínimo mantendo vendors", func(s *bdd.Scenario) {
+			var filter *pkg.ProcessFilter
+			var filtered []dto.ProcessInfo
+			var err error
+			s.Given("NewProcessFilter com regras", func() {
+				filter, err = pkg.NewProcessFilter(dto.ProcessInventoryConfig{
+					MinUptime: "1m",
+					Exclude:   []dto.ProcessRule{{NameRegex: "^bash$"}},
+				})
+			})
+			s.When("Filter é chamado", func() {
+				if filter != nil {
+					filtered = filter.Filter(processes)
+				}
+			})
+			s.Then("deve manter somente nginx e o processo do vendor", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "NewProcessFilter não deve retornar erro")
+				bdd.AssertEqual(t, 2, len(filtered), "quantidade de processos filtrados")
+				if len(filtered) == 2 {
+					bdd.AssertEqual(t, "nginx", filtered[0].Name, "primeiro processo deve ser nginx")
+					bdd.AssertEqual(t, "datadog", filtered[1].Vendor, "segundo processo deve ser do vendor")
+				}
+			})
+		})
+		Scenario("Deve aplicar regras de inclusão por usuário", func(s *bdd.Scenario) {
+			var filter *pkg.ProcessFilter
+			var filtered []dto.ProcessInfo
+			s.Given("NewProcessFilter com inclusão", func() {
+				filter, _ = pkg.NewProcessFilter(dto.ProcessInventoryConfig{
+					Include: []dto.ProcessRule{{User: "root", ExecPath: "/usr/bin/"}},
+				})
+			})
+			s.When("Filter é chamado", func() {
+				if filter != nil {
+					filtered = filter.Filter(processes)
+				}
+			})
+			s.Then("deve manter processos do usuário root e do vendor", func(t *testing.T) {
+				bdd.AssertEqual(t, 3, len(filtered), "quantidade de processos filtrados")
+			})
+		})
+		Scenario("Deve retornar erro com regex inválida", func(s *bdd.Scenario) {
+			var err error
+			s.When("NewProcessFilter é chamado", func() {
+				_, err = pkg.NewProcessFilter(dto.ProcessInventoryConfig{
+					Include: []dto.ProcessRule{{NameRegex: "(["}},
+				})
+			})
+			s.Then("deve retornar erro", func(t *testing.T) {
+				bdd.AssertTrue(t, err != nil, "regex inválida deve retornar erro")
+			})
+		})
+	})
+}
+
+func TestProcessFilterAggregate(t *testing.T) {
+	bdd.Feature(t, "TestProcessFilterAggregate", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve agrupar processos pelo executável", func(s *bdd.Scenario) {
+			var aggregates []dto.ProcessAggregate
+			var vendors []string
+			processes := []dto.ProcessInfo{
+				{Name: "php-fpm", ExecPath: "/usr/sbin/php-fpm", NumThreads: 1, NumFDs: 10},
+				{Name: "php-fpm", ExecPath: "/usr/sbin/php-fpm", NumThreads: 1, NumFDs: 12},
+				{Name: "splunkd", ExecPath: "/opt/splunkforwarder/bin/splunkd", NumThreads: 4, Vendor: "splunk"},
+			}
+			s.When("Aggregate é chamado", func() {
+				filter, _ := pkg.NewProcessFilter(dto.ProcessInventoryConfig{Aggregate: true})
+				aggregates = filter.Aggregate(processes)
+				vendors = pkg.ProcessVendors(processes)
+			})
+			s.Then("deve retornar grupos ordenados pela quantidade", func(t *testing.T) {
+				bdd.AssertEqual(t, 2, len(aggregates), "quantidade de grupos")
+				if len(aggregates) == 2 {
+					bdd.AssertEqual(t, 2, aggregates[0].Count, "quantidade do php-fpm")
+					bdd.AssertEqual(t, int32(22), aggregates[0].NumFDs, "soma dos fds do php-fpm")
+					bdd.AssertEqual(t, "splunk", aggregates[1].Vendor, "vendor do splunkd")
+				}
+				bdd.AssertEqual(t, 1, len(vendors), "quantidade de vendors")
+			})
+		})
+	})
+}
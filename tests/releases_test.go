@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// createRelease create dir of release with binaries in releases dir
+func createRelease(t *testing.T, dir, version string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, version), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, binary := range []string{"manager", "agent"} {
+		if err := os.WriteFile(filepath.Join(dir, version, binary), []byte(version), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReleaseManifest(t *testing.T) {
+	bdd.Feature(t, "TestReleaseManifest", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve manter últimas releases verificadas e remover antigas", func(s *bdd.Scenario) {
+			var dir string
+			var removed []string
+			var err error
+			var previous string
+			s.Given("releases instaladas com saúde registrada", func() {
+				dir = t.TempDir()
+				now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+				manifest, _ := utils.LoadReleaseManifest(dir)
+				for i, version := range []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0"} {
+					createRelease(t, dir, version)
+					manifest.Record(version, now.Add(time.Duration(i)*time.Hour))
+					manifest.SetHealth(version, pkg.RELEASE_HEALTH_HEALTHY, now)
+				}
+				manifest.SetHealth("1.3.0", pkg.RELEASE_HEALTH_FAILED, now)
+				createRelease(t, dir, "0.9.0")
+				if err := manifest.Save(); err != nil {
+					t.Fatal(err)
+				}
+			})
+			s.When("releases são podadas mantendo duas", func() {
+				manifest, _ := utils.LoadReleaseManifest(dir)
+				removed, err = manifest.Prune("1.4.0", 2)
+				manifest.Save()
+				manifest, _ = utils.LoadReleaseManifest(dir)
+				previous, _ = manifest.Previous("1.4.0")
+			})
+			s.Then("somente releases retidas devem permanecer", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "poda sem erro")
+				bdd.AssertEqual(t, 3, len(removed), "releases removidas")
+				bdd.AssertEqual(t, "1.2.0", previous, "release anterior saudável")
+				// 0.9.0 is not registered in manifest, it is not removed
+				for _, version := range []string{"1.4.0", "1.2.0", "0.9.0"} {
+					_, errStat := os.Stat(filepath.Join(dir, version))
+					bdd.AssertTrue(t, errStat == nil, "release retida "+version)
+				}
+				for _, version := range []string{"1.0.0", "1.1.0", "1.3.0"} {
+					_, errStat := os.Stat(filepath.Join(dir, version))
+					bdd.AssertTrue(t, os.IsNotExist(errStat), "release removida "+version)
+				}
+			})
+		})
+
+		Scenario("Deve permitir rollback somente para releases retidas", func(s *bdd.Scenario) {
+			var manifest *utils.ReleaseManifest
+			s.Given("release adotada e release com falha", func() {
+				dir := t.TempDir()
+				createRelease(t, dir, "1.0.0")
+				createRelease(t, dir, "1.1.0")
+				manifest, _ = utils.LoadReleaseManifest(dir)
+				manifest.Adopt("1.0.0", pkg.RELEASE_HEALTH_HEALTHY)
+				manifest.Adopt("../etc", pkg.RELEASE_HEALTH_HEALTHY)
+				manifest.Record("1.1.0", time.Now())
+				manifest.SetHealth("1.1.0", pkg.RELEASE_HEALTH_FAILED, time.Now())
+			})
+			s.Then("somente release saudável pode ser alvo", func(t *testing.T) {
+				bdd.AssertTrue(t, manifest.Retained("1.0.0"), "release adotada")
+				bdd.AssertFalse(t, manifest.Retained("1.1.0"), "release com falha")
+				bdd.AssertFalse(t, manifest.Retained("0.9.0"), "release não instalada")
+				bdd.AssertFalse(t, manifest.Retained("../etc"), "versão inválida")
+				previous, ok := manifest.Previous("1.1.0")
+				bdd.AssertTrue(t, ok, "release anterior encontrada")
+				bdd.AssertEqual(t, "1.0.0", previous, "release anterior")
+			})
+		})
+	})
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// computeIdInBucket return compute id with bucket inside of range
+func computeIdInBucket(min, max int) string {
+	for i := 0; ; i++ {
+		computeId := fmt.Sprintf("compute-%d", i)
+		bucket := utils.RolloutBucket(computeId)
+		if bucket >= min && bucket < max {
+			return computeId
+		}
+	}
+}
+
+func TestResolveAgentUpdate(t *testing.T) {
+	bdd.Feature(t, "TestResolveAgentUpdate", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		versions := dto.AgentVersions{
+			LatestVersion: "1.2.0",
+			Rollout: &dto.AgentRollout{
+				Rings: []dto.RolloutRing{
+					{Name: "canary", Percentage: 5, StartAt: now.Add(-time.Hour)},
+					{Name: "broad", Percentage: 100, StartAt: now.Add(time.Hour)},
+				},
+			},
+		}
+
+		Scenario("Deve liberar versão somente para anéis iniciados", func(s *bdd.Scenario) {
+			var canary, broad dto.AgentUpdateRing
+			s.When("anel do host é resolvido", func() {
+				canary, _ = utils.ResolveAgentUpdate(versions, computeIdInBucket(0, 5), dto.StateCheckDocpAgent{Version: "latest"}, now)
+				broad, _ = utils.ResolveAgentUpdate(versions, computeIdInBucket(5, 100), dto.StateCheckDocpAgent{Version: "latest"}, now)
+			})
+			s.Then("somente canary deve receber versão", func(t *testing.T) {
+				bdd.AssertEqual(t, "canary", canary.Ring, "anel canary")
+				bdd.AssertEqual(t, "1.2.0", canary.Version, "versão liberada")
+				bdd.AssertEqual(t, "broad", broad.Ring, "anel broad")
+				bdd.AssertEqual(t, "", broad.Version, "versão não liberada")
+			})
+		})
+
+		Scenario("Deve respeitar porcentagem, pin e hold do host", func(s *bdd.Scenario) {
+			var waiting, pinned, held dto.AgentUpdateRing
+			s.When("host fora da porcentagem e com pin ou hold", func() {
+				percentage := dto.AgentVersions{LatestVersion: "1.2.0", Versions: []string{"1.0.5", "1.1.0", "1.2.0"}, Rollout: &dto.AgentRollout{Version: "1.1.0", Percentage: 10}}
+				computeId := computeIdInBucket(10, 100)
+				waiting, _ = utils.ResolveAgentUpdate(percentage, computeId, dto.StateCheckDocpAgent{Version: "latest"}, now)
+				pinned, _ = utils.ResolveAgentUpdate(percentage, computeId, dto.StateCheckDocpAgent{Version: "latest", Pin: "1.0.5"}, now)
+				held, _ = utils.ResolveAgentUpdate(percentage, computeIdInBucket(0, 10), dto.StateCheckDocpAgent{Version: "latest", Hold: true}, now)
+			})
+			s.Then("versão deve seguir regras do host", func(t *testing.T) {
+				bdd.AssertEqual(t, "waiting", waiting.Ring, "fora da porcentagem")
+				bdd.AssertEqual(t, "", waiting.Version, "sem versão")
+				bdd.AssertEqual(t, "1.0.5", pinned.Version, "versão do pin")
+				bdd.AssertEqual(t, "rollout", held.Ring, "dentro da porcentagem")
+				bdd.AssertEqual(t, "", held.Version, "hold bloqueia atualização")
+				bdd.AssertEqual(t, utils.RolloutBucket("compute-1"), utils.RolloutBucket("compute-1"), "bucket determinístico")
+			})
+		})
+	})
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+// testRuntimeConfig return runtime config loaded from envs of test,
+// with defaults for values not informed
+func testRuntimeConfig() dto.RuntimeConfig {
+	config, _ := utils.LoadRuntimeConfig(nil)
+	return config
+}
+
+func TestLoadRuntimeConfig(t *testing.T) {
+	bdd.Feature(t, "TestLoadRuntimeConfig", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve aplicar arquivo, env e flags nesta ordem", func(s *bdd.Scenario) {
+			var config dto.RuntimeConfig
+			var err error
+			var configPath string
+			s.Given("config com seção runtime e env", func() {
+				dir := t.TempDir()
+				configPath = filepath.Join(dir, "config.yml")
+				content := "runtime:\n  domain: https://file.example.com\n  agent_port: \"9000\"\n  intervals:\n    tasks: 30s\n    metadata: 2h\n"
+				if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("DOCP_CONFIG_FILE_PATH", configPath)
+				t.Setenv("DOCP_WORKDIR_PATH", dir)
+				t.Setenv("DOCP_AGENT_PORT", "9100")
+				t.Setenv("DOCP_TASKS_INTERVAL", "40s")
+			})
+			s.When("config é carregada com flags", func() {
+				config, err = utils.LoadRuntimeConfig([]string{"run", "-p", "/tmp/docp.pid", "--tasks-interval", "50s", "--datadog-health-interval", "2m"})
+			})
+			s.Then("valores devem respeitar a precedência", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "config não deve retornar erro")
+				bdd.AssertEqual(t, configPath, config.ConfigFilePath, "caminho do arquivo")
+				bdd.AssertEqual(t, "https://file.example.com", config.Domain, "domain do arquivo")
+				bdd.AssertEqual(t, "9100", config.AgentPort, "porta do env")
+				bdd.AssertEqual(t, time.Second*50, config.Intervals.Tasks, "intervalo da flag")
+				bdd.AssertEqual(t, time.Hour*2, config.Intervals.Metadata, "intervalo do arquivo")
+				bdd.AssertEqual(t, time.Minute, config.Intervals.StateCheck, "intervalo padrão")
+				bdd.AssertEqual(t, time.Minute*2, config.Intervals.DatadogHealth, "intervalo de saúde do datadog da flag")
+				bdd.AssertEqual(t, "/tmp/docp.pid", config.PidFile, "arquivo de pid")
+			})
+		})
+
+		Scenario("Deve retornar todos os erros de validação", func(s *bdd.Scenario) {
+			var err error
+			s.Given("env com valores inválidos", func() {
+				t.Setenv("DOCP_CONFIG_FILE_PATH", filepath.Join(t.TempDir(), "config.yml"))
+				t.Setenv("DOCP_WORKDIR_PATH", "")
+				t.Setenv("DOCP_DOMAIN", "ftp://docp")
+				t.Setenv("DOCP_AGENT_PORT", "70000")
+				t.Setenv("DOCP_DATADOG_HEALTH_INTERVAL", "30s")
+			})
+			s.When("config é carregada", func() {
+				_, err = utils.LoadRuntimeConfig([]string{"run", "--tasks-interval", "1s"})
+			})
+			s.Then("erro deve listar cada campo inválido", func(t *testing.T) {
+				bdd.AssertErrorContains(t, err, "invalid configuration", "erro de configuração")
+				bdd.AssertErrorContains(t, err, "domain:", "domain inválido")
+				bdd.AssertErrorContains(t, err, "agent_port:", "porta inválida")
+				bdd.AssertErrorContains(t, err, "intervals.tasks:", "intervalo inválido")
+				bdd.AssertErrorContains(t, err, "intervals.datadog_health:", "intervalo de saúde do datadog inválido")
+			})
+		})
+
+		Scenario("Deve retornar erro para flag desconhecida", func(s *bdd.Scenario) {
+			var err error
+			s.When("config é carregada com flag desconhecida", func() {
+				t.Setenv("DOCP_CONFIG_FILE_PATH", filepath.Join(t.TempDir(), "config.yml"))
+				_, err = utils.LoadRuntimeConfig([]string{"run", "--unknown"})
+			})
+			s.Then("erro deve informar flag", func(t *testing.T) {
+				bdd.AssertErrorContains(t, err, "invalid flags", "flag inválida")
+			})
+		})
+	})
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestSecretStore(t *testing.T) {
+	bdd.Feature(t, "TestSecretStore", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve salvar e recuperar segredo criptografado", func(s *bdd.Scenario) {
+			var dir, value string
+			var err error
+			s.Given("SecretStore em diretório temporário", func() {
+				dir = t.TempDir()
+			})
+			s.When("Set e Get são chamados", func() {
+				store := pkg.NewSecretStore(dir)
+				if err = store.Set(pkg.SECRET_NAME_API_KEY, "my-api-key"); err == nil {
+					value, err = pkg.NewSecretStore(dir).Get(pkg.SECRET_NAME_API_KEY)
+				}
+			})
+			s.Then("deve retornar o segredo sem texto puro no disco", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "SecretStore não deve retornar erro")
+				bdd.AssertEqual(t, "my-api-key", value, "valor do segredo")
+				content, _ := os.ReadFile(filepath.Join(dir, pkg.SECRET_STORE_FILE_NAME))
+				bdd.AssertFalse(t, strings.Contains(string(content), "my-api-key"), "segredo não deve estar em texto puro")
+				info, statErr := os.Stat(filepath.Join(dir, pkg.SECRET_KEY_FILE_NAME))
+				bdd.AssertNoError(t, statErr, "arquivo de chave deve existir")
+				if statErr == nil {
+					bdd.AssertEqual(t, os.FileMode(0600), info.Mode().Perm(), "permissão do arquivo de chave")
+				}
+			})
+		})
+		Scenario("Deve manter segredos escritos por stores concorrentes", func(s *bdd.Scenario) {
+			var dir string
+			var secrets []string
+			var errs []error
+			s.Given("dois SecretStore no mesmo diretório", func() {
+				dir = t.TempDir()
+			})
+			s.When("os dois escrevem ao mesmo tempo", func() {
+				var wg sync.WaitGroup
+				var mu sync.Mutex
+				for store := 0; store < 2; store++ {
+					wg.Add(1)
+					go func(store int) {
+						defer wg.Done()
+						secretStore := pkg.NewSecretStore(dir)
+						for i := 0; i < 20; i++ {
+							if err := secretStore.Set(fmt.Sprintf("store-%d-%d", store, i), "value"); err != nil {
+								mu.Lock()
+								errs = append(errs, err)
+								mu.Unlock()
+							}
+						}
+					}(store)
+				}
+				wg.Wait()
+				for store := 0; store < 2; store++ {
+					for i := 0; i < 20; i++ {
+						value, _ := pkg.NewSecretStore(dir).Get(fmt.Sprintf("store-%d-%d", store, i))
+						if len(value) > 0 {
+							secrets = append(secrets, value)
+						}
+					}
+				}
+			})
+			s.Then("nenhuma escrita deve ser perdida", func(t *testing.T) {
+				bdd.AssertEqual(t, 0, len(errs), "escritas sem erro")
+				bdd.AssertEqual(t, 40, len(secrets), "todos os segredos salvos")
+			})
+		})
+		Scenario("Deve manter segredo quando config é serializado sem segredo", func(s *bdd.Scenario) {
+			var value string
+			var data []byte
+			var errEmpty, err error
+			s.When("config sem api key é serializado e salvo", func() {
+				store := pkg.NewSecretStore(t.TempDir())
+				_ = store.Set(pkg.SECRET_NAME_API_KEY, "my-api-key")
+				ymlClient := pkg.NewYmlClientWithSecrets(store)
+				config := dto.ConfigAgent{Version: "1.0.0", AccessToken: "my-token"}
+				data, err = ymlClient.Marshall(&config)
+				if err == nil {
+					err = ymlClient.SaveSecrets(&config)
+				}
+				errEmpty = store.Set(pkg.SECRET_NAME_API_KEY, "")
+				value, _ = store.Get(pkg.SECRET_NAME_API_KEY)
+			})
+			s.Then("api key salva não deve ser removida", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "Marshall e SaveSecrets sem erro")
+				bdd.AssertEqual(t, "my-api-key", value, "api key mantida")
+				bdd.AssertEqual(t, pkg.ErrSecretEmpty, errEmpty, "valor vazio rejeitado")
+				bdd.AssertFalse(t, strings.Contains(string(data), "my-token"), "token fora do yml")
+			})
+		})
+		Scenario("Deve retornar erro com chave diferente", func(s *bdd.Scenario) {
+			var err error
+			s.When("arquivo de chave é substituído", func() {
+				dir := t.TempDir()
+				_ = pkg.NewSecretStore(dir).Set(pkg.SECRET_NAME_ACCESS_TOKEN, "token")
+				_ = os.WriteFile(filepath.Join(dir, pkg.SECRET_KEY_FILE_NAME), []byte(strings.Repeat("k", 32)), 0600)
+				_, err = pkg.NewSecretStore(dir).Get(pkg.SECRET_NAME_ACCESS_TOKEN)
+			})
+			s.Then("deve retornar chave inválida", func(t *testing.T) {
+				bdd.AssertEqual(t, pkg.ErrSecretKeyInvalid, err, "erro de chave inválida")
+			})
+		})
+	})
+}
+
+func TestMigrateConfigSecrets(t *testing.T) {
+	bdd.Feature(t, "TestMigrateConfigSecrets", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve migrar segredos em texto puro do config", func(s *bdd.Scenario) {
+			var configPath string
+			var migrated bool
+			var config dto.ConfigAgent
+			var err error
+			s.Given("config com api key e token em texto puro", func() {
+				workDir := t.TempDir()
+				t.Setenv("DOCP_WORKDIR_PATH", workDir)
+				configPath = filepath.Join(workDir, "config.yml")
+				_ = os.WriteFile(configPath, []byte("version: 1.0.0\nagent:\n  apiKey: plain-key\naccesstoken: plain-token\n"), 0600)
+			})
+			s.When("MigrateConfigSecrets é chamado", func() {
+				migrated, err = utils.MigrateConfigSecrets(configPath, filepath.Dir(configPath))
+				if err == nil {
+					ymlClient := utils.NewConfigYmlClient(filepath.Dir(configPath))
+					content, _ := os.ReadFile(configPath)
+					err = ymlClient.Unmarshall(content, &config)
+				}
+			})
+			s.Then("config não deve conter segredos em texto puro", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "MigrateConfigSecrets não deve retornar erro")
+				bdd.AssertTrue(t, migrated, "config deve ser migrado")
+				content, _ := os.ReadFile(configPath)
+				bdd.AssertFalse(t, strings.Contains(string(content), "plain-key"), "api key não deve estar no config")
+				bdd.AssertFalse(t, strings.Contains(string(content), "plain-token"), "token não deve estar no config")
+				bdd.AssertEqual(t, "plain-key", config.Agent.ApiKey, "api key do secret store")
+				bdd.AssertEqual(t, "plain-token", config.AccessToken, "token do secret store")
+				bdd.AssertEqual(t, "1.0.0", config.Version, "versão preservada")
+			})
+		})
+	})
+}
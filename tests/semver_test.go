@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestResolveVersion(t *testing.T) {
+	bdd.Feature(t, "TestResolveVersion", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		versions := []string{"0.3.0", "0.3.4", "0.4.0", "1.0.0", "1.2.0", "1.2.5", "1.3.0-rc.1", "2.0.0"}
+
+		Scenario("Deve resolver a maior versão que satisfaz a restrição", func(s *bdd.Scenario) {
+			resolved := make(map[string]string)
+			s.When("restrições são resolvidas", func() {
+				for _, constraint := range []string{"~0.3", "^1.2.0", ">=1.0.0 <2.0.0", "1.2.x", "1.0.0", "<0.4.0 || >=2.0.0"} {
+					resolved[constraint], _ = utils.ResolveVersion(constraint, versions, false)
+				}
+			})
+			s.Then("versões devem respeitar as restrições", func(t *testing.T) {
+				bdd.AssertEqual(t, "0.3.4", resolved["~0.3"], "tilde")
+				bdd.AssertEqual(t, "1.2.5", resolved["^1.2.0"], "caret")
+				bdd.AssertEqual(t, "1.2.5", resolved[">=1.0.0 <2.0.0"], "intervalo sem pre-release")
+				bdd.AssertEqual(t, "1.2.5", resolved["1.2.x"], "versão parcial")
+				bdd.AssertEqual(t, "1.0.0", resolved["1.0.0"], "versão exata")
+				bdd.AssertEqual(t, "2.0.0", resolved["<0.4.0 || >=2.0.0"], "alternativas")
+			})
+		})
+
+		Scenario("Deve incluir pre-release somente quando permitido", func(s *bdd.Scenario) {
+			var resolved string
+			var err error
+			s.When("pre-release é permitido", func() {
+				resolved, err = utils.ResolveVersion(">=1.2.0 <2.0.0", versions, true)
+			})
+			s.Then("pre-release deve ser escolhido", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "restrição válida")
+				bdd.AssertEqual(t, "1.3.0-rc.1", resolved, "pre-release")
+				bdd.AssertTrue(t, utils.IsDowngrade("1.3.0", "1.3.0-rc.1"), "pre-release é menor que release")
+				bdd.AssertFalse(t, utils.IsDowngrade("1.2.0", "1.2.5"), "atualização não é downgrade")
+			})
+		})
+
+		Scenario("Deve retornar erro para restrição inválida ou sem versão", func(s *bdd.Scenario) {
+			var invalidErr, notFoundErr error
+			s.When("restrições são resolvidas", func() {
+				_, invalidErr = utils.ResolveVersion(">=banana", versions, false)
+				_, notFoundErr = utils.ResolveVersion("^3.0.0", versions, false)
+			})
+			s.Then("erros devem ser retornados", func(t *testing.T) {
+				bdd.AssertErrorContains(t, invalidErr, "invalid version", "restrição inválida")
+				bdd.AssertErrorContains(t, notFoundErr, "agent version not found", "sem versão")
+			})
+		})
+
+		Scenario("Deve ignorar versão em rollout para host fora do anel", func(s *bdd.Scenario) {
+			var ring dto.AgentUpdateRing
+			var err error
+			s.When("restrição é resolvida para host aguardando rollout", func() {
+				agentVersions := dto.AgentVersions{LatestVersion: "1.2.5", Versions: versions, Rollout: &dto.AgentRollout{Percentage: 10}}
+				ring, err = utils.ResolveAgentUpdate(agentVersions, computeIdInBucket(10, 100), dto.StateCheckDocpAgent{Version: "^1.2.0"}, time.Now())
+			})
+			s.Then("versão anterior ao rollout deve ser escolhida", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "restrição válida")
+				bdd.AssertEqual(t, "1.2.0", ring.Version, "versão fora do rollout")
+			})
+		})
+	})
+}
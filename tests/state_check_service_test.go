@@ -14,7 +14,7 @@ func TestNewStateCheckService(t *testing.T) {
 			var stateCheck any
 			s.Given("nenhum service criado", func() {})
 			s.When("NewStateCheckService é chamado", func() {
-				stateCheck = services.NewStateCheckService(logger)
+				stateCheck = services.NewStateCheckService(logger, testRuntimeConfig())
 			})
 			s.Then("service não deve ser nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, stateCheck, "Service deve ser diferente de nil")
@@ -28,7 +28,7 @@ func TestStateCheckServiceSetup(t *testing.T) {
 		Scenario("Setup não deve retornar erro", func(s *bdd.Scenario) {
 			var stateCheck *services.StateCheckService
 			s.Given("um StateCheckService válido", func() {
-				stateCheck = services.NewStateCheckService(logger)
+				stateCheck = services.NewStateCheckService(logger, testRuntimeConfig())
 			})
 			s.When("Setup é chamado", func() {
 				err := stateCheck.Setup()
@@ -48,7 +48,7 @@ func TestStateCheckServicePreparePayload(t *testing.T) {
 			var apiKey string
 			var err error
 			s.Given("um StateCheckService válido e setup executado", func() {
-				stateCheck = services.NewStateCheckService(logger)
+				stateCheck = services.NewStateCheckService(logger, testRuntimeConfig())
 				err = stateCheck.Setup()
 			})
 			s.When("PreparePayload é chamado", func() {
@@ -70,7 +70,7 @@ func TestStateCheckServiceGetState(t *testing.T) {
 			var statusCode int
 			var err error
 			s.Given("um StateCheckService válido e setup executado", func() {
-				stateCheck = services.NewStateCheckService(logger)
+				stateCheck = services.NewStateCheckService(logger, testRuntimeConfig())
 				err = stateCheck.Setup()
 			})
 			s.When("GetState é chamado", func() {
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestDiffJSON(t *testing.T) {
+	bdd.Feature(t, "TestDiffJSON", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve retornar diferenças entre estados", func(s *bdd.Scenario) {
+			var lines []string
+			var err error
+			current := []byte(`{"signal":{"type":"update","agents":{"docp-agent":{"version":"1.1.0"},"datadog-agent":{"site":"datadoghq.com"}}}}`)
+			received := []byte(`{"signal":{"type":"update","agents":{"docp-agent":{"version":"1.2.0"},"datadog-agent":{"version":"7.50.0"}}}}`)
+			s.When("diff é executado", func() {
+				lines, err = pkg.DiffJSON(current, received)
+			})
+			s.Then("deve listar caminhos adicionados, removidos e alterados", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "diff não deve retornar erro")
+				bdd.AssertEqual(t, 3, len(lines), "quantidade de diferenças")
+				bdd.AssertEqual(t, `- signal.agents.datadog-agent.site: "datadoghq.com"`, lines[0], "caminho removido")
+				bdd.AssertEqual(t, `+ signal.agents.datadog-agent.version: "7.50.0"`, lines[1], "caminho adicionado")
+				bdd.AssertEqual(t, `~ signal.agents.docp-agent.version: "1.1.0" -> "1.2.0"`, lines[2], "caminho alterado")
+			})
+		})
+
+		Scenario("Deve considerar estado vazio", func(s *bdd.Scenario) {
+			var lines []string
+			var err error
+			s.When("estado atual não existe", func() {
+				lines, err = pkg.DiffJSON(nil, []byte(`{"signal":{"type":"update"}}`))
+			})
+			s.Then("todos os caminhos devem ser adicionados", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "diff não deve retornar erro")
+				bdd.AssertEqual(t, 1, len(lines), "quantidade de diferenças")
+				bdd.AssertEqual(t, `+ signal.type: "update"`, lines[0], "caminho adicionado")
+			})
+		})
+	})
+}
+
+func TestRedactJSON(t *testing.T) {
+	bdd.Feature(t, "TestRedactJSON", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve mascarar segredos do sinal", func(s *bdd.Scenario) {
+			var content []byte
+			var err error
+			signal := []byte(`{"datadog-agent":{"api-key":"my-key","site":"datadoghq.com","envs":[{"name":"DD_API_KEY","value":"0123456789abcdef0123456789abcdef"}]}}`)
+			s.When("sinal é mascarado", func() {
+				content, err = utils.NewRedactor(nil, nil).RedactJSON(signal)
+			})
+			s.Then("segredos não devem aparecer", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "redação não deve retornar erro")
+				bdd.AssertFalse(t, strings.Contains(string(content), "my-key"), "api key mascarada")
+				bdd.AssertFalse(t, strings.Contains(string(content), "0123456789abcdef"), "valor de env mascarado")
+				bdd.AssertTrue(t, strings.Contains(string(content), "datadoghq.com"), "site mantido")
+			})
+		})
+	})
+}
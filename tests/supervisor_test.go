@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestSupervisor(t *testing.T) {
+	bdd.Feature(t, "TestSupervisor", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve cancelar todas as tarefas quando uma falha", func(s *bdd.Scenario) {
+			var supervisor *utils.Supervisor
+			var waitErr error
+			s.Given("supervisor com tarefa bloqueada", func() {
+				supervisor = utils.NewSupervisor(context.Background())
+				supervisor.Go("blocked", func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+			})
+			s.When("outra tarefa retorna erro", func() {
+				supervisor.Go("failed", func(ctx context.Context) error {
+					return errors.New("task failed")
+				})
+				<-supervisor.Context().Done()
+				waitErr = supervisor.Wait(time.Second)
+			})
+			s.Then("erro da tarefa deve ser retornado", func(t *testing.T) {
+				bdd.AssertNoError(t, waitErr, "tarefas finalizadas")
+				bdd.AssertErrorContains(t, supervisor.Err(), "task failed: task failed", "erro da tarefa")
+			})
+		})
+
+		Scenario("Deve recuperar panic de tarefa", func(s *bdd.Scenario) {
+			var supervisor *utils.Supervisor
+			s.When("tarefa entra em panic", func() {
+				supervisor = utils.NewSupervisor(context.Background())
+				supervisor.Go("panic", func(ctx context.Context) error {
+					panic("boom")
+				})
+				<-supervisor.Context().Done()
+			})
+			s.Then("panic deve virar erro", func(t *testing.T) {
+				bdd.AssertNoError(t, supervisor.Wait(time.Second), "tarefas finalizadas")
+				bdd.AssertErrorContains(t, supervisor.Err(), "panic: boom", "erro do panic")
+			})
+		})
+
+		Scenario("Deve informar tarefas que não pararam no timeout", func(s *bdd.Scenario) {
+			var supervisor *utils.Supervisor
+			var waitErr error
+			release := make(chan struct{})
+			s.Given("tarefa que ignora o cancelamento", func() {
+				supervisor = utils.NewSupervisor(context.Background())
+				supervisor.Go("stuck", func(ctx context.Context) error {
+					<-release
+					return nil
+				})
+			})
+			s.When("supervisor é parado", func() {
+				supervisor.Stop()
+				waitErr = supervisor.Wait(time.Millisecond * 50)
+			})
+			s.Then("erro deve listar tarefa", func(t *testing.T) {
+				defer close(release)
+				bdd.AssertErrorContains(t, waitErr, "stuck", "tarefa bloqueada")
+				bdd.AssertTrue(t, supervisor.Err() == nil, "cancelamento não é erro")
+			})
+		})
+	})
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/services"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signedToken return token signed with expiry for tests
+func signedToken(expiresAt time.Time) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"compute_id":  "compute-test",
+		"docp_org_id": 1,
+		"exp":         expiresAt.Unix(),
+	})
+	signed, _ := token.SignedString([]byte("secret"))
+	return signed
+}
+
+func TestTokenManagerGetToken(t *testing.T) {
+	bdd.Feature(t, "TestTokenManagerGetToken", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve renovar o token próximo da expiração uma única vez", func(s *bdd.Scenario) {
+			var calls int32
+			var tokens []string
+			var errs []error
+			var mu sync.Mutex
+			newToken := signedToken(time.Now().Add(time.Hour))
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(time.Millisecond * 50)
+				fmt.Fprintf(w, `{"access_token":"%s"}`, newToken)
+			}))
+			defer server.Close()
+			s.Given("config com token próximo da expiração", func() {
+				workDir := t.TempDir()
+				t.Setenv("DOCP_WORKDIR_PATH", workDir)
+				t.Setenv("DOCP_CONFIG_FILE_PATH", filepath.Join(workDir, "config.yml"))
+				t.Setenv("DOCP_DOMAIN", server.URL)
+				content := fmt.Sprintf("agent:\n  api_key: key\naccesstoken: %s\ncomputeid: compute-test\n", signedToken(time.Now().Add(time.Minute)))
+				_ = os.WriteFile(filepath.Join(workDir, "config.yml"), []byte(content), 0600)
+			})
+			s.When("GetToken é chamado concorrentemente", func() {
+				tokenManager := services.NewTokenManager(logger, testRuntimeConfig())
+				if err := tokenManager.Setup(); err != nil {
+					errs = append(errs, err)
+					return
+				}
+				wg := sync.WaitGroup{}
+				for i := 0; i < 5; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						token, err := tokenManager.GetToken()
+						mu.Lock()
+						defer mu.Unlock()
+						tokens = append(tokens, token)
+						if err != nil {
+							errs = append(errs, err)
+						}
+					}()
+				}
+				wg.Wait()
+			})
+			s.Then("deve executar uma chamada de autenticação", func(t *testing.T) {
+				bdd.AssertEqual(t, 0, len(errs), "GetToken não deve retornar erro")
+				bdd.AssertEqual(t, int32(1), atomic.LoadInt32(&calls), "quantidade de chamadas de autenticação")
+				for _, token := range tokens {
+					bdd.AssertEqual(t, newToken, token, "token renovado")
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestBypassProxy(t *testing.T) {
+	bdd.Feature(t, "TestBypassProxy", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve ignorar proxy para hosts do no proxy", func(s *bdd.Scenario) {
+			noProxy := "internal.example.com, .corp.local,10.0.0.0/8"
+			s.Then("deve validar hosts, domínios e cidrs", func(t *testing.T) {
+				bdd.AssertTrue(t, pkg.BypassProxy("localhost", ""), "localhost sempre ignora proxy")
+				bdd.AssertTrue(t, pkg.BypassProxy("127.0.0.1", ""), "loopback sempre ignora proxy")
+				bdd.AssertTrue(t, pkg.BypassProxy("internal.example.com", noProxy), "host exato")
+				bdd.AssertTrue(t, pkg.BypassProxy("api.internal.example.com", noProxy), "subdomínio do host")
+				bdd.AssertTrue(t, pkg.BypassProxy("app.corp.local", noProxy), "domínio")
+				bdd.AssertTrue(t, pkg.BypassProxy("10.1.2.3", noProxy), "cidr")
+				bdd.AssertFalse(t, pkg.BypassProxy("msapi.sandbox.docphq.tech", noProxy), "host externo usa proxy")
+				bdd.AssertFalse(t, pkg.BypassProxy("notinternal.example.com", noProxy), "sufixo sem ponto usa proxy")
+			})
+		})
+	})
+}
+
+func TestNewTransport(t *testing.T) {
+	bdd.Feature(t, "TestNewTransport", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve enviar requisições pelo proxy com autenticação", func(s *bdd.Scenario) {
+			var calls int32
+			var authorization string
+			var err error
+			var res *http.Response
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				authorization = r.Header.Get("Proxy-Authorization")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer proxy.Close()
+			s.When("requisição é executada", func() {
+				var transport http.RoundTripper
+				transport, err = pkg.NewTransport(dto.NetworkConfig{ProxyUrl: proxy.URL, ProxyUser: "user", ProxyPassword: "pass"}, "")
+				if err == nil {
+					client := &http.Client{Transport: transport}
+					res, err = client.Get("http://docp.example.com/index.json")
+				}
+			})
+			s.Then("proxy deve receber a requisição autenticada", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "requisição não deve retornar erro")
+				bdd.AssertEqual(t, http.StatusOK, res.StatusCode, "status da resposta")
+				bdd.AssertEqual(t, int32(1), atomic.LoadInt32(&calls), "quantidade de chamadas no proxy")
+				bdd.AssertEqual(t, "Basic dXNlcjpwYXNz", authorization, "autenticação do proxy")
+			})
+		})
+		Scenario("Deve aplicar no proxy do config com proxy do ambiente", func(s *bdd.Scenario) {
+			var proxyUrl string
+			var err error
+			s.When("proxy é resolvido para host do no proxy", func() {
+				var transport http.RoundTripper
+				transport, err = pkg.NewTransport(dto.NetworkConfig{NoProxy: ".corp.local"}, "")
+				if err == nil {
+					req, _ := http.NewRequest(http.MethodGet, "http://app.corp.local/status", nil)
+					proxy, _ := transport.(*http.Transport).Proxy(req)
+					if proxy != nil {
+						proxyUrl = proxy.String()
+					}
+				}
+			})
+			s.Then("host do no proxy não deve usar proxy", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "NewTransport não deve retornar erro")
+				bdd.AssertEqual(t, "", proxyUrl, "sem proxy")
+			})
+		})
+		Scenario("Deve retornar erro com certificado sem chave", func(s *bdd.Scenario) {
+			var err error
+			s.When("NewTransport é chamado", func() {
+				_, err = pkg.NewTransport(dto.NetworkConfig{ClientCert: "/tmp/client.pem"}, "msapi.sandbox.docphq.tech")
+			})
+			s.Then("deve retornar erro", func(t *testing.T) {
+				bdd.AssertTrue(t, err != nil, "certificado sem chave deve retornar erro")
+			})
+		})
+	})
+}
+
+func TestGetNetworkConfig(t *testing.T) {
+	bdd.Feature(t, "TestGetNetworkConfig", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve sobrescrever configuração com variáveis de ambiente", func(s *bdd.Scenario) {
+			var network dto.NetworkConfig
+			var err error
+			s.Given("variáveis de ambiente definidas", func() {
+				t.Setenv("DOCP_CONFIG_FILE_PATH", t.TempDir()+"/config.yml")
+				t.Setenv("DOCP_PROXY_URL", "http://proxy.local:3128")
+				t.Setenv("DOCP_NO_PROXY", "169.254.169.254")
+			})
+			s.When("GetNetworkConfig é chamado", func() {
+				network, err = utils.GetNetworkConfig(testRuntimeConfig())
+			})
+			s.Then("deve retornar valores das variáveis", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "GetNetworkConfig não deve retornar erro")
+				bdd.AssertEqual(t, "http://proxy.local:3128", network.ProxyUrl, "proxy url")
+				bdd.AssertEqual(t, "169.254.169.254", network.NoProxy, "no proxy")
+			})
+		})
+		Scenario("Deve ler senha do proxy do secret store", func(s *bdd.Scenario) {
+			var network dto.NetworkConfig
+			var content []byte
+			var err error
+			s.Given("config com senha do proxy em texto puro", func() {
+				workDir := t.TempDir()
+				configPath := filepath.Join(workDir, "config.yml")
+				t.Setenv("DOCP_WORKDIR_PATH", workDir)
+				t.Setenv("DOCP_CONFIG_FILE_PATH", configPath)
+				_ = os.WriteFile(configPath, []byte("network:\n  proxy_url: http://proxy.local:3128\n  proxy_user: user\n  proxy_password: plain-pass\n"), 0600)
+				_, err = utils.MigrateConfigSecrets(configPath, workDir)
+				content, _ = os.ReadFile(configPath)
+			})
+			s.When("GetNetworkConfig é chamado", func() {
+				if err == nil {
+					network, err = utils.GetNetworkConfig(testRuntimeConfig())
+				}
+			})
+			s.Then("senha deve vir do secret store", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "GetNetworkConfig não deve retornar erro")
+				bdd.AssertFalse(t, strings.Contains(string(content), "plain-pass"), "senha fora do config")
+				bdd.AssertEqual(t, "plain-pass", network.ProxyPassword, "senha do proxy")
+			})
+		})
+	})
+}
@@ -3,10 +3,12 @@ package tests
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/adapters"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
 	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
 )
 
@@ -16,7 +18,7 @@ func TestNewUpdaterAdapter(t *testing.T) {
 			var updater *adapters.UpdaterAdapter
 			s.Given("um logger válido", func() {})
 			s.When("instancio o updater adapter", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.Then("o updater adapter deve ser diferente de nil", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, updater, "updater deve ser diferente de nil")
@@ -31,7 +33,7 @@ func TestUpdaterAdapterPrepare(t *testing.T) {
 			var updater *adapters.UpdaterAdapter
 			var err error
 			s.Given("um updater adapter instanciado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = updater.Prepare()
@@ -50,7 +52,7 @@ func TestUpdaterAdapterGetAgentVersion(t *testing.T) {
 			var version string
 			var err error
 			s.Given("um updater adapter instanciado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = updater.Prepare()
@@ -73,7 +75,7 @@ func TestUpdaterAdapterGetContentReceived(t *testing.T) {
 			var content []byte
 			var err error
 			s.Given("um updater adapter instanciado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = updater.Prepare()
@@ -97,7 +99,7 @@ func TestUpdaterAdapterGetAgentVersionFromSignal(t *testing.T) {
 			var response []byte
 			var err error
 			s.Given("um updater adapter instanciado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = updater.Prepare()
@@ -123,7 +125,7 @@ func TestUpdaterAdapterGetAgentRollbackVersion(t *testing.T) {
 			var version string
 			var err error
 			s.Given("um updater adapter instanciado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = updater.Prepare()
@@ -149,7 +151,7 @@ func TestUpdaterAdapterExecuteUpdateVersion(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("UpdaterAdapter criado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o updater adapter", func() {
 				err = updater.Prepare()
@@ -166,6 +168,39 @@ func TestUpdaterAdapterExecuteUpdateVersion(t *testing.T) {
 	})
 }
 
+func TestUpdaterAdapterReleaseRetained(t *testing.T) {
+	bdd.Feature(t, "UpdaterAdapter", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
+		scenario("Deve reutilizar somente releases retidas no manifest", func(s *bdd.Scenario) {
+			var updater *adapters.UpdaterAdapter
+			var err error
+			s.Given("releases no diretório com e sem registro no manifest", func() {
+				config := testRuntimeConfig()
+				config.WorkDirPath = t.TempDir()
+				dir := utils.GetReleasesDirPath(config.WorkDirPath)
+				for _, version := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+					createRelease(t, dir, version)
+				}
+				manifest, _ := utils.LoadReleaseManifest(dir)
+				manifest.Record("1.0.0", time.Now())
+				manifest.Record("1.1.0", time.Now())
+				manifest.SetHealth("1.1.0", pkg.RELEASE_HEALTH_FAILED, time.Now())
+				if err := manifest.Save(); err != nil {
+					t.Fatal(err)
+				}
+				updater = adapters.NewUpdaterAdapter(logger, config)
+				err = updater.Prepare()
+			})
+			s.Then("deve baixar novamente releases falhas ou fora do manifest", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "Prepare não deve retornar erro")
+				bdd.AssertTrue(t, updater.ReleaseRetained("1.0.0"), "release registrada deve ser reutilizada")
+				bdd.AssertFalse(t, updater.ReleaseRetained("1.1.0"), "release falha deve ser baixada novamente")
+				bdd.AssertFalse(t, updater.ReleaseRetained("1.2.0"), "release fora do manifest deve ser baixada novamente")
+				bdd.AssertFalse(t, updater.ReleaseRetained("1.3.0"), "release sem diretório deve ser baixada")
+			})
+		})
+	})
+}
+
 func TestUpdaterAdapterExecuteRollbackVersion(t *testing.T) {
 	bdd.Feature(t, "UpdaterAdapter", func(t *testing.T, scenario func(description string, steps func(s *bdd.Scenario))) {
 		scenario("ExecuteRollbackVersion executa (mock)", func(s *bdd.Scenario) {
@@ -176,7 +211,7 @@ func TestUpdaterAdapterExecuteRollbackVersion(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("UpdaterAdapter criado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o updater adapter", func() {
 				err = updater.Prepare()
@@ -204,7 +239,7 @@ func TestUpdaterAdapterFetchAgentVersions(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("UpdaterAdapter criado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.Given("Configurar o updater adapter", func() {
 				err = updater.Prepare()
@@ -228,7 +263,7 @@ func TestUpdaterAdapterValidateSuccessUpdated(t *testing.T) {
 			var success bool
 			var err error
 			s.Given("um updater adapter instanciado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = updater.Prepare()
@@ -254,7 +289,7 @@ func TestUpdaterAdapterUpdaterUninstall(t *testing.T) {
 				bdd.AssertIsNotNil(t, logger, "Logger deve ser criado")
 			})
 			s.Given("um updater adapter instanciado", func() {
-				updater = adapters.NewUpdaterAdapter(logger)
+				updater = adapters.NewUpdaterAdapter(logger, testRuntimeConfig())
 			})
 			s.When("chamo Prepare", func() {
 				err = updater.Prepare()
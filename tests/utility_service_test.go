@@ -20,7 +20,7 @@ func TestNewUtilityService(t *testing.T) {
 				logger = utils.NewDocpLoggerText(os.Stdout)
 			})
 			s.When("eu instancio o serviço de utilitário", func() {
-				utilityService = services.NewUtilityService(logger)
+				utilityService = services.NewUtilityService(logger, testRuntimeConfig())
 			})
 			s.Then("o serviço de utilitário deve ser criado com sucesso", func(t *testing.T) {
 				bdd.AssertIsNotNil(t, utilityService, "o serviço de utilitário deve ser diferente de nil")
@@ -37,7 +37,7 @@ func TestUtilityServiceSetup(t *testing.T) {
 			var utilityService *services.UtilityService
 			s.Given("eu crio o utility service", func() {
 				logger = utils.NewDocpLoggerText(os.Stdout)
-				utilityService = services.NewUtilityService(logger)
+				utilityService = services.NewUtilityService(logger, testRuntimeConfig())
 			})
 			s.When("eu configuro o utility service", func() {
 				err = utilityService.Setup()
@@ -58,7 +58,7 @@ func TestUtilityServiceFetchAgentVersions(t *testing.T) {
 			var versions dto.AgentVersions
 			s.Given("eu crio o utility service", func() {
 				logger = utils.NewDocpLoggerText(os.Stdout)
-				utilityService = services.NewUtilityService(logger)
+				utilityService = services.NewUtilityService(logger, testRuntimeConfig())
 			})
 			s.When("eu configuro o utility service", func() {
 				err = utilityService.Setup()
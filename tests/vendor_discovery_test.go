@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/components"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/dto"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+)
+
+func TestVendorDiscoveryDiscover(t *testing.T) {
+	bdd.Feature(t, "TestVendorDiscoveryDiscover", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve descobrir vendors sem erro", func(s *bdd.Scenario) {
+			var discovery *components.VendorDiscovery
+			var vendors []dto.VendorInfo
+			var err error
+			s.Given("VendorDiscovery configurado", func() {
+				discovery = components.NewVendorDiscovery(logger)
+				err = discovery.Setup()
+			})
+			s.When("Discover é chamado", func() {
+				if err == nil {
+					vendors, err = discovery.Discover()
+				}
+			})
+			s.Then("não deve retornar erro", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "Discover não deve retornar erro")
+			})
+			s.Then("vendors devem ser conhecidos e ter origem", func(t *testing.T) {
+				for _, vendor := range vendors {
+					_, known := pkg.GetVendorDefinition(vendor.Name)
+					bdd.AssertTrue(t, known, "vendor deve ser conhecido")
+					bdd.AssertTrue(t, len(vendor.Sources) > 0, "vendor deve ter origem")
+				}
+			})
+		})
+
+		Scenario("Deve descobrir vendors por serviços, pacotes e processos", func(s *bdd.Scenario) {
+			var vendors []dto.VendorInfo
+			var err error
+			var scans int
+			var discovery *components.VendorDiscovery
+			s.Given("VendorDiscovery com origens injetadas", func() {
+				discovery = components.NewVendorDiscoveryWithSources(logger, components.VendorSources{
+					ServiceStates: func(units []string) (map[string]string, error) {
+						return map[string]string{"datadog-agent.service": "active", "newrelic-infra.service": "inactive"}, nil
+					},
+					Packages: func() (map[string]string, error) {
+						return map[string]string{"newrelic-infra": "1.50.0", "splunkforwarder": "9.1.0", "curl": "8.0.0"}, nil
+					},
+					Processes: func() ([]dto.ProcessInfo, error) {
+						scans++
+						return []dto.ProcessInfo{{Name: "elastic-agent", Vendor: "elastic"}, {Name: "bash"}}, nil
+					},
+				})
+				err = discovery.Setup()
+			})
+			s.When("Discover é chamado", func() {
+				if err == nil {
+					vendors, err = discovery.Discover()
+				}
+			})
+			s.Then("vendors devem ter status e origem de cada fonte", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "Discover não deve retornar erro")
+				byName := make(map[string]dto.VendorInfo)
+				for _, vendor := range vendors {
+					byName[vendor.Name] = vendor
+				}
+				bdd.AssertEqual(t, 4, len(vendors), "vendors encontrados")
+				bdd.AssertEqual(t, pkg.VENDOR_STATUS_RUNNING, byName["datadog"].Status, "serviço ativo")
+				bdd.AssertEqual(t, pkg.VENDOR_STATUS_STOPPED, byName["newrelic"].Status, "serviço parado tem prioridade sobre pacote")
+				bdd.AssertEqual(t, "1.50.0", byName["newrelic"].Version, "versão do pacote")
+				bdd.AssertEqual(t, 2, len(byName["newrelic"].Sources), "serviço e pacote")
+				bdd.AssertEqual(t, pkg.VENDOR_STATUS_INSTALLED, byName["splunk"].Status, "somente pacote")
+				bdd.AssertEqual(t, pkg.VENDOR_SOURCE_PROCESS, byName["elastic"].Sources[0], "processo")
+				bdd.AssertEqual(t, 1, scans, "processos lidos uma vez")
+			})
+			s.Then("vendors de processos já coletados não devem ler processos", func(t *testing.T) {
+				fromInventory := discovery.DiscoverFrom([]string{"elastic"})
+				bdd.AssertEqual(t, 4, len(fromInventory), "vendors encontrados")
+				bdd.AssertEqual(t, 1, scans, "processos não lidos de novo")
+				version, ok := discovery.PackageVersion("splunk")
+				bdd.AssertTrue(t, ok, "pacote instalado")
+				bdd.AssertEqual(t, "9.1.0", version, "versão do pacote")
+			})
+		})
+	})
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/bdd"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/components"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/pkg"
+	"github.com/DelfiaProducts/docp-agent-os-instance/libs/utils"
+)
+
+func TestVendorLinuxOperationUninstallVendor(t *testing.T) {
+	bdd.Feature(t, "TestVendorLinuxOperationUninstallVendor", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve recusar vendors sem receita de desinstalação", func(s *bdd.Scenario) {
+			var operation *components.VendorLinuxOperation
+			var errDatadog, errUnknown error
+			s.Given("VendorLinuxOperation configurado", func() {
+				operation = components.NewVendorLinuxOperation(logger)
+				_ = operation.Setup()
+			})
+			s.When("UninstallVendor é chamado", func() {
+				errDatadog = operation.UninstallVendor("datadog")
+				errUnknown = operation.UninstallVendor("unknown")
+			})
+			s.Then("deve retornar vendor não suportado", func(t *testing.T) {
+				bdd.AssertTrue(t, errors.Is(errDatadog, pkg.ErrVendorNotSupported), "datadog deve ser desinstalado pelas ações do datadog")
+				bdd.AssertTrue(t, errors.Is(errUnknown, pkg.ErrVendorNotSupported), "vendor desconhecido não deve ser suportado")
+			})
+		})
+		Scenario("Deve possuir receita para os vendors suportados", func(s *bdd.Scenario) {
+			s.Then("vendors devem ter pacote ou comando de desinstalação", func(t *testing.T) {
+				for _, name := range []string{"newrelic", "dynatrace", "zabbix", "elastic"} {
+					vendor, ok := pkg.GetVendorDefinition(name)
+					bdd.AssertTrue(t, ok, "vendor deve ser conhecido")
+					bdd.AssertTrue(t, len(vendor.Packages) > 0 || len(vendor.UninstallCommand) > 0, "vendor deve ter receita")
+					bdd.AssertTrue(t, len(vendor.PurgePaths) > 0, "vendor deve ter caminhos para limpeza")
+				}
+			})
+		})
+	})
+}
+
+func TestRemoveOtherVendors(t *testing.T) {
+	bdd.Feature(t, "TestRemoveOtherVendors", func(t *testing.T, Scenario func(description string, steps func(s *bdd.Scenario))) {
+		Scenario("Deve expandir all sem datadog", func(s *bdd.Scenario) {
+			var all, listed []string
+			s.When("vendors do config são expandidos", func() {
+				detected := []string{"newrelic", "datadog", "dynatrace"}
+				all = utils.ExpandRemoveVendors([]string{"all"}, detected)
+				listed = utils.ExpandRemoveVendors([]string{"datadog", "newrelic"}, detected)
+			})
+			s.Then("datadog somente quando listado", func(t *testing.T) {
+				bdd.AssertEqual(t, 2, len(all), "vendors detectados")
+				bdd.AssertEqual(t, "newrelic", all[0], "newrelic")
+				bdd.AssertEqual(t, "dynatrace", all[1], "dynatrace")
+				bdd.AssertEqual(t, 2, len(listed), "vendors listados")
+			})
+		})
+
+		Scenario("Deve aguardar vendors removidos do host", func(s *bdd.Scenario) {
+			var removed []string
+			var pending []string
+			var err error
+			s.When("vendors deixam de ser detectados", func() {
+				detections := [][]string{{"newrelic", "dynatrace"}, {"dynatrace"}, {}}
+				calls := 0
+				detect := func() ([]string, error) {
+					detected := detections[min(calls, len(detections)-1)]
+					calls++
+					return detected, nil
+				}
+				pending, err = utils.WaitVendorsRemoved(context.Background(), []string{"newrelic", "dynatrace"}, detect, time.Millisecond, time.Second, func(vendor string) {
+					removed = append(removed, vendor)
+				})
+			})
+			s.Then("cada vendor deve ser concluído uma vez", func(t *testing.T) {
+				bdd.AssertNoError(t, err, "sem timeout")
+				bdd.AssertEqual(t, 0, len(pending), "sem pendentes")
+				bdd.AssertEqual(t, 2, len(removed), "vendors removidos")
+				bdd.AssertEqual(t, "newrelic", removed[0], "newrelic primeiro")
+			})
+		})
+
+		Scenario("Deve retornar pendentes no timeout", func(s *bdd.Scenario) {
+			var pending []string
+			var err error
+			s.When("vendor continua detectado", func() {
+				detect := func() ([]string, error) { return []string{"dynatrace"}, nil }
+				pending, err = utils.WaitVendorsRemoved(context.Background(), []string{"newrelic", "dynatrace"}, detect, time.Millisecond, time.Millisecond*20, func(string) {})
+			})
+			s.Then("vendor detectado deve ficar pendente", func(t *testing.T) {
+				bdd.AssertTrue(t, errors.Is(err, pkg.ErrVendorUninstallTimeout), "erro de timeout")
+				bdd.AssertEqual(t, 1, len(pending), "um pendente")
+				bdd.AssertEqual(t, "dynatrace", pending[0], "dynatrace pendente")
+			})
+		})
+	})
+}